@@ -0,0 +1,92 @@
+package abci_client
+
+import (
+	"bytes"
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+)
+
+// MaxAppHashHistoryHeights is the maximal number of heights that can be requested from AppHashHistory at once.
+const MaxAppHashHistoryHeights = 1000
+
+// AppHashEntry is the app hash after the block at a height.
+type AppHashEntry struct {
+	Height int64 `json:"height"`
+	// the app hash that was committed, which is empty if the block failed and was not committed
+	AppHash cmtbytes.HexBytes `json:"app_hash"`
+	// the app hashes of the individual apps, by validator address. Only set if they differed at this height
+	AppHashes map[string]cmtbytes.HexBytes `json:"app_hashes,omitempty"`
+}
+
+// recordAppHashes records the app hashes of the apps at the given height if they are not all equal,
+// so that the history shows which app diverged even if the block is never committed.
+// The clients and responses must be in the same order.
+func (a *AbciClient) recordAppHashes(height int64, clients []string, responses []*abcitypes.ResponseFinalizeBlock) {
+	a.appHashesMutex.Lock()
+	defer a.appHashesMutex.Unlock()
+
+	// a block that failed before may be executed again, e.g. after resuming, so forget about earlier attempts
+	delete(a.divergentAppHashes, height)
+
+	divergent := false
+	for _, response := range responses[1:] {
+		if !bytes.Equal(response.AppHash, responses[0].AppHash) {
+			divergent = true
+			break
+		}
+	}
+	if !divergent {
+		return
+	}
+
+	appHashes := make(map[string]cmtbytes.HexBytes, len(clients))
+	for i, client := range clients {
+		appHashes[client] = responses[i].AppHash
+	}
+	if a.divergentAppHashes == nil {
+		a.divergentAppHashes = make(map[int64]map[string]cmtbytes.HexBytes)
+	}
+	a.divergentAppHashes[height] = appHashes
+}
+
+// AppHashHistory returns the app hashes after the blocks with heights from from to to, inclusive.
+// If from is 0, the history starts at the initial height. If to is 0, it ends at the latest height,
+// or at the height of a later block that was not committed because the apps diverged.
+// At heights where the apps diverged, the app hash of each app is included.
+func (a *AbciClient) AppHashHistory(from, to int64) ([]AppHashEntry, error) {
+	a.appHashesMutex.RLock()
+	defer a.appHashesMutex.RUnlock()
+
+	if from == 0 {
+		from = a.CurState.InitialHeight
+	}
+	if to == 0 {
+		to = a.CurState.LastBlockHeight
+		for height := range a.divergentAppHashes {
+			to = max(to, height)
+		}
+	}
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("heights must not be negative, but got from %d and to %d", from, to)
+	}
+	if from > to {
+		return nil, fmt.Errorf("from %d must be less than or equal to to %d", from, to)
+	}
+	if to-from >= MaxAppHashHistoryHeights {
+		return nil, fmt.Errorf("at most %d heights can be requested at once, but got %d", MaxAppHashHistoryHeights, to-from+1)
+	}
+
+	entries := make([]AppHashEntry, 0)
+	for height := from; height <= to; height++ {
+		entry := AppHashEntry{Height: height, AppHashes: a.divergentAppHashes[height]}
+		if response, err := a.Storage.GetResponses(height); err == nil {
+			entry.AppHash = response.AppHash
+		} else if entry.AppHashes == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
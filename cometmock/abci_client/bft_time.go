@@ -0,0 +1,69 @@
+package abci_client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ParseClockSkews parses a comma-separated list of address=milliseconds pairs,
+// which give the offset of the clock of each validator from the clock of CometMock, see BFTTime.
+// The offsets may be negative for clocks that are behind, e.g. "ABCD...=1500,EF01...=-300".
+func ParseClockSkews(s string) (map[string]time.Duration, error) {
+	skews := make(map[string]time.Duration)
+	if s == "" {
+		return skews, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		address, milliseconds, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid clock skew %q, must be of the form address=milliseconds", pair)
+		}
+		skew, err := strconv.ParseInt(milliseconds, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clock skew %q, the skew must be a number of milliseconds", pair)
+		}
+		skews[strings.ToUpper(address)] = time.Duration(skew) * time.Millisecond
+	}
+	return skews, nil
+}
+
+// voteTime returns the timestamp of the vote of the validator with the given address for the block,
+// where clockTime is the time of the clock of CometMock while the block is voted on.
+// With BFTTime, like in CometBFT before proposer-based timestamps, this is the time of the clock
+// of the validator, i.e. clockTime plus its clock skew, but at least a millisecond after the time of the block.
+// Otherwise, votes have the time of the block.
+func (a *AbciClient) voteTime(address string, block *types.Block, clockTime time.Time) time.Time {
+	if !a.BFTTime {
+		return block.Time
+	}
+
+	validatorTime := clockTime.Add(a.ClockSkews[address])
+	minVoteTime := block.Time.Add(time.Millisecond)
+	if validatorTime.After(minVoteTime) {
+		return validatorTime
+	}
+	return minVoteTime
+}
+
+// medianTime returns the median of the timestamps of the votes in the last commit, weighted by voting power,
+// which is the time of the next block with BFTTime, like in CometBFT before proposer-based timestamps.
+// It returns false if there is no such time, e.g. for the first block, or if the votes in the last commit
+// were not timestamped by the clocks of the validators, e.g. because BFTTime was enabled after a restart.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) medianTime() (time.Time, bool) {
+	if a.LastCommit == nil || a.CurState.LastBlockHeight < a.CurState.InitialHeight {
+		return time.Time{}, false
+	}
+
+	median := state.MedianTime(a.LastCommit.ToCommit(), a.CurState.LastValidators)
+	if !median.After(a.LastBlock.Time) {
+		return time.Time{}, false
+	}
+	return median, true
+}
@@ -0,0 +1,150 @@
+package abci_client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that the median time is the median of the vote timestamps weighted by voting power,
+// and that there is none when the votes were not timestamped by the clocks of the validators.
+func TestMedianTime(t *testing.T) {
+	lastBlockTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	seconds := func(s int) time.Time {
+		return lastBlockTime.Add(time.Duration(s) * time.Second)
+	}
+
+	testCases := []struct {
+		name   string
+		powers []int64
+		// the timestamps of the votes, in the order of the validator set.
+		// Validators with the zero time are absent
+		voteTimes       []time.Time
+		lastBlockHeight int64
+		noLastCommit    bool
+		expected        time.Time
+		expectedOk      bool
+	}{
+		{
+			name:            "equal powers",
+			powers:          []int64{10, 10, 10},
+			voteTimes:       []time.Time{seconds(1), seconds(3), seconds(2)},
+			lastBlockHeight: 1,
+			expected:        seconds(2),
+			expectedOk:      true,
+		},
+		{
+			name:            "weighted by voting power",
+			powers:          []int64{60, 20, 20},
+			voteTimes:       []time.Time{seconds(5), seconds(1), seconds(2)},
+			lastBlockHeight: 1,
+			expected:        seconds(5),
+			expectedOk:      true,
+		},
+		{
+			name:            "absent validators are ignored",
+			powers:          []int64{40, 30, 30},
+			voteTimes:       []time.Time{{}, seconds(4), seconds(1)},
+			lastBlockHeight: 1,
+			expected:        seconds(1),
+			expectedOk:      true,
+		},
+		{
+			name:            "votes with the time of the block",
+			powers:          []int64{10, 10, 10},
+			voteTimes:       []time.Time{seconds(0), seconds(0), seconds(0)},
+			lastBlockHeight: 1,
+		},
+		{
+			name:            "first block",
+			powers:          []int64{10, 10, 10},
+			voteTimes:       []time.Time{seconds(1), seconds(2), seconds(3)},
+			lastBlockHeight: 0,
+		},
+		{
+			name:            "no last commit",
+			powers:          []int64{10, 10, 10},
+			lastBlockHeight: 1,
+			noLastCommit:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validators := make([]*types.Validator, len(tc.powers))
+			for i, power := range tc.powers {
+				validators[i] = types.NewValidator(testValidatorKey(i).PubKey(), power)
+			}
+			validatorSet := types.NewValidatorSet(validators)
+
+			client := &AbciClient{
+				CurState: state.State{
+					InitialHeight:   1,
+					LastBlockHeight: tc.lastBlockHeight,
+					LastValidators:  validatorSet,
+				},
+				LastBlock: &types.Block{Header: types.Header{Height: tc.lastBlockHeight, Time: lastBlockTime}},
+			}
+			if !tc.noLastCommit {
+				// the signatures are in the order of the validator set, which is sorted by voting power
+				commit := &types.ExtendedCommit{Height: tc.lastBlockHeight}
+				for i, validator := range validatorSet.Validators {
+					sig := types.ExtendedCommitSig{CommitSig: types.CommitSig{BlockIDFlag: types.BlockIDFlagAbsent}}
+					if !tc.voteTimes[i].IsZero() {
+						sig.CommitSig = types.CommitSig{
+							BlockIDFlag:      types.BlockIDFlagCommit,
+							ValidatorAddress: validator.Address,
+							Timestamp:        tc.voteTimes[i],
+						}
+					}
+					commit.ExtendedSignatures = append(commit.ExtendedSignatures, sig)
+				}
+				client.LastCommit = commit
+			}
+
+			median, ok := client.medianTime()
+			require.Equal(t, tc.expectedOk, ok)
+			if tc.expectedOk {
+				require.Equal(t, tc.expected, median)
+			}
+		})
+	}
+}
+
+// Tests that with BFTTime, votes have the time of the clock of the validator,
+// but at least a millisecond after the time of the block.
+func TestVoteTime(t *testing.T) {
+	blockTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	block := &types.Block{Header: types.Header{Time: blockTime}}
+	clockTime := blockTime.Add(time.Second)
+
+	client := &AbciClient{
+		ClockSkews: map[string]time.Duration{
+			"AHEAD":  500 * time.Millisecond,
+			"BEHIND": -2 * time.Second,
+		},
+	}
+	require.Equal(t, blockTime, client.voteTime("AHEAD", block, clockTime))
+
+	client.BFTTime = true
+	require.Equal(t, clockTime.Add(500*time.Millisecond), client.voteTime("AHEAD", block, clockTime))
+	require.Equal(t, blockTime.Add(time.Millisecond), client.voteTime("BEHIND", block, clockTime))
+	require.Equal(t, clockTime, client.voteTime("NO_SKEW", block, clockTime))
+}
+
+func TestParseClockSkews(t *testing.T) {
+	skews, err := ParseClockSkews("abcd=1500,EF01=-300")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{
+		"ABCD": 1500 * time.Millisecond,
+		"EF01": -300 * time.Millisecond,
+	}, skews)
+
+	_, err = ParseClockSkews("ABCD")
+	require.Error(t, err)
+	_, err = ParseClockSkews("ABCD=1.5s")
+	require.Error(t, err)
+}
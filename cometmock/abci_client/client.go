@@ -2,18 +2,25 @@ package abci_client
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/barkimedes/go-deepcopy"
 	db "github.com/cometbft/cometbft-db"
+	abciclient "github.com/cometbft/cometbft/abci/client"
 	abcitypes "github.com/cometbft/cometbft/abci/types"
 	cryptoenc "github.com/cometbft/cometbft/crypto/encoding"
 	"github.com/cometbft/cometbft/crypto/merkle"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
 	cometlog "github.com/cometbft/cometbft/libs/log"
 	cmtmath "github.com/cometbft/cometbft/libs/math"
+	"github.com/cometbft/cometbft/mempool"
 	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	"github.com/cometbft/cometbft/state"
 	blockindexkv "github.com/cometbft/cometbft/state/indexer/block/kv"
@@ -21,17 +28,11 @@ import (
 	indexerkv "github.com/cometbft/cometbft/state/txindex/kv"
 	"github.com/cometbft/cometbft/types"
 	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/failpoints"
 	"github.com/informalsystems/CometMock/cometmock/storage"
 	"github.com/informalsystems/CometMock/cometmock/utils"
 )
 
-var GlobalClient *AbciClient
-
-// store a mutex that allows only running one block at a time
-var blockMutex = sync.Mutex{}
-
-var verbose = false
-
 const ABCI_TIMEOUT = 2 * time.Second
 
 type MisbehaviourType int
@@ -43,15 +44,91 @@ const (
 	Equivocation
 )
 
+// ParseMisbehaviourType returns the misbehaviour type with the given name,
+// i.e. one of DuplicateVote, Lunatic, Amnesia, Equivocation.
+func ParseMisbehaviourType(name string) (MisbehaviourType, error) {
+	switch name {
+	case "DuplicateVote":
+		return DuplicateVote, nil
+	case "Lunatic":
+		return Lunatic, nil
+	case "Amnesia":
+		return Amnesia, nil
+	case "Equivocation":
+		return Equivocation, nil
+	default:
+		return 0, fmt.Errorf("unknown misbehaviour type %s, possible types are: DuplicateVote, Equivocation, Lunatic, Amnesia", name)
+	}
+}
+
+func (m MisbehaviourType) String() string {
+	switch m {
+	case DuplicateVote:
+		return "DuplicateVote"
+	case Lunatic:
+		return "Lunatic"
+	case Amnesia:
+		return "Amnesia"
+	case Equivocation:
+		return "Equivocation"
+	default:
+		return fmt.Sprintf("MisbehaviourType(%d)", int(m))
+	}
+}
+
+// ParseMisbehaviours parses a comma-separated list of validator=type pairs,
+// e.g. "ABCD...=DuplicateVote", into the misbehaviour types by validator.
+// The validators are returned as given, to be resolved with ResolveValidator.
+func ParseMisbehaviours(s string) (map[string]MisbehaviourType, error) {
+	misbehaviours := make(map[string]MisbehaviourType)
+	if strings.TrimSpace(s) == "" {
+		return misbehaviours, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		// validators may be given as base64 public keys, which can end with =
+		separator := strings.LastIndex(pair, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid misbehaviour %q, must be of the form validator=type", pair)
+		}
+		validator, name := pair[:separator], pair[separator+1:]
+		misbehaviourType, err := ParseMisbehaviourType(name)
+		if err != nil {
+			return nil, err
+		}
+		misbehaviours[validator] = misbehaviourType
+	}
+	return misbehaviours, nil
+}
+
 // hardcode max data bytes to -1 (unlimited) since we do not utilize a mempool
 // to pick evidence/txs out of
 const maxDataBytes = cmttypes.MaxBlockSizeBytes
 
+// when running many empty blocks at once, progress is logged every this many blocks
+const emptyBlocksLogInterval = 1000
+
+// the number of rounds to look ahead in the proposer rotation to find a proposer with an app
+const maxProposerSearchRounds = 1000
+
 // AbciClient facilitates calls to the ABCI interface of multiple nodes.
 // It also tracks the current state and a common logger.
 type AbciClient struct {
 	Clients map[string]AbciCounterpartyClient // maps validator addresses to their clients
 
+	// held while Clients is replaced, for readers that do not hold the blockMutex
+	clientsMutex sync.RWMutex
+
+	// allows only running one block at a time
+	blockMutex sync.Mutex
+
+	// the validator addresses of the clients, in the order in which the apps were given at startup
+	ClientOrder []string
+
+	// used to reconnect to the apps when resuming after a halt, see Resume
+	ConnectClient ClientConnector
+
 	Logger         cometlog.Logger
 	CurState       state.State
 	EventBus       types.EventBus
@@ -62,25 +139,150 @@ type AbciClient struct {
 	TxIndex        *indexerkv.TxIndex
 	BlockIndex     *blockindexkv.BlockerIndexer
 
-	// if this is true, then an error will be returned if the responses from the clients are not all equal.
+	// decides for each ABCI method what happens if the responses from the clients are not all equal.
 	// can be used to check for nondeterminism in apps, but also slows down execution a bit,
 	// though performance difference was not measured.
-	ErrorOnUnequalResponses bool
+	DeterminismChecks DeterminismChecks
+
+	// decides which apps queries are sent to. If this is empty, queries are sent to all apps
+	QueryMode QueryMode
+	// counts the queries, to rotate through the apps, see QueryModeRoundRobin
+	queryCounter atomic.Uint64
+	// if this is non-nil, responses to queries are cached
+	QueryCache *QueryCache
+
+	// queries that are sent to all apps after each block to compare their state, see InvariantQuery
+	InvariantQueries []InvariantQuery
+
+	// the number of blocks whose ABCI calls are still to be traced, see TraceBlocks
+	tracedBlocks atomic.Int64
+
+	// the failpoints that inject faults while blocks are produced and apps are called.
+	// If this is nil, no faults are injected
+	Failpoints *failpoints.Registry
+
+	// the last time the responses from the clients were not all equal, see LastDivergence
+	lastDivergence      *Divergence
+	lastDivergenceMutex sync.RWMutex
+
+	// the app hashes of the apps at the heights where they were not all equal, see AppHashHistory
+	divergentAppHashes map[int64]map[string]cmtbytes.HexBytes
+	appHashesMutex     sync.RWMutex
+
+	// The file from which the app addresses are read when the apps are reloaded, see ReloadAppsFromFile.
+	AppAddressesFile string
+
+	// the validator addresses of the apps that execute each block twice, see SetDoubleExecution
+	doubleExecution map[string]bool
+
+	// If this is set, the apps commit blocks in FinalizeBlock, like apps that speak the Tendermint v0.34 protocol,
+	// see legacy_abci.SocketClient. Blocks cannot be simulated then, see SimulateBlock.
+	CommitsInFinalizeBlock bool
+
+	// The time to sleep between producing blocks.
+	// If this is <= 0, blocks are only produced when instructed explicitly.
+	// This is only used for reporting, the blocks are produced by the caller of RunBlock.
+	BlockProductionInterval time.Duration
+
+	// If this is set, a random jitter is added to the time slept between blocks
+	// that are produced periodically, see BlockProductionInterval.
+	// This is only used for reporting, like BlockProductionInterval.
+	BlockProductionJitter *Jitter
+
+	// the apps that did not respond in time, see StartWatchdog
+	unresponsiveApps  map[string]UnresponsiveApp
+	unresponsiveMutex sync.RWMutex
+
+	// the results of the liveness probes, see StartLivenessProbes
+	liveness liveness
+
+	// the upgrade scheduled with ScheduleUpgrade, if any
+	upgrade scheduledUpgrade
+
+	// the vote extensions that replace those from ExtendVote, see OverrideVoteExtension
+	voteExtensionOverrides voteExtensionOverrides
+	// the validators whose vote extensions in the LastCommit were overridden
+	lastOverriddenExtensions map[string]bool
+
+	// the callbacks invoked while blocks are produced, see AddBlockHooks
+	blockHooks []BlockHooks
+
+	// the blocks from the last call of ProduceConflictingBlocks, or nil
+	lastConflictingBlocks *ConflictingBlocks
+
+	// the validator addresses by name, and the names by validator address, see SetValidatorNames
+	validatorNames          map[string]string
+	validatorNamesByAddress map[string]string
+	validatorNamesMutex     sync.RWMutex
+
+	// the rules by which validators misbehave automatically, and the seed of the random rules, see SetMisbehaviourRules
+	misbehaviourRules []MisbehaviourRule
+	misbehaviourSeed  int64
+
+	// the round in which blocks are committed unless another round is given, see SetCommitRound
+	commitRound int32
+
+	// validators whose keys are known, but that have no app, see AddPlaceholderValidator
+	placeholderValidators map[string]types.PrivValidator
+
+	// the apps that were not listening at startup, by validator address, see AddMissingApp
+	missingApps      map[string]MissingApp
+	missingAppsMutex sync.RWMutex
+	// the request that was sent to the apps with SendInitChain, to initialize apps that join later
+	initChainRequest *abcitypes.RequestInitChain
+	// the genesis that was sent to the apps with SendInitChain, to initialize them again when the chain is reset
+	genesisDoc *types.GenesisDoc
+
+	// the snapshots of the state that the chain can be rewound to, and how often they are taken, see SetSnapshotPolicy
+	snapshots         stateSnapshots
+	snapshotInterval  int64
+	snapshotRetention int
+
+	// how the chain was started after InitChain, to start it again when it is reset, see ResetChain.
+	// If this is nil, the chain cannot be reset.
+	ChainStart *ChainStart
+
+	// if this is non-nil, block production is halted, see Halted
+	halt      *HaltInfo
+	haltMutex sync.RWMutex
 
 	// validator addresses are mapped to false if they should not be signing, and to true if they should
 	signingStatus      map[string]bool
 	signingStatusMutex sync.RWMutex
+	// the validators that stopped signing because they left the validator set, see syncSigningStatus
+	removedValidators map[string]bool
+
+	// If this is non-empty, the validator with this address proposes all blocks.
+	// Otherwise, the proposer is selected by the proposer-priority rotation
+	// of CometBFT, see GetProposer.
+	FixedProposerAddress string
 
 	// The TimeHandler that will be queried
 	// to obtain the block timestamp for each block.
 	TimeHandler TimeHandler
 
+	// If this is true, the timestamp of each block is the median of the timestamps of the votes
+	// in the last commit, weighted by voting power, like in CometBFT before proposer-based timestamps.
+	// The votes are timestamped by the clocks of the validators, which are the time from
+	// the TimeHandler plus the skew of the validator from ClockSkews, see voteTime.
+	// Otherwise, blocks have the time from the TimeHandler, and so do the votes for them.
+	BFTTime bool
+
+	// The offsets of the clocks of the validators from the time of the TimeHandler, by validator address,
+	// see ParseClockSkews. This is only used with BFTTime.
+	ClockSkews map[string]time.Duration
+
 	// If this is true, then when broadcastTx is called,
 	// a block will automatically be produced immediately.
 	// If not, the transaction will be added to the TxQueue
 	// and consumed when the next block is created.
 	AutoIncludeTx bool
 
+	// If this is true, broadcast transactions are not sent to CheckTx,
+	// neither when they are received nor when they are rechecked before a block,
+	// and are included in the next block as they are.
+	SkipCheckTx bool
+
 	// A list of transactions that will be included in the next block that is created.
 	// When transaction FreshTxQueue[i] is included, it will be removed from the FreshTxQueue,
 	// and the result will be sent to ResponseChannelQueue[i].
@@ -89,11 +291,44 @@ type AbciClient struct {
 	StaleTxQueue []types.Tx
 }
 
+// The limits of the transaction queues, which are the defaults of the CometBFT mempool.
+const (
+	MaxTxBytes         = 1024 * 1024
+	MaxMempoolTxs      = 5000
+	MaxMempoolTxsBytes = 1024 * 1024 * 1024
+)
+
+// CheckMempoolLimits returns the same errors as the CometBFT mempool
+// if the transaction is too large, or if the transaction queues are full.
+func (a *AbciClient) CheckMempoolLimits(tx types.Tx) error {
+	if len(tx) > MaxTxBytes {
+		return mempool.ErrTxTooLarge{Max: MaxTxBytes, Actual: len(tx)}
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	numTxs := len(a.FreshTxQueue) + len(a.StaleTxQueue)
+	var txsBytes int64
+	for _, queued := range append(a.FreshTxQueue, a.StaleTxQueue...) {
+		txsBytes += int64(len(queued))
+	}
+	if numTxs >= MaxMempoolTxs || txsBytes+int64(len(tx)) > MaxMempoolTxsBytes {
+		return mempool.ErrMempoolIsFull{
+			NumTxs:      numTxs,
+			MaxTxs:      MaxMempoolTxs,
+			TxsBytes:    txsBytes,
+			MaxTxsBytes: MaxMempoolTxsBytes,
+		}
+	}
+	return nil
+}
+
 func (a *AbciClient) QueueTx(tx types.Tx) {
 	// lock the block mutex so txs are not queued while a block is being run
-	blockMutex.Lock()
+	a.blockMutex.Lock()
 	a.FreshTxQueue = append(a.FreshTxQueue, tx)
-	blockMutex.Unlock()
+	a.blockMutex.Unlock()
 }
 
 func (a *AbciClient) ClearTxs() {
@@ -101,8 +336,11 @@ func (a *AbciClient) ClearTxs() {
 	a.StaleTxQueue = make([]types.Tx, 0)
 }
 
+// CauseLightClientAttack produces a block with light client attack evidence for the validator,
+// which is given by its name or in any of the formats accepted by utils.ValidatorAddress.
 func (a *AbciClient) CauseLightClientAttack(address string, misbehaviourType string) error {
-	a.Logger.Info("Causing double sign", "address", address)
+	address = a.ResolveValidator(address)
+	a.Logger.Info("Causing double sign", "address", a.validatorLabel(address))
 
 	validator, err := a.GetValidatorFromAddress(address)
 	if err != nil {
@@ -112,12 +350,8 @@ func (a *AbciClient) CauseLightClientAttack(address string, misbehaviourType str
 	// get the misbehaviour type from the string
 	var misbehaviour MisbehaviourType
 	switch misbehaviourType {
-	case "Lunatic":
-		misbehaviour = Lunatic
-	case "Amnesia":
-		misbehaviour = Amnesia
-	case "Equivocation":
-		misbehaviour = Equivocation
+	case "Lunatic", "Amnesia", "Equivocation":
+		misbehaviour, _ = ParseMisbehaviourType(misbehaviourType)
 	default:
 		return fmt.Errorf("unknown misbehaviour type %s, possible types are: Equivocation, Lunatic, Amnesia", misbehaviourType)
 	}
@@ -126,8 +360,11 @@ func (a *AbciClient) CauseLightClientAttack(address string, misbehaviourType str
 	return err
 }
 
+// CauseDoubleSign produces a block with duplicate vote evidence for the validator,
+// which is given by its name or in any of the formats accepted by utils.ValidatorAddress.
 func (a *AbciClient) CauseDoubleSign(address string) error {
-	a.Logger.Info("Causing double sign", "address", address)
+	address = a.ResolveValidator(address)
+	a.Logger.Info("Causing double sign", "address", a.validatorLabel(address))
 
 	validator, err := a.GetValidatorFromAddress(address)
 	if err != nil {
@@ -147,6 +384,9 @@ func (a *AbciClient) GetValidatorFromAddress(address string) (*types.Validator,
 }
 
 func (a *AbciClient) GetCounterpartyFromAddress(address string) (*AbciCounterpartyClient, error) {
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
 	for _, client := range a.Clients {
 		if client.ValidatorAddress == address {
 			return &client, nil
@@ -155,6 +395,25 @@ func (a *AbciClient) GetCounterpartyFromAddress(address string) (*AbciCounterpar
 	return nil, fmt.Errorf("client with address %s not found", address)
 }
 
+// GetCounterpartyFromTarget returns the client selected by target, which is either
+// the validator address of the client (or the id of an observer) or its index in the order in which the apps were given at startup.
+// The validator address may be given by its name or in any of the formats accepted by utils.ValidatorAddress.
+func (a *AbciClient) GetCounterpartyFromTarget(target string) (*AbciCounterpartyClient, error) {
+	if index, err := strconv.Atoi(target); err == nil {
+		a.clientsMutex.RLock()
+		clientOrder := a.ClientOrder
+		a.clientsMutex.RUnlock()
+		if index < 0 || index >= len(clientOrder) {
+			return nil, fmt.Errorf("client index %d is out of range, there are %d clients", index, len(clientOrder))
+		}
+		target = clientOrder[index]
+	}
+	if client, err := a.GetCounterpartyFromAddress(target); err == nil {
+		return client, nil
+	}
+	return a.GetCounterpartyFromAddress(a.ResolveValidator(target))
+}
+
 // GetSigningStatusMap gets a copy of the signing status map that can be used for reading.
 func (a *AbciClient) GetSigningStatusMap() map[string]bool {
 	a.signingStatusMutex.RLock()
@@ -179,7 +438,11 @@ func (a *AbciClient) GetSigningStatus(address string) (bool, error) {
 	return status, nil
 }
 
+// SetSigningStatus sets whether the validator signs blocks.
+// The validator is given by its name or in any of the formats accepted by utils.ValidatorAddress.
 func (a *AbciClient) SetSigningStatus(address string, status bool) error {
+	address = a.ResolveValidator(address)
+
 	a.signingStatusMutex.Lock()
 	defer a.signingStatusMutex.Unlock()
 
@@ -188,8 +451,10 @@ func (a *AbciClient) SetSigningStatus(address string, status bool) error {
 		return fmt.Errorf("address %s not found in signing status map, please double-check this is the key address of a validator key", address)
 	}
 	a.signingStatus[address] = status
+	// an explicit status is kept when a validator comes back into the validator set
+	delete(a.removedValidators, address)
 
-	a.Logger.Info("Set signing status", "address", address, "status", status)
+	a.Logger.Info("Set signing status", "address", a.validatorLabel(address), "status", status)
 
 	return nil
 }
@@ -221,12 +486,17 @@ func NewAbciClient(
 	lastCommit *types.ExtendedCommit,
 	storage storage.Storage,
 	timeHandler TimeHandler,
-	errorOnUnequalResponses bool,
+	determinismChecks DeterminismChecks,
 ) *AbciClient {
 	signingStatus := make(map[string]bool)
-	for addr := range clients {
-		signingStatus[addr] = true
+	clientOrder := make([]string, 0, len(clients))
+	for addr, client := range clients {
+		if !client.Observer && client.ExecutorOf == "" {
+			signingStatus[addr] = true
+		}
+		clientOrder = append(clientOrder, addr)
 	}
+	sort.Strings(clientOrder)
 
 	eventBus, err := CreateAndStartEventBus(logger)
 	if err != nil {
@@ -241,20 +511,22 @@ func NewAbciClient(
 	}
 
 	return &AbciClient{
-		Clients:                 clients,
-		Logger:                  logger,
-		CurState:                curState,
-		EventBus:                *eventBus,
-		LastBlock:               lastBlock,
-		LastCommit:              lastCommit,
-		Storage:                 storage,
-		IndexerService:          indexerService,
-		TxIndex:                 txIndex,
-		BlockIndex:              blockIndex,
-		TimeHandler:             timeHandler,
-		ErrorOnUnequalResponses: errorOnUnequalResponses,
-		signingStatus:           signingStatus,
-		FreshTxQueue:            make([]types.Tx, 0),
+		Clients:           clients,
+		ClientOrder:       clientOrder,
+		Logger:            logger,
+		CurState:          curState,
+		EventBus:          *eventBus,
+		LastBlock:         lastBlock,
+		LastCommit:        lastCommit,
+		Storage:           storage,
+		IndexerService:    indexerService,
+		TxIndex:           txIndex,
+		BlockIndex:        blockIndex,
+		TimeHandler:       timeHandler,
+		DeterminismChecks: determinismChecks,
+		signingStatus:     signingStatus,
+		removedValidators: make(map[string]bool),
+		FreshTxQueue:      make([]types.Tx, 0),
 	}
 }
 
@@ -283,6 +555,116 @@ func NewAbciClient(
 // 	}
 // }
 
+// ClientStatus describes the status of the connection to the app of a validator.
+type ClientStatus struct {
+	NetworkAddress   string `json:"network_address"`
+	ValidatorAddress string `json:"validator_address"`
+	Signing          bool   `json:"signing"`
+	// whether the app is an observer, whose validator address is the id of the observer
+	Observer bool `json:"observer"`
+	// for executors, the validator whose identity the executor shares, see AddExecutor
+	ExecutorOf string `json:"executor_of,omitempty"`
+	// whether the app executes each block twice, see SetDoubleExecution
+	DoubleExecution bool `json:"double_execution"`
+	Connected       bool `json:"connected"`
+	// the error that caused the connection to fail, if any
+	Error string `json:"error,omitempty"`
+	// whether the app was not listening at startup and has not joined yet, see AddMissingApp
+	Missing bool `json:"missing,omitempty"`
+	// set if the app did not respond in time, see StartWatchdog
+	Unresponsive *UnresponsiveApp `json:"unresponsive,omitempty"`
+	// the result of the latest liveness probe of the app, if it was probed, see StartLivenessProbes
+	Liveness *AppLiveness `json:"liveness,omitempty"`
+}
+
+// GetClientStatuses returns the connection and signing status of all clients,
+// sorted by validator address.
+func (a *AbciClient) GetClientStatuses() []ClientStatus {
+	signingStatus := a.GetSigningStatusMap()
+	unresponsiveApps := a.GetUnresponsiveApps()
+	appLiveness := a.GetAppLiveness()
+
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
+	statuses := make([]ClientStatus, 0, len(a.Clients))
+	for _, client := range a.Clients {
+		status := ClientStatus{
+			NetworkAddress:   client.NetworkAddress,
+			ValidatorAddress: client.ValidatorAddress,
+			Signing:          signingStatus[client.ValidatorAddress],
+			Observer:         client.Observer,
+			ExecutorOf:       client.ExecutorOf,
+			DoubleExecution:  a.ExecutesTwice(client.ValidatorAddress),
+			Connected:        client.Client.IsRunning(),
+		}
+		if unresponsive, ok := unresponsiveApps[client.ValidatorAddress]; ok {
+			status.Unresponsive = &unresponsive
+		}
+		if liveness, ok := appLiveness[client.ValidatorAddress]; ok {
+			status.Liveness = &liveness
+		}
+		if err := client.Client.Error(); err != nil {
+			status.Connected = false
+			status.Error = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	for _, missing := range a.MissingApps() {
+		statuses = append(statuses, ClientStatus{
+			NetworkAddress:   missing.NetworkAddress,
+			ValidatorAddress: missing.ValidatorAddress,
+			Signing:          signingStatus[missing.ValidatorAddress],
+			Missing:          true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ValidatorAddress < statuses[j].ValidatorAddress
+	})
+	return statuses
+}
+
+// MempoolSize returns the number of transactions that wait to be included in a block.
+// While a block is produced, the transactions are being moved between the queues,
+// so it returns -1 instead of waiting, which would block for as long as the apps take to respond.
+func (a *AbciClient) MempoolSize() int {
+	if !a.blockMutex.TryLock() {
+		return -1
+	}
+	defer a.blockMutex.Unlock()
+
+	return len(a.FreshTxQueue) + len(a.StaleTxQueue)
+}
+
+// CommittedTxResult returns the result of executing the given transaction in the block at the given height,
+// including the events it emitted, or nil if the block does not contain the transaction.
+// The transaction is found by its index in the block, which is also its index in the results of FinalizeBlock.
+func (a *AbciClient) CommittedTxResult(height int64, tx types.Tx) (*abcitypes.TxResult, error) {
+	block, err := a.Storage.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	index := block.Data.Txs.Index(tx)
+	if index < 0 {
+		return nil, nil
+	}
+
+	responses, err := a.Storage.GetResponses(height)
+	if err != nil {
+		return nil, err
+	}
+	if index >= len(responses.TxResults) {
+		return nil, fmt.Errorf("block %d has %d transactions, but only %d results", height, len(block.Data.Txs), len(responses.TxResults))
+	}
+	return &abcitypes.TxResult{
+		Height: height,
+		Index:  uint32(index),
+		Tx:     tx,
+		Result: *responses.TxResults[index],
+	}, nil
+}
+
 func (a *AbciClient) SyncApp(startHeight int64, client AbciCounterpartyClient) error {
 	return nil
 }
@@ -295,14 +677,27 @@ func (e *ClientUnreachableError) Error() string {
 	return fmt.Sprintf("client at address %v is unavailable", e.Address)
 }
 
+// SignerError is returned when a validator fails to sign a vote,
+// e.g. because its remote signer is unreachable or too slow.
+type SignerError struct {
+	ValidatorAddress string
+	Err              error
+}
+
+func (e *SignerError) Error() string {
+	return fmt.Sprintf("validator %v failed to sign: %v", e.ValidatorAddress, e.Err)
+}
+
+func (e *SignerError) Unwrap() error {
+	return e.Err
+}
+
 func (a *AbciClient) SendAbciInfo() (*abcitypes.ResponseInfo, error) {
-	if verbose {
-		a.Logger.Info("Sending Info to clients")
-	}
+	a.Logger.Debug("Sending Info to clients")
 	// send Info to all clients and collect the responses
 	responses := make([]*abcitypes.ResponseInfo, 0)
 
-	for _, client := range a.Clients {
+	for _, client := range a.clientList() {
 		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
 		response, err := client.Client.Info(ctx, &abcitypes.RequestInfo{})
 		cancel()
@@ -314,24 +709,27 @@ func (a *AbciClient) SendAbciInfo() (*abcitypes.ResponseInfo, error) {
 		responses = append(responses, response)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return nil, fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	if err := checkResponsesEqual(a, "Info", responses); err != nil {
+		return nil, err
 	}
 
 	return responses[0], nil
 }
 
 func (a *AbciClient) SendInitChain(genesisState state.State, genesisDoc *types.GenesisDoc) error {
-	if verbose {
-		a.Logger.Info("Sending InitChain to clients")
-	}
+	a.Logger.Debug("Sending InitChain to clients")
 	// build the InitChain request
 	initChainRequest := CreateInitChainRequest(genesisState, genesisDoc)
+	a.initChainRequest = initChainRequest
+	a.genesisDoc = genesisDoc
+
+	// like the handshake of CometBFT, the headers carry the app version that the apps report in Info,
+	// unless InitChain returns consensus params, which set the app version instead
+	appVersion, err := a.appVersion()
+	if err != nil {
+		return err
+	}
+	a.CurState.Version.Consensus.App = appVersion
 
 	responses := make([]*abcitypes.ResponseInitChain, 0)
 
@@ -347,17 +745,12 @@ func (a *AbciClient) SendInitChain(genesisState state.State, genesisDoc *types.G
 		responses = append(responses, response)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	if err := checkResponsesEqual(a, "InitChain", responses); err != nil {
+		return err
 	}
 
 	// update the state
-	err := a.UpdateStateFromInit(responses[0])
+	err = a.UpdateStateFromInit(responses[0])
 	if err != nil {
 		return err
 	}
@@ -365,6 +758,36 @@ func (a *AbciClient) SendInitChain(genesisState state.State, genesisDoc *types.G
 	return nil
 }
 
+// appVersion returns the app version that the apps report in Info, which has to be the same for all apps.
+// Unlike SendAbciInfo, the other fields of the responses may differ, e.g. the versions of two builds of an app.
+func (a *AbciClient) appVersion() (uint64, error) {
+	appVersions := make(map[uint64][]string)
+	for _, client := range a.Clients {
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		response, err := client.Client.Info(ctx, &abcitypes.RequestInfo{})
+		cancel()
+
+		if err != nil {
+			return 0, fmt.Errorf("error getting the app version of %v: %w", client.NetworkAddress, err)
+		}
+		appVersions[response.AppVersion] = append(appVersions[response.AppVersion], client.NetworkAddress)
+	}
+
+	if len(appVersions) > 1 {
+		return 0, fmt.Errorf("the apps report different app versions in Info: %v", appVersions)
+	}
+	for appVersion := range appVersions {
+		return appVersion, nil
+	}
+	return 0, nil
+}
+
+// GenesisDoc returns the genesis that was sent to the apps with SendInitChain,
+// or nil if the apps were not initialized yet.
+func (a *AbciClient) GenesisDoc() *types.GenesisDoc {
+	return a.genesisDoc
+}
+
 func CreateInitChainRequest(genesisState state.State, genesisDoc *types.GenesisDoc) *abcitypes.RequestInitChain {
 	consensusParams := genesisState.ConsensusParams.ToProto()
 
@@ -394,7 +817,9 @@ func (a *AbciClient) UpdateStateFromInit(res *abcitypes.ResponseInitChain) error
 		a.CurState.AppHash = res.AppHash
 	}
 
-	// if response specified validators, update the validators, otherwise we keep the ones from the genesis file
+	// if response specified validators, update the validators, otherwise we keep the ones from the genesis file.
+	// like in CometBFT, the genesis may have no validators, e.g. for Cosmos SDK chains whose validators
+	// are created from the gentxs in the app state, in which case the app has to return them
 	if len(res.Validators) > 0 {
 		validators, err := types.PB2TM.ValidatorUpdates(res.Validators)
 		if err != nil {
@@ -404,7 +829,10 @@ func (a *AbciClient) UpdateStateFromInit(res *abcitypes.ResponseInitChain) error
 		a.CurState.LastValidators = types.NewValidatorSet(validators)
 		a.CurState.Validators = types.NewValidatorSet(validators)
 		a.CurState.NextValidators = types.NewValidatorSet(validators).CopyIncrementProposerPriority(1)
+	} else if a.CurState.Validators.IsNilOrEmpty() {
+		return errors.New("validator set is nil in genesis and still empty after InitChain")
 	}
+	a.logValidatorKeys()
 
 	// if response specified consensus params, update the consensus params, otherwise we keep the ones from the genesis file
 	if res.ConsensusParams != nil {
@@ -418,8 +846,43 @@ func (a *AbciClient) UpdateStateFromInit(res *abcitypes.ResponseInitChain) error
 	return nil
 }
 
+// logValidatorKeys logs which validators of the current validator set CometMock has the keys of,
+// which are matched by the addresses of their public keys.
+// Like a CometBFT node whose key is not in the validator set, an app whose key is not a validator
+// only follows the chain.
+func (a *AbciClient) logValidatorKeys() {
+	validatorAddresses := make(map[string]bool, a.CurState.Validators.Size())
+	for index, val := range a.CurState.Validators.Validators {
+		address := val.Address.String()
+		validatorAddresses[address] = true
+		if a.HasClient(address) || a.placeholderClient(address) != nil {
+			a.Logger.Info("Found the key of a validator", "validator", a.validatorLabel(address), "index", index, "power", val.VotingPower)
+		}
+	}
+
+	numKeys := 0
+	for _, address := range a.ClientOrder {
+		if a.Clients[address].Observer || a.Clients[address].ExecutorOf != "" {
+			continue
+		}
+		if validatorAddresses[address] {
+			numKeys++
+		} else {
+			a.Logger.Info("The key of an app is not in the validator set, the app only follows the chain", "address", address)
+		}
+	}
+	if numKeys == 0 {
+		a.Logger.Error("None of the apps has the key of a validator, so no blocks can be proposed")
+	}
+}
+
 func (a *AbciClient) SendCommit() (*abcitypes.ResponseCommit, error) {
 	a.Logger.Info("Sending Commit to clients")
+	a.Failpoints.Sleep(failpoints.CommitSlow)
+	if a.Failpoints.Drop(failpoints.CommitDrop) {
+		a.Logger.Error("Not sending Commit to clients", "failpoint", failpoints.CommitDrop)
+		return &abcitypes.ResponseCommit{}, nil
+	}
 	// send Commit to all clients and collect the responses
 
 	responses := make([]*abcitypes.ResponseCommit, 0)
@@ -430,18 +893,13 @@ func (a *AbciClient) SendCommit() (*abcitypes.ResponseCommit, error) {
 		cancel()
 
 		if err != nil {
-			return nil, err
+			return nil, &AppError{Method: "Commit", App: client.NetworkAddress, Err: err}
 		}
 		responses = append(responses, response)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return nil, fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	if err := checkResponsesEqual(a, "Commit", responses); err != nil {
+		return nil, err
 	}
 
 	return responses[0], nil
@@ -454,10 +912,14 @@ func (a *AbciClient) SendCheckTx(checkType abcitypes.CheckTxType, tx *[]byte) (*
 		Type: checkType,
 	}
 
+	if err := a.Failpoints.Error(failpoints.CheckTxError); err != nil {
+		return nil, err
+	}
+
 	// send CheckTx to all clients and collect the responses
 	responses := make([]*abcitypes.ResponseCheckTx, 0)
 
-	for _, client := range a.Clients {
+	for _, client := range a.clientList() {
 		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
 		response, err := client.Client.CheckTx(ctx, &checkTxRequest)
 		cancel()
@@ -469,19 +931,48 @@ func (a *AbciClient) SendCheckTx(checkType abcitypes.CheckTxType, tx *[]byte) (*
 		responses = append(responses, response)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return nil, fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	if err := checkResponsesEqual(a, "CheckTx", responses); err != nil {
+		return nil, err
 	}
 
 	return responses[0], nil
 }
 
+// SendAbciQuery sends the query to the apps selected by the QueryMode.
+// When sending the query to all apps, the response is only returned if all apps respond the same.
+// If the QueryCache is set, cached responses are returned without sending the query.
 func (a *AbciClient) SendAbciQuery(data []byte, path string, height int64, prove bool) (*abcitypes.ResponseQuery, error) {
+	if a.QueryCache == nil {
+		return a.sendAbciQuery(data, path, height, prove)
+	}
+
+	// the last height and the generation are taken together under the blockMutex,
+	// so that both are from before the query is sent, and no block is committed in between
+	a.blockMutex.Lock()
+	lastHeight := a.CurState.LastBlockHeight
+	response, generation, ok := a.QueryCache.Get(data, path, height, prove)
+	a.blockMutex.Unlock()
+	if ok {
+		return response, nil
+	}
+
+	response, err := a.sendAbciQuery(data, path, height, prove)
+	if err != nil {
+		return nil, err
+	}
+	a.QueryCache.Put(data, path, height, prove, generation, lastHeight, response)
+	return response, nil
+}
+
+func (a *AbciClient) sendAbciQuery(data []byte, path string, height int64, prove bool) (*abcitypes.ResponseQuery, error) {
+	if err := a.Failpoints.Error(failpoints.QueryError); err != nil {
+		return nil, err
+	}
+
+	if a.QueryMode != "" && a.QueryMode != QueryModeAll {
+		return a.sendAbciQueryBalanced(data, path, height, prove)
+	}
+
 	// build the Query request
 	request := abcitypes.RequestQuery{
 		Data:   data,
@@ -492,7 +983,7 @@ func (a *AbciClient) SendAbciQuery(data []byte, path string, height int64, prove
 
 	responses := make([]*abcitypes.ResponseQuery, 0)
 
-	for _, client := range a.Clients {
+	for _, client := range a.clientList() {
 		// send Query to all clients and collect the responses
 		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
 		defer cancel()
@@ -504,25 +995,54 @@ func (a *AbciClient) SendAbciQuery(data []byte, path string, height int64, prove
 		responses = append(responses, response)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return nil, fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	if err := checkResponsesEqual(a, "Query", responses); err != nil {
+		return nil, err
 	}
 
 	return responses[0], nil
 }
 
+// SendAbciQueryToClient sends the query only to the given client,
+// e.g. to compare the responses of specific apps.
+func (a *AbciClient) SendAbciQueryToClient(client *AbciCounterpartyClient, data []byte, path string, height int64, prove bool) (*abcitypes.ResponseQuery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	defer cancel()
+	return client.Client.Query(ctx, &abcitypes.RequestQuery{
+		Data:   data,
+		Path:   path,
+		Height: height,
+		Prove:  prove,
+	})
+}
+
 // RunEmptyBlocks runs a specified number of empty blocks through ABCI.
 func (a *AbciClient) RunEmptyBlocks(numBlocks int) error {
+	return a.RunEmptyBlocksWithOptions(numBlocks, BlockOptions{})
+}
+
+// RunEmptyBlocksWithOptions runs a specified number of empty blocks through ABCI,
+// producing each of them with the given options.
+// The blocks are produced back to back without releasing the lock in between,
+// which makes advancing many blocks at once considerably faster.
+// Set opts.SkipEvents to also skip indexing the blocks.
+func (a *AbciClient) RunEmptyBlocksWithOptions(numBlocks int, opts BlockOptions) error {
+	a.Logger.Debug("Locking mutex")
+	a.blockMutex.Lock()
+	defer func() {
+		a.blockMutex.Unlock()
+		a.Logger.Debug("Unlocking mutex")
+	}()
+
+	start := time.Now()
 	for i := 0; i < numBlocks; i++ {
-		err := a.RunBlock()
+		err := a.runBlockLocked(opts)
 		if err != nil {
 			return err
 		}
+
+		if (i+1)%emptyBlocksLogInterval == 0 {
+			a.Logger.Info("Advancing blocks", "done", i+1, "total", numBlocks, "height", a.CurState.LastBlockHeight, "elapsed", time.Since(start))
+		}
 	}
 	return nil
 }
@@ -617,8 +1137,15 @@ func (a *AbciClient) CreateProposalBlock(
 	response, err := proposerApp.Client.PrepareProposal(ctx, request)
 	cancel()
 	if err != nil {
-		// We panic, since there is no meaninful recovery we can perform here.
-		panic(err)
+		// the block is not produced, which leaves the state untouched.
+		// this happens e.g. when the app halts for an upgrade, see RunBlockWithOptions
+		return nil, fmt.Errorf("error from PrepareProposal: %w", err)
+	}
+	err = checkExecutors(a, "PrepareProposal", proposerApp, response, func(ctx context.Context, client abciclient.Client) (*abcitypes.ResponsePrepareProposal, error) {
+		return client.PrepareProposal(ctx, request)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	modifiedTxs := response.GetTxs()
@@ -630,47 +1157,226 @@ func (a *AbciClient) CreateProposalBlock(
 	return curState.MakeBlock(height, txl, commit, *misbehaviour, block.ProposerAddress), nil
 }
 
+// GetProposer returns the validator that proposes the next block.
+// Unless FixedProposerAddress is set, this is the proposer of the current validator set,
+// which rotates according to the proposer priorities, exactly like in CometBFT.
+// The priorities are incremented once per block when the state is updated, see UpdateState.
+// Validators without an app never propose. If the proposer has no app, the proposer
+// of the next round that has an app is selected, like in CometBFT, where the other
+// validators move to the next round when the proposer is offline, and the block is committed in that round.
+func (a *AbciClient) GetProposer() (*types.Validator, error) {
+	return a.GetProposerForRound(0)
+}
+
+// GetProposerForRound returns the validator that proposes the next block in the given round.
+// Like in CometBFT, the proposer of round r is the proposer after incrementing the proposer priorities
+// of the current validator set r times. See GetProposer.
+func (a *AbciClient) GetProposerForRound(round int32) (*types.Validator, error) {
+	proposer, _, err := a.getProposerAndRound(round)
+	return proposer, err
+}
+
+// getProposerAndRound returns the validator that proposes the next block, starting from the given round,
+// and the round in which it proposes, which is later than the given round if proposers without an app are skipped.
+func (a *AbciClient) getProposerAndRound(round int32) (*types.Validator, int32, error) {
+	if a.FixedProposerAddress != "" {
+		proposer, err := a.GetValidatorFromAddress(a.FixedProposerAddress)
+		if err == nil {
+			return proposer, round, nil
+		}
+		// like validators that stop signing when they leave the validator set, the fixed proposer
+		// stops proposing, and the proposer rotates until it is back in the validator set
+		a.Logger.Info("Fixed proposer is not in the validator set, selecting the proposer by rotation", "address", a.validatorLabel(a.FixedProposerAddress))
+	}
+
+	validators := a.CurState.Validators
+	if validators.IsNilOrEmpty() {
+		return nil, 0, fmt.Errorf("could not determine the proposer, the validator set is empty")
+	}
+	if round > 0 {
+		validators = validators.CopyIncrementProposerPriority(round)
+	}
+	proposer := validators.GetProposer()
+
+	for i := 1; !a.HasClient(proposer.Address.String()); i++ {
+		if i > maxProposerSearchRounds {
+			return nil, 0, fmt.Errorf("could not find a proposer with an app in %d rounds", maxProposerSearchRounds)
+		}
+		if i == 1 && round == 0 {
+			validators = validators.Copy()
+		}
+		validators.IncrementProposerPriority(1)
+		proposer = validators.GetProposer()
+		round++
+	}
+	return proposer, round, nil
+}
+
+// LastBlockHeight returns the height of the last block.
+// Unlike reading CurState, it is safe while blocks are produced, since the state is updated under the storage lock.
+func (a *AbciClient) LastBlockHeight() int64 {
+	a.Storage.LockBeforeStateUpdate()
+	defer a.Storage.UnlockAfterStateUpdate()
+
+	return a.CurState.LastBlockHeight
+}
+
+// NextValidators returns the height of the next block and a copy of the validators that sign it, see LastBlockHeight.
+func (a *AbciClient) NextValidators() (int64, *types.ValidatorSet) {
+	a.Storage.LockBeforeStateUpdate()
+	defer a.Storage.UnlockAfterStateUpdate()
+
+	return a.CurState.LastBlockHeight + 1, a.CurState.Validators.Copy()
+}
+
+// HasClient returns whether CometMock is connected to an app for the validator with the given address.
+// Validators without an app, e.g. the validators of a live chain that CometMock was bootstrapped from,
+// do not propose, do not process proposals and never sign, unless they are placeholder validators,
+// see AddPlaceholderValidator.
+func (a *AbciClient) HasClient(address string) bool {
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
+	_, ok := a.Clients[address]
+	return ok
+}
+
+// clientList returns the clients, for requests that are sent outside of block production.
+// The clients are replaced when apps are reloaded or reconnected, so they are copied under the clientsMutex.
+func (a *AbciClient) clientList() []AbciCounterpartyClient {
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
+	clients := make([]AbciCounterpartyClient, 0, len(a.Clients))
+	for _, client := range a.Clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// BlockOptions overrides how a block is produced.
+// The zero value produces a block like RunBlock.
+type BlockOptions struct {
+	// The timestamp of the block. If this is the zero time,
+	// the timestamp is decided by the TimeHandler.
+	Time time.Time
+
+	// The proposer of the block. If this is nil, the proposer is selected by GetProposerForRound.
+	Proposer *types.Validator
+
+	// The round in which the block is proposed and committed.
+	// If this is 0, the block is committed in the round given with SetCommitRound.
+	Round int32
+
+	// If this is non-nil, exactly these transactions are proposed,
+	// instead of the transactions waiting to be included, which are left untouched.
+	// The transactions are not checked with CheckTx, but PrepareProposal may still modify them.
+	Txs *types.Txs
+
+	// Evidence of misbehaviour to include in the block, constructed for the given validators.
+	// If this is nil, evidence is constructed for the validators that misbehave by the rules, see SetMisbehaviourRules.
+	MisbehavingValidators map[*types.Validator]MisbehaviourType
+
+	// If this is non-nil, exactly the validators with these addresses sign the block,
+	// regardless of their signing status.
+	Signers []string
+
+	// If this is true, no events are published for the block,
+	// so it is neither indexed nor sent to subscribers.
+	SkipEvents bool
+}
+
+// SetCommitRound sets the round in which blocks are proposed and committed unless another round is given
+// in their BlockOptions, so that apps observe commits with that round in DecidedLastCommit.
+// Like in CometBFT, the proposer of a later round is the proposer after the priorities were incremented once per round.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) SetCommitRound(round int32) error {
+	if round < 0 {
+		return fmt.Errorf("the commit round must not be negative, but got %d", round)
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	a.commitRound = round
+	return nil
+}
+
+// CommitRound returns the round in which blocks are committed unless another round is given, see SetCommitRound.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) CommitRound() int32 {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	return a.commitRound
+}
+
 // RunBlock runs a block with a specified transaction through the ABCI application.
-// It calls RunBlockWithTimeAndProposer with the current time and the LastValidators.Proposer.
+// It calls RunBlockWithTimeAndProposer with the current time and the proposer selected by GetProposer.
 func (a *AbciClient) RunBlock() error {
-	blockTime := a.TimeHandler.GetBlockTime(a.LastBlock.Time)
-	return a.RunBlockWithTimeAndProposer(blockTime, a.CurState.LastValidators.Proposer, make(map[*types.Validator]MisbehaviourType, 0))
+	return a.RunBlockWithOptions(BlockOptions{})
 }
 
 func (a *AbciClient) RunBlockWithTime(t time.Time) error {
-	return a.RunBlockWithTimeAndProposer(t, a.CurState.LastValidators.Proposer, make(map[*types.Validator]MisbehaviourType, 0))
+	return a.RunBlockWithOptions(BlockOptions{Time: t})
 }
 
 // RunBlockWithEvidence runs a block with a specified transaction through the ABCI application.
 // It also produces the specified evidence for the specified misbehaving validators.
 func (a *AbciClient) RunBlockWithEvidence(misbehavingValidators map[*types.Validator]MisbehaviourType) error {
-	blockTime := a.TimeHandler.GetBlockTime(a.LastBlock.Time)
-	return a.RunBlockWithTimeAndProposer(blockTime, a.CurState.LastValidators.Proposer, misbehavingValidators)
+	return a.RunBlockWithOptions(BlockOptions{MisbehavingValidators: misbehavingValidators})
 }
 
-func (a *AbciClient) ConstructDuplicateVoteEvidence(v *types.Validator) (*types.DuplicateVoteEvidence, error) {
-	privVal := a.Clients[v.Address.String()].PrivValidator
+// evidenceBase returns the height, block id, time and validators that evidence refers to,
+// which are those of the last block.
+// Before the first block, there is no last block, so evidence refers to the initial height
+// (i.e. to votes in an earlier round of the first block), with an empty block id,
+// the genesis time and the genesis validators.
+func (a *AbciClient) evidenceBase() (int64, types.BlockID, time.Time, *types.ValidatorSet, error) {
 	lastBlock := a.LastBlock
+	if lastBlock == nil || lastBlock.Height == 0 {
+		return a.CurState.InitialHeight, types.BlockID{}, a.CurState.LastBlockTime, a.CurState.Validators, nil
+	}
+
 	blockId, err := utils.GetBlockIdFromBlock(lastBlock)
 	if err != nil {
-		return nil, err
+		return 0, types.BlockID{}, time.Time{}, nil, err
 	}
 
 	lastState, err := a.Storage.GetState(lastBlock.Height)
+	if err != nil {
+		return 0, types.BlockID{}, time.Time{}, nil, err
+	}
+	return lastBlock.Height, *blockId, lastBlock.Time, lastState.Validators, nil
+}
+
+// ConstructDuplicateVoteEvidence constructs evidence that the given validator signed two votes for the last block.
+// Before the first block, the votes are nil votes at the initial height, so that
+// evidence can be included in the first block.
+func (a *AbciClient) ConstructDuplicateVoteEvidence(v *types.Validator) (*types.DuplicateVoteEvidence, error) {
+	client, ok := a.Clients[v.Address.String()]
+	if !ok {
+		return nil, fmt.Errorf("cannot double sign for validator %v, its key is not known", v.Address.String())
+	}
+	privVal := client.PrivValidator
+	height, blockId, timestamp, validators, err := a.evidenceBase()
 	if err != nil {
 		return nil, err
 	}
 
 	// get the index of the validator in the last state
-	index, valInLastState := lastState.Validators.GetByAddress(v.Address)
+	index, valInLastState := validators.GetByAddress(v.Address)
+	if valInLastState == nil {
+		return nil, fmt.Errorf("validator %v was not a validator at height %d", v.Address.String(), height)
+	}
 
 	// produce vote A.
 	voteA := &cmtproto.Vote{
 		ValidatorAddress: v.Address,
 		ValidatorIndex:   int32(index),
-		Height:           lastBlock.Height,
+		Height:           height,
 		Round:            1,
-		Timestamp:        lastBlock.Time,
+		Timestamp:        timestamp,
 		Type:             cmtproto.PrecommitType,
 		BlockID:          blockId.ToProto(),
 	}
@@ -680,9 +1386,9 @@ func (a *AbciClient) ConstructDuplicateVoteEvidence(v *types.Validator) (*types.
 	voteB := &cmtproto.Vote{
 		ValidatorAddress: v.Address,
 		ValidatorIndex:   int32(index),
-		Height:           lastBlock.Height,
+		Height:           height,
 		Round:            2, // this is what differentiates the votes
-		Timestamp:        lastBlock.Time,
+		Timestamp:        timestamp,
 		Type:             cmtproto.PrecommitType,
 		BlockID:          blockId.ToProto(),
 	}
@@ -721,9 +1427,9 @@ func (a *AbciClient) ConstructDuplicateVoteEvidence(v *types.Validator) (*types.
 		VoteA: convertedVoteA,
 		VoteB: convertedVoteB,
 
-		TotalVotingPower: lastState.Validators.TotalVotingPower(),
+		TotalVotingPower: validators.TotalVotingPower(),
 		ValidatorPower:   valInLastState.VotingPower,
-		Timestamp:        lastBlock.Time,
+		Timestamp:        timestamp,
 	}
 	return &evidence, nil
 }
@@ -733,6 +1439,9 @@ func (a *AbciClient) ConstructLightClientAttackEvidence(
 	misbehaviourType MisbehaviourType,
 ) (*types.LightClientAttackEvidence, error) {
 	lastBlock := a.LastBlock
+	if lastBlock == nil || lastBlock.Height == 0 {
+		return nil, errors.New("cannot construct light client attack evidence before the first block, since there is no block to conflict with")
+	}
 
 	lastState, err := a.Storage.GetState(lastBlock.Height)
 	if err != nil {
@@ -791,22 +1500,29 @@ func (a *AbciClient) ProcessProposal(
 	timeoutContext, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
 	defer cancel()
 
-	response, err := app.Client.ProcessProposal(timeoutContext, &abcitypes.RequestProcessProposal{
+	request := &abcitypes.RequestProcessProposal{
 		Hash:               block.Header.Hash(),
 		Height:             block.Header.Height,
 		Time:               block.Header.Time,
 		Txs:                block.Data.Txs.ToSliceOfBytes(),
-		ProposedLastCommit: utils.BuildLastCommitInfo(block, a.CurState.Validators, a.CurState.InitialHeight),
+		ProposedLastCommit: utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight),
 		Misbehavior:        block.Evidence.Evidence.ToABCI(),
 		ProposerAddress:    block.ProposerAddress,
 		NextValidatorsHash: block.NextValidatorsHash,
-	})
+	}
+	response, err := app.Client.ProcessProposal(timeoutContext, request)
 	if err != nil {
 		return false, err
 	}
 	if response.IsStatusUnknown() {
 		panic(fmt.Sprintf("ProcessProposal responded with status %s", response.Status.String()))
 	}
+	err = checkExecutors(a, "ProcessProposal", app, response, func(ctx context.Context, client abciclient.Client) (*abcitypes.ResponseProcessProposal, error) {
+		return client.ProcessProposal(ctx, request)
+	})
+	if err != nil {
+		return false, err
+	}
 
 	return response.IsAccepted(), nil
 }
@@ -815,7 +1531,23 @@ func (a *AbciClient) ExtendAndSignVote(
 	app *AbciCounterpartyClient,
 	validator *types.Validator,
 	valIndex int32,
+	round int32,
+	block *types.Block,
+) (*types.Vote, error) {
+	return a.extendAndSignVote(app, validator, valIndex, round, block, block.Time, nil, false)
+}
+
+// extendAndSignVote is like ExtendAndSignVote, but the vote has the given timestamp, and if overrideExtension is true,
+// extensionOverride is used as the vote extension instead of calling ExtendVote.
+func (a *AbciClient) extendAndSignVote(
+	app *AbciCounterpartyClient,
+	validator *types.Validator,
+	valIndex int32,
+	round int32,
 	block *types.Block,
+	timestamp time.Time,
+	extensionOverride []byte,
+	overrideExtension bool,
 ) (*types.Vote, error) {
 	// get the index of this validator in the current validator set
 	blockParts, err := block.MakePartSet(types.BlockPartSizeBytes)
@@ -827,8 +1559,8 @@ func (a *AbciClient) ExtendAndSignVote(
 		ValidatorAddress: validator.Address,
 		ValidatorIndex:   int32(valIndex),
 		Height:           block.Height,
-		Round:            block.LastCommit.Round,
-		Timestamp:        block.Time,
+		Round:            round,
+		Timestamp:        timestamp,
 		Type:             cmtproto.PrecommitType,
 		BlockID: types.BlockID{
 			Hash:          block.Hash(),
@@ -836,35 +1568,47 @@ func (a *AbciClient) ExtendAndSignVote(
 		},
 	}
 
-	if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(vote.Height) {
-		ext, err := app.Client.ExtendVote(context.TODO(), &abcitypes.RequestExtendVote{
+	if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(vote.Height) && overrideExtension {
+		vote.Extension = extensionOverride
+	} else if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(vote.Height) {
+		request := &abcitypes.RequestExtendVote{
 			Hash:               vote.BlockID.Hash,
 			Height:             vote.Height,
 			Time:               block.Time,
 			Txs:                block.Txs.ToSliceOfBytes(),
-			ProposedLastCommit: utils.BuildLastCommitInfo(block, a.CurState.Validators, a.CurState.InitialHeight),
+			ProposedLastCommit: utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight),
 			Misbehavior:        block.Evidence.Evidence.ToABCI(),
 			NextValidatorsHash: block.NextValidatorsHash,
 			ProposerAddress:    block.ProposerAddress,
-		})
+		}
+		ext, err := app.Client.ExtendVote(context.TODO(), request)
 		if err != nil {
 			return nil, fmt.Errorf("error extending vote %v:\n %v", vote.String(), err)
 		}
+		err = checkExecutors(a, "ExtendVote", app, ext, func(ctx context.Context, client abciclient.Client) (*abcitypes.ResponseExtendVote, error) {
+			return client.ExtendVote(ctx, request)
+		})
+		if err != nil {
+			return nil, err
+		}
 		vote.Extension = ext.VoteExtension
 	}
 	// going through ToProto looks weird but this is
 	// how signing is done in CometBFT https://github.com/cometbft/cometbft/blob/f63499c82c7defcdd82696f262f5a2eb495a3ac7/types/vote.go#L405
 	protoVote := vote.ToProto()
 	err = app.PrivValidator.SignVote(a.CurState.ChainID, protoVote)
+	if err != nil {
+		return nil, &SignerError{
+			ValidatorAddress: validator.Address.String(),
+			Err:              fmt.Errorf("error signing vote %v:\n %v", vote.String(), err),
+		}
+	}
 	vote.Signature = protoVote.Signature
 
 	vote.ExtensionSignature = nil
 	if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(vote.Height) {
 		vote.ExtensionSignature = protoVote.ExtensionSignature
 	}
-	if err != nil {
-		return nil, fmt.Errorf("error signing vote %v:\n %v", vote.String(), err)
-	}
 	return vote, nil
 }
 
@@ -886,51 +1630,59 @@ func (a *AbciClient) SendFinalizeBlock(
 		NextValidatorsHash: block.NextValidatorsHash,
 	}
 
+	a.Failpoints.Sleep(failpoints.FinalizeSlow)
+	// the failpoint fails the call before it is sent to any app, so that the apps do not diverge,
+	// and the block can be tried again
+	if err := a.Failpoints.Error(failpoints.FinalizeError); err != nil {
+		return nil, err
+	}
+
 	// send FinalizeBlock to all clients and collect the responses
 	responses := make([]*abcitypes.ResponseFinalizeBlock, 0)
+	respondingClients := make([]string, 0)
 	for _, client := range a.Clients {
-		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		ctx, cancel := context.WithTimeout(utils.ContextWithHeader(context.Background(), &block.Header), ABCI_TIMEOUT)
 		response, err := client.Client.FinalizeBlock(ctx, &request)
 		cancel()
 		if err != nil {
-			return nil, err
+			return nil, &AppError{Method: "FinalizeBlock", App: client.NetworkAddress, Err: err}
+		}
+		if a.ExecutesTwice(client.ValidatorAddress) {
+			response, err = a.finalizeBlockAgain(client, &request, response)
+			if err != nil {
+				return nil, err
+			}
 		}
 		responses = append(responses, response)
+		respondingClients = append(respondingClients, client.ValidatorAddress)
 	}
 
-	if a.ErrorOnUnequalResponses {
-		// return an error if the responses are not all equal
-		for i := 1; i < len(responses); i++ {
-			if !reflect.DeepEqual(responses[i], responses[0]) {
-				return nil, fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
-			}
-		}
+	a.recordAppHashes(block.Height, respondingClients, responses)
+
+	if err := checkResponsesEqual(a, "FinalizeBlock", responses); err != nil {
+		return nil, &FinalizedBlockError{Height: block.Height, Reason: HaltReasonAppsDiverged, Err: err}
+	}
+
+	if err := checkUpgradeConvergence(a, block.Height, responses); err != nil {
+		return nil, err
 	}
 
 	return responses[0], nil
 }
 
-// internal method that runs a block.
-// Should only be used after locking the blockMutex.
-func (a *AbciClient) runBlock_helper(
-	blockTime time.Time,
-	proposer *types.Validator,
-	misbehavingValidators map[*types.Validator]MisbehaviourType,
-) error {
-	a.Logger.Info("Running block")
-	if verbose {
-		a.Logger.Info("State at start of block", "state", a.CurState)
+// recheckTxQueues runs CheckTx for the transactions waiting to be included
+// and returns the transactions that passed it.
+// If SkipCheckTx is set, all transactions are returned without checking them.
+func (a *AbciClient) recheckTxQueues() ([]cmttypes.Tx, error) {
+	if a.SkipCheckTx {
+		return append(append(make([]cmttypes.Tx, 0), a.FreshTxQueue...), a.StaleTxQueue...), nil
 	}
 
-	newHeight := a.CurState.LastBlockHeight + 1
-
-	var err error
-
 	for index, tx := range a.FreshTxQueue {
 		txBytes := []byte(tx)
 		resCheckTx, err := a.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
 		if err != nil {
-			return fmt.Errorf("error from CheckTx: %v", err)
+			return nil, fmt.Errorf("error from CheckTx: %v", err)
 		}
 		// if the CheckTx code is != 0
 		if resCheckTx.Code != abcitypes.CodeTypeOK {
@@ -944,7 +1696,7 @@ func (a *AbciClient) runBlock_helper(
 		txBytes := []byte(tx)
 		resCheckTx, err := a.SendCheckTx(abcitypes.CheckTxType_Recheck, &txBytes)
 		if err != nil {
-			return fmt.Errorf("error from CheckTx: %v", err)
+			return nil, fmt.Errorf("error from CheckTx: %v", err)
 		}
 		// if the CheckTx code is != 0
 		if resCheckTx.Code != abcitypes.CodeTypeOK {
@@ -961,11 +1713,68 @@ func (a *AbciClient) runBlock_helper(
 			newTxQueue = append(newTxQueue, tx)
 		}
 	}
+	return newTxQueue, nil
+}
+
+// internal method that runs a block.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) runBlock_helper(opts BlockOptions) error {
+	a.Logger.Info("Running block")
+	a.Logger.Debug("State at start of block", "state", a.CurState)
+
+	newHeight := a.CurState.LastBlockHeight + 1
+	defer a.traceBlock(newHeight)()
+
+	var err error
+
+	if opts.Round == 0 {
+		opts.Round = a.commitRound
+	}
+
+	blockTime := opts.Time
+	// the time of the clock of CometMock while the block is voted on, see voteTime
+	clockTime := blockTime
+	if blockTime.IsZero() {
+		clockTime = a.TimeHandler.GetBlockTime(a.LastBlock.Time)
+		blockTime = clockTime
+		if a.BFTTime {
+			if median, ok := a.medianTime(); ok {
+				blockTime = median
+			}
+		}
+	}
+
+	// the transactions to propose, and whether they are taken from the queues
+	var newTxQueue []cmttypes.Tx
+	useTxQueues := opts.Txs == nil
+	if useTxQueues {
+		newTxQueue, err = a.recheckTxQueues()
+		if err != nil {
+			return err
+		}
+	} else {
+		newTxQueue = *opts.Txs
+	}
+
+	// if no proposer was specified, select it like CometBFT would.
+	// When proposers without an app are skipped, the block is proposed, voted on and committed
+	// in the round of the selected proposer
+	proposer := opts.Proposer
+	if proposer == nil {
+		proposer, opts.Round, err = a.getProposerAndRound(opts.Round)
+		if err != nil {
+			return err
+		}
+	}
+	proposerAddress := proposer.Address
+
+	if !opts.SkipEvents {
+		a.publishNewRound(newHeight, opts.Round, proposer)
+	}
 
-	// TODO: handle special case where proposer is nil
-	var proposerAddress types.Address
-	if proposer != nil {
-		proposerAddress = proposer.Address
+	misbehavingValidators := opts.MisbehavingValidators
+	if misbehavingValidators == nil {
+		misbehavingValidators = a.scheduledMisbehaviours(newHeight)
 	}
 
 	evidences := make([]types.Evidence, 0)
@@ -1000,37 +1809,61 @@ func (a *AbciClient) runBlock_helper(
 		return fmt.Errorf("could not find proposer app for address %v", proposerAddress)
 	}
 
-	// The proposer runs PrepareProposal
 	txs := cmttypes.Txs(newTxQueue)
+	if err := a.runBeforeProposalHooks(newHeight, proposer, &txs); err != nil {
+		return err
+	}
+
+	// The proposer runs PrepareProposal
 	_, block, err := a.decideProposal(
 		proposerApp,
 		proposer,
 		a.CurState.LastBlockHeight+1,
-		0,
+		opts.Round,
 		&txs,
 		evidences,
 	)
+	if err != nil {
+		return fmt.Errorf("error in decideProposal: %v", err)
+	}
 
 	// set the block time to the time passed as argument
 	block.Time = blockTime
 
-	// clear the tx queues
-	a.ClearTxs()
+	if !opts.SkipEvents {
+		a.publishCompleteProposal(block, opts.Round)
+	}
 
-	// for each tx not included in the block,
-	// put it in the stale queue
-	for _, tx := range newTxQueue {
-		if !utils.Contains(block.Txs, tx) {
-			a.StaleTxQueue = append(a.StaleTxQueue, tx)
+	if useTxQueues {
+		// clear the tx queues
+		a.ClearTxs()
+
+		// for each tx not included in the block,
+		// put it in the stale queue
+		for _, tx := range newTxQueue {
+			if !utils.Contains(block.Txs, tx) {
+				a.StaleTxQueue = append(a.StaleTxQueue, tx)
+			}
 		}
 	}
 
-	if err != nil {
-		return fmt.Errorf("error in decideProposal: %v", err)
+	var signers map[string]bool
+	if opts.Signers != nil {
+		signers = make(map[string]bool, len(opts.Signers))
+		for _, address := range opts.Signers {
+			if !a.HasClient(address) {
+				return fmt.Errorf("cannot sign with validator %v, its key is not known", address)
+			}
+			signers[address] = true
+		}
 	}
 
 	var nonProposers []*AbciCounterpartyClient
 	for _, val := range a.CurState.Validators.Validators {
+		if !a.HasClient(val.Address.String()) {
+			continue
+		}
+
 		client, err := a.GetCounterpartyFromAddress(val.Address.String())
 		if err != nil {
 			return fmt.Errorf("error when getting counterparty client from address: address %v, error %v", val.Address.String(), err)
@@ -1053,23 +1886,60 @@ func (a *AbciClient) runBlock_helper(
 		}
 	}
 
+	if err := a.runAfterProcessProposalHooks(block); err != nil {
+		return err
+	}
+
 	votes := []*types.Vote{}
+	// the validators whose vote extensions were overridden, see OverrideVoteExtension
+	overriddenExtensions := make(map[string]bool)
 
 	// sign the block with all current validators, and call ExtendVote (if necessary)
 	for index, val := range a.CurState.Validators.Validators {
+		placeholder := a.placeholderClient(val.Address.String())
+		if !a.HasClient(val.Address.String()) && placeholder == nil {
+			// validators without an app never sign, unless they are placeholder validators
+			votes = append(votes, nil)
+			continue
+		}
 
-		shouldSign, err := a.GetSigningStatus(val.Address.String())
-		if err != nil {
-			return fmt.Errorf("error getting signing status for validator %v, error %v", val.Address.String(), err)
+		var shouldSign bool
+		if signers != nil {
+			shouldSign = signers[val.Address.String()]
+		} else {
+			shouldSign, err = a.GetSigningStatus(val.Address.String())
+			if err != nil {
+				return fmt.Errorf("error getting signing status for validator %v, error %v", val.Address.String(), err)
+			}
 		}
 
 		if shouldSign {
 			client, ok := a.Clients[val.Address.String()]
-			if !ok {
+			if !ok && placeholder == nil {
 				return fmt.Errorf("did not find privval for address: address %v", val.Address.String())
 			}
-			vote, err := a.ExtendAndSignVote(&client, val, int32(index), block)
+			var extensionOverride []byte
+			var overrideExtension bool
+			if placeholder != nil {
+				// placeholder validators have no app to extend their votes, so their extensions are empty,
+				// and like overridden extensions, their votes are dropped if the extensions are rejected
+				client = *placeholder
+				overrideExtension = true
+				overriddenExtensions[val.Address.String()] = a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(block.Height)
+			} else if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(block.Height) {
+				extensionOverride, overrideExtension = a.takeVoteExtensionOverride(val.Address.String())
+				overriddenExtensions[val.Address.String()] = overrideExtension
+			}
+			voteTime := a.voteTime(val.Address.String(), block, clockTime)
+			vote, err := a.extendAndSignVote(&client, val, int32(index), opts.Round, block, voteTime, extensionOverride, overrideExtension)
 			if err != nil {
+				var signerErr *SignerError
+				if errors.As(err, &signerErr) {
+					// like a node whose signer fails, the validator just misses this block
+					a.Logger.Error("Validator could not sign, it will not vote for this block", "validator", a.validatorLabel(val.Address.String()), "err", err)
+					votes = append(votes, nil)
+					continue
+				}
 				return fmt.Errorf("error when signing vote for validator %v, error %v", val.Address.String(), err)
 			}
 
@@ -1083,13 +1953,17 @@ func (a *AbciClient) runBlock_helper(
 	// verify vote extensions if necessary
 	if a.CurState.ConsensusParams.ABCI.VoteExtensionsEnabled(block.Height) {
 		for _, val := range a.CurState.Validators.Validators {
+			if !a.HasClient(val.Address.String()) {
+				continue
+			}
+
 			a.Logger.Info("Verifying vote extension for validator", val.Address.String())
 			client, err := a.GetCounterpartyFromAddress(val.Address.String())
 			if err != nil {
 				return fmt.Errorf("error when getting counterparty client from address: address %v, error %v", val.Address.String(), err)
 			}
 
-			for _, vote := range votes {
+			for i, vote := range votes {
 				if vote != nil && vote.ValidatorAddress.String() != client.ValidatorAddress {
 					// make a context to time out the request
 					ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
@@ -1101,16 +1975,25 @@ func (a *AbciClient) runBlock_helper(
 						VoteExtension:    vote.Extension,
 					})
 					cancel()
-					// recovering from errors of VerifyVoteExtension seems hard because applications
-					// are typically not supposed to reject valid extensions created by ExtendVote.
+					// errors calling the app leave the state untouched, see RunBlockWithOptions.
 					if err != nil {
-						panic(fmt.Errorf("verify vote extension failed with error %v", err))
+						return fmt.Errorf("verify vote extension failed with error %v", err)
 					}
 
+					// recovering from rejections of VerifyVoteExtension seems hard because applications
+					// are typically not supposed to reject valid extensions created by ExtendVote.
+
 					if resp.IsStatusUnknown() {
 						panic(fmt.Sprintf("verify vote extension responded with status %s", resp.Status.String()))
 					}
 
+					if !resp.IsAccepted() && overriddenExtensions[vote.ValidatorAddress.String()] {
+						// like CometBFT, drop votes with invalid extensions, so the validator misses this block
+						a.Logger.Error("Vote extension override was rejected, dropping the vote", "validator", a.validatorLabel(vote.ValidatorAddress.String()), "rejected_by", a.validatorLabel(client.ValidatorAddress))
+						votes[i] = nil
+						continue
+					}
+
 					if !resp.IsAccepted() {
 						panic(fmt.Sprintf("Verify vote extension rejected an extension for vote %v", vote.String()))
 					}
@@ -1119,6 +2002,10 @@ func (a *AbciClient) runBlock_helper(
 		}
 	}
 
+	if err := a.runFilterVotesHooks(block, votes); err != nil {
+		return err
+	}
+
 	// if vote extensions are enabled, we need an extended vote set
 	// otherwise, we need a regular vote set
 	var voteSet *types.VoteSet
@@ -1126,7 +2013,7 @@ func (a *AbciClient) runBlock_helper(
 		voteSet = types.NewExtendedVoteSet(
 			a.CurState.ChainID,
 			block.Height,
-			0, // round is hardcoded to 0
+			opts.Round,
 			cmtproto.PrecommitType,
 			a.CurState.Validators,
 		)
@@ -1134,7 +2021,7 @@ func (a *AbciClient) runBlock_helper(
 		voteSet = types.NewVoteSet(
 			a.CurState.ChainID,
 			block.Height,
-			0, // round is hardcoded to 0
+			opts.Round,
 			cmtproto.PrecommitType,
 			a.CurState.Validators,
 		)
@@ -1150,22 +2037,26 @@ func (a *AbciClient) runBlock_helper(
 			if !added {
 				return fmt.Errorf("could not add vote %v to vote set", vote.String())
 			}
+			if !opts.SkipEvents {
+				a.publishVote(vote)
+			}
 		}
 	}
 
-	// set the last commit to the vote set
-	a.LastCommit = voteSet.MakeExtendedCommit(a.CurState.ConsensusParams.ABCI)
+	// the commit only becomes the last commit once the block was finalized,
+	// so that a failed block leaves the last commit untouched
+	extCommit := voteSet.MakeExtendedCommit(a.CurState.ConsensusParams.ABCI)
 
 	// sanity check that the commit is signed correctly
-	err = a.CurState.Validators.VerifyCommitLightTrusting(a.CurState.ChainID, a.LastCommit.ToCommit(), cmtmath.Fraction{Numerator: 1, Denominator: 3})
+	err = a.CurState.Validators.VerifyCommitLightTrusting(a.CurState.ChainID, extCommit.ToCommit(), cmtmath.Fraction{Numerator: 1, Denominator: 3})
 	if err != nil {
-		return fmt.Errorf("error verifying commit %v: %v", a.LastCommit.ToCommit().StringIndented("\t"), err)
+		return fmt.Errorf("error verifying commit %v: %v", extCommit.ToCommit().StringIndented("\t"), err)
 	}
 
 	// sanity check that the commit makes a proper light block
 	signedHeader := types.SignedHeader{
 		Header: &block.Header,
-		Commit: a.LastCommit.ToCommit(),
+		Commit: extCommit.ToCommit(),
 	}
 
 	lightBlock := types.LightBlock{
@@ -1179,66 +2070,141 @@ func (a *AbciClient) runBlock_helper(
 		return err
 	}
 
-	lastCommitInfo := utils.BuildLastCommitInfo(block, a.CurState.Validators, a.CurState.InitialHeight)
+	if err := a.runBeforeFinalizeBlockHooks(block, extCommit); err != nil {
+		return err
+	}
+
+	lastCommitInfo := utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight)
 	resFinalizeBlock, err := a.SendFinalizeBlock(block, &lastCommitInfo)
 	if err != nil {
-		return fmt.Errorf("error from FinalizeBlock for block %v: %v", block.String(), err)
+		return fmt.Errorf("error from FinalizeBlock for block %v: %w", block.String(), err)
 	}
 
-	// lock the state update mutex while the stores are updated to avoid
-	// inconsistencies between stores
-	a.Storage.LockBeforeStateUpdate()
-	a.LastBlock = block
+	err = a.storeBlock(block, extCommit, overriddenExtensions, resFinalizeBlock, !opts.SkipEvents)
+	if err != nil {
+		// the apps finalized the block, so it cannot be proposed to them again
+		return &FinalizedBlockError{Height: block.Height, Err: err}
+	}
 
-	// copy state so that the historical state is not mutated
-	state := a.CurState.Copy()
+	_, err = a.SendCommit()
+	if err != nil {
+		return fmt.Errorf("error from Commit for block %v: %w", block.String(), err)
+	}
+	if a.QueryCache != nil {
+		a.QueryCache.NewBlock()
+	}
+	a.CurState.AppHash = resFinalizeBlock.AppHash
+	a.takeSnapshotIfDue()
 
-	// insert entries into the storage
-	err = a.Storage.UpdateStores(newHeight, block, a.LastCommit.ToCommit(), &state, resFinalizeBlock)
+	// the block is committed at this point, so a failed hook or invariant only fails the call
+	err = a.runAfterCommitHooks(block, resFinalizeBlock)
+	if err != nil {
+		return err
+	}
+
+	err = a.checkInvariants()
 	if err != nil {
-		return fmt.Errorf("error updating stores: %v", err)
+		return fmt.Errorf("error checking invariants after block %v: %v", block.Height, err)
 	}
 
+	return nil
+}
+
+// storeBlock stores the block that the apps finalized, together with its commit, the state before it and
+// the responses of the apps, and updates the state after the block.
+// The lock of the storage is held until both are done, so that readers do not see the stores in between.
+// The state after the block is computed before anything is stored, and LastBlock and LastCommit
+// only move to the block once the block is stored, so nothing changes if this fails.
+func (a *AbciClient) storeBlock(
+	block *types.Block,
+	extCommit *types.ExtendedCommit,
+	overriddenExtensions map[string]bool,
+	resFinalizeBlock *abcitypes.ResponseFinalizeBlock,
+	publishEvents bool,
+) error {
 	blockId, err := utils.GetBlockIdFromBlock(block)
 	if err != nil {
 		return fmt.Errorf("error getting block id from block %v: %v", block.String(), err)
 	}
 
-	// updates state as a side effect. returns an error if the state update fails
-	err = a.UpdateStateFromBlock(blockId, block, resFinalizeBlock)
+	newState, validatorUpdates, err := a.stateAfterBlock(blockId, block, resFinalizeBlock)
 	if err != nil {
 		return fmt.Errorf("error updating state for result %v, block %v: %v", resFinalizeBlock.String(), block.String(), err)
 	}
-	// unlock the state mutex, since we are done updating state
-	a.Storage.UnlockAfterStateUpdate()
 
-	_, err = a.SendCommit()
+	a.Storage.LockBeforeStateUpdate()
+	defer a.Storage.UnlockAfterStateUpdate()
+
+	// copy state so that the historical state is not mutated
+	state := a.CurState.Copy()
+
+	// insert entries into the storage
+	err = a.Storage.UpdateStores(block.Height, block, extCommit, &state, resFinalizeBlock)
 	if err != nil {
-		return fmt.Errorf("error from Commit for block %v: %v", block.String(), err)
+		return fmt.Errorf("error updating stores: %w", err)
 	}
-	a.CurState.AppHash = resFinalizeBlock.AppHash
 
+	a.LastBlock = block
+	a.LastCommit = extCommit
+	a.lastOverriddenExtensions = overriddenExtensions
+	a.applyStateAfterBlock(newState, blockId, block, resFinalizeBlock, validatorUpdates, publishEvents)
 	return nil
 }
 
-// RunBlock RunBlockWithTimeAndProposer runs a block through the ABCI application.
+// RunBlockWithTimeAndProposer runs a block through the ABCI application.
+// If proposer is nil, the proposer is selected by GetProposer.
 // RunBlock is safe for use by multiple goroutines simultaneously.
 func (a *AbciClient) RunBlockWithTimeAndProposer(
 	blockTime time.Time,
 	proposer *types.Validator,
 	misbehavingValidators map[*types.Validator]MisbehaviourType,
 ) error {
+	return a.RunBlockWithOptions(BlockOptions{
+		Time:                  blockTime,
+		Proposer:              proposer,
+		MisbehavingValidators: misbehavingValidators,
+	})
+}
+
+// RunBlockWithOptions runs a block through the ABCI application,
+// overriding how the block is produced with the given options.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) RunBlockWithOptions(opts BlockOptions) error {
 	// lock mutex to avoid running two blocks at the same time
 	a.Logger.Debug("Locking mutex")
-	blockMutex.Lock()
+	a.blockMutex.Lock()
 
-	err := a.runBlock_helper(blockTime, proposer, misbehavingValidators)
+	err := a.runBlockLocked(opts)
 
-	blockMutex.Unlock()
+	a.blockMutex.Unlock()
 	a.Logger.Debug("Unlocking mutex")
 	return err
 }
 
+// runBlockLocked runs a block, and halts block production if it fails because the apps went away,
+// or because an app returned an error from FinalizeBlock or Commit.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) runBlockLocked(opts BlockOptions) error {
+	if halt := a.Halted(); halt != nil {
+		return fmt.Errorf("%w at height %d (%s), call resume to continue", ErrHalted, halt.Height, halt.Reason)
+	}
+
+	snapshot := a.takeBlockSnapshot()
+	err := a.runBlock_helper(opts)
+	if err != nil && a.restoreBlockSnapshot(snapshot) {
+		// the block was not produced, so the state is still at the last block.
+		// check whether this is because the apps went away
+		err = a.haltIfAppsUnreachable(snapshot.height+1, err)
+	}
+	if err != nil && !errors.Is(err, ErrHalted) {
+		err = a.haltIfAppFailed(snapshot.height+1, err)
+	}
+	if err != nil && !errors.Is(err, ErrHalted) {
+		err = a.haltIfBlockFinalized(snapshot.height+1, err)
+	}
+	return a.upgradeAfterBlock(snapshot.height+1, opts, err)
+}
+
 // UpdateStateFromBlock updates the AbciClients state
 // after running a block. It updates the
 // last block height, last block ID, last
@@ -1248,16 +2214,40 @@ func (a *AbciClient) UpdateStateFromBlock(
 	block *types.Block,
 	finalizeBlockRes *abcitypes.ResponseFinalizeBlock,
 ) error {
+	return a.updateStateFromBlock(blockId, block, finalizeBlockRes, true)
+}
+
+func (a *AbciClient) updateStateFromBlock(
+	blockId *types.BlockID,
+	block *types.Block,
+	finalizeBlockRes *abcitypes.ResponseFinalizeBlock,
+	publishEvents bool,
+) error {
+	newState, validatorUpdates, err := a.stateAfterBlock(blockId, block, finalizeBlockRes)
+	if err != nil {
+		return err
+	}
+	a.applyStateAfterBlock(newState, blockId, block, finalizeBlockRes, validatorUpdates, publishEvents)
+	return nil
+}
+
+// stateAfterBlock returns the state after the block, and the validator updates of the apps,
+// without changing the current state.
+func (a *AbciClient) stateAfterBlock(
+	blockId *types.BlockID,
+	block *types.Block,
+	finalizeBlockRes *abcitypes.ResponseFinalizeBlock,
+) (state.State, []*types.Validator, error) {
 	// build components of the state update, then call the update function
 	abciValidatorUpdates := finalizeBlockRes.ValidatorUpdates
 	err := validateValidatorUpdates(abciValidatorUpdates, a.CurState.ConsensusParams.Validator)
 	if err != nil {
-		return fmt.Errorf("error in validator updates: %v", err)
+		return state.State{}, nil, fmt.Errorf("error in validator updates: %v", err)
 	}
 
 	validatorUpdates, err := types.PB2TM.ValidatorUpdates(abciValidatorUpdates)
 	if err != nil {
-		return fmt.Errorf("error converting validator updates: %v", err)
+		return state.State{}, nil, fmt.Errorf("error converting validator updates: %v", err)
 	}
 
 	newState, err := UpdateState(
@@ -1268,15 +2258,29 @@ func (a *AbciClient) UpdateStateFromBlock(
 		validatorUpdates,
 	)
 	if err != nil {
-		return fmt.Errorf("error updating state: %v", err)
+		return state.State{}, nil, fmt.Errorf("error updating state: %v", err)
 	}
+	return newState, validatorUpdates, nil
+}
 
+// applyStateAfterBlock makes the state returned by stateAfterBlock the current state,
+// and publishes the events of the block if publishEvents is set.
+func (a *AbciClient) applyStateAfterBlock(
+	newState state.State,
+	blockId *types.BlockID,
+	block *types.Block,
+	finalizeBlockRes *abcitypes.ResponseFinalizeBlock,
+	validatorUpdates []*types.Validator,
+	publishEvents bool,
+) {
 	a.CurState = newState
+	a.syncSigningStatus(newState.Validators)
 
 	// Events are fired after everything else.
 	// NOTE: if we crash between Commit and Save, events wont be fired during replay
-	fireEvents(a.Logger, &a.EventBus, block, *blockId, finalizeBlockRes, validatorUpdates)
-	return nil
+	if publishEvents {
+		fireEvents(a.Logger, &a.EventBus, block, *blockId, finalizeBlockRes, validatorUpdates)
+	}
 }
 
 // adapted from https://github.com/cometbft/cometbft/blob/9267594e0a17c01cc4a97b399ada5eaa8a734db5/state/execution.go#L478
@@ -1310,9 +2314,15 @@ func UpdateState(
 	nextParams := curState.ConsensusParams
 	lastHeightParamsChanged := curState.LastHeightConsensusParamsChanged
 	if finalizeBlockRes.ConsensusParamUpdates != nil {
+		// e.g. vote extensions can only be enabled from a later height on
+		err := curState.ConsensusParams.ValidateUpdate(finalizeBlockRes.ConsensusParamUpdates, blockHeader.Height)
+		if err != nil {
+			return curState, fmt.Errorf("error updating consensus params: %v", err)
+		}
+
 		// NOTE: must not mutate s.ConsensusParams
 		nextParams = curState.ConsensusParams.Update(finalizeBlockRes.ConsensusParamUpdates)
-		err := nextParams.ValidateBasic()
+		err = nextParams.ValidateBasic()
 		if err != nil {
 			return curState, fmt.Errorf("error updating consensus params: %v", err)
 		}
@@ -0,0 +1,116 @@
+package abci_client
+
+import (
+	"fmt"
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ConflictingBlocks are two different blocks at the same height that are both signed
+// by more than 2/3 of the voting power, as produced by ProduceConflictingBlocks.
+type ConflictingBlocks struct {
+	Height int64
+	// the headers of the two blocks, each with a commit that signs it
+	SignedHeaderA *types.SignedHeader
+	SignedHeaderB *types.SignedHeader
+	// the validator set that signed both blocks
+	ValidatorSet *types.ValidatorSet
+}
+
+// ProduceConflictingBlocks builds two different blocks at the next height and has every validator
+// whose key is known sign both of them, which is a fork of the chain, e.g. to test that light clients
+// and relayers detect misbehaviour. The blocks have no transactions and differ in their time.
+// Neither block is sent to the apps and the state does not advance, so the chain continues
+// with a third block at the same height. Producing the blocks fails if the validators
+// whose keys are known do not have more than 2/3 of the voting power.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ProduceConflictingBlocks() (*ConflictingBlocks, error) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	proposer, err := a.GetProposer()
+	if err != nil {
+		return nil, err
+	}
+
+	height := a.CurState.LastBlockHeight + 1
+	blockA := a.CurState.MakeBlock(height, types.Txs{}, a.LastCommit.ToCommit(), nil, proposer.Address)
+	blockA.Time = a.TimeHandler.PeekBlockTime(a.LastBlock.Time)
+	blockB := a.CurState.MakeBlock(height, types.Txs{}, a.LastCommit.ToCommit(), nil, proposer.Address)
+	blockB.Time = blockA.Time.Add(time.Second)
+
+	commitA, err := a.signConflictingBlock(blockA)
+	if err != nil {
+		return nil, err
+	}
+	commitB, err := a.signConflictingBlock(blockB)
+	if err != nil {
+		return nil, err
+	}
+
+	a.lastConflictingBlocks = &ConflictingBlocks{
+		Height:        height,
+		SignedHeaderA: &types.SignedHeader{Header: &blockA.Header, Commit: commitA},
+		SignedHeaderB: &types.SignedHeader{Header: &blockB.Header, Commit: commitB},
+		ValidatorSet:  a.CurState.Validators.Copy(),
+	}
+	return a.lastConflictingBlocks, nil
+}
+
+// LastConflictingBlocks returns the blocks from the last call of ProduceConflictingBlocks,
+// or nil if no conflicting blocks were produced yet.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) LastConflictingBlocks() *ConflictingBlocks {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	return a.lastConflictingBlocks
+}
+
+// signConflictingBlock returns a commit for the block in round 0, signed by all validators whose keys are known.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) signConflictingBlock(block *types.Block) (*types.Commit, error) {
+	blockParts, err := block.MakePartSet(types.BlockPartSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error making block part set: %v", err)
+	}
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: blockParts.Header()}
+
+	voteSet := types.NewVoteSet(a.CurState.ChainID, block.Height, 0, cmtproto.PrecommitType, a.CurState.Validators)
+	for index, val := range a.CurState.Validators.Validators {
+		client, ok := a.Clients[val.Address.String()]
+		if !ok {
+			continue
+		}
+
+		vote := &types.Vote{
+			ValidatorAddress: val.Address,
+			ValidatorIndex:   int32(index),
+			Height:           block.Height,
+			Round:            0,
+			Timestamp:        block.Time,
+			Type:             cmtproto.PrecommitType,
+			BlockID:          blockID,
+		}
+		protoVote := vote.ToProto()
+		if err := client.PrivValidator.SignVote(a.CurState.ChainID, protoVote); err != nil {
+			return nil, &SignerError{
+				ValidatorAddress: val.Address.String(),
+				Err:              fmt.Errorf("error signing vote %v:\n %v", vote.String(), err),
+			}
+		}
+		vote.Signature = protoVote.Signature
+
+		if _, err := voteSet.AddVote(vote); err != nil {
+			return nil, fmt.Errorf("error adding vote %v to vote set: %v", vote.String(), err)
+		}
+	}
+
+	if !voteSet.HasTwoThirdsMajority() {
+		return nil, fmt.Errorf("cannot sign conflicting blocks at height %d, the validators whose keys are known do not have more than 2/3 of the voting power", block.Height)
+	}
+	// the commit has no vote extensions, since the blocks are never finalized
+	return voteSet.MakeExtendedCommit(types.ABCIParams{}).ToCommit(), nil
+}
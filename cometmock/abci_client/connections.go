@@ -0,0 +1,233 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/service"
+)
+
+// ConnectionMode decides how many connections are opened to each app.
+type ConnectionMode string
+
+const (
+	// ConnectionModeSingle sends all calls to an app over a single connection,
+	// so a slow query or CheckTx delays the calls that produce blocks.
+	ConnectionModeSingle ConnectionMode = "single"
+	// ConnectionModePerPurpose opens a connection to each app for each purpose, like CometBFT does,
+	// see MultiConnClient.
+	ConnectionModePerPurpose ConnectionMode = "per-purpose"
+)
+
+// ParseConnectionMode parses the name of a ConnectionMode.
+func ParseConnectionMode(name string) (ConnectionMode, error) {
+	switch mode := ConnectionMode(name); mode {
+	case ConnectionModeSingle, ConnectionModePerPurpose:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown connection mode %q, must be one of %q or %q",
+			name, ConnectionModeSingle, ConnectionModePerPurpose)
+	}
+}
+
+// MultiConnClient is an ABCI client that holds a separate connection to an app for each purpose,
+// like CometBFT does:
+// * the consensus connection for the calls that produce blocks, i.e. InitChain, PrepareProposal,
+// ProcessProposal, ExtendVote, VerifyVoteExtension, FinalizeBlock and Commit
+// * the mempool connection for CheckTx
+// * the query connection for Info, Query and Echo
+// * the snapshot connection for the state sync calls
+// Calls on different connections do not wait for each other, so e.g. a slow query does not
+// delay FinalizeBlock, and CheckTx is not serialized behind block execution.
+// If any of the connections stops, the client stops, and so do the other connections.
+type MultiConnClient struct {
+	service.BaseService
+
+	consensus abciclient.Client
+	mempool   abciclient.Client
+	query     abciclient.Client
+	snapshot  abciclient.Client
+}
+
+var _ abciclient.Client = (*MultiConnClient)(nil)
+
+// ConnectPerPurpose returns a ClientConnector that connects to an app with connect once for each purpose,
+// and returns a started MultiConnClient holding the connections.
+func ConnectPerPurpose(connect ClientConnector) ClientConnector {
+	return func(networkAddress string) (abciclient.Client, error) {
+		connections := make([]abciclient.Client, 0, 4)
+		for len(connections) < cap(connections) {
+			connection, err := connect(networkAddress)
+			if err != nil {
+				for _, opened := range connections {
+					_ = opened.Stop()
+				}
+				return nil, err
+			}
+			connections = append(connections, connection)
+		}
+
+		client := NewMultiConnClient(connections[0], connections[1], connections[2], connections[3])
+		if err := client.Start(); err != nil {
+			// the client does not stop the connections if it did not start
+			for _, opened := range connections {
+				_ = opened.Stop()
+			}
+			return nil, err
+		}
+		return client, nil
+	}
+}
+
+// NewMultiConnClient returns a client that sends the calls for each purpose over the given connection,
+// which must be started already.
+func NewMultiConnClient(consensus, mempool, query, snapshot abciclient.Client) *MultiConnClient {
+	client := &MultiConnClient{
+		consensus: consensus,
+		mempool:   mempool,
+		query:     query,
+		snapshot:  snapshot,
+	}
+	client.BaseService = *service.NewBaseService(nil, "MultiConnClient", client)
+	return client
+}
+
+func (c *MultiConnClient) connections() []abciclient.Client {
+	return []abciclient.Client{c.consensus, c.mempool, c.query, c.snapshot}
+}
+
+// OnStart implements service.Service by stopping the client when any of its connections stops.
+func (c *MultiConnClient) OnStart() error {
+	for _, connection := range c.connections() {
+		go func(connection abciclient.Client) {
+			select {
+			case <-connection.Quit():
+				_ = c.Stop()
+			case <-c.Quit():
+			}
+		}(connection)
+	}
+	return nil
+}
+
+// OnStop implements service.Service by stopping all connections.
+func (c *MultiConnClient) OnStop() {
+	for _, connection := range c.connections() {
+		if connection.IsRunning() {
+			_ = connection.Stop()
+		}
+	}
+}
+
+// SetLogger sets the logger of the client and of its connections.
+func (c *MultiConnClient) SetLogger(logger log.Logger) {
+	c.BaseService.SetLogger(logger)
+	for _, connection := range c.connections() {
+		connection.SetLogger(logger)
+	}
+}
+
+// IsRunning returns whether the client and all of its connections are running.
+func (c *MultiConnClient) IsRunning() bool {
+	if !c.BaseService.IsRunning() {
+		return false
+	}
+	for _, connection := range c.connections() {
+		if !connection.IsRunning() {
+			return false
+		}
+	}
+	return true
+}
+
+// Error returns the error of the first connection that has one, in the order consensus, mempool, query, snapshot.
+func (c *MultiConnClient) Error() error {
+	for _, connection := range c.connections() {
+		if err := connection.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush flushes all connections.
+func (c *MultiConnClient) Flush(ctx context.Context) error {
+	for _, connection := range c.connections() {
+		if err := connection.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MultiConnClient) Echo(ctx context.Context, msg string) (*abcitypes.ResponseEcho, error) {
+	return c.query.Echo(ctx, msg)
+}
+
+func (c *MultiConnClient) Info(ctx context.Context, req *abcitypes.RequestInfo) (*abcitypes.ResponseInfo, error) {
+	return c.query.Info(ctx, req)
+}
+
+func (c *MultiConnClient) Query(ctx context.Context, req *abcitypes.RequestQuery) (*abcitypes.ResponseQuery, error) {
+	return c.query.Query(ctx, req)
+}
+
+func (c *MultiConnClient) CheckTx(ctx context.Context, req *abcitypes.RequestCheckTx) (*abcitypes.ResponseCheckTx, error) {
+	return c.mempool.CheckTx(ctx, req)
+}
+
+func (c *MultiConnClient) CheckTxAsync(ctx context.Context, req *abcitypes.RequestCheckTx) (*abciclient.ReqRes, error) {
+	return c.mempool.CheckTxAsync(ctx, req)
+}
+
+// SetResponseCallback sets the callback for the responses to CheckTxAsync, which are received on the mempool connection.
+func (c *MultiConnClient) SetResponseCallback(callback abciclient.Callback) {
+	c.mempool.SetResponseCallback(callback)
+}
+
+func (c *MultiConnClient) InitChain(ctx context.Context, req *abcitypes.RequestInitChain) (*abcitypes.ResponseInitChain, error) {
+	return c.consensus.InitChain(ctx, req)
+}
+
+func (c *MultiConnClient) PrepareProposal(ctx context.Context, req *abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error) {
+	return c.consensus.PrepareProposal(ctx, req)
+}
+
+func (c *MultiConnClient) ProcessProposal(ctx context.Context, req *abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error) {
+	return c.consensus.ProcessProposal(ctx, req)
+}
+
+func (c *MultiConnClient) ExtendVote(ctx context.Context, req *abcitypes.RequestExtendVote) (*abcitypes.ResponseExtendVote, error) {
+	return c.consensus.ExtendVote(ctx, req)
+}
+
+func (c *MultiConnClient) VerifyVoteExtension(ctx context.Context, req *abcitypes.RequestVerifyVoteExtension) (*abcitypes.ResponseVerifyVoteExtension, error) {
+	return c.consensus.VerifyVoteExtension(ctx, req)
+}
+
+func (c *MultiConnClient) FinalizeBlock(ctx context.Context, req *abcitypes.RequestFinalizeBlock) (*abcitypes.ResponseFinalizeBlock, error) {
+	return c.consensus.FinalizeBlock(ctx, req)
+}
+
+func (c *MultiConnClient) Commit(ctx context.Context, req *abcitypes.RequestCommit) (*abcitypes.ResponseCommit, error) {
+	return c.consensus.Commit(ctx, req)
+}
+
+func (c *MultiConnClient) ListSnapshots(ctx context.Context, req *abcitypes.RequestListSnapshots) (*abcitypes.ResponseListSnapshots, error) {
+	return c.snapshot.ListSnapshots(ctx, req)
+}
+
+func (c *MultiConnClient) OfferSnapshot(ctx context.Context, req *abcitypes.RequestOfferSnapshot) (*abcitypes.ResponseOfferSnapshot, error) {
+	return c.snapshot.OfferSnapshot(ctx, req)
+}
+
+func (c *MultiConnClient) LoadSnapshotChunk(ctx context.Context, req *abcitypes.RequestLoadSnapshotChunk) (*abcitypes.ResponseLoadSnapshotChunk, error) {
+	return c.snapshot.LoadSnapshotChunk(ctx, req)
+}
+
+func (c *MultiConnClient) ApplySnapshotChunk(ctx context.Context, req *abcitypes.RequestApplySnapshotChunk) (*abcitypes.ResponseApplySnapshotChunk, error) {
+	return c.snapshot.ApplySnapshotChunk(ctx, req)
+}
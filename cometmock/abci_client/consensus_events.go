@@ -0,0 +1,53 @@
+package abci_client
+
+import (
+	cstypes "github.com/cometbft/cometbft/consensus/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// CometMock does not run the consensus algorithm, so the consensus events that CometBFT publishes
+// while nodes agree on a block are synthesized from the steps of producing a block:
+// a NewRound event when the proposer is selected, a CompleteProposal event once the proposal is decided,
+// and a Vote event for each precommit that is added to the commit.
+// Prevotes are not synthesized, since validators only sign precommits in CometMock.
+
+// publishNewRound publishes the NewRound event for the round in which the block at the given height is proposed.
+func (a *AbciClient) publishNewRound(height int64, round int32, proposer *types.Validator) {
+	index, _ := a.CurState.Validators.GetByAddress(proposer.Address)
+	if err := a.EventBus.PublishEventNewRound(types.EventDataNewRound{
+		Height: height,
+		Round:  round,
+		Step:   cstypes.RoundStepNewRound.String(),
+		Proposer: types.ValidatorInfo{
+			Address: proposer.Address,
+			Index:   index,
+		},
+	}); err != nil {
+		a.Logger.Error("failed publishing new round", "err", err)
+	}
+}
+
+// publishCompleteProposal publishes the CompleteProposal event for the proposed block.
+func (a *AbciClient) publishCompleteProposal(block *types.Block, round int32) {
+	blockId, err := utils.GetBlockIdFromBlock(block)
+	if err != nil {
+		a.Logger.Error("failed publishing complete proposal", "err", err)
+		return
+	}
+	if err := a.EventBus.PublishEventCompleteProposal(types.EventDataCompleteProposal{
+		Height:  block.Height,
+		Round:   round,
+		Step:    cstypes.RoundStepPropose.String(),
+		BlockID: *blockId,
+	}); err != nil {
+		a.Logger.Error("failed publishing complete proposal", "err", err)
+	}
+}
+
+// publishVote publishes the Vote event for a vote that was added to the commit.
+func (a *AbciClient) publishVote(vote *types.Vote) {
+	if err := a.EventBus.PublishEventVote(types.EventDataVote{Vote: vote}); err != nil {
+		a.Logger.Error("failed publishing vote", "err", err)
+	}
+}
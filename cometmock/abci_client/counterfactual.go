@@ -0,0 +1,174 @@
+package abci_client
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// BlockChange changes a block when the blocks after a snapshot are replayed, see ReplayFromSnapshot.
+type BlockChange struct {
+	Height int64
+	// If this is non-nil, exactly these transactions are proposed instead of the transactions of the original block.
+	Txs *types.Txs
+	// If this is not the zero time, the timestamp of the block instead of the timestamp of the original block.
+	Time time.Time
+}
+
+// ReplayedBlock compares the outcome of a replayed block with the outcome of the original block at its height.
+type ReplayedBlock struct {
+	Height int64 `json:"height"`
+	// whether the block was changed by a BlockChange
+	Changed bool `json:"changed"`
+	// the app hash and the hash of the transaction results after the original block
+	OriginalAppHash     cmtbytes.HexBytes `json:"original_app_hash"`
+	OriginalResultsHash cmtbytes.HexBytes `json:"original_results_hash"`
+	// the app hash and the hash of the transaction results after the replayed block
+	AppHash     cmtbytes.HexBytes `json:"app_hash"`
+	ResultsHash cmtbytes.HexBytes `json:"results_hash"`
+	// whether the app hash and the results hash differ from those of the original block
+	Diverged bool `json:"diverged"`
+}
+
+// originalBlock is what is needed to replay a block as it was produced, and to compare the outcome.
+type originalBlock struct {
+	block       *types.Block
+	round       int32
+	signers     []string
+	appHash     []byte
+	resultsHash []byte
+}
+
+// ReplayFromSnapshot rewinds the chain to the snapshot at the given height, or to the most recent snapshot
+// if the height is 0, like RewindToSnapshot, and then produces the blocks that followed the snapshot again,
+// with the changes given for some of the heights. The replayed blocks replace the original blocks.
+// Blocks without changes are replayed with the transactions, the timestamp, the proposer, the commit round
+// and the signers of the original block, so that they have the same outcome unless the apps are nondeterministic,
+// or a changed block before them changed the state. Evidence is not replayed.
+// The apps need to have been restored to the height of the snapshot, see RewindToSnapshot.
+// It returns the app hash and the results hash after each replayed block, next to those after the original block.
+// If a block cannot be replayed, the chain stays at the last replayed block, and the blocks replayed so far are returned
+// with the error.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ReplayFromSnapshot(height int64, appAddresses []string, changes []BlockChange) ([]ReplayedBlock, error) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	snapshot, err := a.findSnapshot(height)
+	if err != nil {
+		return nil, err
+	}
+	lastHeight := a.CurState.LastBlockHeight
+	if lastHeight == snapshot.Height {
+		return nil, fmt.Errorf("there are no blocks after the snapshot at height %d to replay", snapshot.Height)
+	}
+
+	changesByHeight := make(map[int64]BlockChange, len(changes))
+	for _, change := range changes {
+		if change.Height <= snapshot.Height || change.Height > lastHeight {
+			return nil, fmt.Errorf("cannot change the block at height %d, only the blocks from height %d to %d are replayed",
+				change.Height, snapshot.Height+1, lastHeight)
+		}
+		if _, ok := changesByHeight[change.Height]; ok {
+			return nil, fmt.Errorf("got more than one change for the block at height %d", change.Height)
+		}
+		changesByHeight[change.Height] = change
+	}
+
+	// the original blocks are removed from the storage when the chain is rewound
+	originals := make([]originalBlock, 0, lastHeight-snapshot.Height)
+	for h := snapshot.Height + 1; h <= lastHeight; h++ {
+		original, err := a.getOriginalBlock(h)
+		if err != nil {
+			return nil, fmt.Errorf("cannot replay the block at height %d: %w", h, err)
+		}
+		originals = append(originals, original)
+	}
+
+	if err := a.rewindLocked(snapshot, appAddresses); err != nil {
+		return nil, err
+	}
+
+	replayed := make([]ReplayedBlock, 0, len(originals))
+	for _, original := range originals {
+		h := original.block.Height
+		txs := original.block.Txs
+		opts := BlockOptions{
+			Time:    original.block.Time,
+			Round:   original.round,
+			Txs:     &txs,
+			Signers: original.signers,
+			// evidence is not replayed, and no evidence is made up for the misbehaviour rules either
+			MisbehavingValidators: map[*types.Validator]MisbehaviourType{},
+		}
+		if _, proposer := a.CurState.Validators.GetByAddress(original.block.ProposerAddress); proposer != nil {
+			opts.Proposer = proposer
+		}
+
+		change, changed := changesByHeight[h]
+		if change.Txs != nil {
+			opts.Txs = change.Txs
+		}
+		if !change.Time.IsZero() {
+			opts.Time = change.Time
+		}
+
+		if err := a.runBlockLocked(opts); err != nil {
+			return replayed, fmt.Errorf("error replaying the block at height %d: %w", h, err)
+		}
+
+		responses, err := a.Storage.GetResponses(h)
+		if err != nil {
+			return replayed, err
+		}
+		resultsHash := state.TxResultsHash(responses.TxResults)
+		replayed = append(replayed, ReplayedBlock{
+			Height:              h,
+			Changed:             changed,
+			OriginalAppHash:     original.appHash,
+			OriginalResultsHash: original.resultsHash,
+			AppHash:             responses.AppHash,
+			ResultsHash:         resultsHash,
+			Diverged:            !bytes.Equal(responses.AppHash, original.appHash) || !bytes.Equal(resultsHash, original.resultsHash),
+		})
+	}
+
+	a.Logger.Info("Replayed the blocks after a snapshot", "height", snapshot.Height, "blocks", len(replayed), "changes", len(changes))
+	return replayed, nil
+}
+
+// getOriginalBlock returns the stored block at the given height, and what is needed to replay it.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) getOriginalBlock(height int64) (originalBlock, error) {
+	block, err := a.Storage.GetBlock(height)
+	if err != nil {
+		return originalBlock{}, err
+	}
+	commit, err := a.Storage.GetCommit(height)
+	if err != nil {
+		return originalBlock{}, err
+	}
+	responses, err := a.Storage.GetResponses(height)
+	if err != nil {
+		return originalBlock{}, err
+	}
+
+	signers := make([]string, 0, len(commit.Signatures))
+	for _, sig := range commit.Signatures {
+		if sig.BlockIDFlag == types.BlockIDFlagCommit {
+			signers = append(signers, sig.ValidatorAddress.String())
+		}
+	}
+
+	return originalBlock{
+		block:       block,
+		round:       commit.Round,
+		signers:     signers,
+		appHash:     responses.AppHash,
+		resultsHash: state.TxResultsHash(responses.TxResults),
+	}, nil
+}
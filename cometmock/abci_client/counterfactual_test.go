@@ -0,0 +1,125 @@
+package abci_client
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/cometbft/cometbft-db"
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// copyDB returns a copy of the given database, to restore an app to the state it had when the copy was made.
+func copyDB(t *testing.T, source db.DB) db.DB {
+	copied := db.NewMemDB()
+	it, err := source.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		require.NoError(t, copied.Set(it.Key(), it.Value()))
+	}
+	return copied
+}
+
+// Tests that replaying the blocks after a snapshot gives the original app hashes for unchanged blocks,
+// and reports the blocks that changed the outcome.
+func TestReplayFromSnapshot(t *testing.T) {
+	dbs := []db.DB{db.NewMemDB(), db.NewMemDB()}
+	client := newTestClient(t, kvstore.NewApplication(dbs[0]), kvstore.NewApplication(dbs[1]))
+	require.NoError(t, client.SetSnapshotPolicy(2, 0))
+
+	runBlockWithTxs := func(txs ...string) {
+		blockTxs := make(types.Txs, len(txs))
+		for i, tx := range txs {
+			blockTxs[i] = types.Tx(tx)
+		}
+		require.NoError(t, client.RunBlockWithOptions(BlockOptions{Txs: &blockTxs}))
+	}
+
+	runBlockWithTxs("a=1")
+	runBlockWithTxs("b=2")
+	// the apps as they were at the snapshot at height 2, by network address
+	restored := make(map[string]db.DB, len(dbs))
+	for i, address := range client.ClientOrder {
+		restored[client.Clients[address].NetworkAddress] = copyDB(t, dbs[i])
+	}
+	runBlockWithTxs("c=3")
+	runBlockWithTxs("d=4")
+	runBlockWithTxs("e=5")
+	original4, err := client.Storage.GetBlock(4)
+	require.NoError(t, err)
+
+	client.ConnectClient = func(networkAddress string) (abciclient.Client, error) {
+		app := kvstore.NewApplication(copyDB(t, restored[networkAddress]))
+		appClient := abciclient.NewLocalClient(nil, app)
+		if err := appClient.Start(); err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() { _ = appClient.Stop() })
+		return appClient, nil
+	}
+
+	// more transactions change the app hash of the kvstore, which is derived from the number of keys
+	changedTxs := types.Txs{types.Tx("x=1"), types.Tx("y=2")}
+	replayed, err := client.ReplayFromSnapshot(2, nil, []BlockChange{{Height: 4, Txs: &changedTxs}})
+	require.NoError(t, err)
+	require.Len(t, replayed, 3)
+
+	require.Equal(t, int64(3), replayed[0].Height)
+	require.False(t, replayed[0].Changed)
+	require.False(t, replayed[0].Diverged)
+	require.Equal(t, replayed[0].OriginalAppHash, replayed[0].AppHash)
+
+	require.Equal(t, int64(4), replayed[1].Height)
+	require.True(t, replayed[1].Changed)
+	require.True(t, replayed[1].Diverged)
+	require.NotEqual(t, replayed[1].OriginalAppHash, replayed[1].AppHash)
+
+	// the unchanged block after the changed one diverges as well, since the state before it changed
+	require.False(t, replayed[2].Changed)
+	require.True(t, replayed[2].Diverged)
+
+	// the replayed blocks replace the original blocks, and keep their timestamps
+	require.Equal(t, int64(5), client.LastBlockHeight())
+	block4, err := client.Storage.GetBlock(4)
+	require.NoError(t, err)
+	require.Equal(t, changedTxs, block4.Txs)
+	require.Equal(t, original4.Time, block4.Time)
+	require.Equal(t, original4.ProposerAddress, block4.ProposerAddress)
+
+	res, err := client.Clients[client.ClientOrder[0]].Client.Query(
+		context.Background(), &abcitypes.RequestQuery{Data: []byte("x")})
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), res.Value)
+}
+
+func TestReplayFromSnapshotErrors(t *testing.T) {
+	client := newTestClient(t, kvstore.NewInMemoryApplication())
+	require.NoError(t, client.SetSnapshotPolicy(2, 0))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.RunBlock())
+	}
+
+	testCases := []struct {
+		name    string
+		height  int64
+		changes []BlockChange
+	}{
+		{name: "no snapshot at the height", height: 1},
+		{name: "change before the snapshot", height: 2, changes: []BlockChange{{Height: 2}}},
+		{name: "change after the last block", height: 2, changes: []BlockChange{{Height: 4}}},
+		{name: "two changes at one height", height: 2, changes: []BlockChange{{Height: 3}, {Height: 3}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.ReplayFromSnapshot(tc.height, nil, tc.changes)
+			require.Error(t, err)
+			// the chain is not rewound
+			require.Equal(t, int64(3), client.LastBlockHeight())
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package abci_client
 
 import (
+	"fmt"
+
 	abciclient "github.com/cometbft/cometbft/abci/client"
 	"github.com/cometbft/cometbft/types"
 )
@@ -10,15 +12,35 @@ import (
 // * the address of the app
 // * whether the app is alive
 // * the priv validator associated with that app (i.e. its private key)
+// * whether the app is an observer
 type AbciCounterpartyClient struct {
-	Client           abciclient.Client
-	NetworkAddress   string
+	Client         abciclient.Client
+	NetworkAddress string
+	// the address of the validator of the app, which is the key of the app in AbciClient.Clients.
+	// For observers, this is the id of the observer, see ObserverID
 	ValidatorAddress string
-	PrivValidator    types.PrivValidator
+	// nil for observers
+	PrivValidator types.PrivValidator
+	// Observers execute all blocks like the apps of validators, but have no validator identity,
+	// so they never propose, process proposals or sign, like the apps of full nodes.
+	Observer bool
+	// For executors, the address of the validator whose identity the executor shares, see AddExecutor.
+	// The validator address of an executor is the id of the executor, see ExecutorID
+	ExecutorOf string
+}
+
+// ObserverID returns the id of the observer with the given index, in the order in which
+// the observers were given at startup. The id takes the place of the validator address for observers.
+func ObserverID(index int) string {
+	return fmt.Sprintf("observer-%d", index)
 }
 
 // NewAbciCounterpartyClient creates a new AbciCounterpartyClient.
+// The client is wrapped in a WatchedClient, so that the watchdog can detect when the app is unresponsive.
 func NewAbciCounterpartyClient(client abciclient.Client, networkAddress, validatorAddress string, privValidator types.PrivValidator) *AbciCounterpartyClient {
+	if _, ok := client.(*WatchedClient); !ok {
+		client = NewWatchedClient(client)
+	}
 	return &AbciCounterpartyClient{
 		Client:           client,
 		NetworkAddress:   networkAddress,
@@ -26,3 +48,10 @@ func NewAbciCounterpartyClient(client abciclient.Client, networkAddress, validat
 		PrivValidator:    privValidator,
 	}
 }
+
+// NewObserverClient creates a new AbciCounterpartyClient for an observer with the given index, see ObserverID.
+func NewObserverClient(client abciclient.Client, networkAddress string, index int) *AbciCounterpartyClient {
+	observer := NewAbciCounterpartyClient(client, networkAddress, ObserverID(index), nil)
+	observer.Observer = true
+	return observer
+}
@@ -0,0 +1,479 @@
+package abci_client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	dbm "github.com/cometbft/cometbft-db"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/privval"
+	cmtstate "github.com/cometbft/cometbft/proto/tendermint/state"
+	cometstate "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/store"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ExportedDataDir describes a data directory written by ExportDataDir.
+type ExportedDataDir struct {
+	Dir string `json:"dir"`
+	// the lowest and the highest height of the blocks in the block store
+	Base   int64 `json:"base"`
+	Height int64 `json:"height"`
+}
+
+// ExportDataDir writes the stored history of the chain into the given directory in the format of the data
+// directory of a CometBFT node, so that CometBFT nodes can take over the chain from the latest height:
+// the blocks with their commits into blockstore.db, the states, validator sets, consensus parameters and
+// FinalizeBlock responses into state.db, and the last sign state of the validators into priv_validator_state.json,
+// which is the same for all validators, since they all sign each block in the same round.
+// The commit of the latest block is signed again if needed, see handoverCommit.
+// The history starts at the lowest height that is still stored, see MapStorage.MaxHeights.
+// The apps need to stay at the latest height, i.e. no more blocks may be produced before the nodes take over.
+// The directory is created if it does not exist, and must be empty otherwise.
+func (a *AbciClient) ExportDataDir(dir string) (*ExportedDataDir, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%v is not empty", dir)
+	}
+
+	// the latest state is read while no block is produced, so that it belongs to the latest stored block
+	a.Storage.LockBeforeStateUpdate()
+	latestState := a.CurState.Copy()
+	a.Storage.UnlockAfterStateUpdate()
+
+	height := latestState.LastBlockHeight
+	if height < latestState.InitialHeight {
+		return nil, fmt.Errorf("no blocks were produced yet")
+	}
+	base := max(latestState.InitialHeight, a.Storage.Info().PrunedBelow)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	blockStoreDB, err := dbm.NewDB("blockstore", dbm.GoLevelDBBackend, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the block store: %w", err)
+	}
+	blockStore := store.NewBlockStore(blockStoreDB)
+	defer blockStore.Close()
+	stateDB, err := dbm.NewDB("state", dbm.GoLevelDBBackend, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the state store: %w", err)
+	}
+	stateStore := cometstate.NewStore(stateDB, cometstate.StoreOptions{})
+	defer stateStore.Close()
+
+	// the storage holds the state before each block, and the state store needs a state to start from.
+	// Like after state sync, the validator sets and consensus parameters are stored in full at the base,
+	// so that later heights do not refer to heights before the base
+	baseState, err := a.Storage.GetState(base)
+	if err != nil {
+		return nil, err
+	}
+	bootstrapState := baseState.Copy()
+	bootstrapState.LastHeightValidatorsChanged = base
+	bootstrapState.LastHeightConsensusParamsChanged = base
+	if err := stateStore.Bootstrap(bootstrapState); err != nil {
+		return nil, fmt.Errorf("error storing the state before height %d: %w", base, err)
+	}
+
+	var lastCommit *types.ExtendedCommit
+	for h := base; h <= height; h++ {
+		block, err := a.Storage.GetBlock(h)
+		if err != nil {
+			return nil, err
+		}
+		extendedCommit, err := a.Storage.GetExtendedCommit(h)
+		if err != nil {
+			return nil, err
+		}
+		responses, err := a.Storage.GetResponses(h)
+		if err != nil {
+			return nil, err
+		}
+		stateBefore, err := a.Storage.GetState(h)
+		if err != nil {
+			return nil, err
+		}
+		if h == height {
+			extendedCommit, err = a.handoverCommit(block, extendedCommit, stateBefore)
+			if err != nil {
+				return nil, err
+			}
+		}
+		stateAfter := latestState
+		if h < height {
+			nextState, err := a.Storage.GetState(h + 1)
+			if err != nil {
+				return nil, err
+			}
+			stateAfter = nextState.Copy()
+		}
+		stateAfter.LastHeightValidatorsChanged = max(stateAfter.LastHeightValidatorsChanged, base)
+		stateAfter.LastHeightConsensusParamsChanged = max(stateAfter.LastHeightConsensusParamsChanged, base)
+		// the block ID in the state of CometMock has another part set header than the block ID that was signed,
+		// see utils.GetBlockIdFromBlock, but CometBFT verifies the commit of the next block against the state
+		stateAfter.LastBlockID = extendedCommit.BlockID
+
+		blockParts, err := block.MakePartSet(types.BlockPartSizeBytes)
+		if err != nil {
+			return nil, fmt.Errorf("error making the part set of block %d: %w", h, err)
+		}
+		if stateBefore.ConsensusParams.ABCI.VoteExtensionsEnabled(h) {
+			if err := extendedCommit.EnsureExtensions(true); err != nil {
+				return nil, fmt.Errorf("the commit of block %d lacks vote extensions: %w", h, err)
+			}
+			blockStore.SaveBlockWithExtendedCommit(block, blockParts, extendedCommit)
+		} else {
+			blockStore.SaveBlock(block, blockParts, extendedCommit.ToCommit())
+		}
+
+		if err := stateStore.SaveFinalizeBlockResponse(h, responses); err != nil {
+			return nil, fmt.Errorf("error storing the responses of block %d: %w", h, err)
+		}
+		if err := stateStore.Save(stateAfter); err != nil {
+			return nil, fmt.Errorf("error storing the state after block %d: %w", h, err)
+		}
+		lastCommit = extendedCommit
+	}
+
+	// the validators precommitted the latest block, so they must not sign anything up to that
+	signState := privval.FilePVLastSignState{
+		Height: height,
+		Round:  lastCommit.Round,
+		Step:   stepPrecommit,
+	}
+	signStateBytes, err := cmtjson.MarshalIndent(&signState, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "priv_validator_state.json"), signStateBytes, 0o600); err != nil {
+		return nil, err
+	}
+
+	a.Logger.Info("Exported data directory", "dir", dir, "base", base, "height", height)
+	return &ExportedDataDir{Dir: dir, Base: base, Height: height}, nil
+}
+
+// handoverCommit returns the commit of the latest block in a form that CometBFT can build the next block on.
+// CometBFT takes the time of the next block from the median of the timestamps of the votes in the commit,
+// which must be after the time of the latest block, but unless BFTTime is enabled, the votes of CometMock
+// have the time of the block. In that case, the votes of the validators whose keys CometMock has
+// are signed again, a millisecond after the time of the block.
+// state is the state before the block.
+func (a *AbciClient) handoverCommit(block *types.Block, extendedCommit *types.ExtendedCommit, state *cometstate.State) (*types.ExtendedCommit, error) {
+	if cometstate.MedianTime(extendedCommit.ToCommit(), state.Validators).After(block.Time) {
+		return extendedCommit, nil
+	}
+
+	extensionsEnabled := state.ConsensusParams.ABCI.VoteExtensionsEnabled(block.Height)
+	timestamp := block.Time.Add(time.Millisecond)
+	commit := extendedCommit.Clone()
+	for i, signature := range commit.ExtendedSignatures {
+		if signature.BlockIDFlag != types.BlockIDFlagCommit {
+			continue
+		}
+		privVal := a.privValidator(signature.ValidatorAddress.String())
+		if privVal == nil {
+			continue
+		}
+
+		vote := commit.GetExtendedVote(int32(i)).ToProto()
+		vote.Timestamp = timestamp
+		if err := privVal.SignVote(state.ChainID, vote); err != nil {
+			a.Logger.Error("Could not sign the commit again", "validator", a.validatorLabel(signature.ValidatorAddress.String()), "err", err)
+			continue
+		}
+		// signers that already signed the vote return the old signature with its old timestamp
+		if !vote.Timestamp.Equal(timestamp) {
+			continue
+		}
+		signature.Timestamp = vote.Timestamp
+		signature.Signature = vote.Signature
+		if extensionsEnabled {
+			signature.ExtensionSignature = vote.ExtensionSignature
+		}
+		commit.ExtendedSignatures[i] = signature
+	}
+
+	if !cometstate.MedianTime(commit.ToCommit(), state.Validators).After(block.Time) {
+		return nil, fmt.Errorf("the votes for block %d cannot be signed again with a later time, "+
+			"so CometBFT could not produce the next block. Run CometMock with --bft-time to timestamp votes after their block", block.Height)
+	}
+	return commit, nil
+}
+
+// privValidator returns the priv validator of the validator with the given address, or nil if CometMock does not have its key.
+func (a *AbciClient) privValidator(address string) types.PrivValidator {
+	a.clientsMutex.RLock()
+	client, ok := a.Clients[address]
+	a.clientsMutex.RUnlock()
+	if ok && client.PrivValidator != nil {
+		return client.PrivValidator
+	}
+	if placeholder := a.placeholderClient(address); placeholder != nil {
+		return placeholder.PrivValidator
+	}
+	return nil
+}
+
+// ImportDataDir adopts the chain from the data directory of a stopped CometBFT node, instead of starting it
+// from the genesis with SendInitChain: the latest state of the node becomes the current state, so that the next block
+// is produced at the height after the latest block of the node, with the validator set of the node.
+// The blocks, commits, validator sets, consensus parameters and FinalizeBlock responses of the node are imported
+// into the storage and indexed, from the latest height back to the lowest height for which the node stored all of them.
+// The apps need to be at the latest height of the node with its app hash, e.g. the apps of the node itself.
+// genesisDoc is the genesis of the chain, which is kept like by SendInitChain, e.g. for ResetChain.
+// It returns the lowest imported height.
+// It needs to be called instead of SendInitChain, before any block is produced.
+func (a *AbciClient) ImportDataDir(dir string, genesisDoc *types.GenesisDoc) (int64, error) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	for _, name := range []string{"blockstore.db", "state.db"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return 0, fmt.Errorf("no %v found in %v: %w", name, dir, err)
+		}
+	}
+	blockStoreDB, err := dbm.NewDB("blockstore", dbm.GoLevelDBBackend, dir)
+	if err != nil {
+		return 0, fmt.Errorf("error opening the block store in %v: %w", dir, err)
+	}
+	blockStore := store.NewBlockStore(blockStoreDB)
+	defer blockStore.Close()
+	stateDB, err := dbm.NewDB("state", dbm.GoLevelDBBackend, dir)
+	if err != nil {
+		return 0, fmt.Errorf("error opening the state store in %v: %w", dir, err)
+	}
+	stateStore := cometstate.NewStore(stateDB, cometstate.StoreOptions{})
+	defer stateStore.Close()
+
+	latestState, err := stateStore.Load()
+	if err != nil {
+		return 0, fmt.Errorf("error loading the state of the node: %w", err)
+	}
+	if latestState.IsEmpty() {
+		return 0, fmt.Errorf("there is no state in %v", dir)
+	}
+	if latestState.ChainID != genesisDoc.ChainID {
+		return 0, fmt.Errorf("the node in %v is on chain %v, but the genesis is for chain %v", dir, latestState.ChainID, genesisDoc.ChainID)
+	}
+	height := latestState.LastBlockHeight
+	if height < latestState.InitialHeight {
+		return 0, fmt.Errorf("the node in %v did not commit any blocks yet", dir)
+	}
+	// CometBFT saves a block before the state after it, and completes the state the next time it starts
+	if blockStore.Height() != height {
+		return 0, fmt.Errorf("the block store in %v is at height %d, but the state at height %d. Start and stop the node once to let it recover",
+			dir, blockStore.Height(), height)
+	}
+	if err := a.checkAppsAt(height, latestState.AppHash); err != nil {
+		return 0, err
+	}
+
+	stores := &nodeStores{
+		blockStore:  blockStore,
+		stateStore:  stateStore,
+		latestState: latestState,
+		genesisTime: genesisDoc.GenesisTime,
+	}
+	latest, err := stores.load(height)
+	if err != nil {
+		return 0, fmt.Errorf("error importing the latest block: %w", err)
+	}
+	if latestState.ConsensusParams.ABCI.VoteExtensionsEnabled(height) {
+		if err := latest.extendedCommit.EnsureExtensions(true); err != nil {
+			return 0, fmt.Errorf("the node did not store the vote extensions of the latest block, which the next block needs: %w", err)
+		}
+	}
+
+	// nodes prune their stores, so the history starts at the lowest height whose entries are all still there
+	base := height
+	for base > max(blockStore.Base(), latestState.InitialHeight) {
+		if _, err := stores.load(base - 1); err != nil {
+			a.Logger.Info("Not importing the heights before an incomplete height", "height", base-1, "err", err)
+			break
+		}
+		base--
+	}
+
+	if base > latestState.InitialHeight {
+		a.Storage.PruneBelow(base)
+	}
+	for h := base; h <= height; h++ {
+		entry := latest
+		if h < height {
+			if entry, err = stores.load(h); err != nil {
+				return 0, err
+			}
+		}
+		a.Storage.LockBeforeStateUpdate()
+		err := a.Storage.UpdateStores(h, entry.block, entry.extendedCommit, entry.state, entry.responses)
+		a.Storage.UnlockAfterStateUpdate()
+		if err != nil {
+			return 0, fmt.Errorf("error storing height %d: %w", h, err)
+		}
+	}
+
+	genesisState, err := cometstate.MakeGenesisState(genesisDoc)
+	if err != nil {
+		return 0, err
+	}
+	a.Storage.LockBeforeStateUpdate()
+	a.CurState = latestState
+	a.LastBlock = latest.block
+	a.LastCommit = latest.extendedCommit
+	a.Storage.UnlockAfterStateUpdate()
+	a.genesisDoc = genesisDoc
+	a.initChainRequest = CreateInitChainRequest(genesisState, genesisDoc)
+	a.logValidatorKeys()
+
+	if err := a.reindex(height); err != nil {
+		return 0, err
+	}
+
+	a.Logger.Info("Imported data directory", "dir", dir, "base", base, "height", height, "app_hash", latestState.AppHash)
+	return base, nil
+}
+
+// checkAppsAt checks that the connected apps are at the given height, with the given app hash.
+func (a *AbciClient) checkAppsAt(height int64, appHash []byte) error {
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
+	for _, client := range a.Clients {
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		info, err := client.Client.Info(ctx, &abcitypes.RequestInfo{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error calling Info on app at %v: %w", client.NetworkAddress, err)
+		}
+		if info.LastBlockHeight != height {
+			return fmt.Errorf("app at %v is at height %d, but needs to be at height %d", client.NetworkAddress, info.LastBlockHeight, height)
+		}
+		if !bytes.Equal(info.LastBlockAppHash, appHash) {
+			return fmt.Errorf("app at %v has app hash %X, but needs to have app hash %X", client.NetworkAddress, info.LastBlockAppHash, appHash)
+		}
+	}
+	return nil
+}
+
+// nodeStores reads the history of the chain from the block store and the state store of a CometBFT node.
+type nodeStores struct {
+	blockStore  *store.BlockStore
+	stateStore  cometstate.Store
+	latestState cometstate.State
+	genesisTime time.Time
+}
+
+// nodeHeight holds what CometMock stores for a height, see storage.Storage.
+type nodeHeight struct {
+	block          *types.Block
+	extendedCommit *types.ExtendedCommit
+	// the state before the block
+	state     *cometstate.State
+	responses *abcitypes.ResponseFinalizeBlock
+}
+
+// load reads the entries of the given height, or returns an error if the node did not store all of them.
+func (n *nodeStores) load(height int64) (*nodeHeight, error) {
+	block := n.blockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block %d is not stored", height)
+	}
+
+	// the commits of earlier blocks are the last commits of the blocks after them,
+	// and the commit of the latest block is the one the node saw
+	extendedCommit := n.blockStore.LoadBlockExtendedCommit(height)
+	if extendedCommit == nil {
+		commit := n.blockStore.LoadBlockCommit(height)
+		if commit == nil {
+			commit = n.blockStore.LoadSeenCommit(height)
+		}
+		if commit == nil {
+			return nil, fmt.Errorf("the commit of block %d is not stored", height)
+		}
+		extendedCommit = commit.WrappedExtendedCommit()
+	}
+
+	responses, err := n.stateStore.LoadFinalizeBlockResponse(height)
+	if err != nil && height == n.latestState.LastBlockHeight {
+		// nodes that discard the responses keep those of the latest block
+		responses, err = n.stateStore.LoadLastFinalizeBlockResponse(height)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the responses of block %d are not stored: %w", height, err)
+	}
+
+	state, err := n.stateBefore(block)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeHeight{
+		block:          block,
+		extendedCommit: extendedCommit,
+		state:          state,
+		responses:      responses,
+	}, nil
+}
+
+// stateBefore puts together the state before the given block. The node only stores its latest state,
+// but the validator sets and consensus parameters of each height, and the block has the hashes of the state.
+func (n *nodeStores) stateBefore(block *types.Block) (*cometstate.State, error) {
+	height := block.Height
+	validators, err := n.stateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	nextValidators, err := n.stateStore.LoadValidators(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	lastValidators := types.NewValidatorSet(nil)
+	lastBlockTime := n.genesisTime
+	if height > n.latestState.InitialHeight {
+		if lastValidators, err = n.stateStore.LoadValidators(height - 1); err != nil {
+			return nil, err
+		}
+		// the time of a pruned block is not known, e.g. before the first block after state sync
+		lastBlockTime = time.Time{}
+		if meta := n.blockStore.LoadBlockMeta(height - 1); meta != nil {
+			lastBlockTime = meta.Header.Time
+		}
+	}
+	consensusParams, err := n.stateStore.LoadConsensusParams(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cometstate.State{
+		Version: cmtstate.Version{
+			Consensus: block.Version,
+			Software:  n.latestState.Version.Software,
+		},
+		ChainID:         n.latestState.ChainID,
+		InitialHeight:   n.latestState.InitialHeight,
+		LastBlockHeight: height - 1,
+		LastBlockID:     block.LastBlockID,
+		LastBlockTime:   lastBlockTime,
+		NextValidators:  nextValidators,
+		Validators:      validators,
+		LastValidators:  lastValidators,
+		// the node does not store when the validators and consensus parameters changed before its latest state.
+		// Like this, the full validator sets and consensus parameters are written for each height when the
+		// history is exported again, see ExportDataDir
+		LastHeightValidatorsChanged:      height + 1,
+		ConsensusParams:                  consensusParams,
+		LastHeightConsensusParamsChanged: height,
+		LastResultsHash:                  block.LastResultsHash,
+		AppHash:                          block.AppHash,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package abci_client
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/state"
+)
+
+// DeterminismCheck decides what happens when the apps respond differently to the same request.
+type DeterminismCheck string
+
+const (
+	// DeterminismCheckStrict returns an error when the responses are not all equal.
+	DeterminismCheckStrict DeterminismCheck = "strict"
+	// DeterminismCheckWarn logs an error when the responses are not all equal, and carries on
+	// with the response of the first app.
+	DeterminismCheckWarn DeterminismCheck = "warn"
+	// DeterminismCheckOff does not compare the responses.
+	DeterminismCheckOff DeterminismCheck = "off"
+	// DeterminismCheckAppHash only compares the app hash and the hash of the transaction results
+	// of FinalizeBlock responses, and returns an error when they differ.
+	// Responses to other methods are not compared.
+	// This catches divergences that break consensus with much less overhead than a strict check.
+	DeterminismCheckAppHash DeterminismCheck = "app_hash"
+)
+
+// checkedMethods are the ABCI methods whose responses are compared.
+var checkedMethods = []string{"Info", "InitChain", "CheckTx", "Query", "FinalizeBlock", "Commit", "Invariant", "DoubleExecution", "PrepareProposal", "ProcessProposal", "ExtendVote"}
+
+// DeterminismChecks maps ABCI method names to the check for their responses.
+// Methods that are not in the map are checked with DeterminismCheckStrict,
+// unless a default is given for the method "*".
+type DeterminismChecks map[string]DeterminismCheck
+
+// For returns the check for the given ABCI method.
+func (d DeterminismChecks) For(method string) DeterminismCheck {
+	if check, ok := d[method]; ok {
+		return check
+	}
+	if check, ok := d["*"]; ok {
+		return check
+	}
+	return DeterminismCheckStrict
+}
+
+// ParseDeterminismChecks parses a comma-separated list of method=check pairs,
+// e.g. "FinalizeBlock=strict,Info=off,CheckTx=warn".
+// The method "*" sets the check for all methods that are not listed.
+func ParseDeterminismChecks(s string) (DeterminismChecks, error) {
+	checks := make(DeterminismChecks)
+	if strings.TrimSpace(s) == "" {
+		return checks, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		method, check, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid determinism check %q, must be of the form method=check", pair)
+		}
+
+		if method != "*" && !slices.Contains(checkedMethods, method) {
+			return nil, fmt.Errorf("unknown ABCI method %q, must be one of %v or *", method, checkedMethods)
+		}
+
+		switch DeterminismCheck(check) {
+		case DeterminismCheckStrict, DeterminismCheckWarn, DeterminismCheckOff, DeterminismCheckAppHash:
+			checks[method] = DeterminismCheck(check)
+		default:
+			return nil, fmt.Errorf("unknown determinism check %q for method %v, must be one of %q, %q, %q or %q",
+				check, method, DeterminismCheckStrict, DeterminismCheckWarn, DeterminismCheckOff, DeterminismCheckAppHash)
+		}
+	}
+	return checks, nil
+}
+
+// Divergence describes an occasion where the responses of the apps were not all equal.
+type Divergence struct {
+	// the height of the latest block when the divergence occurred
+	Height int64            `json:"height"`
+	Method string           `json:"method"`
+	Error  string           `json:"error"`
+	Check  DeterminismCheck `json:"check"`
+	Time   time.Time        `json:"time"`
+}
+
+// LastDivergence returns the last time the responses of the apps were not all equal,
+// or nil if no divergence was detected so far.
+func (a *AbciClient) LastDivergence() *Divergence {
+	a.lastDivergenceMutex.RLock()
+	defer a.lastDivergenceMutex.RUnlock()
+
+	return a.lastDivergence
+}
+
+// checkResponsesEqual checks whether the responses of the clients to the given ABCI method are all equal,
+// according to the DeterminismChecks for the method.
+// If they are not, the divergence is recorded as the last divergence, and
+// an error is returned if the method is checked strictly.
+func checkResponsesEqual[T any](a *AbciClient, method string, responses []T) error {
+	check := a.DeterminismChecks.For(method)
+	if check == DeterminismCheckOff {
+		return nil
+	}
+
+	if check == DeterminismCheckAppHash {
+		return checkAppHashesEqual(a, method, responses)
+	}
+
+	for i := 1; i < len(responses); i++ {
+		if !responsesEqual(responses[i], responses[0]) {
+			err := fmt.Errorf("responses are not all equal: %v is not equal to %v", responses[i], responses[0])
+
+			a.recordDivergence(method, check, err)
+
+			if check == DeterminismCheckWarn {
+				a.Logger.Error("Apps responded differently", "method", method, "err", err)
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// protoMessage is implemented by the gogoproto messages of ABCI.
+type protoMessage interface {
+	Marshal() ([]byte, error)
+}
+
+// responsesEqual returns whether the responses are equal. Protobuf messages are equal if their encodings are equal,
+// which is canonical for ABCI messages, so that e.g. nil and empty slices are equal like on the wire,
+// and is much faster than comparing them with reflection. Other responses are compared with reflect.DeepEqual.
+func responsesEqual[T any](x, y T) bool {
+	messageX, okX := any(x).(protoMessage)
+	messageY, okY := any(y).(protoMessage)
+	if okX && okY && !isNilPointer(x) && !isNilPointer(y) {
+		bzX, errX := messageX.Marshal()
+		bzY, errY := messageY.Marshal()
+		if errX == nil && errY == nil {
+			return bytes.Equal(bzX, bzY)
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// isNilPointer returns whether the value is a nil pointer, on which the generated Marshal methods panic.
+func isNilPointer(value any) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// checkAppHashesEqual returns an error if the responses are FinalizeBlock responses
+// that do not all have the same app hash and transaction results hash.
+// Responses of other types are not compared.
+func checkAppHashesEqual[T any](a *AbciClient, method string, responses []T) error {
+	hashes := make([]string, 0, len(responses))
+	for _, response := range responses {
+		finalizeBlockRes, ok := any(response).(*abcitypes.ResponseFinalizeBlock)
+		if !ok {
+			return nil
+		}
+		hashes = append(hashes, fmt.Sprintf("app hash %X, results hash %X",
+			finalizeBlockRes.AppHash, state.TxResultsHash(finalizeBlockRes.TxResults)))
+	}
+
+	for i := 1; i < len(hashes); i++ {
+		if hashes[i] != hashes[0] {
+			err := fmt.Errorf("hashes are not all equal: %v is not equal to %v", hashes[i], hashes[0])
+			a.recordDivergence(method, DeterminismCheckAppHash, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AbciClient) recordDivergence(method string, check DeterminismCheck, err error) {
+	a.lastDivergenceMutex.Lock()
+	defer a.lastDivergenceMutex.Unlock()
+
+	a.lastDivergence = &Divergence{
+		Height: a.CurState.LastBlockHeight,
+		Method: method,
+		Error:  err.Error(),
+		Check:  check,
+		Time:   time.Now(),
+	}
+}
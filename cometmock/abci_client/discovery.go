@@ -0,0 +1,139 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The schemes of app addresses that stand for several apps, which are discovered via DNS, see ExpandAppAddresses.
+const (
+	// dns://<name>:<port> stands for one app per A or AAAA record of the name, e.g. of a headless service in Kubernetes
+	DNSScheme = "dns://"
+	// dns+srv://<name> stands for one app per SRV record of the name, e.g. _abci._tcp.<headless service>
+	DNSSRVScheme = "dns+srv://"
+)
+
+// DNSLookupTimeout is the maximal time to wait for the records of an app address.
+const DNSLookupTimeout = 10 * time.Second
+
+// Resolver looks up the records that app addresses are expanded to, see ExpandAppAddresses.
+// net.DefaultResolver implements it.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// ExpandAppAddresses replaces the app addresses with the schemes DNSScheme and DNSSRVScheme
+// by the addresses of the apps they stand for, in place, so that the apps of one address are given
+// in a stable order:
+//   - dns://<name>:<port> is expanded to tcp://<ip>:<port> for each IP address of the name, ordered by IP address,
+//   - dns+srv://<name> is expanded to tcp://<target>:<port> for each SRV record of the name, ordered by target,
+//     where numbers in the targets are compared by value, so that app-2 comes before app-10.
+//
+// The targets of SRV records are host names, e.g. the names of the pods of a StatefulSet, which keep their identity
+// when the apps are restarted, and are resolved again each time CometMock connects to them.
+// The IP addresses of A and AAAA records are resolved only once, and change when an app is restarted,
+// so the order of the apps might change, too. Other addresses are kept as they are. Host names in them
+// are resolved each time CometMock connects to an app.
+func ExpandAppAddresses(ctx context.Context, resolver Resolver, addresses []string) ([]string, error) {
+	expanded := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		switch {
+		case strings.HasPrefix(address, DNSSRVScheme):
+			name := strings.TrimPrefix(address, DNSSRVScheme)
+			_, records, err := resolver.LookupSRV(ctx, "", "", name)
+			if err != nil {
+				return nil, fmt.Errorf("error looking up the SRV records of app address %v: %w", address, err)
+			}
+			if len(records) == 0 {
+				return nil, fmt.Errorf("app address %v has no SRV records", address)
+			}
+			sort.Slice(records, func(i, j int) bool {
+				if records[i].Target != records[j].Target {
+					return naturalLess(records[i].Target, records[j].Target)
+				}
+				return records[i].Port < records[j].Port
+			})
+			for _, record := range records {
+				target := strings.TrimSuffix(record.Target, ".")
+				expanded = append(expanded, "tcp://"+net.JoinHostPort(target, strconv.Itoa(int(record.Port))))
+			}
+		case strings.HasPrefix(address, DNSScheme):
+			host, port, err := net.SplitHostPort(strings.TrimPrefix(address, DNSScheme))
+			if err != nil {
+				return nil, fmt.Errorf("app address %v needs to be of the form %v<name>:<port>: %w", address, DNSScheme, err)
+			}
+			hosts, err := resolver.LookupHost(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("error looking up the IP addresses of app address %v: %w", address, err)
+			}
+			if len(hosts) == 0 {
+				return nil, fmt.Errorf("app address %v has no IP addresses", address)
+			}
+			ips := make([]netip.Addr, 0, len(hosts))
+			for _, host := range hosts {
+				ip, err := netip.ParseAddr(host)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing IP address %v of app address %v: %w", host, address, err)
+				}
+				ips = append(ips, ip)
+			}
+			sort.Slice(ips, func(i, j int) bool { return ips[i].Less(ips[j]) })
+			for _, ip := range ips {
+				expanded = append(expanded, "tcp://"+net.JoinHostPort(ip.String(), port))
+			}
+		default:
+			expanded = append(expanded, address)
+		}
+	}
+	return expanded, nil
+}
+
+// HasDNSAppAddresses returns whether any of the addresses is expanded by ExpandAppAddresses.
+func HasDNSAppAddresses(addresses []string) bool {
+	for _, address := range addresses {
+		if strings.HasPrefix(address, DNSScheme) || strings.HasPrefix(address, DNSSRVScheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// naturalLess compares the strings like strings do, but compares runs of digits by their value.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aValue := strings.TrimLeft(aDigits, "0")
+			bValue := strings.TrimLeft(bDigits, "0")
+			if len(aValue) != len(bValue) {
+				return len(aValue) < len(bValue)
+			}
+			if aValue != bValue {
+				return aValue < bValue
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// leadingDigits returns the digits at the start of the string.
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
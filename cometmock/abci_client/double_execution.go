@@ -0,0 +1,67 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// SetDoubleExecution makes the apps selected by the given targets execute each block twice.
+// A target is either the validator address of an app or its index in the app addresses,
+// like for the target of abci_query, or "all" for all apps.
+// Since the state of an app is only persisted on Commit, FinalizeBlock can be sent again
+// for the same block, and an app re-executes it from the state after the last block.
+// The responses of both executions are compared according to the determinism check for DoubleExecution,
+// which catches nondeterminism within a single binary, e.g. from map iteration or reading the system time,
+// that comparing the responses of different apps can miss, e.g. with a single validator.
+func (a *AbciClient) SetDoubleExecution(targets []string) error {
+	addresses := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		if target == "all" {
+			for _, address := range a.ClientOrder {
+				addresses[address] = true
+			}
+			continue
+		}
+
+		client, err := a.GetCounterpartyFromTarget(target)
+		if err != nil {
+			return fmt.Errorf("invalid double execution target %v: %w", target, err)
+		}
+		addresses[client.ValidatorAddress] = true
+	}
+	a.doubleExecution = addresses
+	return nil
+}
+
+// ExecutesTwice returns whether the app with the given validator address executes each block twice.
+func (a *AbciClient) ExecutesTwice(address string) bool {
+	return a.doubleExecution[address]
+}
+
+// finalizeBlockAgain sends the FinalizeBlock request a second time to the client,
+// and compares the response with the response to the first request.
+// It returns the second response, since the app commits the state from the second execution.
+func (a *AbciClient) finalizeBlockAgain(
+	client AbciCounterpartyClient,
+	request *abcitypes.RequestFinalizeBlock,
+	first *abcitypes.ResponseFinalizeBlock,
+) (*abcitypes.ResponseFinalizeBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	second, err := client.Client.FinalizeBlock(ctx, request)
+	cancel()
+	if err != nil {
+		return nil, &AppError{Method: "FinalizeBlock", App: client.NetworkAddress, Err: fmt.Errorf("error executing block %d again: %w", request.Height, err)}
+	}
+
+	err = checkResponsesEqual(a, "DoubleExecution", []*abcitypes.ResponseFinalizeBlock{first, second})
+	if err != nil {
+		return nil, &FinalizedBlockError{
+			Height: request.Height,
+			Reason: HaltReasonAppsDiverged,
+			Err:    fmt.Errorf("app %v executed the block differently the second time: %w", client.NetworkAddress, err),
+		}
+	}
+	return second, nil
+}
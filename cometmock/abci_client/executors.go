@@ -0,0 +1,88 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+)
+
+// ExecutorID returns the id of the executor with the given index among the executors of the validator
+// with the given address. The id takes the place of the validator address for executors.
+func ExecutorID(validatorAddress string, index int) string {
+	return fmt.Sprintf("%v-executor-%d", validatorAddress, index)
+}
+
+// AddExecutor adds a redundant executor for the validator selected by the target, which is either
+// the validator address of its app or its index in the app addresses, like for the target of abci_query.
+// An executor is another instance of the app of the validator, e.g. a different build of the app,
+// with the same validator identity: it executes every block and receives the same PrepareProposal,
+// ProcessProposal and ExtendVote requests as the app of the validator, but only the app of the validator
+// signs, and only its responses are used.
+// The responses of the executor are compared with those of the app of the validator according to the
+// determinism checks, which allows diffing two builds of an app under the same consensus identity.
+// Should only be called before the chain is initialized.
+func (a *AbciClient) AddExecutor(target string, client abciclient.Client, networkAddress string) (*AbciCounterpartyClient, error) {
+	validator, err := a.GetCounterpartyFromTarget(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid executor target %v: %w", target, err)
+	}
+	if validator.Observer || validator.ExecutorOf != "" {
+		return nil, fmt.Errorf("invalid executor target %v: executors can only be added for the apps of validators", target)
+	}
+
+	id := ExecutorID(validator.ValidatorAddress, len(a.executorsOf(validator.ValidatorAddress)))
+	executor := NewAbciCounterpartyClient(client, networkAddress, id, nil)
+	executor.ExecutorOf = validator.ValidatorAddress
+
+	a.clientsMutex.Lock()
+	a.Clients[id] = *executor
+	a.ClientOrder = append(a.ClientOrder, id)
+	a.clientsMutex.Unlock()
+	return executor, nil
+}
+
+// executorsOf returns the executors of the validator with the given address, in the order in which they were added.
+func (a *AbciClient) executorsOf(validatorAddress string) []AbciCounterpartyClient {
+	executors := make([]AbciCounterpartyClient, 0)
+	if validatorAddress == "" {
+		return executors
+	}
+	for _, id := range a.ClientOrder {
+		if client := a.Clients[id]; client.ExecutorOf == validatorAddress {
+			executors = append(executors, client)
+		}
+	}
+	return executors
+}
+
+// checkExecutors sends a request that was answered by the given app to the executors of its validator,
+// and compares their responses with the response of the app according to the determinism check for the method.
+func checkExecutors[T any](
+	a *AbciClient,
+	method string,
+	app *AbciCounterpartyClient,
+	response T,
+	send func(ctx context.Context, client abciclient.Client) (T, error),
+) error {
+	executors := a.executorsOf(app.ValidatorAddress)
+	if len(executors) == 0 {
+		return nil
+	}
+
+	responses := []T{response}
+	for _, executor := range executors {
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		executorResponse, err := send(ctx, executor.Client)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error from %v of executor %v: %w", method, executor.ValidatorAddress, err)
+		}
+		responses = append(responses, executorResponse)
+	}
+
+	if err := checkResponsesEqual(a, method, responses); err != nil {
+		return fmt.Errorf("executors of validator %v responded differently to %v: %w", app.ValidatorAddress, method, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package abci_client
+
+import (
+	"fmt"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+)
+
+// MaxExportHeights is the maximal number of heights that can be requested from ExportIndex at once.
+const MaxExportHeights = 1000
+
+// ExportedBlock is a block with the events and transaction results that are indexed for it, see ExportIndex.
+type ExportedBlock struct {
+	Height int64             `json:"height"`
+	Time   time.Time         `json:"time"`
+	Hash   cmtbytes.HexBytes `json:"hash"`
+	// the events of FinalizeBlock that are not emitted by a transaction
+	Events []abcitypes.Event `json:"events"`
+	Txs    []ExportedTx      `json:"txs"`
+}
+
+// ExportedTx is the result of executing a transaction of an ExportedBlock.
+type ExportedTx struct {
+	// the index of the transaction in the block
+	Index     uint32            `json:"index"`
+	Hash      cmtbytes.HexBytes `json:"hash"`
+	Code      uint32            `json:"code"`
+	Codespace string            `json:"codespace"`
+	Log       string            `json:"log"`
+	GasWanted int64             `json:"gas_wanted"`
+	GasUsed   int64             `json:"gas_used"`
+	Events    []abcitypes.Event `json:"events"`
+}
+
+// ExportIndex returns the blocks with heights from from to to, inclusive, with the events and transaction results
+// that the block and transaction indexers index, so that they can be analyzed without paging through tx_search
+// and block_search. If from is 0, the export starts at the initial height, and if to is 0, it ends at the latest height.
+// The blocks are read from the storage, so blocks that were produced without indexing are included, too.
+func (a *AbciClient) ExportIndex(from, to int64) ([]ExportedBlock, error) {
+	initialHeight := a.CurState.InitialHeight
+	lastHeight := a.CurState.LastBlockHeight
+
+	if from == 0 {
+		from = initialHeight
+	}
+	if to == 0 {
+		to = lastHeight
+	}
+	if from < 0 || to < 0 {
+		return nil, fmt.Errorf("heights must not be negative, but got from %d and to %d", from, to)
+	}
+	if from > to {
+		return nil, fmt.Errorf("from %d must be less than or equal to to %d", from, to)
+	}
+	if to-from >= MaxExportHeights {
+		return nil, fmt.Errorf("at most %d heights can be requested at once, but got %d", MaxExportHeights, to-from+1)
+	}
+
+	blocks := make([]ExportedBlock, 0, to-from+1)
+	for height := max(from, initialHeight); height <= min(to, lastHeight); height++ {
+		block, err := a.Storage.GetBlock(height)
+		if err != nil {
+			return nil, err
+		}
+		responses, err := a.Storage.GetResponses(height)
+		if err != nil {
+			return nil, err
+		}
+
+		exported := ExportedBlock{
+			Height: height,
+			Time:   block.Time,
+			Hash:   block.Hash(),
+			Events: responses.Events,
+			Txs:    make([]ExportedTx, len(block.Txs)),
+		}
+		for i, tx := range block.Txs {
+			result := responses.TxResults[i]
+			exported.Txs[i] = ExportedTx{
+				Index:     uint32(i),
+				Hash:      tx.Hash(),
+				Code:      result.Code,
+				Codespace: result.Codespace,
+				Log:       result.Log,
+				GasWanted: result.GasWanted,
+				GasUsed:   result.GasUsed,
+				Events:    result.Events,
+			}
+		}
+		blocks = append(blocks, exported)
+	}
+	return blocks, nil
+}
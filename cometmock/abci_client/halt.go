@@ -0,0 +1,362 @@
+package abci_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ErrHalted is returned when a block should be produced while block production is halted.
+var ErrHalted = errors.New("block production is halted")
+
+// HaltReasonUpgrade is the reason for halts where the apps stopped
+// to be upgraded, like Cosmos SDK apps do at the height of a scheduled upgrade.
+const HaltReasonUpgrade = "upgrade"
+
+// HaltReasonAppUnreachable is the reason for halts where an app became unreachable
+// for another reason than an upgrade, e.g. because it crashed.
+const HaltReasonAppUnreachable = "app_unreachable"
+
+// HaltReasonAppError is the reason for halts where an app returned an error
+// from FinalizeBlock or Commit, but is still reachable.
+const HaltReasonAppError = "app_error"
+
+// HaltReasonBlockNotStored is the reason for halts where the apps finalized a block,
+// but it could not be stored, e.g. because of a failpoint, so it cannot be proposed to them again.
+const HaltReasonBlockNotStored = "block_not_stored"
+
+// HaltReasonAppsDiverged is the reason for halts where the apps finalized a block, but responded differently,
+// or an app executed it differently the second time, so it cannot be proposed to them again.
+const HaltReasonAppsDiverged = "apps_diverged"
+
+// AppError is returned when an app returns an error from FinalizeBlock or Commit while a block is produced.
+type AppError struct {
+	Method string
+	// the network address of the app
+	App string
+	Err error
+}
+
+func (e *AppError) Error() string {
+	return fmt.Sprintf("error from %v of app %v: %v", e.Method, e.App, e.Err)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// FinalizedBlockError is returned when the apps finalized a block, but it could not be stored,
+// the state could not be updated after it, or the responses of the apps differed.
+type FinalizedBlockError struct {
+	Height int64
+	// the reason to halt with, HaltReasonBlockNotStored if empty
+	Reason string
+	Err    error
+}
+
+func (e *FinalizedBlockError) Error() string {
+	if e.reason() == HaltReasonAppsDiverged {
+		return fmt.Sprintf("the apps finalized the block at height %d, but diverged: %v", e.Height, e.Err)
+	}
+	return fmt.Sprintf("the apps finalized the block at height %d, but it was not stored: %v", e.Height, e.Err)
+}
+
+func (e *FinalizedBlockError) reason() string {
+	if e.Reason == "" {
+		return HaltReasonBlockNotStored
+	}
+	return e.Reason
+}
+
+func (e *FinalizedBlockError) Unwrap() error {
+	return e.Err
+}
+
+// HaltInfo describes why block production is halted.
+type HaltInfo struct {
+	// the height of the block that could not be produced
+	Height int64  `json:"height"`
+	Reason string `json:"reason"`
+	// the error that occurred when producing the block
+	Error string `json:"error"`
+	// for app errors, the address of the app that returned the error
+	App string `json:"app,omitempty"`
+	// the addresses of the apps that were unreachable
+	UnreachableApps []string  `json:"unreachable_apps"`
+	Time            time.Time `json:"time"`
+}
+
+// Halted returns why block production is halted, or nil if it is not halted.
+func (a *AbciClient) Halted() *HaltInfo {
+	a.haltMutex.RLock()
+	defer a.haltMutex.RUnlock()
+
+	return a.halt
+}
+
+// haltIfAppsUnreachable checks whether the failure to produce a block was caused by
+// apps that are no longer reachable, which happens when apps stop for an upgrade:
+// Cosmos SDK apps panic at the upgrade height, which closes the connection.
+// In that case, block production is halted until Resume is called.
+// It returns the error to report for the failed block.
+func (a *AbciClient) haltIfAppsUnreachable(height int64, blockErr error) error {
+	unreachable := a.unreachableApps()
+	if len(unreachable) == 0 {
+		return blockErr
+	}
+
+	reason := HaltReasonAppUnreachable
+	if strings.Contains(blockErr.Error(), "UPGRADE") {
+		reason = HaltReasonUpgrade
+	} else {
+		for _, client := range a.Clients {
+			if err := client.Client.Error(); err != nil && strings.Contains(err.Error(), "UPGRADE") {
+				reason = HaltReasonUpgrade
+			}
+		}
+	}
+
+	// an app that closes the connection without reporting an error is most likely
+	// stopping for an upgrade, which is the common cause for all apps disappearing at once
+	if reason == HaltReasonAppUnreachable && len(unreachable) == len(a.Clients) {
+		reason = HaltReasonUpgrade
+	}
+
+	haltInfo := &HaltInfo{
+		Height:          height,
+		Reason:          reason,
+		Error:           blockErr.Error(),
+		UnreachableApps: unreachable,
+		Time:            time.Now(),
+	}
+
+	a.haltMutex.Lock()
+	a.halt = haltInfo
+	a.haltMutex.Unlock()
+
+	a.Logger.Error("Halting block production", "height", height, "reason", reason, "unreachable_apps", unreachable, "err", blockErr)
+
+	return fmt.Errorf("%w at height %d (%s): %v", ErrHalted, height, reason, blockErr)
+}
+
+// haltIfAppFailed halts block production if the failure to produce a block was caused by an app
+// that returned an error from FinalizeBlock or Commit, until Resume is called.
+// Since the app is still reachable, it can be inspected, e.g. via abci_query, while block production is halted.
+// It returns the error to report for the failed block.
+func (a *AbciClient) haltIfAppFailed(height int64, blockErr error) error {
+	var appErr *AppError
+	if !errors.As(blockErr, &appErr) {
+		return blockErr
+	}
+
+	reason := HaltReasonAppError
+	if strings.Contains(blockErr.Error(), "UPGRADE") {
+		reason = HaltReasonUpgrade
+	}
+
+	haltInfo := &HaltInfo{
+		Height:          height,
+		Reason:          reason,
+		Error:           blockErr.Error(),
+		App:             appErr.App,
+		UnreachableApps: []string{},
+		Time:            time.Now(),
+	}
+
+	a.haltMutex.Lock()
+	a.halt = haltInfo
+	a.haltMutex.Unlock()
+
+	a.Logger.Error("Halting block production", "height", height, "reason", reason, "app", appErr.App, "method", appErr.Method, "err", blockErr)
+
+	return fmt.Errorf("%w at height %d (%s): %v", ErrHalted, height, reason, blockErr)
+}
+
+// haltIfBlockFinalized halts block production if the apps finalized the block, but it could not be produced,
+// e.g. because it could not be stored or the apps diverged, until Resume is called. Unlike blocks that failed
+// before the apps finalized them, the block cannot be tried again, since the apps would be sent
+// a second FinalizeBlock for the height without a Commit in between.
+// It returns the error to report for the failed block.
+func (a *AbciClient) haltIfBlockFinalized(height int64, blockErr error) error {
+	var finalizedErr *FinalizedBlockError
+	if !errors.As(blockErr, &finalizedErr) {
+		return blockErr
+	}
+	reason := finalizedErr.reason()
+
+	haltInfo := &HaltInfo{
+		Height:          height,
+		Reason:          reason,
+		Error:           blockErr.Error(),
+		UnreachableApps: []string{},
+		Time:            time.Now(),
+	}
+
+	a.haltMutex.Lock()
+	a.halt = haltInfo
+	a.haltMutex.Unlock()
+
+	a.Logger.Error("Halting block production", "height", height, "reason", reason, "err", blockErr)
+
+	return fmt.Errorf("%w at height %d (%s): %v", ErrHalted, height, reason, blockErr)
+}
+
+// unreachableApps returns the network addresses of the apps that do not respond to an Echo.
+func (a *AbciClient) unreachableApps() []string {
+	unreachable := make([]string, 0)
+	for _, validatorAddress := range a.ClientOrder {
+		client := a.Clients[validatorAddress]
+		if !client.Client.IsRunning() || client.Client.Error() != nil {
+			unreachable = append(unreachable, client.NetworkAddress)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		_, err := client.Client.Echo(ctx, "halt check")
+		cancel()
+		if err != nil {
+			unreachable = append(unreachable, client.NetworkAddress)
+		}
+	}
+	return unreachable
+}
+
+// Resume resumes block production after it was halted.
+// The apps are reconnected at the given addresses, which are given in the same order
+// as the apps at startup (see ClientOrder). If no addresses are given, the apps are
+// reconnected at their previous addresses, e.g. for upgraded apps that were restarted in place.
+// The same goes for single empty addresses.
+// The apps need to be at the height at which block production was halted.
+func (a *AbciClient) Resume(appAddresses []string) error {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if a.Halted() == nil {
+		return errors.New("block production is not halted")
+	}
+
+	return a.resumeLocked(appAddresses)
+}
+
+// resumeLocked reconnects the apps and resumes block production, see Resume.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) resumeLocked(appAddresses []string) error {
+	if a.ConnectClient == nil {
+		return errors.New("cannot reconnect to apps, no way to connect to apps is configured")
+	}
+
+	if len(appAddresses) == 0 {
+		appAddresses = make([]string, len(a.ClientOrder))
+	}
+
+	if len(appAddresses) != len(a.ClientOrder) {
+		return fmt.Errorf("got %d app addresses, but there are %d apps", len(appAddresses), len(a.ClientOrder))
+	}
+
+	// keep the previous addresses for apps without a new address
+	addresses := make([]string, len(appAddresses))
+	for i, validatorAddress := range a.ClientOrder {
+		addresses[i] = appAddresses[i]
+		if addresses[i] == "" {
+			addresses[i] = a.Clients[validatorAddress].NetworkAddress
+		}
+	}
+	appAddresses = addresses
+
+	clients := make(map[string]AbciCounterpartyClient, len(a.Clients))
+	for i, validatorAddress := range a.ClientOrder {
+		client, err := a.ConnectClient(appAddresses[i])
+		if err != nil {
+			stopClients(clients)
+			return fmt.Errorf("error connecting to app at %v: %w", appAddresses[i], err)
+		}
+
+		// the apps need to continue where they halted
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		info, err := client.Info(ctx, &abcitypes.RequestInfo{})
+		cancel()
+		if err != nil {
+			_ = client.Stop()
+			stopClients(clients)
+			return fmt.Errorf("error calling Info on app at %v: %w", appAddresses[i], err)
+		}
+		if info.LastBlockHeight != a.CurState.LastBlockHeight {
+			_ = client.Stop()
+			stopClients(clients)
+			return fmt.Errorf("app at %v is at height %d, but needs to be at height %d", appAddresses[i], info.LastBlockHeight, a.CurState.LastBlockHeight)
+		}
+
+		previous := a.Clients[validatorAddress]
+		counterparty := NewAbciCounterpartyClient(client, appAddresses[i], validatorAddress, previous.PrivValidator)
+		counterparty.Observer = previous.Observer
+		counterparty.ExecutorOf = previous.ExecutorOf
+		clients[validatorAddress] = *counterparty
+	}
+
+	a.clientsMutex.Lock()
+	replaced := a.Clients
+	a.Clients = clients
+	a.clientsMutex.Unlock()
+	a.stopReplacedClients(replaced)
+
+	a.haltMutex.Lock()
+	a.halt = nil
+	a.haltMutex.Unlock()
+
+	a.Logger.Info("Resumed block production", "height", a.CurState.LastBlockHeight+1, "app_addresses", appAddresses)
+	return nil
+}
+
+func stopClients(clients map[string]AbciCounterpartyClient) {
+	for _, client := range clients {
+		_ = client.Client.Stop()
+	}
+}
+
+// stopReplacedClients stops the clients that were replaced by new clients.
+// They are only stopped after the swap, so that requests outside of block production,
+// e.g. queries, never pick up a stopped client.
+func (a *AbciClient) stopReplacedClients(replaced map[string]AbciCounterpartyClient) {
+	for _, client := range replaced {
+		if err := client.Client.Stop(); err != nil {
+			a.Logger.Debug("Error stopping client", "address", client.NetworkAddress, "err", err)
+		}
+	}
+}
+
+// blockSnapshot holds the parts of the AbciClient that are modified
+// while producing a block before the state is updated,
+// so that they can be restored if the block could not be produced.
+type blockSnapshot struct {
+	height       int64
+	freshTxQueue []types.Tx
+	staleTxQueue []types.Tx
+}
+
+func (a *AbciClient) takeBlockSnapshot() blockSnapshot {
+	return blockSnapshot{
+		height:       a.CurState.LastBlockHeight,
+		freshTxQueue: append([]types.Tx(nil), a.FreshTxQueue...),
+		staleTxQueue: append([]types.Tx(nil), a.StaleTxQueue...),
+	}
+}
+
+// restoreBlockSnapshot restores the snapshot if the state was not updated since it was taken,
+// and returns whether it did.
+func (a *AbciClient) restoreBlockSnapshot(snapshot blockSnapshot) bool {
+	if a.CurState.LastBlockHeight != snapshot.height {
+		return false
+	}
+	a.FreshTxQueue = snapshot.freshTxQueue
+	a.StaleTxQueue = snapshot.staleTxQueue
+	return true
+}
+
+// ClientConnector connects to the app at the given address.
+type ClientConnector func(networkAddress string) (abciclient.Client, error)
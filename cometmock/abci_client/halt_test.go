@@ -0,0 +1,125 @@
+package abci_client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// divergingApp is a kvstore app that changes the app hash of its response to the given FinalizeBlock call.
+type divergingApp struct {
+	*kvstore.Application
+	divergeAtCall int
+	calls         int
+}
+
+func (app *divergingApp) FinalizeBlock(ctx context.Context, req *abcitypes.RequestFinalizeBlock) (*abcitypes.ResponseFinalizeBlock, error) {
+	res, err := app.Application.FinalizeBlock(ctx, req)
+	app.calls++
+	if err == nil && app.calls == app.divergeAtCall {
+		res.AppHash = append([]byte("diverged"), res.AppHash...)
+	}
+	return res, err
+}
+
+// newTestClient returns an AbciClient at the genesis of a chain with a validator for each app,
+// which are connected with local clients.
+func newTestClient(t *testing.T, apps ...abcitypes.Application) *AbciClient {
+	genesisValidators := make([]types.GenesisValidator, len(apps))
+	for i := range apps {
+		key := testValidatorKey(i)
+		genesisValidators[i] = types.GenesisValidator{Address: key.PubKey().Address(), PubKey: key.PubKey(), Power: 10}
+	}
+	genesisState, err := state.MakeGenesisState(&types.GenesisDoc{
+		ChainID:         "test-chain",
+		GenesisTime:     time.Unix(0, 0),
+		InitialHeight:   1,
+		ConsensusParams: types.DefaultConsensusParams(),
+		Validators:      genesisValidators,
+	})
+	require.NoError(t, err)
+
+	clients := make(map[string]AbciCounterpartyClient, len(apps))
+	for i, app := range apps {
+		key := testValidatorKey(i)
+		client := abciclient.NewLocalClient(nil, app)
+		require.NoError(t, client.Start())
+		t.Cleanup(func() { _ = client.Stop() })
+
+		address := key.PubKey().Address().String()
+		clients[address] = *NewAbciCounterpartyClient(client, address, address, types.NewMockPVWithParams(key, false, false))
+	}
+
+	return NewAbciClient(
+		clients,
+		cometlog.NewNopLogger(),
+		genesisState,
+		&types.Block{},
+		&types.ExtendedCommit{},
+		&storage.MapStorage{},
+		NewFixedBlockTimeHandler(time.Second),
+		DeterminismChecks{},
+	)
+}
+
+// Tests that block production halts when the apps diverge after they finalized a block,
+// since the block cannot be proposed to them again without a Commit in between.
+func TestHaltWhenAppsDiverge(t *testing.T) {
+	testCases := []struct {
+		name string
+		// the app of the second validator, whose responses differ from those of the first
+		app             *divergingApp
+		doubleExecution bool
+		expectedErr     string
+	}{
+		{
+			name:        "responses differ",
+			app:         &divergingApp{Application: kvstore.NewInMemoryApplication(), divergeAtCall: 2},
+			expectedErr: "responses are not all equal",
+		},
+		{
+			name:            "second execution differs",
+			app:             &divergingApp{Application: kvstore.NewInMemoryApplication(), divergeAtCall: 4},
+			doubleExecution: true,
+			expectedErr:     "executed the block differently the second time",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newTestClient(t, kvstore.NewInMemoryApplication(), tc.app)
+			if tc.doubleExecution {
+				require.NoError(t, client.SetDoubleExecution([]string{"all"}))
+			}
+
+			require.NoError(t, client.RunBlock())
+			require.Nil(t, client.Halted())
+			calls := tc.app.calls
+
+			err := client.RunBlock()
+			require.True(t, errors.Is(err, ErrHalted), "unexpected error: %v", err)
+			halt := client.Halted()
+			require.NotNil(t, halt)
+			require.Equal(t, HaltReasonAppsDiverged, halt.Reason)
+			require.Equal(t, int64(2), halt.Height)
+			require.Contains(t, halt.Error, tc.expectedErr)
+			require.Equal(t, int64(1), client.LastBlockHeight())
+			require.Greater(t, tc.app.calls, calls)
+
+			// the block is not sent to the apps again while block production is halted
+			calls = tc.app.calls
+			require.ErrorIs(t, client.RunBlock(), ErrHalted)
+			require.Equal(t, calls, tc.app.calls)
+		})
+	}
+}
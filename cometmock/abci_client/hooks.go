@@ -0,0 +1,114 @@
+package abci_client
+
+import (
+	"errors"
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ErrBlockVetoed is returned when a hook vetoed a block, which is therefore not produced.
+// Hooks wrap it in the errors they return to veto a block, see BlockHooks.
+// Unlike other errors, a veto does not stop periodic block production.
+var ErrBlockVetoed = errors.New("block was vetoed")
+
+// BlockHooks are callbacks that are invoked at defined points while a block is produced,
+// so that code embedding CometMock can make assertions about blocks and responses, or mutate them,
+// without forking abci_client. Hooks that are nil are skipped.
+// Hooks run while the block is produced, so they must not produce blocks or call methods that wait for
+// a block to be produced. If a hook returns an error, producing the block fails with that error.
+// Errors from hooks that run before the block is committed leave the state untouched,
+// like errors from the apps, while the block stays committed if AfterCommit returns an error.
+// Hooks are not invoked for blocks that are replayed from an archive.
+type BlockHooks struct {
+	// BeforeProposal is called with the height, the proposer and the transactions to propose,
+	// before the proposer runs PrepareProposal. It may change the transactions.
+	BeforeProposal func(height int64, proposer *types.Validator, txs *types.Txs) error
+
+	// AfterProcessProposal is called with the proposed block after all non-proposers accepted it in ProcessProposal.
+	AfterProcessProposal func(block *types.Block) error
+
+	// FilterVotes is called with the block and the votes of the validators, in the order of the validator set,
+	// before they are added to the commit. Votes are nil for validators that did not sign.
+	// Votes can be dropped by setting them to nil, as long as more than 2/3 of the voting power still signs.
+	FilterVotes func(block *types.Block, votes []*types.Vote) error
+
+	// BeforeFinalizeBlock is called with the block and the commit that signs it, before FinalizeBlock is sent to the apps.
+	BeforeFinalizeBlock func(block *types.Block, commit *types.ExtendedCommit) error
+
+	// AfterCommit is called with the block and the response to FinalizeBlock after the apps committed the block.
+	AfterCommit func(block *types.Block, response *abcitypes.ResponseFinalizeBlock) error
+}
+
+// AddBlockHooks registers hooks that are invoked while blocks are produced.
+// Hooks registered by several calls are invoked in the order in which they were registered.
+func (a *AbciClient) AddBlockHooks(hooks BlockHooks) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	a.blockHooks = append(a.blockHooks, hooks)
+}
+
+// The following methods invoke the registered hooks of the respective kind.
+// They should only be used after locking the blockMutex.
+
+func (a *AbciClient) runBeforeProposalHooks(height int64, proposer *types.Validator, txs *types.Txs) error {
+	for _, hooks := range a.blockHooks {
+		if hooks.BeforeProposal == nil {
+			continue
+		}
+		if err := hooks.BeforeProposal(height, proposer, txs); err != nil {
+			return fmt.Errorf("error from BeforeProposal hook: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *AbciClient) runAfterProcessProposalHooks(block *types.Block) error {
+	for _, hooks := range a.blockHooks {
+		if hooks.AfterProcessProposal == nil {
+			continue
+		}
+		if err := hooks.AfterProcessProposal(block); err != nil {
+			return fmt.Errorf("error from AfterProcessProposal hook: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *AbciClient) runFilterVotesHooks(block *types.Block, votes []*types.Vote) error {
+	for _, hooks := range a.blockHooks {
+		if hooks.FilterVotes == nil {
+			continue
+		}
+		if err := hooks.FilterVotes(block, votes); err != nil {
+			return fmt.Errorf("error from FilterVotes hook: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *AbciClient) runBeforeFinalizeBlockHooks(block *types.Block, commit *types.ExtendedCommit) error {
+	for _, hooks := range a.blockHooks {
+		if hooks.BeforeFinalizeBlock == nil {
+			continue
+		}
+		if err := hooks.BeforeFinalizeBlock(block, commit); err != nil {
+			return fmt.Errorf("error from BeforeFinalizeBlock hook: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *AbciClient) runAfterCommitHooks(block *types.Block, response *abcitypes.ResponseFinalizeBlock) error {
+	for _, hooks := range a.blockHooks {
+		if hooks.AfterCommit == nil {
+			continue
+		}
+		if err := hooks.AfterCommit(block, response); err != nil {
+			return fmt.Errorf("error from AfterCommit hook: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package abci_client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InvariantQuery is an abci query that is sent to all apps after each block.
+// The responses are compared like the responses to other ABCI methods,
+// with the determinism check for the method "Invariant", see DeterminismChecks.
+// This catches divergences in state that do not show up in the app hash right away,
+// e.g. in stores that are hashed lazily.
+type InvariantQuery struct {
+	Path string
+	Data []byte
+}
+
+// ParseInvariantQueries parses a comma-separated list of queries of the form path or path=data,
+// where data is hex encoded, e.g. "/cosmos.bank.v1beta1.Query/TotalSupply,/store/bank/key=0a0b".
+func ParseInvariantQueries(s string) ([]InvariantQuery, error) {
+	queries := make([]InvariantQuery, 0)
+	if strings.TrimSpace(s) == "" {
+		return queries, nil
+	}
+
+	for _, query := range strings.Split(s, ",") {
+		path, hexData, _ := strings.Cut(strings.TrimSpace(query), "=")
+		if path == "" {
+			return nil, fmt.Errorf("invalid invariant query %q, the path must not be empty", query)
+		}
+
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data for invariant query %q: %w", query, err)
+		}
+
+		queries = append(queries, InvariantQuery{Path: path, Data: data})
+	}
+	return queries, nil
+}
+
+// invariantQueryResult holds the parts of a query response that are compared,
+// other parts like the log may legitimately differ between apps.
+type invariantQueryResult struct {
+	Code   uint32
+	Value  []byte
+	Height int64
+}
+
+// checkInvariants sends the InvariantQueries to all apps at the latest height
+// and compares the responses.
+func (a *AbciClient) checkInvariants() error {
+	for _, query := range a.InvariantQueries {
+		results := make([]invariantQueryResult, 0, len(a.ClientOrder))
+		for _, validatorAddress := range a.ClientOrder {
+			client := a.Clients[validatorAddress]
+			response, err := a.SendAbciQueryToClient(&client, query.Data, query.Path, 0, false)
+			if err != nil {
+				return fmt.Errorf("error sending invariant query %v to app at %v: %w", query.Path, client.NetworkAddress, err)
+			}
+			results = append(results, invariantQueryResult{
+				Code:   response.Code,
+				Value:  response.Value,
+				Height: response.Height,
+			})
+		}
+
+		if err := checkResponsesEqual(a, "Invariant", results); err != nil {
+			return fmt.Errorf("invariant query %v: %w", query.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package abci_client
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JitterDistribution is the distribution from which the jitter of block intervals is drawn.
+type JitterDistribution string
+
+const (
+	// the jitter is uniformly distributed between -amount and +amount
+	JitterUniform JitterDistribution = "uniform"
+	// the jitter is normally distributed around 0, with the amount as the standard deviation
+	JitterNormal JitterDistribution = "normal"
+	// the jitter is an additional delay that is exponentially distributed, with the amount as the mean.
+	// This mimics networks in which blocks are mostly on time, but occasionally much later
+	JitterExponential JitterDistribution = "exponential"
+)
+
+// Jitter randomizes the intervals between blocks, to mimic the variance of block times of a real network.
+// It is safe to use concurrently.
+type Jitter struct {
+	Distribution JitterDistribution
+	Amount       time.Duration
+	// the seed of the random numbers, so that runs with the same seed have the same intervals
+	Seed int64
+
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// ParseJitter parses a jitter given as distribution:milliseconds, e.g. uniform:300.
+// It returns nil for an empty string.
+func ParseJitter(s string, seed int64) (*Jitter, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	distribution, amountString, found := strings.Cut(s, ":")
+	if !found {
+		return nil, fmt.Errorf("invalid jitter %v, must be distribution:milliseconds, e.g. uniform:300", s)
+	}
+	switch JitterDistribution(distribution) {
+	case JitterUniform, JitterNormal, JitterExponential:
+	default:
+		return nil, fmt.Errorf("invalid jitter distribution %v, must be one of %v, %v, %v", distribution, JitterUniform, JitterNormal, JitterExponential)
+	}
+	amount, err := strconv.ParseInt(amountString, 10, 64)
+	if err != nil || amount < 0 {
+		return nil, fmt.Errorf("invalid jitter amount %v, must be a non-negative number of milliseconds", amountString)
+	}
+
+	return &Jitter{
+		Distribution: JitterDistribution(distribution),
+		Amount:       time.Duration(amount) * time.Millisecond,
+		Seed:         seed,
+		rand:         rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Apply returns the interval with a random jitter added, which is never below the given minimum.
+func (j *Jitter) Apply(interval time.Duration, minimum time.Duration) time.Duration {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var jitter time.Duration
+	switch j.Distribution {
+	case JitterUniform:
+		jitter = time.Duration((2*j.rand.Float64() - 1) * float64(j.Amount))
+	case JitterNormal:
+		jitter = time.Duration(j.rand.NormFloat64() * float64(j.Amount))
+	case JitterExponential:
+		jitter = time.Duration(j.rand.ExpFloat64() * float64(j.Amount))
+	}
+
+	if interval+jitter < minimum {
+		return minimum
+	}
+	return interval + jitter
+}
+
+func (j *Jitter) String() string {
+	return fmt.Sprintf("%v:%d (seed %d)", j.Distribution, j.Amount.Milliseconds(), j.Seed)
+}
@@ -0,0 +1,99 @@
+package abci_client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJitter(t *testing.T) {
+	testCases := []struct {
+		input                string
+		expectedDistribution JitterDistribution
+		expectedAmount       time.Duration
+		expectedErr          bool
+	}{
+		{input: "uniform:300", expectedDistribution: JitterUniform, expectedAmount: 300 * time.Millisecond},
+		{input: "normal:50", expectedDistribution: JitterNormal, expectedAmount: 50 * time.Millisecond},
+		{input: "exponential:0", expectedDistribution: JitterExponential, expectedAmount: 0},
+		{input: "uniform", expectedErr: true},
+		{input: "poisson:300", expectedErr: true},
+		{input: "uniform:-1", expectedErr: true},
+		{input: "uniform:1s", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			jitter, err := ParseJitter(tc.input, 1)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDistribution, jitter.Distribution)
+			require.Equal(t, tc.expectedAmount, jitter.Amount)
+		})
+	}
+
+	jitter, err := ParseJitter("", 1)
+	require.NoError(t, err)
+	require.Nil(t, jitter)
+}
+
+// Tests that the jitter stays within the bounds of its distribution and the minimum,
+// and that the same seed gives the same intervals.
+func TestJitterApply(t *testing.T) {
+	const interval = time.Second
+	const numSamples = 1000
+
+	testCases := []struct {
+		input string
+		// the bounds of the intervals with jitter, before the minimum is applied
+		min, max time.Duration
+	}{
+		{input: "uniform:300", min: interval - 300*time.Millisecond, max: interval + 300*time.Millisecond},
+		{input: "exponential:200", min: interval, max: interval + 200*time.Millisecond*50},
+		{input: "normal:100", min: interval - 100*time.Millisecond*10, max: interval + 100*time.Millisecond*10},
+		{input: "uniform:0", min: interval, max: interval},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			jitter, err := ParseJitter(tc.input, 42)
+			require.NoError(t, err)
+			sameSeed, err := ParseJitter(tc.input, 42)
+			require.NoError(t, err)
+
+			var sum time.Duration
+			for i := 0; i < numSamples; i++ {
+				applied := jitter.Apply(interval, 0)
+				require.GreaterOrEqual(t, applied, tc.min)
+				require.LessOrEqual(t, applied, tc.max)
+				require.Equal(t, applied, sameSeed.Apply(interval, 0))
+				sum += applied
+			}
+
+			// the uniform and normal jitter is centered around the interval
+			if tc.input != "exponential:200" {
+				require.InDelta(t, float64(interval), float64(sum/numSamples), float64(30*time.Millisecond))
+			}
+		})
+	}
+}
+
+func TestJitterApplyMinimum(t *testing.T) {
+	jitter, err := ParseJitter("uniform:1000", 7)
+	require.NoError(t, err)
+
+	belowInterval := false
+	for i := 0; i < 100; i++ {
+		applied := jitter.Apply(100*time.Millisecond, time.Millisecond)
+		require.GreaterOrEqual(t, applied, time.Millisecond)
+		if applied == time.Millisecond {
+			belowInterval = true
+		}
+	}
+	// the jitter is larger than the interval, so the minimum must have been applied
+	require.True(t, belowInterval)
+}
@@ -0,0 +1,141 @@
+package abci_client
+
+import (
+	"time"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+)
+
+// LatencyBuckets are the upper bounds in seconds of the buckets of the latency histograms,
+// which are those of the ABCI timing metrics of CometBFT.
+var LatencyBuckets = []float64{.0001, .0004, .002, .009, .02, .1, .65, 2, 6, 25}
+
+// LatencyHistogram is a histogram of the latencies of the calls of an ABCI method to an app.
+type LatencyHistogram struct {
+	// the number of calls
+	Count uint64 `json:"count"`
+	// the sum and the maximum of the latencies, in seconds
+	Sum float64 `json:"sum"`
+	Max float64 `json:"max"`
+	// the number of calls with a latency of at most the upper bound of each of LatencyBuckets,
+	// which are cumulative like the buckets of Prometheus histograms
+	Buckets []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket is a bucket of a LatencyHistogram.
+type LatencyBucket struct {
+	// the upper bound in seconds
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// latencyHistogram collects the latencies of the calls of an ABCI method.
+// It is not safe for concurrent use, the WatchedClient that owns it guards it with its mutex.
+type latencyHistogram struct {
+	count uint64
+	sum   float64
+	max   float64
+	// the number of calls in each bucket, not cumulative, with the calls above the last bucket at the end
+	bucketCounts []uint64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{bucketCounts: make([]uint64, len(LatencyBuckets)+1)}
+}
+
+func (h *latencyHistogram) observe(latency time.Duration) {
+	seconds := latency.Seconds()
+	h.count++
+	h.sum += seconds
+	if seconds > h.max {
+		h.max = seconds
+	}
+	bucket := len(LatencyBuckets)
+	for i, upperBound := range LatencyBuckets {
+		if seconds <= upperBound {
+			bucket = i
+			break
+		}
+	}
+	h.bucketCounts[bucket]++
+}
+
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	buckets := make([]LatencyBucket, len(LatencyBuckets))
+	var cumulative uint64
+	for i, upperBound := range LatencyBuckets {
+		cumulative += h.bucketCounts[i]
+		buckets[i] = LatencyBucket{UpperBound: upperBound, Count: cumulative}
+	}
+	return LatencyHistogram{
+		Count:   h.count,
+		Sum:     h.sum,
+		Max:     h.max,
+		Buckets: buckets,
+	}
+}
+
+// ClientLatencies are the latency histograms of the ABCI methods called on the app of a validator,
+// an observer or an executor, by method.
+type ClientLatencies struct {
+	ValidatorAddress string `json:"validator_address"`
+	// the name of the validator, see SetValidatorNames
+	Name           string                      `json:"name,omitempty"`
+	NetworkAddress string                      `json:"network_address"`
+	Methods        map[string]LatencyHistogram `json:"methods"`
+}
+
+// ABCILatencies returns the latency histograms of the ABCI methods called on each app, in the order of ClientOrder.
+// The latencies of an app are recorded since CometMock last connected to it, e.g. when it was reloaded.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ABCILatencies() []ClientLatencies {
+	a.clientsMutex.RLock()
+	clients := make([]AbciCounterpartyClient, 0, len(a.ClientOrder))
+	for _, validatorAddress := range a.ClientOrder {
+		if client, ok := a.Clients[validatorAddress]; ok {
+			clients = append(clients, client)
+		}
+	}
+	a.clientsMutex.RUnlock()
+
+	names := make(map[string]string)
+	for name, address := range a.ValidatorNames() {
+		names[address] = name
+	}
+	latencies := make([]ClientLatencies, 0, len(clients))
+	for _, client := range clients {
+		watchedClient, ok := client.Client.(*WatchedClient)
+		if !ok {
+			continue
+		}
+		latencies = append(latencies, ClientLatencies{
+			ValidatorAddress: client.ValidatorAddress,
+			Name:             names[client.ValidatorAddress],
+			NetworkAddress:   client.NetworkAddress,
+			Methods:          watchedClient.Latencies(),
+		})
+	}
+	return latencies
+}
+
+// ConnectWithLatencies returns a ClientConnector that connects to apps with connect, and adds the latency
+// given for the network address of an app to each call to it, e.g. to simulate apps on remote machines.
+// Since the latency belongs to the address, it is kept when the app is connected again, e.g. when it is reloaded.
+func ConnectWithLatencies(connect ClientConnector, latencies map[string]time.Duration) ClientConnector {
+	return func(networkAddress string) (abciclient.Client, error) {
+		client, err := connect(networkAddress)
+		if err != nil {
+			return nil, err
+		}
+		latency, ok := latencies[networkAddress]
+		if !ok || latency <= 0 {
+			return client, nil
+		}
+		watchedClient, ok := client.(*WatchedClient)
+		if !ok {
+			watchedClient = NewWatchedClient(client)
+		}
+		watchedClient.SetLatency(latency)
+		return watchedClient, nil
+	}
+}
@@ -0,0 +1,264 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+)
+
+// EventAppLivenessChanged is published on the event bus when a liveness probe finds that
+// an app stopped responding, or that it responds again, see StartLivenessProbes.
+const EventAppLivenessChanged = "AppLivenessChanged"
+
+// EventDataAppLivenessChanged is the data of EventAppLivenessChanged events.
+type EventDataAppLivenessChanged struct {
+	AppLiveness
+}
+
+func init() {
+	cmtjson.RegisterType(EventDataAppLivenessChanged{}, "cometmock/event/AppLivenessChanged")
+}
+
+// AppLiveness is the result of the latest liveness probe of an app.
+type AppLiveness struct {
+	ValidatorAddress string `json:"validator_address"`
+	NetworkAddress   string `json:"network_address"`
+	Alive            bool   `json:"alive"`
+	// why the app is not alive, e.g. the error of the probe
+	Error string `json:"error,omitempty"`
+	// when the app was probed the last time
+	LastProbe time.Time `json:"last_probe"`
+	// since when the app is alive, or not alive
+	Since time.Time `json:"since"`
+}
+
+// liveness holds the results of the liveness probes, see StartLivenessProbes.
+type liveness struct {
+	mutex sync.RWMutex
+	apps  map[string]AppLiveness
+	// the apps whose previous probe did not return yet, by validator address
+	probing map[string]bool
+	// the error of the last attempt to reconnect to apps, which is only logged when it changes
+	reconnectError string
+}
+
+// StartLivenessProbes starts a goroutine that sends an Echo to every app at the given interval, between blocks,
+// so that apps that stopped responding, or whose connection failed, are found before the next block fails.
+// An app is not alive if its connection failed, or if it does not respond to the Echo within the interval.
+// The results are reported by GetAppLiveness, make CheckHealth return an error for apps that are not alive,
+// and an EventAppLivenessChanged is published when an app stops or starts responding.
+// Apps whose connection failed are reconnected at their address as soon as they accept connections again,
+// like ReloadApps does, unless block production is halted, in which case they are reconnected by Resume.
+func (a *AbciClient) StartLivenessProbes(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.probeLiveness(interval)
+		}
+	}()
+}
+
+// probeLiveness probes all apps once, see StartLivenessProbes. Nothing is probed while a block is produced.
+func (a *AbciClient) probeLiveness(timeout time.Duration) {
+	// the apps are not probed while they execute a block, which would delay the probes behind the block
+	if !a.blockMutex.TryLock() {
+		return
+	}
+	a.blockMutex.Unlock()
+
+	// the clients are replaced when apps are reconnected
+	a.clientsMutex.RLock()
+	clients := make([]AbciCounterpartyClient, 0, len(a.Clients))
+	for _, client := range a.Clients {
+		clients = append(clients, client)
+	}
+	a.clientsMutex.RUnlock()
+
+	results := make([]AppLiveness, len(clients))
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client AbciCounterpartyClient) {
+			defer wg.Done()
+			results[i] = a.probeApp(client, timeout)
+		}(i, client)
+	}
+	wg.Wait()
+
+	a.updateLiveness(results)
+	a.reconnectFailedApps(results)
+}
+
+// probeApp sends an Echo to the app, and waits for the response until the timeout has passed.
+// The clients do not stop waiting for responses when their context is done, so the Echo is left behind
+// if the app does not respond, and the app is not probed again until it responds.
+func (a *AbciClient) probeApp(client AbciCounterpartyClient, timeout time.Duration) AppLiveness {
+	result := AppLiveness{
+		ValidatorAddress: client.ValidatorAddress,
+		NetworkAddress:   client.NetworkAddress,
+		LastProbe:        time.Now(),
+	}
+	if err := client.Client.Error(); err != nil {
+		result.Error = fmt.Sprintf("the connection failed: %v", err)
+		return result
+	}
+	if !client.Client.IsRunning() {
+		result.Error = "the connection is closed"
+		return result
+	}
+
+	a.liveness.mutex.Lock()
+	if a.liveness.probing[client.ValidatorAddress] {
+		a.liveness.mutex.Unlock()
+		result.Error = "the app did not respond to the previous probe yet"
+		return result
+	}
+	if a.liveness.probing == nil {
+		a.liveness.probing = make(map[string]bool)
+	}
+	a.liveness.probing[client.ValidatorAddress] = true
+	a.liveness.mutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		_, err := client.Client.Echo(ctx, "liveness probe")
+		cancel()
+
+		a.liveness.mutex.Lock()
+		delete(a.liveness.probing, client.ValidatorAddress)
+		a.liveness.mutex.Unlock()
+		done <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			result.Error = fmt.Sprintf("error from Echo: %v", err)
+		} else {
+			result.Alive = true
+		}
+	case <-timer.C:
+		result.Error = fmt.Sprintf("the app did not respond to Echo within %v", timeout)
+	}
+	return result
+}
+
+// updateLiveness replaces the results of the previous probes, and publishes an EventAppLivenessChanged
+// for each app that stopped or started responding. Apps are assumed to be alive before their first probe.
+func (a *AbciClient) updateLiveness(results []AppLiveness) {
+	a.liveness.mutex.Lock()
+	previous := a.liveness.apps
+	apps := make(map[string]AppLiveness, len(results))
+	changed := make([]AppLiveness, 0)
+	for _, result := range results {
+		before, ok := previous[result.ValidatorAddress]
+		switch {
+		case ok && before.Alive == result.Alive:
+			result.Since = before.Since
+		case !ok && result.Alive:
+			result.Since = result.LastProbe
+		default:
+			result.Since = result.LastProbe
+			changed = append(changed, result)
+		}
+		apps[result.ValidatorAddress] = result
+	}
+	a.liveness.apps = apps
+	a.liveness.mutex.Unlock()
+
+	for _, app := range changed {
+		if app.Alive {
+			a.Logger.Info("App is alive again", "validator", a.validatorLabel(app.ValidatorAddress), "address", app.NetworkAddress)
+		} else {
+			a.Logger.Error("App is not alive", "validator", a.validatorLabel(app.ValidatorAddress), "address", app.NetworkAddress, "err", app.Error)
+		}
+		if err := a.EventBus.Publish(EventAppLivenessChanged, EventDataAppLivenessChanged{app}); err != nil {
+			a.Logger.Error("Error publishing event", "event", EventAppLivenessChanged, "err", err)
+		}
+	}
+}
+
+// reconnectFailedApps reconnects the apps whose connection failed, if they accept connections again,
+// by reloading the apps at their current addresses, see ReloadApps.
+func (a *AbciClient) reconnectFailedApps(results []AppLiveness) {
+	if a.Halted() != nil || a.ConnectClient == nil || len(a.MissingApps()) > 0 {
+		return
+	}
+
+	reconnect := false
+	for _, result := range results {
+		if result.Alive {
+			continue
+		}
+		a.clientsMutex.RLock()
+		client, ok := a.Clients[result.ValidatorAddress]
+		a.clientsMutex.RUnlock()
+		if !ok || (client.Client.IsRunning() && client.Client.Error() == nil) {
+			continue
+		}
+		// some clients, e.g. gRPC clients, wait until the app accepts connections, so check that it does first
+		if !acceptsConnections(client.NetworkAddress) {
+			continue
+		}
+		reconnect = true
+	}
+	if !reconnect {
+		return
+	}
+
+	a.clientsMutex.RLock()
+	appAddresses := make([]string, len(a.ClientOrder))
+	for i, validatorAddress := range a.ClientOrder {
+		appAddresses[i] = a.Clients[validatorAddress].NetworkAddress
+	}
+	a.clientsMutex.RUnlock()
+	err := a.ReloadApps(appAddresses)
+	reconnectError := ""
+	if err != nil {
+		reconnectError = err.Error()
+	}
+	a.liveness.mutex.Lock()
+	repeated := reconnectError == a.liveness.reconnectError
+	a.liveness.reconnectError = reconnectError
+	a.liveness.mutex.Unlock()
+
+	// the attempts are repeated with each probe until the apps are back
+	if err != nil && repeated {
+		a.Logger.Debug("Error reconnecting to apps whose connection failed", "err", err)
+	} else if err != nil {
+		a.Logger.Error("Error reconnecting to apps whose connection failed", "err", err)
+	}
+}
+
+// acceptsConnections returns whether a connection to the app at the given address can be opened.
+func acceptsConnections(appAddress string) bool {
+	protocol, address := cmtnet.ProtocolAndAddress(appAddress)
+	conn, err := net.DialTimeout(protocol, address, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// GetAppLiveness returns the results of the latest liveness probes, keyed by validator address.
+// It is empty if the apps are not probed, see StartLivenessProbes.
+func (a *AbciClient) GetAppLiveness() map[string]AppLiveness {
+	a.liveness.mutex.RLock()
+	defer a.liveness.mutex.RUnlock()
+
+	apps := make(map[string]AppLiveness, len(a.liveness.apps))
+	for k, v := range a.liveness.apps {
+		apps[k] = v
+	}
+	return apps
+}
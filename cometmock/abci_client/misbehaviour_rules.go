@@ -0,0 +1,172 @@
+package abci_client
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// the name of the validator in rules that apply to a random validator
+const randomValidator = "random"
+
+// MisbehaviourRule makes a validator misbehave automatically while blocks are produced,
+// e.g. to soak-test how apps handle evidence over long runs, see SetMisbehaviourRules.
+type MisbehaviourRule struct {
+	// the address of the misbehaving validator, or empty for a validator that is chosen at random
+	// among the validators whose keys are known
+	Validator string
+	Type      MisbehaviourType
+	// if this is positive, the validator misbehaves in every block whose height is a multiple of Every
+	Every int64
+	// otherwise, the validator misbehaves in each block with this probability
+	Probability float64
+}
+
+// ParseMisbehaviourRules parses a comma-separated list of rules of the form validator=type@schedule,
+// where validator is the name of a validator (see SetValidatorNames), its address in any of the formats accepted by utils.ValidatorAddress, or random, type is one of DuplicateVote, Lunatic, Amnesia, Equivocation,
+// and schedule is either every:N for every N blocks or p:P for each block with probability P,
+// e.g. "ABCD...=DuplicateVote@every:100,random=Equivocation@p:0.01".
+func ParseMisbehaviourRules(s string) ([]MisbehaviourRule, error) {
+	rules := make([]MisbehaviourRule, 0)
+	if s == "" {
+		return rules, nil
+	}
+
+	for _, ruleString := range strings.Split(s, ",") {
+		// validators may be given as base64 public keys, which can end with =
+		rest, schedule, foundSchedule := strings.Cut(ruleString, "@")
+		separator := strings.LastIndex(rest, "=")
+		kind, value, foundValue := strings.Cut(schedule, ":")
+		if separator < 0 || !foundSchedule || !foundValue {
+			return nil, fmt.Errorf("invalid misbehaviour rule %q, must be of the form validator=type@every:N or validator=type@p:P", ruleString)
+		}
+
+		validator, typeName := rest[:separator], rest[separator+1:]
+		misbehaviourType, err := ParseMisbehaviourType(typeName)
+		if err != nil {
+			return nil, err
+		}
+		rule := MisbehaviourRule{Type: misbehaviourType}
+		if validator != randomValidator {
+			rule.Validator = utils.ValidatorAddress(validator)
+		}
+
+		switch kind {
+		case "every":
+			rule.Every, err = strconv.ParseInt(value, 10, 64)
+			if err != nil || rule.Every < 1 {
+				return nil, fmt.Errorf("invalid schedule in misbehaviour rule %q, every must be a positive number of blocks", ruleString)
+			}
+		case "p":
+			rule.Probability, err = strconv.ParseFloat(value, 64)
+			if err != nil || rule.Probability < 0 || rule.Probability > 1 {
+				return nil, fmt.Errorf("invalid schedule in misbehaviour rule %q, p must be a probability between 0 and 1", ruleString)
+			}
+		default:
+			return nil, fmt.Errorf("invalid schedule in misbehaviour rule %q, must be every:N or p:P", ruleString)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r MisbehaviourRule) String() string {
+	validator := r.Validator
+	if validator == "" {
+		validator = randomValidator
+	}
+	if r.Every > 0 {
+		return fmt.Sprintf("%v=%v@every:%d", validator, r.Type, r.Every)
+	}
+	return fmt.Sprintf("%v=%v@p:%v", validator, r.Type, r.Probability)
+}
+
+// SetMisbehaviourRules replaces the rules by which validators misbehave automatically.
+// Evidence for the misbehaviour is included in the blocks in which the rules apply,
+// unless the misbehaving validators of a block are given explicitly, see BlockOptions.
+// Random rules are decided by the seed and the height, so that runs with the same seed misbehave at the same heights.
+// Rules for validators that are not in the validator set, e.g. because they were removed for misbehaving,
+// are skipped, as are duplicate votes of validators whose keys are not known
+// and light client attacks before there is a block to conflict with.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) SetMisbehaviourRules(rules []MisbehaviourRule, seed int64) {
+	for i := range rules {
+		if rules[i].Validator != "" {
+			rules[i].Validator = a.ResolveValidator(rules[i].Validator)
+		}
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	a.misbehaviourRules = rules
+	a.misbehaviourSeed = seed
+}
+
+// MisbehaviourRules returns the rules by which validators misbehave automatically, and the seed of the random rules.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) MisbehaviourRules() ([]MisbehaviourRule, int64) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	return a.misbehaviourRules, a.misbehaviourSeed
+}
+
+// scheduledMisbehaviours returns the validators that misbehave in the block at the given height by the rules.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) scheduledMisbehaviours(height int64) map[*types.Validator]MisbehaviourType {
+	misbehavingValidators := make(map[*types.Validator]MisbehaviourType)
+	if len(a.misbehaviourRules) == 0 {
+		return misbehavingValidators
+	}
+
+	random := rand.New(rand.NewSource(a.misbehaviourSeed + height))
+	for _, rule := range a.misbehaviourRules {
+		// draw for every rule, so that the draws of a rule do not depend on whether earlier rules applied
+		draw := random.Float64()
+		pick := random.Int()
+		if rule.Every > 0 && height%rule.Every != 0 {
+			continue
+		}
+		if rule.Every == 0 && draw >= rule.Probability {
+			continue
+		}
+		if rule.Type != DuplicateVote && height <= a.CurState.InitialHeight {
+			continue
+		}
+
+		var validator *types.Validator
+		if rule.Validator == "" {
+			candidates := make([]*types.Validator, 0)
+			for _, val := range a.CurState.Validators.Validators {
+				if a.HasClient(val.Address.String()) {
+					candidates = append(candidates, val)
+				}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+			validator = candidates[pick%len(candidates)]
+		} else {
+			var err error
+			validator, err = a.GetValidatorFromAddress(rule.Validator)
+			if err != nil {
+				a.Logger.Info("Skipping misbehaviour rule", "rule", rule.String(), "height", height, "reason", err)
+				continue
+			}
+			// duplicate votes are signed with the key of the validator
+			if rule.Type == DuplicateVote && !a.HasClient(rule.Validator) {
+				a.Logger.Info("Skipping misbehaviour rule", "rule", rule.String(), "height", height, "reason", "the key of the validator is not known")
+				continue
+			}
+		}
+
+		a.Logger.Info("Validator misbehaves by rule", "rule", rule.String(), "height", height, "validator", a.validatorLabel(validator.Address.String()))
+		misbehavingValidators[validator] = rule.Type
+	}
+	return misbehavingValidators
+}
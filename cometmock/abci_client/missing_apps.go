@@ -0,0 +1,165 @@
+package abci_client
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+// MissingApp is the app of a validator that was not listening when CometMock started, see AddMissingApp.
+type MissingApp struct {
+	NetworkAddress   string `json:"network_address"`
+	ValidatorAddress string `json:"validator_address"`
+
+	// the index of the app in the app addresses given at startup, see ClientOrder
+	index         int
+	privValidator types.PrivValidator
+}
+
+// AddMissingApp adds the app of a validator that was not listening at startup, so that blocks can be produced
+// without it while the validators with apps have enough voting power, see ConnectedVotingPower.
+// Until the app joins, the validator does not sign, does not propose and does not process proposals,
+// like a node that is down, and its signing status is false.
+// Once the app is listening, see JoinMissingApps, it is initialized with InitChain, caught up
+// with the blocks that were produced without it, and the validator signs again.
+// The index is the index of the app in the app addresses given at startup, where the app is inserted
+// into ClientOrder when it joins.
+// Should be called before InitChain is sent to the apps.
+func (a *AbciClient) AddMissingApp(index int, appAddress string, privVal types.PrivValidator) error {
+	pubKey, err := privVal.GetPubKey()
+	if err != nil {
+		return fmt.Errorf("error getting the public key of the validator of app %v: %w", appAddress, err)
+	}
+	address := pubKey.Address().String()
+	if a.HasClient(address) {
+		return fmt.Errorf("validator %v already has an app", address)
+	}
+
+	a.missingAppsMutex.Lock()
+	if a.missingApps == nil {
+		a.missingApps = make(map[string]MissingApp)
+	}
+	a.missingApps[address] = MissingApp{
+		NetworkAddress:   appAddress,
+		ValidatorAddress: address,
+		index:            index,
+		privValidator:    privVal,
+	}
+	a.missingAppsMutex.Unlock()
+
+	a.signingStatusMutex.Lock()
+	a.signingStatus[address] = false
+	a.signingStatusMutex.Unlock()
+	return nil
+}
+
+// MissingApps returns the apps that were not listening at startup and have not joined yet,
+// sorted by validator address.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) MissingApps() []MissingApp {
+	a.missingAppsMutex.RLock()
+	defer a.missingAppsMutex.RUnlock()
+
+	missingApps := make([]MissingApp, 0, len(a.missingApps))
+	for _, missing := range a.missingApps {
+		missingApps = append(missingApps, missing)
+	}
+	sort.Slice(missingApps, func(i, j int) bool {
+		return missingApps[i].ValidatorAddress < missingApps[j].ValidatorAddress
+	})
+	return missingApps
+}
+
+// ConnectedVotingPower returns the voting power of the validators in the current validator set
+// that have an app, or are placeholder validators, and the total voting power.
+// Blocks can only be committed while validators with more than 2/3 of the total voting power sign.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ConnectedVotingPower() (connected int64, total int64) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	for _, val := range a.CurState.Validators.Validators {
+		address := val.Address.String()
+		if a.HasClient(address) || a.placeholderClient(address) != nil {
+			connected += val.VotingPower
+		}
+	}
+	return connected, a.CurState.Validators.TotalVotingPower()
+}
+
+// JoinMissingApps starts a goroutine per missing app that tries to connect to the app,
+// waiting for the given interval between attempts, until the app joins.
+// When an app is listening, it is initialized with InitChain and caught up like an app that is reloaded,
+// see ReloadApps, and from then on, its validator signs, proposes and processes proposals.
+// If the app cannot be caught up, e.g. because it arrives at another app hash, it is disconnected again
+// and the next attempt starts over.
+func (a *AbciClient) JoinMissingApps(interval time.Duration) {
+	for _, missing := range a.MissingApps() {
+		go func(missing MissingApp) {
+			for {
+				err := a.joinMissingApp(missing)
+				if err == nil {
+					return
+				}
+				a.Logger.Info("Missing app did not join yet, retrying", "address", missing.NetworkAddress, "validator", a.validatorLabel(missing.ValidatorAddress), "err", err)
+				time.Sleep(interval)
+			}
+		}(missing)
+	}
+}
+
+// joinMissingApp connects to the missing app, catches it up and adds it to the clients.
+func (a *AbciClient) joinMissingApp(missing MissingApp) error {
+	if a.ConnectClient == nil {
+		return fmt.Errorf("cannot connect to apps, no way to connect to apps is configured")
+	}
+	// connect before blocking the production of blocks, since connecting waits for the app
+	client, err := a.ConnectClient(missing.NetworkAddress)
+	if err != nil {
+		return fmt.Errorf("error connecting to app at %v: %w", missing.NetworkAddress, err)
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if err := a.catchUp(client, missing.NetworkAddress, true); err != nil {
+		_ = client.Stop()
+		return err
+	}
+
+	counterparty := NewAbciCounterpartyClient(client, missing.NetworkAddress, missing.ValidatorAddress, missing.privValidator)
+
+	a.missingAppsMutex.Lock()
+	delete(a.missingApps, missing.ValidatorAddress)
+	// the apps before this one in the app addresses given at startup that are in ClientOrder
+	position := missing.index
+	for _, other := range a.missingApps {
+		if other.index < missing.index {
+			position--
+		}
+	}
+	a.missingAppsMutex.Unlock()
+
+	a.clientsMutex.Lock()
+	clients := make(map[string]AbciCounterpartyClient, len(a.Clients)+1)
+	for address, client := range a.Clients {
+		clients[address] = client
+	}
+	clients[missing.ValidatorAddress] = *counterparty
+	clientOrder := make([]string, 0, len(a.ClientOrder)+1)
+	clientOrder = append(clientOrder, a.ClientOrder[:position]...)
+	clientOrder = append(clientOrder, missing.ValidatorAddress)
+	clientOrder = append(clientOrder, a.ClientOrder[position:]...)
+	a.Clients = clients
+	a.ClientOrder = clientOrder
+	a.clientsMutex.Unlock()
+
+	a.signingStatusMutex.Lock()
+	a.signingStatus[missing.ValidatorAddress] = true
+	a.signingStatusMutex.Unlock()
+
+	a.Logger.Info("Missing app joined, its validator signs from now on", "address", missing.NetworkAddress, "validator", a.validatorLabel(missing.ValidatorAddress))
+	return nil
+}
@@ -0,0 +1,46 @@
+package abci_client
+
+import (
+	"fmt"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+// AddPlaceholderValidator adds a validator whose key is known, but that has no app, e.g. one of the
+// generated validators for which no app address was given.
+// Placeholder validators sign blocks according to their signing status, like validators with an app,
+// but they do not propose and do not process proposals.
+// Since there is no app to extend their votes, their vote extensions are empty.
+func (a *AbciClient) AddPlaceholderValidator(privVal types.PrivValidator) error {
+	pubKey, err := privVal.GetPubKey()
+	if err != nil {
+		return fmt.Errorf("error getting the public key of a placeholder validator: %w", err)
+	}
+	address := pubKey.Address().String()
+	if a.HasClient(address) {
+		return fmt.Errorf("validator %v already has an app", address)
+	}
+
+	if a.placeholderValidators == nil {
+		a.placeholderValidators = make(map[string]types.PrivValidator)
+	}
+	a.placeholderValidators[address] = privVal
+
+	a.signingStatusMutex.Lock()
+	a.signingStatus[address] = true
+	a.signingStatusMutex.Unlock()
+	return nil
+}
+
+// placeholderClient returns a client without an app for the placeholder validator with the given address,
+// which can only be used to sign votes, or nil if there is no such placeholder validator.
+func (a *AbciClient) placeholderClient(address string) *AbciCounterpartyClient {
+	privVal, ok := a.placeholderValidators[address]
+	if !ok {
+		return nil
+	}
+	return &AbciCounterpartyClient{
+		ValidatorAddress: address,
+		PrivValidator:    privVal,
+	}
+}
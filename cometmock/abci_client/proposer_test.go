@@ -0,0 +1,193 @@
+package abci_client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testValidatorKey returns the deterministic key of the i-th validator of a test.
+func testValidatorKey(i int) ed25519.PrivKey {
+	return ed25519.GenPrivKeyFromSecret([]byte{byte(i)})
+}
+
+// Tests that the proposers selected by CometMock follow the proposer-priority rotation of CometBFT,
+// also across changes of the validator set, by comparing them with a types.ValidatorSet that is
+// updated like CometBFT updates the validators of its state.
+func TestProposerSequenceMatchesCometBFT(t *testing.T) {
+	testCases := []struct {
+		name   string
+		powers []int64
+		// the voting power updates that the apps return from FinalizeBlock, by height and validator index.
+		// A power of 0 removes the validator, and validators that are not in the genesis are added
+		updates map[int64]map[int]int64
+	}{
+		{
+			name:   "equal powers",
+			powers: []int64{10, 10, 10, 10},
+		},
+		{
+			name:   "unequal powers",
+			powers: []int64{1, 2, 3, 50},
+		},
+		{
+			name:   "power increase and decrease",
+			powers: []int64{10, 20, 30},
+			updates: map[int64]map[int]int64{
+				3: {0: 100},
+				7: {2: 5},
+				9: {0: 10, 1: 40},
+			},
+		},
+		{
+			name:   "validator joins and leaves",
+			powers: []int64{10, 10, 10},
+			updates: map[int64]map[int]int64{
+				2:  {3: 25},
+				5:  {1: 0},
+				8:  {4: 7, 3: 0},
+				12: {1: 15},
+			},
+		},
+	}
+
+	const numHeights = 25
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			genesisValidators := make([]types.GenesisValidator, len(tc.powers))
+			for i, power := range tc.powers {
+				key := testValidatorKey(i)
+				genesisValidators[i] = types.GenesisValidator{
+					Address: key.PubKey().Address(),
+					PubKey:  key.PubKey(),
+					Power:   power,
+				}
+			}
+			genesisState, err := state.MakeGenesisState(&types.GenesisDoc{
+				ChainID:         "test-chain",
+				GenesisTime:     time.Unix(0, 0),
+				InitialHeight:   1,
+				ConsensusParams: types.DefaultConsensusParams(),
+				Validators:      genesisValidators,
+			})
+			require.NoError(t, err)
+
+			// all validators, including those that only join later, have apps
+			clients := make(map[string]AbciCounterpartyClient)
+			for i := 0; i < 10; i++ {
+				address := testValidatorKey(i).PubKey().Address().String()
+				clients[address] = AbciCounterpartyClient{ValidatorAddress: address}
+			}
+			client := &AbciClient{
+				CurState: genesisState,
+				Clients:  clients,
+			}
+
+			// the reference validator sets of the current and the next height
+			expectedValidators := genesisState.Validators.Copy()
+			expectedNextValidators := genesisState.NextValidators.Copy()
+
+			for height := int64(1); height <= numHeights; height++ {
+				for round := int32(0); round < 3; round++ {
+					proposer, err := client.GetProposerForRound(round)
+					require.NoError(t, err)
+					expected := expectedValidators.GetProposer()
+					if round > 0 {
+						expected = expectedValidators.CopyIncrementProposerPriority(round).GetProposer()
+					}
+					require.Equal(t, expected.Address, proposer.Address, "proposer at height %d and round %d", height, round)
+				}
+
+				res := &abcitypes.ResponseFinalizeBlock{}
+				changes := make([]*types.Validator, 0)
+				for i, power := range tc.updates[height] {
+					key := testValidatorKey(i)
+					res.ValidatorUpdates = append(res.ValidatorUpdates, types.TM2PB.NewValidatorUpdate(key.PubKey(), power))
+					changes = append(changes, types.NewValidator(key.PubKey(), power))
+				}
+
+				newState, _, err := client.stateAfterBlock(
+					&types.BlockID{},
+					&types.Block{Header: types.Header{Height: height, Time: time.Unix(height, 0)}},
+					res,
+				)
+				require.NoError(t, err)
+				client.CurState = newState
+
+				// like CometBFT, the updates apply to the validators of the height after the next,
+				// whose proposer priorities are incremented once per height
+				expectedValidators = expectedNextValidators.Copy()
+				if len(changes) > 0 {
+					require.NoError(t, expectedNextValidators.UpdateWithChangeSet(changes))
+				}
+				expectedNextValidators.IncrementProposerPriority(1)
+			}
+		})
+	}
+}
+
+// Tests that the proposers without an app are skipped, like CometBFT moves to the next round
+// when the proposer is offline.
+func TestProposerWithoutAppIsSkipped(t *testing.T) {
+	validators := make([]*types.Validator, 3)
+	for i := range validators {
+		validators[i] = types.NewValidator(testValidatorKey(i).PubKey(), 10)
+	}
+	validatorSet := types.NewValidatorSet(validators)
+	offline := validatorSet.GetProposer()
+
+	clients := make(map[string]AbciCounterpartyClient)
+	for _, validator := range validatorSet.Validators {
+		if validator.Address.String() != offline.Address.String() {
+			clients[validator.Address.String()] = AbciCounterpartyClient{ValidatorAddress: validator.Address.String()}
+		}
+	}
+	client := &AbciClient{
+		CurState: state.State{Validators: validatorSet},
+		Clients:  clients,
+	}
+
+	proposer, err := client.GetProposer()
+	require.NoError(t, err)
+	require.Equal(t, validatorSet.CopyIncrementProposerPriority(1).GetProposer().Address, proposer.Address)
+	// the validator set is not changed by skipping the proposer
+	require.Equal(t, offline.Address, client.CurState.Validators.GetProposer().Address)
+}
+
+// Tests that a block whose proposer was skipped is committed in the round of the proposer that proposed it,
+// and that the round does not change the proposer priorities of the next height, like in CometBFT.
+func TestBlockIsCommittedInRoundOfSkippedToProposer(t *testing.T) {
+	client := newTestClient(t,
+		kvstore.NewInMemoryApplication(), kvstore.NewInMemoryApplication(),
+		kvstore.NewInMemoryApplication(), kvstore.NewInMemoryApplication())
+
+	// the proposer of round 0 signs, but has no app to propose with
+	offline := client.CurState.Validators.GetProposer()
+	offlineClient := client.Clients[offline.Address.String()]
+	delete(client.Clients, offline.Address.String())
+	for i, address := range client.ClientOrder {
+		if address == offline.Address.String() {
+			client.ClientOrder = append(client.ClientOrder[:i], client.ClientOrder[i+1:]...)
+			break
+		}
+	}
+	require.NoError(t, client.AddPlaceholderValidator(offlineClient.PrivValidator))
+
+	expectedProposer := client.CurState.Validators.CopyIncrementProposerPriority(1).GetProposer()
+	expectedNextValidators := client.CurState.NextValidators.Copy()
+	require.NoError(t, client.RunBlock())
+
+	block, err := client.Storage.GetBlock(1)
+	require.NoError(t, err)
+	require.Equal(t, expectedProposer.Address, block.ProposerAddress)
+	commit, err := client.Storage.GetCommit(1)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), commit.Round)
+	require.Equal(t, expectedNextValidators.GetProposer().Address, client.CurState.Validators.GetProposer().Address)
+}
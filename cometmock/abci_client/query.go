@@ -0,0 +1,87 @@
+package abci_client
+
+import (
+	"fmt"
+	"sort"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// QueryMode decides which apps abci queries are sent to.
+type QueryMode string
+
+const (
+	// QueryModeAll sends each query to all apps and checks that they respond the same.
+	QueryModeAll QueryMode = "all"
+	// QueryModeRoundRobin sends each query to a single app, cycling through the apps.
+	QueryModeRoundRobin QueryMode = "round-robin"
+	// QueryModeLeastLoaded sends each query to the app with the fewest calls in flight.
+	QueryModeLeastLoaded QueryMode = "least-loaded"
+)
+
+// ParseQueryMode parses the name of a QueryMode.
+func ParseQueryMode(name string) (QueryMode, error) {
+	switch mode := QueryMode(name); mode {
+	case QueryModeAll, QueryModeRoundRobin, QueryModeLeastLoaded:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown query mode %q, must be one of %q, %q or %q",
+			name, QueryModeAll, QueryModeRoundRobin, QueryModeLeastLoaded)
+	}
+}
+
+// sendAbciQueryBalanced sends the query to a single app, selected according to the QueryMode.
+// If calling the app fails, the query is retried on the next app, until all apps were tried.
+func (a *AbciClient) sendAbciQueryBalanced(data []byte, path string, height int64, prove bool) (*abcitypes.ResponseQuery, error) {
+	var lastErr error
+	for _, client := range a.queryCandidates() {
+		client := client
+		response, err := a.SendAbciQueryToClient(&client, data, path, height, prove)
+		if err == nil {
+			return response, nil
+		}
+
+		a.Logger.Error("Query failed, trying the next app", "address", client.NetworkAddress, "err", err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("no apps to send the query to")
+	}
+	return nil, fmt.Errorf("query failed on all apps, last error: %w", lastErr)
+}
+
+// queryCandidates returns the clients in the order in which queries should be tried on them.
+func (a *AbciClient) queryCandidates() []AbciCounterpartyClient {
+	a.clientsMutex.RLock()
+	clients := make([]AbciCounterpartyClient, 0, len(a.ClientOrder))
+	for _, validatorAddress := range a.ClientOrder {
+		clients = append(clients, a.Clients[validatorAddress])
+	}
+	a.clientsMutex.RUnlock()
+
+	if len(clients) == 0 {
+		return clients
+	}
+
+	// start at the next app in the rotation, so that the load is spread
+	// even if it is not tracked, or all apps are equally loaded
+	start := int((a.queryCounter.Add(1) - 1) % uint64(len(clients)))
+	clients = append(clients[start:], clients[:start]...)
+
+	if a.QueryMode == QueryModeLeastLoaded {
+		sort.SliceStable(clients, func(i, j int) bool {
+			return inFlightCalls(clients[i]) < inFlightCalls(clients[j])
+		})
+	}
+
+	return clients
+}
+
+func inFlightCalls(client AbciCounterpartyClient) int {
+	watchedClient, ok := client.Client.(*WatchedClient)
+	if !ok {
+		return 0
+	}
+	return watchedClient.InFlightCalls()
+}
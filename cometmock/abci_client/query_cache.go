@@ -0,0 +1,107 @@
+package abci_client
+
+import (
+	"sync"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// QueryCache caches the responses to abci queries.
+// Responses to queries for committed heights never change, so they are kept until the cache is full.
+// Responses to queries for the latest height (height 0) are dropped whenever a new block is committed.
+// Only successful responses are cached.
+type QueryCache struct {
+	mutex sync.Mutex
+
+	// the maximum number of responses to cache, for the latest height and for committed heights each.
+	// When it is reached, the responses are dropped
+	maxEntries int
+
+	historical map[queryCacheKey]*abcitypes.ResponseQuery
+	latest     map[queryCacheKey]*abcitypes.ResponseQuery
+
+	// incremented for each new block, so that responses to queries
+	// that were sent before the block was committed are not cached
+	generation uint64
+}
+
+type queryCacheKey struct {
+	path   string
+	data   string
+	height int64
+	prove  bool
+}
+
+func NewQueryCache(maxEntries int) *QueryCache {
+	return &QueryCache{
+		maxEntries: maxEntries,
+		historical: make(map[queryCacheKey]*abcitypes.ResponseQuery),
+		latest:     make(map[queryCacheKey]*abcitypes.ResponseQuery),
+	}
+}
+
+// Get returns the cached response to the query, if there is one,
+// and the generation to pass to Put when the query is sent.
+func (c *QueryCache) Get(data []byte, path string, height int64, prove bool) (*abcitypes.ResponseQuery, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := queryCacheKey{path: path, data: string(data), height: height, prove: prove}
+	var response *abcitypes.ResponseQuery
+	var ok bool
+	if height == 0 {
+		response, ok = c.latest[key]
+	} else {
+		response, ok = c.historical[key]
+	}
+	return response, c.generation, ok
+}
+
+// Put caches the response to a query that was sent at the given generation, see Get.
+// lastHeight is the height of the last committed block, responses for later heights are not cached.
+func (c *QueryCache) Put(data []byte, path string, height int64, prove bool, generation uint64, lastHeight int64, response *abcitypes.ResponseQuery) {
+	if !response.IsOK() || height > lastHeight {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := queryCacheKey{path: path, data: string(data), height: height, prove: prove}
+	if height == 0 {
+		// a block was committed while the query was in flight, so the response might be outdated
+		if generation != c.generation {
+			return
+		}
+		if len(c.latest) >= c.maxEntries {
+			c.latest = make(map[queryCacheKey]*abcitypes.ResponseQuery)
+		}
+		c.latest[key] = response
+		return
+	}
+
+	if len(c.historical) >= c.maxEntries {
+		c.historical = make(map[queryCacheKey]*abcitypes.ResponseQuery)
+	}
+	c.historical[key] = response
+}
+
+// Reset drops all responses, e.g. when the chain is reset.
+func (c *QueryCache) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.historical = make(map[queryCacheKey]*abcitypes.ResponseQuery)
+	c.latest = make(map[queryCacheKey]*abcitypes.ResponseQuery)
+}
+
+// NewBlock drops the responses to queries for the latest height.
+// It needs to be called whenever a block is committed.
+func (c *QueryCache) NewBlock() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.latest = make(map[queryCacheKey]*abcitypes.ResponseQuery)
+}
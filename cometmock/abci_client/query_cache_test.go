@@ -0,0 +1,47 @@
+package abci_client
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	"github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that responses to queries sent while blocks are committed do not outlive the blocks.
+// Run with -race to check that the query path does not read the state of the chain without locking.
+func TestQueryCacheWhileBlocksAreCommitted(t *testing.T) {
+	client := newTestClient(t, kvstore.NewInMemoryApplication())
+	client.QueryCache = NewQueryCache(100)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := client.SendAbciQuery([]byte("key"), "", 0, false); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for i := 1; i <= 20; i++ {
+		txs := types.Txs{types.Tx(fmt.Sprintf("key=%d", i))}
+		require.NoError(t, client.RunBlockWithOptions(BlockOptions{Txs: &txs}))
+	}
+	close(done)
+	wg.Wait()
+
+	response, err := client.SendAbciQuery([]byte("key"), "", 0, false)
+	require.NoError(t, err)
+	require.Equal(t, []byte("20"), response.Value)
+}
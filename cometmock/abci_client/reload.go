@@ -0,0 +1,247 @@
+package abci_client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// ReadAppAddressesFile reads the app addresses from the file at the given path.
+// The addresses are separated by commas or newlines, and lines starting with # are ignored.
+func ReadAppAddressesFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading app addresses: %w", err)
+	}
+
+	addresses := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, address := range strings.Split(line, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// ReloadAppsFromFile reloads the apps from the addresses in AppAddressesFile, see ReloadApps.
+func (a *AbciClient) ReloadAppsFromFile() error {
+	if a.AppAddressesFile == "" {
+		return errors.New("cannot reload apps, no app addresses file is configured")
+	}
+	appAddresses, err := ReadAppAddressesFile(a.AppAddressesFile)
+	if err != nil {
+		return err
+	}
+	return a.ReloadApps(appAddresses)
+}
+
+// ReloadApps reconnects to the apps whose addresses changed, or whose connection failed,
+// while blocks are produced, e.g. when apps are restarted under new addresses.
+// The addresses are given in the same order as the apps at startup (see ClientOrder),
+// and additional addresses are added as observers.
+// Apps cannot be removed. Addresses are resolved again when reconnecting, and addresses of apps
+// that are discovered via DNS are expanded again, see ExpandAppAddresses.
+// Apps that are behind, e.g. because they were restarted from an older state, are caught up
+// by replaying the stored blocks, but apps need to have been initialized with InitChain.
+// If any app cannot be connected or caught up, none of the apps are replaced.
+// Apps cannot be reloaded while apps that were missing at startup have not joined, see AddMissingApp,
+// since they are not in ClientOrder yet.
+func (a *AbciClient) ReloadApps(appAddresses []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DNSLookupTimeout)
+	appAddresses, err := ExpandAppAddresses(ctx, net.DefaultResolver, appAddresses)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if missingApps := a.MissingApps(); len(missingApps) > 0 {
+		return fmt.Errorf("cannot reload apps while %d apps that were missing at startup have not joined", len(missingApps))
+	}
+
+	if a.ConnectClient == nil {
+		return errors.New("cannot reconnect to apps, no way to connect to apps is configured")
+	}
+	if len(appAddresses) < len(a.ClientOrder) {
+		return fmt.Errorf("got %d app addresses, but there are %d apps, and apps cannot be removed", len(appAddresses), len(a.ClientOrder))
+	}
+
+	numObservers := 0
+	for _, client := range a.Clients {
+		if client.Observer {
+			numObservers++
+		}
+	}
+
+	clients := make(map[string]AbciCounterpartyClient, len(appAddresses))
+	clientOrder := append(make([]string, 0, len(appAddresses)), a.ClientOrder...)
+	// the new clients, which are stopped if reloading fails
+	connected := make(map[string]AbciCounterpartyClient)
+	// the previous clients that were replaced, which are stopped if reloading succeeds
+	replaced := make(map[string]AbciCounterpartyClient)
+
+	for i, appAddress := range appAddresses {
+		if i < len(a.ClientOrder) {
+			previous := a.Clients[a.ClientOrder[i]]
+			if previous.NetworkAddress == appAddress && previous.Client.IsRunning() && previous.Client.Error() == nil {
+				clients[previous.ValidatorAddress] = previous
+				continue
+			}
+		}
+
+		client, err := a.connectAndCatchUp(appAddress)
+		if err != nil {
+			stopClients(connected)
+			return err
+		}
+
+		var counterparty *AbciCounterpartyClient
+		if i < len(a.ClientOrder) {
+			previous := a.Clients[a.ClientOrder[i]]
+			counterparty = NewAbciCounterpartyClient(client, appAddress, previous.ValidatorAddress, previous.PrivValidator)
+			counterparty.Observer = previous.Observer
+			counterparty.ExecutorOf = previous.ExecutorOf
+			replaced[previous.ValidatorAddress] = previous
+		} else {
+			counterparty = NewObserverClient(client, appAddress, numObservers)
+			numObservers++
+			clientOrder = append(clientOrder, counterparty.ValidatorAddress)
+		}
+		clients[counterparty.ValidatorAddress] = *counterparty
+		connected[counterparty.ValidatorAddress] = *counterparty
+	}
+
+	a.clientsMutex.Lock()
+	a.Clients = clients
+	a.ClientOrder = clientOrder
+	a.clientsMutex.Unlock()
+	a.stopReplacedClients(replaced)
+
+	a.Logger.Info("Reloaded apps", "app_addresses", appAddresses, "reconnected", len(connected))
+	return nil
+}
+
+// connectAndCatchUp connects to the app at the given address, and replays the blocks it is missing.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) connectAndCatchUp(appAddress string) (abciclient.Client, error) {
+	client, err := a.ConnectClient(appAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to app at %v: %w", appAddress, err)
+	}
+	if err := a.catchUp(client, appAddress, false); err != nil {
+		_ = client.Stop()
+		return nil, err
+	}
+	return client, nil
+}
+
+// catchUp replays the blocks the app at the given address is missing.
+// If initChain is true, apps that have not executed any blocks are assumed to have never been initialized,
+// so they are sent InitChain and caught up from the first block, see AddMissingApp.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) catchUp(client abciclient.Client, appAddress string, initChain bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	info, err := client.Info(ctx, &abcitypes.RequestInfo{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("error calling Info on app at %v: %w", appAddress, err)
+	}
+
+	lastHeight := a.CurState.LastBlockHeight
+	if info.LastBlockHeight > lastHeight {
+		return fmt.Errorf("app at %v is at height %d, which is after the latest height %d", appAddress, info.LastBlockHeight, lastHeight)
+	}
+	fromHeight := info.LastBlockHeight
+	if info.LastBlockHeight < a.CurState.InitialHeight {
+		switch {
+		case initChain:
+			if a.initChainRequest == nil {
+				return fmt.Errorf("cannot initialize app at %v, InitChain was not sent to the other apps yet", appAddress)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+			_, err := client.InitChain(ctx, a.initChainRequest)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("error calling InitChain on app at %v: %w", appAddress, err)
+			}
+			fromHeight = a.CurState.InitialHeight - 1
+		case lastHeight >= a.CurState.InitialHeight:
+			// apps that did not execute any block cannot be told apart from apps that were never initialized
+			return fmt.Errorf("app at %v has not executed any blocks, apps can only be caught up from a state after the first block", appAddress)
+		}
+	}
+
+	for height := fromHeight + 1; height <= lastHeight; height++ {
+		if err := a.catchUpBlock(client, height); err != nil {
+			return fmt.Errorf("error catching up app at %v at height %d: %w", appAddress, height, err)
+		}
+	}
+	if fromHeight < lastHeight {
+		a.Logger.Info("Caught up app", "address", appAddress, "from_height", fromHeight, "to_height", lastHeight)
+	}
+	return nil
+}
+
+// catchUpBlock sends the stored block at the given height to the app, and checks that the app
+// arrives at the same app hash as the other apps did.
+func (a *AbciClient) catchUpBlock(client abciclient.Client, height int64) error {
+	block, err := a.Storage.GetBlock(height)
+	if err != nil {
+		return err
+	}
+	expected, err := a.Storage.GetResponses(height)
+	if err != nil {
+		return err
+	}
+
+	// the last commit of the block was signed by the validators of the previous height,
+	// which are the last validators of the state that is stored for the height, i.e. the state before the block
+	var lastCommitInfo abcitypes.CommitInfo
+	if height > a.CurState.InitialHeight {
+		blockState, err := a.Storage.GetState(height)
+		if err != nil {
+			return err
+		}
+		lastCommitInfo = utils.BuildLastCommitInfo(block, blockState.LastValidators, a.CurState.InitialHeight)
+	}
+
+	ctx, cancel := context.WithTimeout(utils.ContextWithHeader(context.Background(), &block.Header), ABCI_TIMEOUT)
+	response, err := client.FinalizeBlock(ctx, &abcitypes.RequestFinalizeBlock{
+		Txs:                block.Txs.ToSliceOfBytes(),
+		DecidedLastCommit:  lastCommitInfo,
+		Misbehavior:        block.Evidence.Evidence.ToABCI(),
+		Height:             block.Height,
+		Hash:               block.Hash(),
+		Time:               block.Time,
+		ProposerAddress:    block.ProposerAddress,
+		NextValidatorsHash: block.NextValidatorsHash,
+	})
+	cancel()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(response.AppHash, expected.AppHash) {
+		return fmt.Errorf("app hash %X differs from the app hash %X of the other apps", response.AppHash, expected.AppHash)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	_, err = client.Commit(ctx, &abcitypes.RequestCommit{})
+	cancel()
+	return err
+}
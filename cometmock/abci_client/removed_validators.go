@@ -0,0 +1,34 @@
+package abci_client
+
+import (
+	"github.com/cometbft/cometbft/types"
+)
+
+// syncSigningStatus stops the signing of validators whose keys are known once they left the validator set,
+// e.g. because the app removed them with a validator update of power 0 when jailing them,
+// and resumes the signing of validators that were stopped this way once they are back in the validator set.
+// This keeps the signing status in line with the validator set, see GetSigningStatusMap.
+// Validators whose signing status was changed with SetSigningStatus while they were not in the validator set
+// keep that status when they come back.
+func (a *AbciClient) syncSigningStatus(validators *types.ValidatorSet) {
+	inValidatorSet := make(map[string]bool, validators.Size())
+	for _, val := range validators.Validators {
+		inValidatorSet[val.Address.String()] = true
+	}
+
+	a.signingStatusMutex.Lock()
+	defer a.signingStatusMutex.Unlock()
+
+	for address, signing := range a.signingStatus {
+		switch {
+		case !inValidatorSet[address] && signing:
+			a.signingStatus[address] = false
+			a.removedValidators[address] = true
+			a.Logger.Info("Validator left the validator set, it stops signing", "address", a.validatorLabel(address))
+		case inValidatorSet[address] && a.removedValidators[address]:
+			a.signingStatus[address] = true
+			delete(a.removedValidators, address)
+			a.Logger.Info("Validator is back in the validator set, it resumes signing", "address", a.validatorLabel(address))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package abci_client
+
+import (
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// ReplayBlock runs a block that was produced elsewhere, e.g. on a live chain, through the apps.
+// Unlike RunBlock, the block is not proposed and signed, but only finalized and committed,
+// and the state is updated from the responses of the apps.
+// The commit for the block is stored if it is given, e.g. from the LastCommit of the next block,
+// otherwise no commit is stored for the height.
+// The block needs to be at the height after the last block.
+func (a *AbciClient) ReplayBlock(block *types.Block, commit *types.Commit) (*abcitypes.ResponseFinalizeBlock, error) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if block.Height != a.CurState.LastBlockHeight+1 {
+		return nil, fmt.Errorf("cannot replay block at height %d, the next height is %d", block.Height, a.CurState.LastBlockHeight+1)
+	}
+
+	if block.Height != a.CurState.InitialHeight && block.LastCommit.Size() != a.CurState.LastValidators.Size() {
+		return nil, fmt.Errorf("cannot replay block at height %d, its last commit has %d signatures, but there were %d validators",
+			block.Height, block.LastCommit.Size(), a.CurState.LastValidators.Size())
+	}
+
+	lastCommitInfo := utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight)
+	resFinalizeBlock, err := a.SendFinalizeBlock(block, &lastCommitInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error from FinalizeBlock for block %v: %v", block.Height, err)
+	}
+
+	blockId, err := utils.GetBlockIdFromBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block id from block %v: %v", block.Height, err)
+	}
+
+	a.Storage.LockBeforeStateUpdate()
+	a.LastBlock = block
+
+	// copy state so that the historical state is not mutated
+	state := a.CurState.Copy()
+
+	// the vote extensions of replayed blocks are not known
+	var extendedCommit *types.ExtendedCommit
+	if commit != nil {
+		extendedCommit = commit.WrappedExtendedCommit()
+	}
+	err = a.Storage.UpdateStores(block.Height, block, extendedCommit, &state, resFinalizeBlock)
+	if err != nil {
+		a.Storage.UnlockAfterStateUpdate()
+		return nil, fmt.Errorf("error updating stores: %v", err)
+	}
+
+	err = a.UpdateStateFromBlock(blockId, block, resFinalizeBlock)
+	a.Storage.UnlockAfterStateUpdate()
+	if err != nil {
+		return nil, fmt.Errorf("error updating state for block %v: %v", block.Height, err)
+	}
+
+	_, err = a.SendCommit()
+	if err != nil {
+		return nil, fmt.Errorf("error from Commit for block %v: %v", block.Height, err)
+	}
+	a.CurState.AppHash = resFinalizeBlock.AppHash
+	if a.QueryCache != nil {
+		a.QueryCache.NewBlock()
+	}
+
+	return resFinalizeBlock, nil
+}
@@ -0,0 +1,252 @@
+package abci_client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+)
+
+// ChainStart describes how the chain is started after the apps were initialized with InitChain,
+// so that ResetChain can start it again the same way.
+type ChainStart struct {
+	// the time of the first block
+	FirstBlockTime time.Time
+	// creates the TimeHandler that decides the times of the blocks after the first
+	NewTimeHandler func() TimeHandler
+	// the validators that misbehave in the first block, by name or address
+	Misbehaviours map[string]MisbehaviourType
+	// if this is set, it replaces the validator set returned by InitChain, e.g. to give substituted validators
+	// more than 2/3 of the voting power
+	AdjustValidators func(validators *types.ValidatorSet) (*types.ValidatorSet, error)
+}
+
+// AdjustValidatorsAfterInitChain replaces the validator set after InitChain with the one returned by
+// ChainStart.AdjustValidators, if any. This only changes the voting power known to CometMock, the apps keep their own.
+// It needs to be called after SendInitChain.
+func (a *AbciClient) AdjustValidatorsAfterInitChain() error {
+	if a.ChainStart == nil || a.ChainStart.AdjustValidators == nil {
+		return nil
+	}
+	validators, err := a.ChainStart.AdjustValidators(a.CurState.Validators)
+	if err != nil {
+		return err
+	}
+	a.CurState.LastValidators = validators.Copy()
+	a.CurState.Validators = validators
+	a.CurState.NextValidators = validators.CopyIncrementProposerPriority(1)
+	return nil
+}
+
+// FirstBlockOptions returns the options to produce the first block with, see ChainStart.
+func (a *AbciClient) FirstBlockOptions() (BlockOptions, error) {
+	if a.ChainStart == nil {
+		return BlockOptions{}, errors.New("no way to start the chain is configured")
+	}
+	misbehavingValidators := make(map[*types.Validator]MisbehaviourType, len(a.ChainStart.Misbehaviours))
+	for address, misbehaviourType := range a.ChainStart.Misbehaviours {
+		validator, err := a.GetValidatorFromAddress(a.ResolveValidator(address))
+		if err != nil {
+			return BlockOptions{}, err
+		}
+		misbehavingValidators[validator] = misbehaviourType
+	}
+	return BlockOptions{
+		Time:                  a.ChainStart.FirstBlockTime,
+		MisbehavingValidators: misbehavingValidators,
+	}, nil
+}
+
+// ResetChain wipes the chain and starts it again from the genesis: the stored blocks, states and indexes
+// are removed, the apps are initialized with InitChain again, and the first block is produced like at startup,
+// see ChainStart. The apps are reconnected at the given addresses, which are given in the same order
+// as the apps at startup (see ClientOrder). If no addresses are given, the apps are reconnected at their previous
+// addresses, and the same goes for single empty addresses.
+// The apps need to have been reset or freshly started, i.e. they must not have executed any blocks.
+// Transactions waiting to be included, halts, upgrades, vote extension overrides, snapshots and time advances are dropped,
+// and all validators with keys sign again. Settings that do not depend on the chain, e.g. misbehaviour rules, are kept.
+// If the apps cannot be connected or have executed blocks, the chain is not reset, but the previous connections
+// to the apps are closed, like when resuming block production, see Resume.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ResetChain(appAddresses []string) error {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if a.ChainStart == nil || a.genesisDoc == nil {
+		return errors.New("cannot reset the chain, it was not started from a genesis")
+	}
+	if a.ConnectClient == nil {
+		return errors.New("cannot reconnect to apps, no way to connect to apps is configured")
+	}
+	if missingApps := a.MissingApps(); len(missingApps) > 0 {
+		return fmt.Errorf("cannot reset the chain while %d apps that were missing at startup have not joined", len(missingApps))
+	}
+
+	if len(appAddresses) == 0 {
+		appAddresses = make([]string, len(a.ClientOrder))
+	}
+	if len(appAddresses) != len(a.ClientOrder) {
+		return fmt.Errorf("got %d app addresses, but there are %d apps", len(appAddresses), len(a.ClientOrder))
+	}
+
+	genesisState, err := state.MakeGenesisState(a.genesisDoc)
+	if err != nil {
+		return err
+	}
+
+	if err := a.reconnectAppsAt(appAddresses, 0, nil); err != nil {
+		return err
+	}
+
+	a.Storage.Reset()
+	if err := a.restartIndexers(); err != nil {
+		return err
+	}
+	if a.QueryCache != nil {
+		a.QueryCache.Reset()
+	}
+	a.resetChainState()
+
+	a.Storage.LockBeforeStateUpdate()
+	a.CurState = genesisState
+	a.LastBlock = &types.Block{}
+	a.LastCommit = &types.ExtendedCommit{}
+	a.Storage.UnlockAfterStateUpdate()
+	a.TimeHandler = a.ChainStart.NewTimeHandler()
+
+	if err := a.SendInitChain(genesisState, a.genesisDoc); err != nil {
+		return fmt.Errorf("error from InitChain: %w", err)
+	}
+	if err := a.AdjustValidatorsAfterInitChain(); err != nil {
+		return err
+	}
+	opts, err := a.FirstBlockOptions()
+	if err != nil {
+		return err
+	}
+	if err := a.runBlockLocked(opts); err != nil {
+		return fmt.Errorf("error producing the first block: %w", err)
+	}
+
+	a.Logger.Info("Reset the chain", "chain_id", a.CurState.ChainID, "height", a.CurState.LastBlockHeight)
+	return nil
+}
+
+// reconnectAppsAt closes the connections to the apps and connects to the apps at the given addresses,
+// which must be at the given height, and have the given app hash if it is not nil, see ResetChain and RewindToSnapshot.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) reconnectAppsAt(appAddresses []string, height int64, appHash []byte) error {
+	// keep the previous addresses for apps without a new address
+	addresses := make([]string, len(appAddresses))
+	for i, validatorAddress := range a.ClientOrder {
+		addresses[i] = appAddresses[i]
+		if addresses[i] == "" {
+			addresses[i] = a.Clients[validatorAddress].NetworkAddress
+		}
+	}
+
+	clients := make(map[string]AbciCounterpartyClient, len(a.Clients))
+	for i, validatorAddress := range a.ClientOrder {
+		client, err := a.ConnectClient(addresses[i])
+		if err != nil {
+			stopClients(clients)
+			return fmt.Errorf("error connecting to app at %v: %w", addresses[i], err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+		info, err := client.Info(ctx, &abcitypes.RequestInfo{})
+		cancel()
+		if err != nil {
+			_ = client.Stop()
+			stopClients(clients)
+			return fmt.Errorf("error calling Info on app at %v: %w", addresses[i], err)
+		}
+		if info.LastBlockHeight != height {
+			_ = client.Stop()
+			stopClients(clients)
+			if height == 0 {
+				return fmt.Errorf("app at %v is at height %d, it needs to be reset or freshly started", addresses[i], info.LastBlockHeight)
+			}
+			return fmt.Errorf("app at %v is at height %d, but needs to be at height %d", addresses[i], info.LastBlockHeight, height)
+		}
+		if appHash != nil && !bytes.Equal(info.LastBlockAppHash, appHash) {
+			_ = client.Stop()
+			stopClients(clients)
+			return fmt.Errorf("app at %v has app hash %X, but needs to have app hash %X", addresses[i], info.LastBlockAppHash, appHash)
+		}
+
+		previous := a.Clients[validatorAddress]
+		counterparty := NewAbciCounterpartyClient(client, addresses[i], validatorAddress, previous.PrivValidator)
+		counterparty.Observer = previous.Observer
+		counterparty.ExecutorOf = previous.ExecutorOf
+		clients[validatorAddress] = *counterparty
+	}
+
+	a.clientsMutex.Lock()
+	replaced := a.Clients
+	a.Clients = clients
+	a.clientsMutex.Unlock()
+	a.stopReplacedClients(replaced)
+	return nil
+}
+
+// restartIndexers replaces the transaction and block indexes with empty ones.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) restartIndexers() error {
+	if err := a.IndexerService.Stop(); err != nil {
+		a.Logger.Debug("Error stopping the indexer service", "err", err)
+	}
+	indexerService, txIndex, blockIndex, err := CreateAndStartIndexerService(&a.EventBus, a.Logger)
+	if err != nil {
+		return err
+	}
+	a.IndexerService = indexerService
+	a.TxIndex = txIndex
+	a.BlockIndex = blockIndex
+	return nil
+}
+
+// resetChainState drops everything that CometMock keeps about the chain beyond the state and the stored blocks.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) resetChainState() {
+	a.ClearTxs()
+
+	a.haltMutex.Lock()
+	a.halt = nil
+	a.haltMutex.Unlock()
+
+	a.upgrade.mutex.Lock()
+	a.upgrade.status = nil
+	a.upgrade.mutex.Unlock()
+
+	a.lastDivergenceMutex.Lock()
+	a.lastDivergence = nil
+	a.lastDivergenceMutex.Unlock()
+
+	a.appHashesMutex.Lock()
+	a.divergentAppHashes = nil
+	a.appHashesMutex.Unlock()
+
+	a.voteExtensionOverrides.mutex.Lock()
+	a.voteExtensionOverrides.overrides = nil
+	a.voteExtensionOverrides.mutex.Unlock()
+	a.lastOverriddenExtensions = nil
+
+	a.lastConflictingBlocks = nil
+
+	a.snapshots.mutex.Lock()
+	a.snapshots.snapshots = nil
+	a.snapshots.mutex.Unlock()
+
+	a.signingStatusMutex.Lock()
+	for address := range a.signingStatus {
+		a.signingStatus[address] = true
+	}
+	a.removedValidators = make(map[string]bool)
+	a.signingStatusMutex.Unlock()
+}
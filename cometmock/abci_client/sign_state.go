@@ -0,0 +1,123 @@
+package abci_client
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/privval"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// the steps of the last sign state, like in privval
+const (
+	stepPropose   int8 = 1
+	stepPrevote   int8 = 2
+	stepPrecommit int8 = 3
+)
+
+// SignStatePV wraps a priv validator and persists the height, round and step of its last signature,
+// in the format of priv_validator_state.json, but in a file of its own, so that the state of CometBFT is not touched.
+// Signatures for heights, rounds and steps up to the last signature before the state was loaded,
+// i.e. before CometMock was restarted, are refused, so that a restarted CometMock does not accidentally double sign.
+// Within a run of CometMock, signatures are not restricted, so double signing on purpose,
+// e.g. with cause_double_sign, still works.
+type SignStatePV struct {
+	types.PrivValidator
+
+	mutex    sync.Mutex
+	filePath string
+	// the last signature before the state was loaded
+	restartState privval.FilePVLastSignState
+	lastState    privval.FilePVLastSignState
+}
+
+// NewSignStatePV wraps the priv validator, persisting its last sign state to the file at the given path.
+// The last sign state is loaded from the file if it exists.
+func NewSignStatePV(privValidator types.PrivValidator, filePath string) (*SignStatePV, error) {
+	pv := &SignStatePV{
+		PrivValidator: privValidator,
+		filePath:      filePath,
+	}
+
+	stateBytes, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = cmtjson.Unmarshal(stateBytes, &pv.restartState)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the sign state from %v: %w", filePath, err)
+		}
+	}
+	pv.lastState = pv.restartState
+	return pv, nil
+}
+
+func (pv *SignStatePV) SignVote(chainID string, vote *cmtproto.Vote) error {
+	step := stepPrevote
+	if vote.Type == cmtproto.PrecommitType {
+		step = stepPrecommit
+	}
+	if err := pv.checkAndRecord(vote.Height, vote.Round, step); err != nil {
+		return err
+	}
+	return pv.PrivValidator.SignVote(chainID, vote)
+}
+
+func (pv *SignStatePV) SignProposal(chainID string, proposal *cmtproto.Proposal) error {
+	if err := pv.checkAndRecord(proposal.Height, proposal.Round, stepPropose); err != nil {
+		return err
+	}
+	return pv.PrivValidator.SignProposal(chainID, proposal)
+}
+
+// checkAndRecord refuses signatures up to the state before the restart,
+// and persists the height, round and step if they are after the last signature.
+func (pv *SignStatePV) checkAndRecord(height int64, round int32, step int8) error {
+	pv.mutex.Lock()
+	defer pv.mutex.Unlock()
+
+	if !isAfter(height, round, step, &pv.restartState) {
+		return fmt.Errorf("refusing to sign at height %d, round %d, step %d, since the priv validator already signed up to height %d, round %d, step %d before CometMock was restarted. Delete %v to sign anyway",
+			height, round, step, pv.restartState.Height, pv.restartState.Round, pv.restartState.Step, pv.filePath)
+	}
+
+	if !isAfter(height, round, step, &pv.lastState) {
+		return nil
+	}
+	pv.lastState.Height = height
+	pv.lastState.Round = round
+	pv.lastState.Step = step
+	return pv.save()
+}
+
+// save writes the last sign state to the file, via a temporary file so that the file is never half written.
+// Unlike privval, the file is not synced to disk, which would slow down advancing many blocks a lot.
+func (pv *SignStatePV) save() error {
+	stateBytes, err := cmtjson.MarshalIndent(&pv.lastState, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := pv.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, stateBytes, 0o600); err != nil {
+		return fmt.Errorf("error writing the sign state: %w", err)
+	}
+	if err := os.Rename(tmpPath, pv.filePath); err != nil {
+		return fmt.Errorf("error writing the sign state: %w", err)
+	}
+	return nil
+}
+
+// isAfter returns whether the height, round and step are after the given sign state.
+func isAfter(height int64, round int32, step int8, state *privval.FilePVLastSignState) bool {
+	if height != state.Height {
+		return height > state.Height
+	}
+	if round != state.Round {
+		return round > state.Round
+	}
+	return step > state.Step
+}
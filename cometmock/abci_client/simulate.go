@@ -0,0 +1,204 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// SimulatedBlock is the outcome of a block that was simulated with SimulateBlock.
+type SimulatedBlock struct {
+	Height int64
+	// the transactions of the block after PrepareProposal
+	Txs types.Txs
+	// whether the app accepted the block in ProcessProposal
+	Accepted bool
+	// the response of the app to FinalizeBlock, or nil if the app rejected the block
+	Response *abcitypes.ResponseFinalizeBlock
+}
+
+// SimulateBlock runs PrepareProposal, ProcessProposal and FinalizeBlock for the next block
+// against the app selected by the target, which is either the validator address of the app
+// or its index in the app addresses, like for the target of abci_query.
+// If txs is nil, the transactions waiting to be included are proposed, without checking them again.
+// The block is never committed: the app is not sent Commit, and the state of CometMock,
+// the transactions waiting to be included and the time of the next block are left untouched.
+// Like for double execution, the app discards the state of the simulated block
+// and executes the next block from the state after the last committed block, see SetDoubleExecution.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) SimulateBlock(target string, txs *types.Txs) (*SimulatedBlock, error) {
+	if a.CommitsInFinalizeBlock {
+		return nil, fmt.Errorf("cannot simulate blocks, the apps commit blocks in FinalizeBlock")
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	client, err := a.GetCounterpartyFromTarget(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation target %v: %w", target, err)
+	}
+	proposer, err := a.simulationProposer(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockTxs types.Txs
+	if txs != nil {
+		blockTxs = *txs
+	} else {
+		blockTxs = append(append(make(types.Txs, 0), a.FreshTxQueue...), a.StaleTxQueue...)
+	}
+
+	block := a.simulationBlock(proposer, blockTxs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	resPrepareProposal, err := client.Client.PrepareProposal(ctx, &abcitypes.RequestPrepareProposal{
+		MaxTxBytes:         maxDataBytes,
+		Txs:                block.Txs.ToSliceOfBytes(),
+		LocalLastCommit:    utils.BuildExtendedCommitInfo(a.LastCommit, a.CurState.LastValidators, a.CurState.InitialHeight, a.CurState.ConsensusParams.ABCI),
+		Misbehavior:        block.Evidence.Evidence.ToABCI(),
+		Height:             block.Height,
+		Time:               block.Time,
+		NextValidatorsHash: block.NextValidatorsHash,
+		ProposerAddress:    block.ProposerAddress,
+	})
+	cancel()
+	if err != nil {
+		return nil, &AppError{Method: "PrepareProposal", App: client.NetworkAddress, Err: err}
+	}
+	preparedTxs := types.ToTxs(resPrepareProposal.GetTxs())
+	if err := preparedTxs.Validate(maxDataBytes); err != nil {
+		return nil, err
+	}
+
+	block = a.simulationBlock(proposer, preparedTxs)
+	result := &SimulatedBlock{Height: block.Height, Txs: block.Txs}
+
+	ctx, cancel = context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	resProcessProposal, err := client.Client.ProcessProposal(ctx, &abcitypes.RequestProcessProposal{
+		Hash:               block.Header.Hash(),
+		Height:             block.Header.Height,
+		Time:               block.Header.Time,
+		Txs:                block.Data.Txs.ToSliceOfBytes(),
+		ProposedLastCommit: utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight),
+		Misbehavior:        block.Evidence.Evidence.ToABCI(),
+		ProposerAddress:    block.ProposerAddress,
+		NextValidatorsHash: block.NextValidatorsHash,
+	})
+	cancel()
+	if err != nil {
+		return nil, &AppError{Method: "ProcessProposal", App: client.NetworkAddress, Err: err}
+	}
+	result.Accepted = resProcessProposal.IsAccepted()
+	if !result.Accepted {
+		// the block would never be finalized
+		return result, nil
+	}
+
+	result.Response, err = a.finalizeSimulatedBlock(client, block)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SimulatedTx is the outcome of a transaction that was simulated with SimulateTx.
+type SimulatedTx struct {
+	Height  int64
+	CheckTx *abcitypes.ResponseCheckTx
+	// the result of the transaction in FinalizeBlock, or nil if the transaction failed CheckTx
+	TxResult *abcitypes.ExecTxResult
+}
+
+// SimulateTx runs CheckTx for the transaction against the app selected by the target, like SimulateBlock,
+// and if the transaction passes it, FinalizeBlock for the next block with only this transaction.
+// Unlike the simulation of the app, e.g. the simulate query of the Cosmos SDK, the transaction
+// is executed in a block like any other transaction, e.g. with its signatures verified and its fees deducted.
+// Like SimulateBlock, the block is never committed, but the app keeps the state of CheckTx
+// until the next block is committed, e.g. the Cosmos SDK increments the sequence of the sender,
+// so that the same transaction fails CheckTx until then.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) SimulateTx(target string, tx types.Tx) (*SimulatedTx, error) {
+	if a.CommitsInFinalizeBlock {
+		return nil, fmt.Errorf("cannot simulate transactions, the apps commit blocks in FinalizeBlock")
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	client, err := a.GetCounterpartyFromTarget(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation target %v: %w", target, err)
+	}
+	proposer, err := a.simulationProposer(client)
+	if err != nil {
+		return nil, err
+	}
+
+	block := a.simulationBlock(proposer, types.Txs{tx})
+	result := &SimulatedTx{Height: block.Height}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ABCI_TIMEOUT)
+	result.CheckTx, err = client.Client.CheckTx(ctx, &abcitypes.RequestCheckTx{Tx: tx, Type: abcitypes.CheckTxType_New})
+	cancel()
+	if err != nil {
+		return nil, &AppError{Method: "CheckTx", App: client.NetworkAddress, Err: err}
+	}
+	if result.CheckTx.Code != abcitypes.CodeTypeOK {
+		return result, nil
+	}
+
+	response, err := a.finalizeSimulatedBlock(client, block)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.TxResults) != 1 {
+		return nil, fmt.Errorf("app %v returned %d transaction results for a block with 1 transaction", client.NetworkAddress, len(response.TxResults))
+	}
+	result.TxResult = response.TxResults[0]
+	return result, nil
+}
+
+// simulationProposer returns the validator that proposes simulated blocks for the client:
+// the validator of the client if it is in the validator set, and the next proposer otherwise.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) simulationProposer(client *AbciCounterpartyClient) (*types.Validator, error) {
+	if proposer, err := a.GetValidatorFromAddress(client.ValidatorAddress); err == nil {
+		return proposer, nil
+	}
+	return a.GetProposer()
+}
+
+// simulationBlock returns a block at the next height with the given transactions,
+// with the time the next block would have.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) simulationBlock(proposer *types.Validator, txs types.Txs) *types.Block {
+	block := a.CurState.MakeBlock(a.CurState.LastBlockHeight+1, txs, a.LastCommit.ToCommit(), nil, proposer.Address)
+	block.Time = a.TimeHandler.PeekBlockTime(a.LastBlock.Time)
+	return block
+}
+
+// finalizeSimulatedBlock sends FinalizeBlock for the simulated block to the client, without committing it.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) finalizeSimulatedBlock(client *AbciCounterpartyClient, block *types.Block) (*abcitypes.ResponseFinalizeBlock, error) {
+	ctx, cancel := context.WithTimeout(utils.ContextWithHeader(context.Background(), &block.Header), ABCI_TIMEOUT)
+	defer cancel()
+	response, err := client.Client.FinalizeBlock(ctx, &abcitypes.RequestFinalizeBlock{
+		Txs:                block.Txs.ToSliceOfBytes(),
+		DecidedLastCommit:  utils.BuildLastCommitInfo(block, a.CurState.LastValidators, a.CurState.InitialHeight),
+		Misbehavior:        block.Evidence.Evidence.ToABCI(),
+		Height:             block.Height,
+		Hash:               block.Hash(),
+		Time:               block.Time,
+		ProposerAddress:    block.ProposerAddress,
+		NextValidatorsHash: block.NextValidatorsHash,
+	})
+	if err != nil {
+		return nil, &AppError{Method: "FinalizeBlock", App: client.NetworkAddress, Err: err}
+	}
+	return response, nil
+}
@@ -0,0 +1,296 @@
+package abci_client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/state/txindex"
+	"github.com/cometbft/cometbft/types"
+)
+
+// DefaultSnapshotRetention is the number of snapshots that are kept if no retention is configured.
+const DefaultSnapshotRetention = 3
+
+// StateSnapshot is a snapshot of the state of CometMock after a block, which the chain can be rewound to,
+// see RewindToSnapshot. Blocks before the snapshot are not copied, since they never change.
+type StateSnapshot struct {
+	// the height and the time of the block after which the snapshot was taken
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+	// the app hash after the block, which the apps need to have when the chain is rewound
+	AppHash cmtbytes.HexBytes `json:"app_hash"`
+
+	state             state.State
+	lastBlock         *types.Block
+	lastCommit        *types.ExtendedCommit
+	signingStatus     map[string]bool
+	removedValidators map[string]bool
+}
+
+type stateSnapshots struct {
+	mutex     sync.RWMutex
+	snapshots []*StateSnapshot
+}
+
+// SetSnapshotPolicy makes CometMock take a snapshot of its state after every interval blocks,
+// keeping the last retention snapshots. If interval is 0, no snapshots are taken.
+// If retention is 0, DefaultSnapshotRetention snapshots are kept.
+func (a *AbciClient) SetSnapshotPolicy(interval int64, retention int) error {
+	if interval < 0 {
+		return fmt.Errorf("the snapshot interval must not be negative, got %d", interval)
+	}
+	if retention < 0 {
+		return fmt.Errorf("the snapshot retention must not be negative, got %d", retention)
+	}
+	if retention == 0 {
+		retention = DefaultSnapshotRetention
+	}
+
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	a.snapshotInterval = interval
+	a.snapshotRetention = retention
+	return nil
+}
+
+// Snapshots returns the snapshots that the chain can be rewound to, from the oldest to the most recent.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) Snapshots() []StateSnapshot {
+	a.snapshots.mutex.RLock()
+	defer a.snapshots.mutex.RUnlock()
+
+	snapshots := make([]StateSnapshot, 0, len(a.snapshots.snapshots))
+	for _, snapshot := range a.snapshots.snapshots {
+		snapshots = append(snapshots, StateSnapshot{
+			Height:  snapshot.Height,
+			Time:    snapshot.Time,
+			AppHash: snapshot.AppHash,
+		})
+	}
+	return snapshots
+}
+
+// takeSnapshotIfDue takes a snapshot of the state after the last block if the snapshot policy asks for one,
+// see SetSnapshotPolicy.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) takeSnapshotIfDue() {
+	height := a.CurState.LastBlockHeight
+	if a.snapshotInterval <= 0 || height%a.snapshotInterval != 0 {
+		return
+	}
+
+	snapshot := &StateSnapshot{
+		Height:            height,
+		Time:              a.LastBlock.Time,
+		AppHash:           a.CurState.AppHash,
+		state:             a.CurState.Copy(),
+		lastBlock:         a.LastBlock,
+		lastCommit:        a.LastCommit,
+		signingStatus:     a.GetSigningStatusMap(),
+		removedValidators: make(map[string]bool, len(a.removedValidators)),
+	}
+	a.signingStatusMutex.RLock()
+	for address := range a.removedValidators {
+		snapshot.removedValidators[address] = true
+	}
+	a.signingStatusMutex.RUnlock()
+
+	a.snapshots.mutex.Lock()
+	defer a.snapshots.mutex.Unlock()
+
+	// a block that was produced again after rewinding replaces the snapshot of the earlier block at its height
+	snapshots := a.snapshots.snapshots
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1].Height >= height {
+		snapshots = snapshots[:len(snapshots)-1]
+	}
+	snapshots = append(snapshots, snapshot)
+	if len(snapshots) > a.snapshotRetention {
+		snapshots = snapshots[len(snapshots)-a.snapshotRetention:]
+	}
+	a.snapshots.snapshots = snapshots
+	a.Logger.Debug("Took snapshot", "height", height)
+}
+
+// RewindToSnapshot rewinds the chain to the snapshot at the given height, or to the most recent snapshot
+// if the height is 0: the blocks after the snapshot are removed, and the next block is produced at the height
+// after the snapshot. The apps are reconnected at the given addresses, which are given in the same order
+// as the apps at startup (see ClientOrder). If no addresses are given, the apps are reconnected at their previous
+// addresses, and the same goes for single empty addresses.
+// The apps need to have been restored to the height of the snapshot, e.g. from snapshots of their own,
+// and need to have the app hash of the snapshot.
+// Transactions waiting to be included, halts, upgrades and vote extension overrides are dropped,
+// and the validators sign like they did at the height of the snapshot. Block times keep advancing from the time
+// of the snapshot, including time advanced with advance_time since.
+// If the apps cannot be connected or are not at the snapshot, the chain is not rewound, but the previous connections
+// to the apps are closed, like when resuming block production, see Resume.
+// It returns the snapshot that the chain was rewound to.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) RewindToSnapshot(height int64, appAddresses []string) (*StateSnapshot, error) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	snapshot, err := a.findSnapshot(height)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.rewindLocked(snapshot, appAddresses); err != nil {
+		return nil, err
+	}
+	return &StateSnapshot{Height: snapshot.Height, Time: snapshot.Time, AppHash: snapshot.AppHash}, nil
+}
+
+// rewindLocked rewinds the chain to the given snapshot, see RewindToSnapshot.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) rewindLocked(snapshot *StateSnapshot, appAddresses []string) error {
+	if a.ConnectClient == nil {
+		return errors.New("cannot reconnect to apps, no way to connect to apps is configured")
+	}
+	if missingApps := a.MissingApps(); len(missingApps) > 0 {
+		return fmt.Errorf("cannot rewind the chain while %d apps that were missing at startup have not joined", len(missingApps))
+	}
+
+	if len(appAddresses) == 0 {
+		appAddresses = make([]string, len(a.ClientOrder))
+	}
+	if len(appAddresses) != len(a.ClientOrder) {
+		return fmt.Errorf("got %d app addresses, but there are %d apps", len(appAddresses), len(a.ClientOrder))
+	}
+
+	if err := a.reconnectAppsAt(appAddresses, snapshot.Height, snapshot.AppHash); err != nil {
+		return err
+	}
+
+	a.Storage.DeleteAfter(snapshot.Height)
+	if err := a.restartIndexers(); err != nil {
+		return err
+	}
+	if err := a.reindex(snapshot.Height); err != nil {
+		return err
+	}
+	if a.QueryCache != nil {
+		a.QueryCache.Reset()
+	}
+	a.restoreSnapshot(snapshot)
+
+	a.Logger.Info("Rewound the chain to a snapshot", "height", snapshot.Height, "app_hash", snapshot.AppHash)
+	return nil
+}
+
+// findSnapshot returns the snapshot at the given height, or the most recent snapshot if the height is 0.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) findSnapshot(height int64) (*StateSnapshot, error) {
+	a.snapshots.mutex.RLock()
+	defer a.snapshots.mutex.RUnlock()
+
+	snapshots := a.snapshots.snapshots
+	if len(snapshots) == 0 {
+		return nil, errors.New("there are no snapshots, see --snapshot-interval")
+	}
+	if height == 0 {
+		return snapshots[len(snapshots)-1], nil
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Height == height {
+			return snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no snapshot at height %d", height)
+}
+
+// reindex indexes the stored blocks up to the given height and their transactions,
+// like the indexer service indexes the events of new blocks. Blocks that were dropped from the storage are skipped.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) reindex(toHeight int64) error {
+	for height := max(a.CurState.InitialHeight, a.Storage.Info().PrunedBelow); height <= toHeight; height++ {
+		block, err := a.Storage.GetBlock(height)
+		if err != nil {
+			return err
+		}
+		responses, err := a.Storage.GetResponses(height)
+		if err != nil {
+			return err
+		}
+
+		batch := txindex.NewBatch(int64(len(block.Txs)))
+		for i, tx := range block.Txs {
+			if err := batch.Add(&abcitypes.TxResult{
+				Height: height,
+				Index:  uint32(i),
+				Tx:     tx,
+				Result: *responses.TxResults[i],
+			}); err != nil {
+				return err
+			}
+		}
+		if err := a.BlockIndex.Index(types.EventDataNewBlockEvents{
+			Height: height,
+			Events: responses.Events,
+			NumTxs: int64(len(block.Txs)),
+		}); err != nil {
+			return fmt.Errorf("error indexing block %d: %w", height, err)
+		}
+		if err := a.TxIndex.AddBatch(batch); err != nil {
+			return fmt.Errorf("error indexing the transactions of block %d: %w", height, err)
+		}
+	}
+	return nil
+}
+
+// restoreSnapshot restores the state from the snapshot, and drops the snapshots and the divergent app hashes
+// of later heights, as well as everything that CometMock keeps about the blocks after the snapshot.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) restoreSnapshot(snapshot *StateSnapshot) {
+	a.Storage.LockBeforeStateUpdate()
+	a.CurState = snapshot.state.Copy()
+	a.LastBlock = snapshot.lastBlock
+	a.LastCommit = snapshot.lastCommit
+	a.Storage.UnlockAfterStateUpdate()
+	a.lastOverriddenExtensions = nil
+	a.lastConflictingBlocks = nil
+	a.ClearTxs()
+
+	a.haltMutex.Lock()
+	a.halt = nil
+	a.haltMutex.Unlock()
+
+	a.upgrade.mutex.Lock()
+	a.upgrade.status = nil
+	a.upgrade.mutex.Unlock()
+
+	a.appHashesMutex.Lock()
+	for height := range a.divergentAppHashes {
+		if height > snapshot.Height {
+			delete(a.divergentAppHashes, height)
+		}
+	}
+	a.appHashesMutex.Unlock()
+
+	a.voteExtensionOverrides.mutex.Lock()
+	a.voteExtensionOverrides.overrides = nil
+	a.voteExtensionOverrides.mutex.Unlock()
+
+	a.signingStatusMutex.Lock()
+	a.signingStatus = make(map[string]bool, len(snapshot.signingStatus))
+	for address, signing := range snapshot.signingStatus {
+		a.signingStatus[address] = signing
+	}
+	a.removedValidators = make(map[string]bool, len(snapshot.removedValidators))
+	for address := range snapshot.removedValidators {
+		a.removedValidators[address] = true
+	}
+	a.signingStatusMutex.Unlock()
+
+	a.snapshots.mutex.Lock()
+	snapshots := a.snapshots.snapshots
+	for len(snapshots) > 0 && snapshots[len(snapshots)-1].Height > snapshot.Height {
+		snapshots = snapshots[:len(snapshots)-1]
+	}
+	a.snapshots.snapshots = snapshots
+	a.snapshots.mutex.Unlock()
+}
@@ -19,19 +19,29 @@ type TimeHandler interface {
 	// It returns the timestamp of the next block.
 	GetBlockTime(lastBlockTimestamp time.Time) time.Time
 
+	// PeekBlockTime returns the timestamp that GetBlockTime would return
+	// if it was called now, without affecting the timestamps of future blocks.
+	PeekBlockTime(lastBlockTimestamp time.Time) time.Time
+
 	// AdvanceTime advances the timestamp of all following blocks by
 	// the given duration.
 	// The duration needs to be non-negative.
 	// It returns the timestamp that the next block would have if it
 	// was produced now.
 	AdvanceTime(duration time.Duration) time.Time
+
+	// TimeOffset returns the offset by which the block timestamps
+	// are currently shifted from the timestamps the TimeHandler would
+	// produce without any calls to AdvanceTime.
+	// For the SystemClockTimeHandler, this is the offset from the system time.
+	TimeOffset() time.Duration
 }
 
 // The SystemClockTimeHandler uses the system clock
 // to decide the timestamps of blocks.
 // It will return the system time + offset for each block.
-// The offset is calculated by the initial timestamp
-// + the sum of all durations passed to AdvanceTime.
+// The offset is the difference between the initial timestamp and the system time
+// when the handler was created, plus the sum of all durations passed to AdvanceTime.
 type SystemClockTimeHandler struct {
 	// The offset to add to the system time.
 	curOffset time.Duration
@@ -43,14 +53,21 @@ type SystemClockTimeHandler struct {
 
 func NewSystemClockTimeHandler(initialTimestamp time.Time) *SystemClockTimeHandler {
 	return &SystemClockTimeHandler{
-		curOffset: time.Since(initialTimestamp),
+		curOffset: time.Until(initialTimestamp),
 	}
 }
 
 func (s *SystemClockTimeHandler) GetBlockTime(lastBlockTimestamp time.Time) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	return time.Now().Add(s.curOffset)
 }
 
+func (s *SystemClockTimeHandler) PeekBlockTime(lastBlockTimestamp time.Time) time.Time {
+	return s.GetBlockTime(lastBlockTimestamp)
+}
+
 func (s *SystemClockTimeHandler) AdvanceTime(duration time.Duration) time.Time {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -59,6 +76,13 @@ func (s *SystemClockTimeHandler) AdvanceTime(duration time.Duration) time.Time {
 	return time.Now().Add(s.curOffset)
 }
 
+func (s *SystemClockTimeHandler) TimeOffset() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.curOffset
+}
+
 var _ TimeHandler = (*SystemClockTimeHandler)(nil)
 
 // The FixedBlockTimeHandler uses a fixed duration
@@ -70,6 +94,12 @@ type FixedBlockTimeHandler struct {
 	// when deciding the next block timestamp.
 	blockTime time.Duration
 
+	// If this is set, a random jitter is added to the block time of each block.
+	jitter *Jitter
+
+	// The block time of the next block, i.e. the block time with jitter.
+	nextBlockTime time.Duration
+
 	// The offset to add to the last block time.
 	// This will be cleared after each block,
 	// but since the block time of the next block depends
@@ -77,6 +107,9 @@ type FixedBlockTimeHandler struct {
 	// this will shift the timestamps of all future blocks.
 	curBlockOffset time.Duration
 
+	// The sum of all durations passed to AdvanceTime.
+	totalOffset time.Duration
+
 	// A mutex that ensures that GetBlockTime and AdvanceTime
 	// are not called concurrently.
 	// Otherwise, the block offset might be put into a broken state.
@@ -91,20 +124,49 @@ type FixedBlockTimeHandler struct {
 func NewFixedBlockTimeHandler(blockTime time.Duration) *FixedBlockTimeHandler {
 	return &FixedBlockTimeHandler{
 		blockTime:      blockTime,
+		nextBlockTime:  blockTime,
 		curBlockOffset: 0,
 	}
 }
 
+// SetJitter makes the handler add a random jitter to the block time of each following block.
+// Block times are at least a millisecond, so that block timestamps keep increasing.
+func (f *FixedBlockTimeHandler) SetJitter(jitter *Jitter) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.jitter = jitter
+	f.nextBlockTime = jitter.Apply(f.blockTime, time.Millisecond)
+}
+
+// Jitter returns the jitter added to the block times, if any.
+func (f *FixedBlockTimeHandler) Jitter() *Jitter {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.jitter
+}
+
 func (f *FixedBlockTimeHandler) GetBlockTime(lastBlockTimestamp time.Time) time.Time {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
-	res := lastBlockTimestamp.Add(f.blockTime + f.curBlockOffset)
+	res := lastBlockTimestamp.Add(f.nextBlockTime + f.curBlockOffset)
 	f.curBlockOffset = 0
 	f.lastBlockTimestamp = res
+	if f.jitter != nil {
+		f.nextBlockTime = f.jitter.Apply(f.blockTime, time.Millisecond)
+	}
 	return res
 }
 
+func (f *FixedBlockTimeHandler) PeekBlockTime(lastBlockTimestamp time.Time) time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return lastBlockTimestamp.Add(f.nextBlockTime + f.curBlockOffset)
+}
+
 // FixedBlockTimeHandler.AdvanceTime will only return the correct next block time
 // after GetBlockTime has been called once, but it will
 // still advance the time correctly before that - only the output will be wrong.
@@ -113,7 +175,15 @@ func (f *FixedBlockTimeHandler) AdvanceTime(duration time.Duration) time.Time {
 	defer f.mutex.Unlock()
 
 	f.curBlockOffset += duration
-	return f.lastBlockTimestamp.Add(f.blockTime + f.curBlockOffset)
+	f.totalOffset += duration
+	return f.lastBlockTimestamp.Add(f.nextBlockTime + f.curBlockOffset)
+}
+
+func (f *FixedBlockTimeHandler) TimeOffset() time.Duration {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.totalOffset
 }
 
 var _ TimeHandler = (*FixedBlockTimeHandler)(nil)
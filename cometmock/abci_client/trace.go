@@ -0,0 +1,58 @@
+package abci_client
+
+// TraceBlocks makes CometMock log the full requests and responses of the ABCI calls to all apps
+// while the next numBlocks blocks are produced, e.g. to debug a single bad block without restarting CometMock
+// with other settings. The calls are logged at the info level, with the module abci-trace.
+// If numBlocks is 0, tracing stops after the block that is being produced.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) TraceBlocks(numBlocks int) {
+	a.tracedBlocks.Store(int64(numBlocks))
+}
+
+// TracedBlocks returns the number of blocks whose ABCI calls are still to be traced, see TraceBlocks.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) TracedBlocks() int {
+	return int(a.tracedBlocks.Load())
+}
+
+// traceBlock starts tracing the ABCI calls of the block with the given height, if blocks are to be traced,
+// and returns a function that stops tracing them.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) traceBlock(height int64) func() {
+	for {
+		remaining := a.tracedBlocks.Load()
+		if remaining <= 0 {
+			return func() {}
+		}
+		if a.tracedBlocks.CompareAndSwap(remaining, remaining-1) {
+			break
+		}
+	}
+
+	logger := a.Logger.With("module", "abci-trace", "height", height)
+	a.clientsMutex.RLock()
+	traced := make([]*WatchedClient, 0, len(a.Clients))
+	for _, client := range a.Clients {
+		watchedClient, ok := client.Client.(*WatchedClient)
+		if !ok {
+			continue
+		}
+		app := a.validatorLabel(client.ValidatorAddress)
+		networkAddress := client.NetworkAddress
+		watchedClient.setTracer(func(method string, request, response interface{}, err error) {
+			if err != nil {
+				logger.Info("ABCI call", "app", app, "network_address", networkAddress, "method", method, "request", request, "err", err)
+				return
+			}
+			logger.Info("ABCI call", "app", app, "network_address", networkAddress, "method", method, "request", request, "response", response)
+		})
+		traced = append(traced, watchedClient)
+	}
+	a.clientsMutex.RUnlock()
+
+	return func() {
+		for _, watchedClient := range traced {
+			watchedClient.setTracer(nil)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package abci_client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The states of an upgrade, see UpgradeStatus.
+const (
+	UpgradeStateScheduled = "scheduled"
+	// the apps halted at the upgrade height and were reconnected,
+	// but the app hashes were not compared yet
+	UpgradeStateResumed   = "resumed"
+	UpgradeStateCompleted = "completed"
+	UpgradeStateFailed    = "failed"
+)
+
+// how long to wait for the upgraded apps to become reachable
+const upgradeConnectTimeout = 60 * time.Second
+
+// UpgradeStatus describes an upgrade scheduled with ScheduleUpgrade.
+type UpgradeStatus struct {
+	Height int64 `json:"height"`
+	// the addresses of the upgraded apps, in the order of ClientOrder.
+	// Empty addresses mean that the app is reconnected at its previous address
+	AppAddresses []string `json:"app_addresses"`
+	State        string   `json:"state"`
+	// why the upgrade failed, if it did
+	Error string `json:"error,omitempty"`
+}
+
+type scheduledUpgrade struct {
+	mutex  sync.RWMutex
+	status *UpgradeStatus
+}
+
+// ScheduleUpgrade announces an upgrade at the given height, at which the apps
+// that run the pre-upgrade version must halt, while apps that already run
+// the post-upgrade version may carry on. When apps halt at the upgrade height,
+// CometMock reconnects to the apps at the given addresses, which run the post-upgrade version,
+// and produces the block at the upgrade height again.
+// It then checks that the app hashes of all apps are equal after the block at the upgrade height,
+// i.e. that all apps agree on the state after the upgrade.
+// The progress is reported by GetUpgradeStatus.
+func (a *AbciClient) ScheduleUpgrade(height int64, appAddresses []string) error {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	if height <= a.CurState.LastBlockHeight {
+		return fmt.Errorf("the upgrade height %d must be after the last block height %d", height, a.CurState.LastBlockHeight)
+	}
+	if len(appAddresses) != 0 && len(appAddresses) != len(a.ClientOrder) {
+		return fmt.Errorf("got %d app addresses, but there are %d apps", len(appAddresses), len(a.ClientOrder))
+	}
+
+	a.upgrade.mutex.Lock()
+	defer a.upgrade.mutex.Unlock()
+
+	a.upgrade.status = &UpgradeStatus{
+		Height:       height,
+		AppAddresses: appAddresses,
+		State:        UpgradeStateScheduled,
+	}
+	a.Logger.Info("Scheduled upgrade", "height", height, "app_addresses", appAddresses)
+	return nil
+}
+
+// GetUpgradeStatus returns the status of the last scheduled upgrade, or nil if no upgrade was scheduled.
+func (a *AbciClient) GetUpgradeStatus() *UpgradeStatus {
+	a.upgrade.mutex.RLock()
+	defer a.upgrade.mutex.RUnlock()
+
+	if a.upgrade.status == nil {
+		return nil
+	}
+	status := *a.upgrade.status
+	return &status
+}
+
+func (a *AbciClient) setUpgradeState(state string, err error) {
+	a.upgrade.mutex.Lock()
+	defer a.upgrade.mutex.Unlock()
+
+	a.upgrade.status.State = state
+	if err != nil {
+		a.upgrade.status.Error = err.Error()
+		a.Logger.Error("Upgrade failed", "height", a.upgrade.status.Height, "err", err)
+	} else {
+		a.Logger.Info("Upgrade progressed", "height", a.upgrade.status.Height, "state", state)
+	}
+}
+
+// upgradeAfterBlock advances a scheduled upgrade after the block at the given height
+// was produced (or failed with blockErr), and returns the error to report for the block.
+// If the apps halted at the upgrade height, they are reconnected and the block is produced again.
+// Should only be used after locking the a.blockMutex.
+func (a *AbciClient) upgradeAfterBlock(height int64, opts BlockOptions, blockErr error) error {
+	upgrade := a.GetUpgradeStatus()
+	if upgrade == nil {
+		return blockErr
+	}
+
+	switch upgrade.State {
+	case UpgradeStateScheduled:
+		if !errors.Is(blockErr, ErrHalted) {
+			if height == upgrade.Height && blockErr == nil {
+				a.setUpgradeState(UpgradeStateFailed, fmt.Errorf("no app halted at the upgrade height %d", height))
+			}
+			return blockErr
+		}
+
+		if height != upgrade.Height {
+			a.setUpgradeState(UpgradeStateFailed, fmt.Errorf("the apps halted at height %d, but the upgrade is at height %d", height, upgrade.Height))
+			return blockErr
+		}
+
+		if err := a.resumeForUpgrade(upgrade.AppAddresses); err != nil {
+			a.setUpgradeState(UpgradeStateFailed, fmt.Errorf("could not connect to the upgraded apps: %w", err))
+			return blockErr
+		}
+		a.setUpgradeState(UpgradeStateResumed, nil)
+
+		// produce the block at the upgrade height with the upgraded apps
+		err := a.runBlockLocked(opts)
+		if err != nil {
+			a.setUpgradeState(UpgradeStateFailed, fmt.Errorf("error producing the block at the upgrade height: %w", err))
+		}
+		return err
+	case UpgradeStateResumed:
+		// the app hashes after the block at the upgrade height were checked in SendFinalizeBlock
+		if height == upgrade.Height && blockErr == nil {
+			a.setUpgradeState(UpgradeStateCompleted, nil)
+		}
+	}
+	return blockErr
+}
+
+// resumeForUpgrade reconnects to the upgraded apps, waiting for them to become reachable.
+func (a *AbciClient) resumeForUpgrade(appAddresses []string) error {
+	deadline := time.Now().Add(upgradeConnectTimeout)
+	for {
+		err := a.resumeLocked(appAddresses)
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		a.Logger.Info("Waiting for the upgraded apps", "err", err)
+		time.Sleep(time.Second)
+	}
+}
+
+// checkUpgradeConvergence returns an error if the block at the given height is the block
+// at the upgrade height produced by the upgraded apps, and the app hashes of the apps differ.
+// Since the apps already finalized the block, the error halts block production, see FinalizedBlockError.
+func checkUpgradeConvergence[T any](a *AbciClient, height int64, responses []T) error {
+	upgrade := a.GetUpgradeStatus()
+	if upgrade == nil || upgrade.State != UpgradeStateResumed || upgrade.Height != height {
+		return nil
+	}
+
+	if err := checkAppHashesEqual(a, "FinalizeBlock", responses); err != nil {
+		err = fmt.Errorf("the app hashes did not converge after the upgrade: %w", err)
+		a.setUpgradeState(UpgradeStateFailed, err)
+		return &FinalizedBlockError{Height: height, Reason: HaltReasonAppsDiverged, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package abci_client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that block production halts when the app hashes of the upgraded apps do not converge
+// after the block at the upgrade height, even if the responses to FinalizeBlock are not compared otherwise.
+func TestHaltWhenUpgradeDoesNotConverge(t *testing.T) {
+	app := &divergingApp{Application: kvstore.NewInMemoryApplication(), divergeAtCall: 2}
+	client := newTestClient(t, kvstore.NewInMemoryApplication(), app)
+	client.DeterminismChecks = DeterminismChecks{"FinalizeBlock": DeterminismCheckOff}
+	require.NoError(t, client.RunBlock())
+
+	// the apps halted at height 2 and were reconnected after the upgrade
+	client.upgrade.status = &UpgradeStatus{Height: 2, State: UpgradeStateResumed}
+
+	err := client.RunBlock()
+	require.True(t, errors.Is(err, ErrHalted), "unexpected error: %v", err)
+	halt := client.Halted()
+	require.NotNil(t, halt)
+	require.Equal(t, HaltReasonAppsDiverged, halt.Reason)
+	require.Contains(t, halt.Error, "did not converge after the upgrade")
+	require.Equal(t, int64(1), client.LastBlockHeight())
+	require.Equal(t, UpgradeStateFailed, client.GetUpgradeStatus().State)
+
+	calls := app.calls
+	require.ErrorIs(t, client.RunBlock(), ErrHalted)
+	require.Equal(t, calls, app.calls)
+}
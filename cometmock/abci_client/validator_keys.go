@@ -0,0 +1,89 @@
+package abci_client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+// ValidatorKeyCheck is the result of matching the keys that CometMock has with the current validator set,
+// see CheckValidatorKeys.
+type ValidatorKeyCheck struct {
+	// the validators of the current validator set whose keys CometMock does not have.
+	// They never sign, like validators whose nodes are down.
+	MissingKeys []*types.Validator
+	// the addresses of the keys of apps that are not in the current validator set.
+	// Their apps only follow the chain, like CometBFT nodes whose key is not a validator.
+	UnmatchedKeys []string
+	// the voting power of the validators whose keys CometMock has, and the total voting power
+	PowerWithKeys int64
+	TotalPower    int64
+}
+
+// CheckValidatorKeys matches the keys that CometMock has, i.e. those of the apps, of the placeholder validators
+// and of the apps that were missing at startup, with the current validator set, by the addresses of their public keys.
+// A key that does not match any validator usually means that a node home holds another key than the one
+// the genesis was created with, which otherwise only shows up as commits that cannot be verified,
+// e.g. by light clients of the chain.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) CheckValidatorKeys() ValidatorKeyCheck {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+	a.missingAppsMutex.RLock()
+	defer a.missingAppsMutex.RUnlock()
+
+	hasKey := func(address string) bool {
+		_, missing := a.missingApps[address]
+		return a.HasClient(address) || a.placeholderClient(address) != nil || missing
+	}
+
+	check := ValidatorKeyCheck{TotalPower: a.CurState.Validators.TotalVotingPower()}
+	validatorAddresses := make(map[string]bool, a.CurState.Validators.Size())
+	for _, val := range a.CurState.Validators.Validators {
+		address := val.Address.String()
+		validatorAddresses[address] = true
+		if hasKey(address) {
+			check.PowerWithKeys += val.VotingPower
+		} else {
+			check.MissingKeys = append(check.MissingKeys, val)
+		}
+	}
+
+	for _, address := range a.ClientOrder {
+		client := a.Clients[address]
+		if client.Observer || client.ExecutorOf != "" {
+			continue
+		}
+		if !validatorAddresses[address] {
+			check.UnmatchedKeys = append(check.UnmatchedKeys, address)
+		}
+	}
+	for address := range a.missingApps {
+		if !validatorAddresses[address] {
+			check.UnmatchedKeys = append(check.UnmatchedKeys, address)
+		}
+	}
+	return check
+}
+
+// MissingKeysError returns an error that lists the validators whose keys are missing,
+// and the keys that match no validator, or nil if no keys are missing.
+func (a *AbciClient) MissingKeysError(check ValidatorKeyCheck) error {
+	if len(check.MissingKeys) == 0 {
+		return nil
+	}
+
+	missing := make([]string, len(check.MissingKeys))
+	for i, val := range check.MissingKeys {
+		missing[i] = fmt.Sprintf("%v with power %d", a.validatorLabel(val.Address.String()), val.VotingPower)
+	}
+	message := fmt.Sprintf("the keys of %d validators with %d of %d voting power are missing: %v",
+		len(check.MissingKeys), check.TotalPower-check.PowerWithKeys, check.TotalPower, strings.Join(missing, ", "))
+	if len(check.UnmatchedKeys) > 0 {
+		message += fmt.Sprintf(". These keys match no validator, check that the node homes hold the keys the genesis was created with: %v",
+			strings.Join(check.UnmatchedKeys, ", "))
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,156 @@
+package abci_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// ParseValidatorNames parses a comma-separated list of name=validator pairs, where the validator
+// is given in any of the formats accepted by utils.ValidatorAddress, e.g. "alice=ABCD...,bob=cosmosvalcons1...".
+// It returns the validator addresses by name.
+func ParseValidatorNames(s string) (map[string]string, error) {
+	names := make(map[string]string)
+	if s == "" {
+		return names, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		// validators may be given as base64 public keys, which can end with =
+		name, validator, found := strings.Cut(pair, "=")
+		if !found || name == "" || validator == "" {
+			return nil, fmt.Errorf("invalid validator name %q, must be of the form name=validator", pair)
+		}
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("the validator name %v is given more than once", name)
+		}
+		names[name] = utils.ValidatorAddress(validator)
+	}
+	return names, nil
+}
+
+// GenesisValidatorNames returns the validator addresses by name from the genesis:
+// the names of the validators of the genesis, and the monikers of the validators in the app state
+// of Cosmos SDK chains, from their gentxs or, for exported genesis files, from the staking module.
+// Names that belong to more than one validator are left out, since they cannot identify a validator.
+func GenesisValidatorNames(genesisDoc *types.GenesisDoc) map[string]string {
+	names := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	add := func(name, address string) {
+		if name == "" || ambiguous[name] {
+			return
+		}
+		if previous, ok := names[name]; ok && previous != address {
+			delete(names, name)
+			ambiguous[name] = true
+			return
+		}
+		names[name] = address
+	}
+
+	for _, val := range genesisDoc.Validators {
+		add(val.Name, val.PubKey.Address().String())
+	}
+
+	var appState struct {
+		Genutil struct {
+			GenTxs []struct {
+				Body struct {
+					Messages []struct {
+						Description struct {
+							Moniker string `json:"moniker"`
+						} `json:"description"`
+						Pubkey struct {
+							Key string `json:"key"`
+						} `json:"pubkey"`
+					} `json:"messages"`
+				} `json:"body"`
+			} `json:"gen_txs"`
+		} `json:"genutil"`
+		Staking struct {
+			Validators []struct {
+				Description struct {
+					Moniker string `json:"moniker"`
+				} `json:"description"`
+				ConsensusPubkey struct {
+					Key string `json:"key"`
+				} `json:"consensus_pubkey"`
+			} `json:"validators"`
+		} `json:"staking"`
+	}
+	// the app state of apps other than Cosmos SDK chains simply has no monikers
+	if err := json.Unmarshal(genesisDoc.AppState, &appState); err != nil {
+		return names
+	}
+	for _, tx := range appState.Genutil.GenTxs {
+		for _, msg := range tx.Body.Messages {
+			if msg.Pubkey.Key != "" {
+				add(msg.Description.Moniker, utils.ValidatorAddress(msg.Pubkey.Key))
+			}
+		}
+	}
+	for _, val := range appState.Staking.Validators {
+		if val.ConsensusPubkey.Key != "" {
+			add(val.Description.Moniker, utils.ValidatorAddress(val.ConsensusPubkey.Key))
+		}
+	}
+	return names
+}
+
+// SetValidatorNames replaces the names of the validators, given as validator addresses by name,
+// which can then be used in place of the addresses of the validators, see ResolveValidator,
+// and are shown next to the addresses in the log output.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) SetValidatorNames(names map[string]string) {
+	a.validatorNamesMutex.Lock()
+	defer a.validatorNamesMutex.Unlock()
+
+	a.validatorNames = make(map[string]string, len(names))
+	a.validatorNamesByAddress = make(map[string]string, len(names))
+	for name, address := range names {
+		a.validatorNames[name] = address
+		a.validatorNamesByAddress[address] = name
+	}
+}
+
+// ValidatorNames returns the validator addresses by name, see SetValidatorNames.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ValidatorNames() map[string]string {
+	a.validatorNamesMutex.RLock()
+	defer a.validatorNamesMutex.RUnlock()
+
+	names := make(map[string]string, len(a.validatorNames))
+	for name, address := range a.validatorNames {
+		names[name] = address
+	}
+	return names
+}
+
+// ResolveValidator returns the address of the validator with the given identifier,
+// which is either the name of the validator, see SetValidatorNames, or any of the formats
+// accepted by utils.ValidatorAddress.
+// It is safe for use by multiple goroutines simultaneously.
+func (a *AbciClient) ResolveValidator(identifier string) string {
+	a.validatorNamesMutex.RLock()
+	address, ok := a.validatorNames[strings.TrimSpace(identifier)]
+	a.validatorNamesMutex.RUnlock()
+
+	if ok {
+		return address
+	}
+	return utils.ValidatorAddress(identifier)
+}
+
+// validatorLabel returns the address of the validator for log output, with its name if it has one.
+func (a *AbciClient) validatorLabel(address string) string {
+	a.validatorNamesMutex.RLock()
+	defer a.validatorNamesMutex.RUnlock()
+
+	if name, ok := a.validatorNamesByAddress[address]; ok {
+		return fmt.Sprintf("%v (%v)", name, address)
+	}
+	return address
+}
@@ -0,0 +1,163 @@
+package abci_client
+
+import (
+	"fmt"
+	"sync"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// VoteExtension is the vote extension of a validator in a commit.
+type VoteExtension struct {
+	ValidatorAddress string `json:"validator_address"`
+	// the network address of the app of the validator, if CometMock is connected to it
+	AppAddress string `json:"app_address,omitempty"`
+	// whether the validator voted for the block. Validators that did not vote have no extension
+	Voted              bool              `json:"voted"`
+	Extension          cmtbytes.HexBytes `json:"extension"`
+	ExtensionSignature cmtbytes.HexBytes `json:"extension_signature"`
+	// whether the extension was set with OverrideVoteExtension instead of coming from ExtendVote
+	Overridden bool `json:"overridden"`
+}
+
+// LastVoteExtensions returns the height of the last commit, and the vote extensions in it,
+// in the order of the validators. The extensions are empty if vote extensions are not enabled.
+func (a *AbciClient) LastVoteExtensions() (int64, []VoteExtension) {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	commit := a.LastCommit
+	if commit == nil {
+		return 0, nil
+	}
+
+	a.clientsMutex.RLock()
+	defer a.clientsMutex.RUnlock()
+
+	extensions := make([]VoteExtension, 0, len(commit.ExtendedSignatures))
+	for _, sig := range commit.ExtendedSignatures {
+		if sig.BlockIDFlag == types.BlockIDFlagAbsent {
+			// absent signatures do not have the validator address, so it is taken from the validator set
+			extensions = append(extensions, VoteExtension{})
+			continue
+		}
+		extension := VoteExtension{
+			ValidatorAddress:   sig.ValidatorAddress.String(),
+			Voted:              sig.BlockIDFlag == types.BlockIDFlagCommit,
+			Extension:          sig.Extension,
+			ExtensionSignature: sig.ExtensionSignature,
+			Overridden:         a.lastOverriddenExtensions[sig.ValidatorAddress.String()],
+		}
+		extensions = append(extensions, extension)
+	}
+
+	// the last commit was signed by the validators of the state before the last block
+	validators := a.CurState.LastValidators
+	for i := range extensions {
+		if extensions[i].ValidatorAddress == "" && validators != nil && i < validators.Size() {
+			extensions[i].ValidatorAddress = validators.Validators[i].Address.String()
+		}
+		if client, ok := a.Clients[extensions[i].ValidatorAddress]; ok {
+			extensions[i].AppAddress = client.NetworkAddress
+		}
+	}
+	return commit.Height, extensions
+}
+
+// SetVoteExtensionsEnableHeight sets the height from which on vote extensions are enabled,
+// like an update of the consensus params returned by FinalizeBlock for the last block would.
+// As in CometBFT, the height must be after the last block, and cannot be changed once vote extensions are enabled.
+// The precommits for blocks from the height on are extended, so the proposer of the block after it
+// is the first to receive vote extensions in PrepareProposal.
+// Note that the apps are not told about the change, so apps that keep their own copy
+// of the consensus params need to be updated separately.
+func (a *AbciClient) SetVoteExtensionsEnableHeight(height int64) error {
+	a.blockMutex.Lock()
+	defer a.blockMutex.Unlock()
+
+	lastHeight := a.CurState.LastBlockHeight
+	update := &cmtproto.ConsensusParams{
+		Abci: &cmtproto.ABCIParams{VoteExtensionsEnableHeight: height},
+	}
+	if err := a.CurState.ConsensusParams.ValidateUpdate(update, lastHeight); err != nil {
+		return err
+	}
+	params := a.CurState.ConsensusParams.Update(update)
+	if err := params.ValidateBasic(); err != nil {
+		return err
+	}
+
+	a.CurState.ConsensusParams = params
+	a.CurState.LastHeightConsensusParamsChanged = lastHeight + 1
+	a.Logger.Info("Set vote extensions enable height", "height", height)
+	return nil
+}
+
+// voteExtensionOverride replaces the vote extension of a validator for a number of blocks.
+type voteExtensionOverride struct {
+	extension []byte
+	// the number of blocks for which the extension is still replaced
+	remaining int64
+}
+
+type voteExtensionOverrides struct {
+	mutex     sync.Mutex
+	overrides map[string]*voteExtensionOverride
+}
+
+// OverrideVoteExtension makes the validator with the given address use the given vote extension
+// for the next numBlocks blocks, instead of calling ExtendVote on its app.
+// The overridden extension is still signed by the validator and verified by the other apps,
+// and if an app rejects it, the vote is dropped from the commit, like CometBFT drops votes
+// with invalid extensions. If numBlocks is 0, the override is removed.
+// The validator is given by its name or in any of the formats accepted by utils.ValidatorAddress.
+func (a *AbciClient) OverrideVoteExtension(address string, extension []byte, numBlocks int64) error {
+	if numBlocks < 0 {
+		return fmt.Errorf("the number of blocks must not be negative, got %d", numBlocks)
+	}
+	address = a.ResolveValidator(address)
+	if _, err := a.GetValidatorFromAddress(address); err != nil {
+		return err
+	}
+	if !a.HasClient(address) {
+		return fmt.Errorf("cannot override the vote extension of validator %v, it has no app and never signs", address)
+	}
+
+	a.voteExtensionOverrides.mutex.Lock()
+	defer a.voteExtensionOverrides.mutex.Unlock()
+
+	if numBlocks == 0 {
+		delete(a.voteExtensionOverrides.overrides, address)
+		a.Logger.Info("Removed vote extension override", "validator", a.validatorLabel(address))
+		return nil
+	}
+
+	if a.voteExtensionOverrides.overrides == nil {
+		a.voteExtensionOverrides.overrides = make(map[string]*voteExtensionOverride)
+	}
+	a.voteExtensionOverrides.overrides[address] = &voteExtensionOverride{
+		extension: extension,
+		remaining: numBlocks,
+	}
+	a.Logger.Info("Overriding vote extension", "validator", a.validatorLabel(address), "extension", fmt.Sprintf("%X", extension), "num_blocks", numBlocks)
+	return nil
+}
+
+// takeVoteExtensionOverride returns the overridden vote extension of the validator with the given address
+// for the current block, if there is one, and counts it as used.
+func (a *AbciClient) takeVoteExtensionOverride(address string) ([]byte, bool) {
+	a.voteExtensionOverrides.mutex.Lock()
+	defer a.voteExtensionOverrides.mutex.Unlock()
+
+	override, ok := a.voteExtensionOverrides.overrides[address]
+	if !ok {
+		return nil, false
+	}
+	override.remaining--
+	if override.remaining <= 0 {
+		delete(a.voteExtensionOverrides.overrides, address)
+	}
+	return override.extension, true
+}
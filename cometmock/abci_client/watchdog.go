@@ -0,0 +1,341 @@
+package abci_client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	abciclient "github.com/cometbft/cometbft/abci/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+)
+
+// EventAppUnresponsive is published on the event bus when the watchdog
+// marks an app as unresponsive.
+const EventAppUnresponsive = "AppUnresponsive"
+
+// EventDataAppUnresponsive is the data of EventAppUnresponsive events.
+type EventDataAppUnresponsive struct {
+	UnresponsiveApp
+}
+
+func init() {
+	cmtjson.RegisterType(EventDataAppUnresponsive{}, "cometmock/event/AppUnresponsive")
+}
+
+// UnresponsiveApp describes an app that did not respond to a call in time.
+type UnresponsiveApp struct {
+	ValidatorAddress string `json:"validator_address"`
+	NetworkAddress   string `json:"network_address"`
+	// the ABCI method the app did not respond to
+	Method string `json:"method"`
+	// when the method was called
+	Since time.Time `json:"since"`
+}
+
+// WatchedClient wraps an ABCI client and keeps track of the calls that are in flight,
+// so that the watchdog can detect apps that stopped responding.
+// It also records the latencies of the calls, see Latencies.
+type WatchedClient struct {
+	abciclient.Client
+
+	mutex    sync.Mutex
+	nextID   uint64
+	inFlight map[uint64]inFlightCall
+	// the latencies of the calls by method
+	latencies map[string]*latencyHistogram
+	// if not nil, called with the request and response of each call, see TraceBlocks
+	tracer func(method string, request, response interface{}, err error)
+	// added to each call, see ConnectWithLatencies
+	latency time.Duration
+}
+
+type inFlightCall struct {
+	method string
+	start  time.Time
+}
+
+var _ abciclient.Client = (*WatchedClient)(nil)
+
+func NewWatchedClient(client abciclient.Client) *WatchedClient {
+	return &WatchedClient{
+		Client:    client,
+		inFlight:  make(map[uint64]inFlightCall),
+		latencies: make(map[string]*latencyHistogram),
+	}
+}
+
+// track records a call as in flight and returns a function that marks it as done
+// and records its latency. If the client has a latency, track waits for it before returning,
+// so that it counts like the time the app takes to respond.
+func (c *WatchedClient) track(method string) func() {
+	start := time.Now()
+	c.mutex.Lock()
+	id := c.nextID
+	c.nextID++
+	c.inFlight[id] = inFlightCall{method: method, start: start}
+	latency := c.latency
+	c.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	return func() {
+		latency := time.Since(start)
+		c.mutex.Lock()
+		delete(c.inFlight, id)
+		histogram, ok := c.latencies[method]
+		if !ok {
+			histogram = newLatencyHistogram()
+			c.latencies[method] = histogram
+		}
+		histogram.observe(latency)
+		c.mutex.Unlock()
+	}
+}
+
+// setTracer sets the function that is called with the request and response of each call,
+// or stops tracing the calls if it is nil.
+func (c *WatchedClient) setTracer(tracer func(method string, request, response interface{}, err error)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.tracer = tracer
+}
+
+// SetLatency sets the latency that is added to each call.
+func (c *WatchedClient) SetLatency(latency time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.latency = latency
+}
+
+// trace passes the request and response of a call to the tracer, if any.
+func (c *WatchedClient) trace(method string, request, response interface{}, err error) {
+	c.mutex.Lock()
+	tracer := c.tracer
+	c.mutex.Unlock()
+
+	if tracer != nil {
+		tracer(method, request, response, err)
+	}
+}
+
+// Latencies returns the histograms of the latencies of the calls, by method.
+// Methods that were not called are left out.
+func (c *WatchedClient) Latencies() map[string]LatencyHistogram {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	latencies := make(map[string]LatencyHistogram, len(c.latencies))
+	for method, histogram := range c.latencies {
+		latencies[method] = histogram.snapshot()
+	}
+	return latencies
+}
+
+// OldestInFlightCall returns the method and start time of the call that has been in flight the longest.
+// ok is false if no call is in flight.
+func (c *WatchedClient) OldestInFlightCall() (method string, start time.Time, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, call := range c.inFlight {
+		if !ok || call.start.Before(start) {
+			method, start, ok = call.method, call.start, true
+		}
+	}
+	return method, start, ok
+}
+
+// InFlightCalls returns the number of calls that are in flight.
+func (c *WatchedClient) InFlightCalls() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.inFlight)
+}
+
+func (c *WatchedClient) Echo(ctx context.Context, msg string) (*abcitypes.ResponseEcho, error) {
+	defer c.track("Echo")()
+	return c.Client.Echo(ctx, msg)
+}
+
+func (c *WatchedClient) Info(ctx context.Context, req *abcitypes.RequestInfo) (*abcitypes.ResponseInfo, error) {
+	defer c.track("Info")()
+	res, err := c.Client.Info(ctx, req)
+	c.trace("Info", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) Query(ctx context.Context, req *abcitypes.RequestQuery) (*abcitypes.ResponseQuery, error) {
+	defer c.track("Query")()
+	res, err := c.Client.Query(ctx, req)
+	c.trace("Query", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) CheckTx(ctx context.Context, req *abcitypes.RequestCheckTx) (*abcitypes.ResponseCheckTx, error) {
+	defer c.track("CheckTx")()
+	res, err := c.Client.CheckTx(ctx, req)
+	c.trace("CheckTx", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) InitChain(ctx context.Context, req *abcitypes.RequestInitChain) (*abcitypes.ResponseInitChain, error) {
+	defer c.track("InitChain")()
+	res, err := c.Client.InitChain(ctx, req)
+	c.trace("InitChain", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) PrepareProposal(ctx context.Context, req *abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error) {
+	defer c.track("PrepareProposal")()
+	res, err := c.Client.PrepareProposal(ctx, req)
+	c.trace("PrepareProposal", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) ProcessProposal(ctx context.Context, req *abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error) {
+	defer c.track("ProcessProposal")()
+	res, err := c.Client.ProcessProposal(ctx, req)
+	c.trace("ProcessProposal", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) FinalizeBlock(ctx context.Context, req *abcitypes.RequestFinalizeBlock) (*abcitypes.ResponseFinalizeBlock, error) {
+	defer c.track("FinalizeBlock")()
+	res, err := c.Client.FinalizeBlock(ctx, req)
+	c.trace("FinalizeBlock", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) ExtendVote(ctx context.Context, req *abcitypes.RequestExtendVote) (*abcitypes.ResponseExtendVote, error) {
+	defer c.track("ExtendVote")()
+	res, err := c.Client.ExtendVote(ctx, req)
+	c.trace("ExtendVote", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) VerifyVoteExtension(ctx context.Context, req *abcitypes.RequestVerifyVoteExtension) (*abcitypes.ResponseVerifyVoteExtension, error) {
+	defer c.track("VerifyVoteExtension")()
+	res, err := c.Client.VerifyVoteExtension(ctx, req)
+	c.trace("VerifyVoteExtension", req, res, err)
+	return res, err
+}
+
+func (c *WatchedClient) Commit(ctx context.Context, req *abcitypes.RequestCommit) (*abcitypes.ResponseCommit, error) {
+	defer c.track("Commit")()
+	res, err := c.Client.Commit(ctx, req)
+	c.trace("Commit", req, res, err)
+	return res, err
+}
+
+// StartWatchdog starts a goroutine that marks apps as unresponsive
+// when a call to them has been in flight for longer than the given threshold.
+// Unresponsive apps are reported by GetUnresponsiveApps, and
+// an EventAppUnresponsive is published when an app is marked as unresponsive.
+// Apps are no longer marked once the call returns.
+func (a *AbciClient) StartWatchdog(threshold time.Duration) {
+	interval := threshold / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.checkResponsiveness(threshold)
+		}
+	}()
+}
+
+func (a *AbciClient) checkResponsiveness(threshold time.Duration) {
+	// the clients are replaced when resuming after a halt
+	a.clientsMutex.RLock()
+	clients := make([]AbciCounterpartyClient, 0, len(a.Clients))
+	for _, client := range a.Clients {
+		clients = append(clients, client)
+	}
+	a.clientsMutex.RUnlock()
+
+	unresponsive := make(map[string]UnresponsiveApp)
+	for _, client := range clients {
+		watchedClient, ok := client.Client.(*WatchedClient)
+		if !ok {
+			continue
+		}
+
+		method, start, ok := watchedClient.OldestInFlightCall()
+		if !ok || time.Since(start) < threshold {
+			continue
+		}
+		unresponsive[client.ValidatorAddress] = UnresponsiveApp{
+			ValidatorAddress: client.ValidatorAddress,
+			NetworkAddress:   client.NetworkAddress,
+			Method:           method,
+			Since:            start,
+		}
+	}
+
+	a.unresponsiveMutex.Lock()
+	previous := a.unresponsiveApps
+	a.unresponsiveApps = unresponsive
+	a.unresponsiveMutex.Unlock()
+
+	for validatorAddress, app := range unresponsive {
+		if _, ok := previous[validatorAddress]; ok {
+			continue
+		}
+		a.Logger.Error("App is unresponsive", "validator", a.validatorLabel(app.ValidatorAddress), "address", app.NetworkAddress, "method", app.Method, "since", app.Since)
+		if err := a.EventBus.Publish(EventAppUnresponsive, EventDataAppUnresponsive{app}); err != nil {
+			a.Logger.Error("Error publishing event", "event", EventAppUnresponsive, "err", err)
+		}
+	}
+	for validatorAddress, app := range previous {
+		if _, ok := unresponsive[validatorAddress]; !ok {
+			a.Logger.Info("App is responsive again", "validator", a.validatorLabel(app.ValidatorAddress), "address", app.NetworkAddress)
+		}
+	}
+}
+
+// GetUnresponsiveApps returns the apps that the watchdog currently considers unresponsive,
+// keyed by validator address. See StartWatchdog.
+func (a *AbciClient) GetUnresponsiveApps() map[string]UnresponsiveApp {
+	a.unresponsiveMutex.RLock()
+	defer a.unresponsiveMutex.RUnlock()
+
+	apps := make(map[string]UnresponsiveApp, len(a.unresponsiveApps))
+	for k, v := range a.unresponsiveApps {
+		apps[k] = v
+	}
+	return apps
+}
+
+// CheckHealth returns an error if block production is halted, if any app is unresponsive,
+// or if any app was not alive at its latest liveness probe, see StartLivenessProbes.
+func (a *AbciClient) CheckHealth() error {
+	if halt := a.Halted(); halt != nil {
+		return fmt.Errorf("%w at height %d (%s): %v", ErrHalted, halt.Height, halt.Reason, halt.Error)
+	}
+
+	descriptions := make([]string, 0)
+	for _, app := range a.GetUnresponsiveApps() {
+		descriptions = append(descriptions, fmt.Sprintf("%v did not respond to %v for %v", app.NetworkAddress, app.Method, time.Since(app.Since).Round(time.Millisecond)))
+	}
+	for _, app := range a.GetAppLiveness() {
+		if !app.Alive {
+			descriptions = append(descriptions, fmt.Sprintf("%v is not alive since %v: %v", app.NetworkAddress, app.Since.Format(time.RFC3339), app.Error))
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	sort.Strings(descriptions)
+	return fmt.Errorf("apps are unresponsive: %v", descriptions)
+}
@@ -0,0 +1,223 @@
+// Package bootstrap allows to start CometMock from the exported genesis of a live chain.
+// The exported genesis contains the validator set of the live chain, whose keys are not available.
+// To be able to produce blocks, the keys of some of the validators are substituted by keys
+// that CometMock has access to, both in the validator set and in the app state.
+// Afterwards, the voting power of the substituted validators is scaled
+// so that they hold more than 2/3 of the voting power,
+// which means that the remaining validators, which never sign, cannot halt the chain.
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// SubstituteTopValidators is the value that selects the validators with the highest voting power
+// to be substituted, see SelectTopValidators.
+const SubstituteTopValidators = "top"
+
+// SelectTopValidators returns the addresses of the n validators with the highest voting power
+// in the genesis. Ties are broken by address, like in the validator set of CometBFT.
+func SelectTopValidators(genesisDoc *types.GenesisDoc, n int) ([]string, error) {
+	if n > len(genesisDoc.Validators) {
+		return nil, fmt.Errorf("cannot substitute %d validators, the genesis only has %d validators", n, len(genesisDoc.Validators))
+	}
+
+	validators := make([]types.GenesisValidator, len(genesisDoc.Validators))
+	copy(validators, genesisDoc.Validators)
+	sort.SliceStable(validators, func(i, j int) bool {
+		if validators[i].Power != validators[j].Power {
+			return validators[i].Power > validators[j].Power
+		}
+		return bytes.Compare(validators[i].PubKey.Address(), validators[j].PubKey.Address()) < 0
+	})
+
+	addresses := make([]string, n)
+	for i := 0; i < n; i++ {
+		addresses[i] = validators[i].PubKey.Address().String()
+	}
+	return addresses, nil
+}
+
+// SubstituteValidators replaces the keys of the genesis validators with the given addresses
+// by the given public keys, i.e. the validator with addresses[i] gets the key pubKeys[i].
+// The keys are replaced in the validator set of the genesis and in the app state,
+// where the public keys are replaced in their base64 encoding, and the consensus addresses
+// are replaced in their bech32 (e.g. cosmosvalcons1...) and hex encodings.
+// The voting powers are left unchanged, since the app checks them against its own state,
+// see ScaleVotingPower for how to give the substituted validators a majority.
+func SubstituteValidators(genesisDoc *types.GenesisDoc, addresses []string, pubKeys []crypto.PubKey) error {
+	if len(addresses) != len(pubKeys) {
+		return fmt.Errorf("got %d validators to substitute, but %d keys", len(addresses), len(pubKeys))
+	}
+
+	replacer := newStringReplacer()
+	for i, address := range addresses {
+		index := -1
+		for j, validator := range genesisDoc.Validators {
+			if validator.PubKey.Address().String() == strings.ToUpper(address) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("validator with address %s not found in the genesis", address)
+		}
+
+		oldValidator := genesisDoc.Validators[index]
+		if oldValidator.PubKey.Type() != pubKeys[i].Type() {
+			return fmt.Errorf("cannot substitute validator %s: key type %s does not match key type %s of the substitute",
+				address, oldValidator.PubKey.Type(), pubKeys[i].Type())
+		}
+
+		genesisDoc.Validators[index] = types.GenesisValidator{
+			Address: pubKeys[i].Address(),
+			PubKey:  pubKeys[i],
+			Power:   oldValidator.Power,
+			Name:    oldValidator.Name,
+		}
+
+		replacer.addKey(oldValidator.PubKey, pubKeys[i])
+	}
+
+	if len(genesisDoc.AppState) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(genesisDoc.AppState))
+	// keep numbers as they are, app states contain integers that do not fit into a float64
+	decoder.UseNumber()
+
+	var appState interface{}
+	if err := decoder.Decode(&appState); err != nil {
+		return fmt.Errorf("error decoding app state: %w", err)
+	}
+
+	appStateBytes, err := json.Marshal(replacer.replaceAll(appState))
+	if err != nil {
+		return fmt.Errorf("error encoding app state: %w", err)
+	}
+	genesisDoc.AppState = appStateBytes
+
+	return nil
+}
+
+// ScaleVotingPower returns a copy of the validator set in which the voting power of the validators
+// with the given addresses is multiplied by the smallest integer factor that
+// makes them hold more than 2/3 of the total voting power.
+// If they already hold more than 2/3 of the voting power, the validator set is returned unchanged.
+func ScaleVotingPower(validatorSet *types.ValidatorSet, addresses []string) (*types.ValidatorSet, error) {
+	substituted := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		substituted[strings.ToUpper(address)] = true
+	}
+
+	var substitutedPower, otherPower int64
+	for _, validator := range validatorSet.Validators {
+		if substituted[validator.Address.String()] {
+			substitutedPower += validator.VotingPower
+		} else {
+			otherPower += validator.VotingPower
+		}
+	}
+
+	if substitutedPower == 0 {
+		return nil, fmt.Errorf("none of the validators %v is in the validator set", addresses)
+	}
+
+	if substitutedPower > 2*otherPower {
+		return validatorSet.Copy(), nil
+	}
+
+	factor := 2*otherPower/substitutedPower + 1
+	if substitutedPower*factor+otherPower > types.MaxTotalVotingPower {
+		return nil, fmt.Errorf("cannot scale the voting power of validators %v by %d, the total voting power would exceed %d",
+			addresses, factor, types.MaxTotalVotingPower)
+	}
+
+	validators := make([]*types.Validator, len(validatorSet.Validators))
+	for i, validator := range validatorSet.Validators {
+		power := validator.VotingPower
+		if substituted[validator.Address.String()] {
+			power *= factor
+		}
+		validators[i] = types.NewValidator(validator.PubKey, power)
+	}
+
+	return types.NewValidatorSet(validators), nil
+}
+
+// stringReplacer replaces the encodings of substituted keys and addresses
+// in arbitrary decoded json values.
+type stringReplacer struct {
+	// replacements maps base64 encoded public keys and hex encoded addresses to their substitutes
+	replacements map[string]string
+	// addresses maps the raw bytes of substituted addresses to their substitutes,
+	// used for bech32 encoded addresses, which can have arbitrary prefixes
+	addresses map[string][]byte
+}
+
+func newStringReplacer() *stringReplacer {
+	return &stringReplacer{
+		replacements: make(map[string]string),
+		addresses:    make(map[string][]byte),
+	}
+}
+
+func (r *stringReplacer) addKey(oldKey, newKey crypto.PubKey) {
+	r.replacements[base64.StdEncoding.EncodeToString(oldKey.Bytes())] = base64.StdEncoding.EncodeToString(newKey.Bytes())
+	r.replacements[oldKey.Address().String()] = newKey.Address().String()
+	r.addresses[string(oldKey.Address())] = newKey.Address()
+}
+
+func (r *stringReplacer) replaceAll(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = r.replaceAll(elem)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = r.replaceAll(elem)
+		}
+		return v
+	case string:
+		return r.replaceString(v)
+	default:
+		return v
+	}
+}
+
+func (r *stringReplacer) replaceString(s string) string {
+	if replacement, ok := r.replacements[s]; ok {
+		return replacement
+	}
+
+	// only consensus addresses are replaced, decoding every string would be slow for large app states
+	if !strings.Contains(s, "valcons1") {
+		return s
+	}
+
+	hrp, address, err := bech32.DecodeAndConvert(s)
+	if err != nil {
+		return s
+	}
+	newAddress, ok := r.addresses[string(address)]
+	if !ok {
+		return s
+	}
+	replacement, err := bech32.ConvertAndEncode(hrp, newAddress)
+	if err != nil {
+		return s
+	}
+	return replacement
+}
@@ -0,0 +1,197 @@
+package bootstrap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/crypto/secp256k1"
+	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey returns the deterministic public key of the i-th key of a test.
+func testKey(i int) crypto.PubKey {
+	return ed25519.GenPrivKeyFromSecret([]byte{byte(i)}).PubKey()
+}
+
+func TestSubstituteValidators(t *testing.T) {
+	live := []crypto.PubKey{testKey(0), testKey(1), testKey(2)}
+	substitutes := []crypto.PubKey{testKey(10), testKey(11)}
+
+	valcons := func(key crypto.PubKey) string {
+		address, err := bech32.ConvertAndEncode("cosmosvalcons", key.Address())
+		require.NoError(t, err)
+		return address
+	}
+	appStateOf := func(keys []crypto.PubKey) string {
+		validators := make([]string, len(keys))
+		for i, key := range keys {
+			validators[i] = fmt.Sprintf(`{"pub_key":%q,"address":%q,"cons_address":%q}`,
+				base64.StdEncoding.EncodeToString(key.Bytes()), key.Address().String(), valcons(key))
+		}
+		return fmt.Sprintf(`{"validators":[%v,%v,%v],"supply":123456789012345678901234567890,"memo":"valcons1notanaddress"}`,
+			validators[0], validators[1], validators[2])
+	}
+
+	genesisDoc := &types.GenesisDoc{
+		Validators: []types.GenesisValidator{
+			{Address: live[0].Address(), PubKey: live[0], Power: 30, Name: "a"},
+			{Address: live[1].Address(), PubKey: live[1], Power: 20, Name: "b"},
+			{Address: live[2].Address(), PubKey: live[2], Power: 10, Name: "c"},
+		},
+		AppState: json.RawMessage(appStateOf(live)),
+	}
+
+	// the addresses are case-insensitive
+	addresses := []string{live[0].Address().String(), fmt.Sprintf("%x", []byte(live[2].Address()))}
+	require.NoError(t, SubstituteValidators(genesisDoc, addresses, substitutes))
+
+	require.Equal(t, []types.GenesisValidator{
+		{Address: substitutes[0].Address(), PubKey: substitutes[0], Power: 30, Name: "a"},
+		{Address: live[1].Address(), PubKey: live[1], Power: 20, Name: "b"},
+		{Address: substitutes[1].Address(), PubKey: substitutes[1], Power: 10, Name: "c"},
+	}, genesisDoc.Validators)
+	// the keys and addresses are replaced in all encodings, and other values, like large numbers, are kept
+	require.JSONEq(t, appStateOf([]crypto.PubKey{substitutes[0], live[1], substitutes[1]}), string(genesisDoc.AppState))
+	require.Contains(t, string(genesisDoc.AppState), "123456789012345678901234567890")
+}
+
+func TestSubstituteValidatorsErrors(t *testing.T) {
+	live := testKey(0)
+	newGenesisDoc := func() *types.GenesisDoc {
+		return &types.GenesisDoc{
+			Validators: []types.GenesisValidator{{Address: live.Address(), PubKey: live, Power: 10}},
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		addresses []string
+		pubKeys   []crypto.PubKey
+	}{
+		{
+			name:      "more addresses than keys",
+			addresses: []string{live.Address().String()},
+		},
+		{
+			name:      "unknown validator",
+			addresses: []string{testKey(1).Address().String()},
+			pubKeys:   []crypto.PubKey{testKey(2)},
+		},
+		{
+			name:      "different key type",
+			addresses: []string{live.Address().String()},
+			pubKeys:   []crypto.PubKey{secp256k1.GenPrivKeySecp256k1([]byte{1}).PubKey()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Error(t, SubstituteValidators(newGenesisDoc(), tc.addresses, tc.pubKeys))
+		})
+	}
+}
+
+func TestScaleVotingPower(t *testing.T) {
+	testCases := []struct {
+		name   string
+		powers []int64
+		// the indexes of the substituted validators
+		substituted    []int
+		expectedPowers []int64
+		expectedErr    bool
+	}{
+		{
+			name:           "one of three equal validators",
+			powers:         []int64{10, 10, 10},
+			substituted:    []int{0},
+			expectedPowers: []int64{50, 10, 10},
+		},
+		{
+			name:           "two small validators",
+			powers:         []int64{100, 5, 5},
+			substituted:    []int{1, 2},
+			expectedPowers: []int64{100, 105, 105},
+		},
+		{
+			name:           "already more than 2/3",
+			powers:         []int64{70, 20, 10},
+			substituted:    []int{0},
+			expectedPowers: []int64{70, 20, 10},
+		},
+		{
+			name:           "exactly 2/3 is not enough",
+			powers:         []int64{20, 10},
+			substituted:    []int{0},
+			expectedPowers: []int64{40, 10},
+		},
+		{
+			name:        "no substituted validator in the set",
+			powers:      []int64{10, 10},
+			substituted: []int{5},
+			expectedErr: true,
+		},
+		{
+			name:        "total voting power too large",
+			powers:      []int64{1, types.MaxTotalVotingPower / 2},
+			substituted: []int{0},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validators := make([]*types.Validator, len(tc.powers))
+			for i, power := range tc.powers {
+				validators[i] = types.NewValidator(testKey(i), power)
+			}
+			validatorSet := types.NewValidatorSet(validators)
+
+			addresses := make([]string, len(tc.substituted))
+			for i, index := range tc.substituted {
+				addresses[i] = testKey(index).Address().String()
+			}
+
+			scaled, err := ScaleVotingPower(validatorSet, addresses)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for i, power := range tc.expectedPowers {
+				_, validator := scaled.GetByAddress(testKey(i).Address())
+				require.NotNil(t, validator)
+				require.Equal(t, power, validator.VotingPower, "voting power of validator %d", i)
+			}
+			// the validator set passed in is not changed
+			_, validator := validatorSet.GetByAddress(testKey(0).Address())
+			require.Equal(t, tc.powers[0], validator.VotingPower)
+		})
+	}
+}
+
+func TestSelectTopValidators(t *testing.T) {
+	genesisDoc := &types.GenesisDoc{}
+	for i, power := range []int64{5, 20, 10, 20} {
+		genesisDoc.Validators = append(genesisDoc.Validators, types.GenesisValidator{PubKey: testKey(i), Power: power})
+	}
+
+	top, err := SelectTopValidators(genesisDoc, 3)
+	require.NoError(t, err)
+	require.Len(t, top, 3)
+	// ties are broken by address
+	tied := []string{testKey(1).Address().String(), testKey(3).Address().String()}
+	if tied[0] > tied[1] {
+		tied[0], tied[1] = tied[1], tied[0]
+	}
+	require.Equal(t, []string{tied[0], tied[1], testKey(2).Address().String()}, top)
+
+	_, err = SelectTopValidators(genesisDoc, 5)
+	require.Error(t, err)
+}
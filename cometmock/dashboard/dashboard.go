@@ -0,0 +1,302 @@
+// Package dashboard shows the state of a running CometMock in the terminal, refreshed live:
+// the height, the block rate, the signing status and the apps of the validators, the mempool size
+// and the last divergence, with hotkeys for common control actions, see the dashboard command.
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+	"golang.org/x/term"
+)
+
+// DefaultRefreshInterval is the default interval at which the dashboard is refreshed.
+const DefaultRefreshInterval = time.Second
+
+// rateWindow is the time over which the block rate is averaged.
+const rateWindow = 10 * time.Second
+
+// the escape sequences that control the terminal
+const (
+	enterAlternateScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAlternateScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen          = "\x1b[H\x1b[2J"
+)
+
+// the keys of the control actions
+const (
+	keyBlock      = 'b'
+	keyTenBlocks  = 'B'
+	keyMinute     = 't'
+	keyHour       = 'T'
+	keyResume     = 'r'
+	keyQuit       = 'q'
+	keyInterrupt  = 3 // Ctrl-C, which does not send a signal in raw mode
+	keyFirstIndex = '1'
+	keyLastIndex  = '9'
+)
+
+// Help describes the hotkeys, as shown at the bottom of the dashboard.
+const Help = "[b] 1 block  [B] 10 blocks  [t] +1 min  [T] +1 hour  [1-9] toggle signing of validator  [r] resume  [q] quit"
+
+// sample is the height of the chain at a time, from which the block rate is computed.
+type sample struct {
+	time   time.Time
+	height int64
+}
+
+// Dashboard shows the state of the CometMock at an RPC address.
+type Dashboard struct {
+	rpcAddress string
+	client     *jsonrpcclient.Client
+	// the heights of the last rateWindow, oldest first
+	samples []sample
+	// the outcome of the last control action, shown below the hotkeys
+	message string
+	// the validators in the order in which they are shown, for the hotkeys that refer to them by index
+	validators []abci_client.ClientStatus
+}
+
+// New returns a dashboard for the CometMock whose RPC listens on the given address, e.g. tcp://127.0.0.1:22331.
+func New(rpcAddress string) (*Dashboard, error) {
+	client, err := jsonrpcclient.New(rpcAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &Dashboard{rpcAddress: rpcAddress, client: client}, nil
+}
+
+// Run shows the dashboard on the terminal of in and out until q or Ctrl-C is pressed, or the context is done.
+// The terminal is switched to raw mode and to the alternate screen while the dashboard is shown.
+func (d *Dashboard) Run(ctx context.Context, in *os.File, out io.Writer, refreshInterval time.Duration) error {
+	if !term.IsTerminal(int(in.Fd())) {
+		return fmt.Errorf("the dashboard needs to run in a terminal")
+	}
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+	fmt.Fprint(out, enterAlternateScreen)
+	defer fmt.Fprint(out, leaveAlternateScreen)
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := in.Read(buf); err != nil {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		d.refresh(ctx, out)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok || key == keyQuit || key == keyInterrupt {
+				return nil
+			}
+			d.message = d.handleKey(ctx, key)
+		}
+	}
+}
+
+// refresh fetches the state of CometMock and draws the dashboard.
+func (d *Dashboard) refresh(ctx context.Context, out io.Writer) {
+	status := new(rpc_server.ResultCometMockStatus)
+	_, err := d.client.Call(ctx, "cometmock_status", map[string]interface{}{}, status)
+	names := new(rpc_server.ResultValidatorNames)
+	if err == nil {
+		_, err = d.client.Call(ctx, "validator_names", map[string]interface{}{}, names)
+	}
+
+	var lines []string
+	if err != nil {
+		lines = []string{
+			fmt.Sprintf("CometMock at %v", d.rpcAddress),
+			"",
+			fmt.Sprintf("Cannot reach CometMock: %v", err),
+		}
+	} else {
+		d.addSample(time.Now(), status.LatestBlockHeight)
+		d.validators = status.Validators
+		lines = d.render(status, names.Names)
+	}
+	lines = append(lines, "", Help)
+	if d.message != "" {
+		lines = append(lines, d.message)
+	}
+	// the terminal is in raw mode, so lines need a carriage return
+	fmt.Fprint(out, clearScreen+strings.Join(lines, "\r\n"))
+}
+
+// addSample records the height at the given time, and drops the samples older than rateWindow.
+func (d *Dashboard) addSample(now time.Time, height int64) {
+	// the chain was reset or rewound, so the old samples do not count
+	if len(d.samples) > 0 && height < d.samples[len(d.samples)-1].height {
+		d.samples = nil
+	}
+	d.samples = append(d.samples, sample{time: now, height: height})
+	for len(d.samples) > 2 && now.Sub(d.samples[0].time) > rateWindow {
+		d.samples = d.samples[1:]
+	}
+}
+
+// blockRate returns the number of blocks per second over the samples.
+func (d *Dashboard) blockRate() float64 {
+	if len(d.samples) < 2 {
+		return 0
+	}
+	first, last := d.samples[0], d.samples[len(d.samples)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last.height-first.height) / elapsed
+}
+
+// render returns the lines of the dashboard for the status, where names are the validator addresses by name.
+func (d *Dashboard) render(status *rpc_server.ResultCometMockStatus, names map[string]string) []string {
+	lines := []string{
+		fmt.Sprintf("CometMock at %v                %v", d.rpcAddress, time.Now().Format(time.TimeOnly)),
+		"",
+		fmt.Sprintf("Height        %d   (block time %v, time offset %v)",
+			status.LatestBlockHeight, status.LatestBlockTime.Format(time.RFC3339), status.TimeOffset),
+		fmt.Sprintf("Block rate    %.2f blocks/s", d.blockRate()),
+	}
+
+	production := status.BlockProduction
+	productionLine := fmt.Sprintf("Production    %v, %v block times", production.Mode, production.BlockTimeMode)
+	if production.Mode == "interval" {
+		productionLine += fmt.Sprintf(", every %v", production.Interval)
+	}
+	if production.Jitter != "" {
+		productionLine += fmt.Sprintf(", jitter %v", production.Jitter)
+	}
+	lines = append(lines, productionLine, fmt.Sprintf("Mempool       %d txs", status.MempoolSize))
+
+	if status.Halt != nil {
+		lines = append(lines, fmt.Sprintf("HALTED        at height %d (%v): %v", status.Halt.Height, status.Halt.Reason, status.Halt.Error))
+	}
+	if divergence := status.LastDivergence; divergence != nil {
+		lines = append(lines, fmt.Sprintf("Divergence    %v at height %d, %v ago: %v",
+			divergence.Method, divergence.Height, time.Since(divergence.Time).Round(time.Second), divergence.Error))
+	} else {
+		lines = append(lines, "Divergence    none")
+	}
+
+	namesByAddress := make(map[string]string, len(names))
+	for name, address := range names {
+		namesByAddress[address] = name
+	}
+	// validators first, then executors and observers, each by address like in the status
+	sort.SliceStable(d.validators, func(i, j int) bool {
+		return role(d.validators[i]) < role(d.validators[j])
+	})
+
+	lines = append(lines, "", fmt.Sprintf("   %-44s %-24s %-9s %v", "VALIDATOR", "APP", "SIGNING", "APP STATUS"))
+	for i, validator := range d.validators {
+		label := validator.ValidatorAddress
+		if name, ok := namesByAddress[validator.ValidatorAddress]; ok {
+			label = fmt.Sprintf("%v (%.8s)", name, validator.ValidatorAddress)
+		}
+		index := " "
+		if i < keyLastIndex-keyFirstIndex+1 {
+			index = string(rune(keyFirstIndex + i))
+		}
+		signing := "no"
+		if validator.Signing {
+			signing = "yes"
+		}
+		switch {
+		case validator.Observer:
+			signing = "observer"
+		case validator.ExecutorOf != "":
+			signing = "executor"
+		}
+		lines = append(lines, fmt.Sprintf("%v  %-44s %-24s %-9s %v", index, label, validator.NetworkAddress, signing, appStatus(validator)))
+	}
+	return lines
+}
+
+// role orders validators before executors, and executors before observers.
+func role(validator abci_client.ClientStatus) int {
+	switch {
+	case validator.Observer:
+		return 2
+	case validator.ExecutorOf != "":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// appStatus describes the connection to the app of the validator.
+func appStatus(validator abci_client.ClientStatus) string {
+	switch {
+	case validator.Missing:
+		return "missing"
+	case !validator.Connected:
+		return "disconnected: " + validator.Error
+	case validator.Unresponsive != nil:
+		return "unresponsive"
+	default:
+		return "connected"
+	}
+}
+
+// handleKey runs the control action of the key, and returns its outcome.
+func (d *Dashboard) handleKey(ctx context.Context, key byte) string {
+	switch {
+	case key == keyBlock:
+		return d.call(ctx, "Produced 1 block", "advance_blocks", map[string]interface{}{"num_blocks": 1})
+	case key == keyTenBlocks:
+		return d.call(ctx, "Produced 10 blocks", "advance_blocks", map[string]interface{}{"num_blocks": 10})
+	case key == keyMinute:
+		return d.call(ctx, "Advanced the time by 1 minute", "advance_time", map[string]interface{}{"duration_in_seconds": 60})
+	case key == keyHour:
+		return d.call(ctx, "Advanced the time by 1 hour", "advance_time", map[string]interface{}{"duration_in_seconds": 3600})
+	case key == keyResume:
+		return d.call(ctx, "Resumed block production", "resume", map[string]interface{}{})
+	case key >= keyFirstIndex && key <= keyLastIndex:
+		index := int(key - keyFirstIndex)
+		if index >= len(d.validators) || d.validators[index].Observer || d.validators[index].ExecutorOf != "" {
+			return fmt.Sprintf("There is no validator %c", key)
+		}
+		validator := d.validators[index]
+		status := "down"
+		if !validator.Signing {
+			status = "up"
+		}
+		return d.call(ctx, fmt.Sprintf("Set the signing status of %v to %v", validator.ValidatorAddress, status),
+			"set_signing_status", map[string]interface{}{"private_key_address": validator.ValidatorAddress, "status": status})
+	default:
+		return ""
+	}
+}
+
+// call calls the method, and returns the message if it succeeds, and the error otherwise.
+func (d *Dashboard) call(ctx context.Context, message string, method string, params map[string]interface{}) string {
+	var result json.RawMessage
+	if _, err := d.client.Call(ctx, method, params, &result); err != nil {
+		return fmt.Sprintf("Error calling %v: %v", method, err)
+	}
+	return message
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/informalsystems/CometMock/cometmock/dashboard"
+	"github.com/urfave/cli/v2"
+)
+
+// dashboardCommand shows the state of a running CometMock in the terminal, with hotkeys for control actions.
+func dashboardCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dashboard",
+		Usage:     "Show the state of a running CometMock in the terminal, with hotkeys for common control actions",
+		ArgsUsage: "[--refresh-interval=<value>] <cometmock-listen-address>",
+		Description: `Shows the height, the block rate, how blocks are produced, the mempool size, halts, the last divergence,
+and the signing and connection status of each validator of the CometMock whose RPC listens on the given address,
+refreshed live, so that it is easy to see whether CometMock is alive without tailing its logs.
+The hotkeys produce blocks, advance the time, toggle the signing status of the validators and resume block production:
+` + dashboard.Help,
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:  "refresh-interval",
+				Usage: "The interval in milliseconds at which the dashboard is refreshed.",
+				Value: dashboard.DefaultRefreshInterval.Milliseconds(),
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock dashboard "+c.Command.ArgsUsage, 1)
+			}
+			refreshInterval := time.Duration(c.Int64("refresh-interval")) * time.Millisecond
+			if refreshInterval <= 0 {
+				return cli.Exit("refresh-interval must be greater than 0", 1)
+			}
+
+			d, err := dashboard.New(c.Args().Get(0))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error connecting to CometMock: %v", err), 1)
+			}
+			if err := d.Run(context.Background(), os.Stdin, os.Stdout, refreshInterval); err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,194 @@
+// Package diff drives two sets of apps, e.g. two builds of the same app, with identical blocks,
+// transactions and times, and reports where their responses diverge.
+// The blocks are produced with the first set of apps, and then replayed through the second set,
+// so both sets see exactly the same blocks. This checks whether a change to an app is consensus-compatible.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/loadgen"
+)
+
+// Divergence describes a field of a response in which the two sets of apps differ.
+type Divergence struct {
+	// the height of the block for which the responses differ, or 0 for InitChain
+	Height int64 `json:"height"`
+	// the field of the response that differs, e.g. finalize_block.tx_results[0].code
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("height %d: %v differs: a has %v, b has %v", d.Height, d.Field, d.A, d.B)
+}
+
+// Options configure how the apps are driven.
+type Options struct {
+	NumBlocks int64
+	// the time between consecutive blocks, starting from the genesis time
+	BlockTime time.Duration
+	// the transactions to include in the blocks, or nil for empty blocks
+	Generator   loadgen.Generator
+	TxsPerBlock int64
+}
+
+// Run produces blocks with the apps of a and replays them through the apps of b,
+// both of which must have been initialized with InitChain from the same genesis.
+// It stops after the first height at which the responses differ, and returns all differing fields
+// at that height, or no divergences if the apps agree on all blocks.
+// genesisTime is the time from which the block times start.
+func Run(a, b *abci_client.AbciClient, genesisTime time.Time, opts Options) ([]Divergence, error) {
+	if divergences := compareInitChain(a, b); len(divergences) > 0 {
+		return divergences, nil
+	}
+
+	var txIndex int64
+	for i := int64(1); i <= opts.NumBlocks; i++ {
+		txs := make(types.Txs, 0, opts.TxsPerBlock)
+		divergences := make([]Divergence, 0)
+		height := a.CurState.LastBlockHeight + 1
+
+		if opts.Generator != nil {
+			for j := int64(0); j < opts.TxsPerBlock; j++ {
+				tx, err := opts.Generator.Next(txIndex)
+				if err != nil {
+					return nil, fmt.Errorf("error generating transaction %d: %w", txIndex, err)
+				}
+				txIndex++
+
+				checkTxDivergences, err := compareCheckTx(a, b, height, len(txs), tx)
+				if err != nil {
+					return nil, err
+				}
+				divergences = append(divergences, checkTxDivergences...)
+				txs = append(txs, tx)
+			}
+		}
+
+		err := a.RunBlockWithOptions(abci_client.BlockOptions{
+			Time: genesisTime.Add(time.Duration(i) * opts.BlockTime),
+			Txs:  &txs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error producing block %d with a: %w", height, err)
+		}
+
+		block := a.LastBlock
+		resA, err := a.Storage.GetResponses(block.Height)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the responses of a for block %d: %w", block.Height, err)
+		}
+
+		resB, err := b.ReplayBlock(block, a.LastCommit.ToCommit())
+		if err != nil {
+			return nil, fmt.Errorf("error replaying block %d with b: %w", block.Height, err)
+		}
+
+		divergences = append(divergences, compareFinalizeBlock(block.Height, resA, resB)...)
+		if len(divergences) > 0 {
+			return divergences, nil
+		}
+	}
+	return nil, nil
+}
+
+func compareInitChain(a, b *abci_client.AbciClient) []Divergence {
+	divergences := make([]Divergence, 0)
+	if !bytes.Equal(a.CurState.AppHash, b.CurState.AppHash) {
+		divergences = append(divergences, Divergence{
+			Field: "init_chain.app_hash",
+			A:     format(a.CurState.AppHash),
+			B:     format(b.CurState.AppHash),
+		})
+	}
+	if !bytes.Equal(a.CurState.Validators.Hash(), b.CurState.Validators.Hash()) {
+		divergences = append(divergences, Divergence{
+			Field: "init_chain.validators",
+			A:     a.CurState.Validators.String(),
+			B:     b.CurState.Validators.String(),
+		})
+	}
+	return divergences
+}
+
+// compareCheckTx sends the transaction with the given index in the block at the given height
+// to both sets of apps, and compares the responses.
+func compareCheckTx(a, b *abci_client.AbciClient, height int64, index int, tx types.Tx) ([]Divergence, error) {
+	txBytes := []byte(tx)
+	resA, err := a.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error from CheckTx of a: %w", err)
+	}
+	resB, err := b.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error from CheckTx of b: %w", err)
+	}
+
+	return compareFields(height, fmt.Sprintf("check_tx[%d]", index), []field{
+		{"code", resA.Code, resB.Code},
+		{"codespace", resA.Codespace, resB.Codespace},
+		{"data", resA.Data, resB.Data},
+		{"gas_wanted", resA.GasWanted, resB.GasWanted},
+	}), nil
+}
+
+func compareFinalizeBlock(height int64, resA, resB *abcitypes.ResponseFinalizeBlock) []Divergence {
+	fields := []field{
+		{"app_hash", resA.AppHash, resB.AppHash},
+		{"num_tx_results", len(resA.TxResults), len(resB.TxResults)},
+		{"events", resA.Events, resB.Events},
+		{"validator_updates", resA.ValidatorUpdates, resB.ValidatorUpdates},
+		{"consensus_param_updates", resA.ConsensusParamUpdates, resB.ConsensusParamUpdates},
+	}
+	divergences := compareFields(height, "finalize_block", fields)
+
+	for i := 0; i < len(resA.TxResults) && i < len(resB.TxResults); i++ {
+		txA, txB := resA.TxResults[i], resB.TxResults[i]
+		divergences = append(divergences, compareFields(height, fmt.Sprintf("finalize_block.tx_results[%d]", i), []field{
+			{"code", txA.Code, txB.Code},
+			{"codespace", txA.Codespace, txB.Codespace},
+			{"data", txA.Data, txB.Data},
+			{"log", txA.Log, txB.Log},
+			{"gas_wanted", txA.GasWanted, txB.GasWanted},
+			{"gas_used", txA.GasUsed, txB.GasUsed},
+			{"events", txA.Events, txB.Events},
+		})...)
+	}
+	return divergences
+}
+
+type field struct {
+	name string
+	a, b interface{}
+}
+
+func compareFields(height int64, prefix string, fields []field) []Divergence {
+	divergences := make([]Divergence, 0)
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.a, f.b) {
+			divergences = append(divergences, Divergence{
+				Height: height,
+				Field:  prefix + "." + f.name,
+				A:      format(f.a),
+				B:      format(f.b),
+			})
+		}
+	}
+	return divergences
+}
+
+// format formats byte slices as hex, and everything else with %v.
+func format(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return fmt.Sprintf("%X", b)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/diff"
+	"github.com/informalsystems/CometMock/cometmock/loadgen"
+	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/urfave/cli/v2"
+)
+
+// diffCommand drives two sets of apps with identical blocks and reports where they diverge.
+func diffCommand(logger cometlog.Logger) *cli.Command {
+	argumentString := "[--num-blocks=<value>] [--block-time=<value>] [--txs=<value>] [--txs-per-block=<value>] [--determinism-checks=<value>] <app-addresses-a> <app-addresses-b> <genesis-file> <node-homes> <abci-connection-mode>"
+
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "Drive two sets of apps with identical blocks and report where their responses diverge",
+		Description: `Connects to two sets of apps at the given comma-separated addresses, a and b, e.g. two builds of the same app,
+and initializes both from the genesis file. Blocks are produced with the apps of a, which sign with the keys
+in the node homes, and the same blocks are then replayed through the apps of b.
+Transactions are sent to CheckTx of both sets before they are included.
+After each block, the responses to CheckTx and FinalizeBlock of both sets are compared, including the app hash.
+At the first height at which they differ, all differing fields are printed, and the exit code is 1.
+Within each set, the responses of the apps are compared according to --determinism-checks.`,
+		ArgsUsage: argumentString,
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:  "num-blocks",
+				Usage: "The number of blocks to produce.",
+				Value: 100,
+			},
+			&cli.Int64Flag{
+				Name:  "block-time",
+				Usage: "The number of milliseconds between blocks. The first block is one block time after the genesis time.",
+				Value: 1000,
+			},
+			&cli.StringFlag{
+				Name: "txs",
+				Usage: `
+The generator of the transactions to include, e.g. file:/path/to/txs with one base64 encoded transaction per line,
+or template:key{{.Index}}=value. See start_load for the generators.
+If this is empty, the blocks are empty.`,
+				Value: "",
+			},
+			&cli.Int64Flag{
+				Name:  "txs-per-block",
+				Usage: "The number of transactions to include in each block, if --txs is set.",
+				Value: 1,
+			},
+			&cli.StringFlag{
+				Name: "determinism-checks",
+				Usage: `
+Decides what happens when the apps within a set respond differently to the same request, per ABCI method,
+see the flag of the same name of cometmock.`,
+				Value: "",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 5 {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock diff "+argumentString, 1)
+			}
+
+			appAddressesA := strings.Split(c.Args().Get(0), ",")
+			appAddressesB := strings.Split(c.Args().Get(1), ",")
+			genesisFile := c.Args().Get(2)
+			nodeHomes := strings.Split(c.Args().Get(3), ",")
+			connectionMode := c.Args().Get(4)
+
+			if connectionMode != "socket" && connectionMode != "grpc" {
+				return cli.Exit(fmt.Sprintf("Invalid connection mode: %s. Connection mode must be either 'socket' or 'grpc'.", connectionMode), 1)
+			}
+			if len(appAddressesA) != len(nodeHomes) {
+				return cli.Exit(fmt.Sprintf("Got %d app addresses for a, but %d node homes. There must be one node home per app.", len(appAddressesA), len(nodeHomes)), 1)
+			}
+
+			opts := diff.Options{
+				NumBlocks:   c.Int64("num-blocks"),
+				BlockTime:   time.Duration(c.Int64("block-time")) * time.Millisecond,
+				TxsPerBlock: c.Int64("txs-per-block"),
+			}
+			if opts.NumBlocks <= 0 || opts.BlockTime <= 0 || opts.TxsPerBlock < 0 {
+				return cli.Exit("--num-blocks and --block-time must be positive, and --txs-per-block must not be negative", 1)
+			}
+			if txs := c.String("txs"); txs != "" {
+				generator, err := loadgen.NewGenerator(txs)
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				opts.Generator = generator
+			}
+
+			determinismChecks, err := abci_client.ParseDeterminismChecks(c.String("determinism-checks"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			appGenesis, err := genutiltypes.AppGenesisFromFile(genesisFile)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+			}
+			genesisDoc, err := appGenesis.ToGenesisDoc()
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+			}
+
+			connectClient := NewConnectClient(connectionMode, logger)
+
+			privValsA, err := GetMockPVsFromNodeHomes(nodeHomes)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading the keys of the validators: %v", err), 1)
+			}
+			// b replays the blocks produced by a, so its apps only need keys to be told apart
+			privValsB := make([]types.PrivValidator, len(appAddressesB))
+			for i := range privValsB {
+				privValsB[i] = types.NewMockPV()
+			}
+
+			clients := make([]*abci_client.AbciClient, 2)
+			for i, set := range []struct {
+				name         string
+				appAddresses []string
+				privVals     []types.PrivValidator
+			}{
+				{"a", appAddressesA, privValsA},
+				{"b", appAddressesB, privValsB},
+			} {
+				curState, err := state.MakeGenesisState(genesisDoc)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+				}
+
+				clientMap, clientOrder, err := ConnectApps(set.appAddresses, set.privVals, connectClient, logger.With("apps", set.name))
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Error connecting to apps %v: %v", set.name, err), 1)
+				}
+				clients[i] = abci_client.NewAbciClient(
+					clientMap,
+					logger.With("apps", set.name),
+					curState,
+					&types.Block{},
+					&types.ExtendedCommit{},
+					&storage.MapStorage{},
+					abci_client.NewFixedBlockTimeHandler(opts.BlockTime),
+					determinismChecks,
+				)
+				clients[i].ClientOrder = clientOrder
+				clients[i].ConnectClient = connectClient
+
+				err = clients[i].SendInitChain(curState, genesisDoc)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Error from InitChain of %v: %v", set.name, err), 1)
+				}
+			}
+
+			divergences, err := diff.Run(clients[0], clients[1], genesisDoc.GenesisTime, opts)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error driving the apps: %v", err), 1)
+			}
+			if len(divergences) > 0 {
+				for _, divergence := range divergences {
+					fmt.Fprintln(os.Stderr, divergence.String())
+				}
+				return cli.Exit(fmt.Sprintf("The apps diverged at height %d", divergences[0].Height), 1)
+			}
+
+			fmt.Printf("The apps agreed on %d blocks\n", opts.NumBlocks)
+			return nil
+		},
+	}
+}
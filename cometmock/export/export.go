@@ -0,0 +1,170 @@
+// Package export fetches the indexed blocks, transactions and events of a range of heights from the RPC of CometMock
+// and writes them as JSON or CSV for offline analysis, see the export command.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+)
+
+// The formats that blocks can be written in.
+const (
+	// one JSON object per block and line
+	FormatJSON = "json"
+	// one row per event attribute, see CSVHeader
+	FormatCSV = "csv"
+)
+
+// CSVHeader are the columns of the CSV format. Events of blocks have no transaction,
+// and transactions without events and blocks without events and transactions have a row without an event.
+var CSVHeader = []string{
+	"height", "time", "block_hash",
+	"tx_index", "tx_hash", "tx_code",
+	"event_index", "event_type", "attribute_key", "attribute_value", "attribute_indexed",
+}
+
+// Writer writes exported blocks in a format.
+type Writer interface {
+	Write(block abci_client.ExportedBlock) error
+	// Flush writes buffered data, and needs to be called after the last block.
+	Flush() error
+}
+
+// NewWriter returns a writer for the given format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		writer := &csvWriter{writer: csv.NewWriter(w)}
+		if err := writer.writer.Write(CSVHeader); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unknown format %v, expected %v or %v", format, FormatJSON, FormatCSV)
+	}
+}
+
+type jsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonWriter) Write(block abci_client.ExportedBlock) error {
+	return w.encoder.Encode(block)
+}
+
+func (w *jsonWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(block abci_client.ExportedBlock) error {
+	blockColumns := []string{strconv.FormatInt(block.Height, 10), block.Time.Format(time.RFC3339Nano), block.Hash.String()}
+	noTx := []string{"", "", ""}
+
+	// a block without events only gets a row of its own if it has no transactions either
+	if err := w.writeEvents(blockColumns, noTx, block.Events, len(block.Txs) == 0); err != nil {
+		return err
+	}
+	for _, tx := range block.Txs {
+		txColumns := []string{strconv.FormatUint(uint64(tx.Index), 10), tx.Hash.String(), strconv.FormatUint(uint64(tx.Code), 10)}
+		if err := w.writeEvents(blockColumns, txColumns, tx.Events, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEvents writes a row per attribute of the events, or, if there are no events and empty is true,
+// a row without an event.
+func (w *csvWriter) writeEvents(blockColumns, txColumns []string, events []abcitypes.Event, empty bool) error {
+	write := func(eventColumns ...string) error {
+		row := make([]string, 0, len(CSVHeader))
+		row = append(row, blockColumns...)
+		row = append(row, txColumns...)
+		return w.writer.Write(append(row, eventColumns...))
+	}
+
+	if len(events) == 0 && empty {
+		return write("", "", "", "", "")
+	}
+	for i, event := range events {
+		eventIndex := strconv.Itoa(i)
+		if len(event.Attributes) == 0 {
+			if err := write(eventIndex, event.Type, "", "", ""); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, attribute := range event.Attributes {
+			if err := write(eventIndex, event.Type, attribute.Key, attribute.Value, strconv.FormatBool(attribute.Index)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Export fetches the blocks with heights from from to to, inclusive, from the RPC at the given address,
+// e.g. tcp://127.0.0.1:22331, and writes them to the writer. If from is 0, the export starts at the earliest height,
+// and if to is 0, it ends at the latest height. The blocks are fetched with export_index, in pages of
+// abci_client.MaxExportHeights heights. It returns the number of exported blocks.
+func Export(ctx context.Context, rpcAddress string, from, to int64, writer Writer) (int, error) {
+	client, err := jsonrpcclient.New(rpcAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	if from == 0 || to == 0 {
+		status := new(ctypes.ResultStatus)
+		if _, err := client.Call(ctx, "status", map[string]interface{}{}, status); err != nil {
+			return 0, fmt.Errorf("error calling status: %w", err)
+		}
+		if from == 0 {
+			from = status.SyncInfo.EarliestBlockHeight
+		}
+		if to == 0 {
+			to = status.SyncInfo.LatestBlockHeight
+		}
+	}
+	if from > to {
+		return 0, fmt.Errorf("from %d must be less than or equal to to %d", from, to)
+	}
+
+	exported := 0
+	for pageFrom := from; pageFrom <= to; pageFrom += abci_client.MaxExportHeights {
+		pageTo := min(pageFrom+abci_client.MaxExportHeights-1, to)
+		result := new(rpc_server.ResultExportIndex)
+		params := map[string]interface{}{"from": pageFrom, "to": pageTo}
+		if _, err := client.Call(ctx, "export_index", params, result); err != nil {
+			return exported, fmt.Errorf("error exporting heights %d to %d: %w", pageFrom, pageTo, err)
+		}
+		for _, block := range result.Blocks {
+			if err := writer.Write(block); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+	}
+	return exported, writer.Flush()
+}
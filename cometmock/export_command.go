@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/informalsystems/CometMock/cometmock/export"
+	"github.com/urfave/cli/v2"
+)
+
+// exportCommand writes the indexed blocks, transactions and events of a running CometMock as JSON or CSV.
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Export the indexed transactions and events of a running CometMock as JSON or CSV",
+		ArgsUsage: "[--from=<value>] [--to=<value>] [--format=<value>] [--output=<value>] <cometmock-listen-address>",
+		Description: `Fetches the blocks of a range of heights from the RPC of CometMock at the given address with export_index,
+with the events of the blocks and the results and events of their transactions, and writes them for offline analysis.
+In the json format, each block is written as a JSON object on a line of its own.
+In the csv format, each attribute of an event is written as a row, with the height, time and hash of the block,
+and the index, hash and code of the transaction if the event was emitted by a transaction.`,
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:  "from",
+				Usage: "The first height to export. If this is 0, the export starts at the earliest height.",
+				Value: 0,
+			},
+			&cli.Int64Flag{
+				Name:  "to",
+				Usage: "The last height to export. If this is 0, the export ends at the latest height.",
+				Value: 0,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "The format to write, either json or csv.",
+				Value: export.FormatJSON,
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "The file to write to. If this is empty, the export is written to stdout.",
+				Value: "",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock export "+c.Command.ArgsUsage, 1)
+			}
+			rpcAddress := c.Args().Get(0)
+
+			var output io.Writer = os.Stdout
+			if path := c.String("output"); path != "" {
+				file, err := os.Create(path)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("Error creating the output file: %v", err), 1)
+				}
+				defer file.Close()
+				output = file
+			}
+
+			writer, err := export.NewWriter(c.String("format"), output)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			exported, err := export.Export(context.Background(), rpcAddress, c.Int64("from"), c.Int64("to"), writer)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error exporting blocks: %v", err), 1)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d blocks\n", exported)
+			return nil
+		},
+	}
+}
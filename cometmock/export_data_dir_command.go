@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+	"github.com/urfave/cli/v2"
+)
+
+// exportDataDirCommand writes the history of a running CometMock as the data directory of a CometBFT node.
+func exportDataDirCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export-data-dir",
+		Usage:     "Export the history of a running CometMock as the data directory of a CometBFT node",
+		ArgsUsage: "--output=<value> <cometmock-listen-address>",
+		Description: `Makes the CometMock whose RPC listens on the given address write its stored blocks, commits, states and
+FinalizeBlock responses into blockstore.db and state.db, and the last sign state of its validators into
+priv_validator_state.json, with export_data_dir, so that CometBFT nodes can take over the chain from the latest height:
+fast-forward with CometMock, then continue under real consensus.
+The directory is written by CometMock, so it is on the machine of CometMock, below its --data-dir-export-root.
+To hand over, stop CometMock without producing further blocks, copy the directory into the data folder of each node,
+and start the nodes with the apps of CometMock, which are at the latest height.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "The directory to write the data directory into, relative to the --data-dir-export-root of CometMock. It must not exist yet, or be empty.",
+				Value: "",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 || c.String("output") == "" {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock export-data-dir "+c.Command.ArgsUsage, 1)
+			}
+			dir := c.String("output")
+			client, err := jsonrpcclient.New(c.Args().Get(0))
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error connecting to CometMock: %v", err), 1)
+			}
+			result := new(rpc_server.ResultExportDataDir)
+			if _, err := client.Call(context.Background(), "export_data_dir", map[string]interface{}{"dir": dir}, result); err != nil {
+				return cli.Exit(fmt.Sprintf("Error exporting the data directory: %v", err), 1)
+			}
+			fmt.Printf("Exported the blocks from height %d to %d into %v\n", result.Base, result.Height, result.Dir)
+			return nil
+		},
+	}
+}
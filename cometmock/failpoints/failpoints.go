@@ -0,0 +1,219 @@
+// Package failpoints lets tests inject faults at named places in CometMock while it runs,
+// e.g. make storing a block fail, or make FinalizeBlock slow, see the enable_failpoint endpoint.
+// Each failpoint has a kind, which is what it does when it fires: it returns an error, delays the call it guards,
+// or drops the call.
+package failpoints
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The failpoints of CometMock.
+const (
+	// storing a block, its commit, state and responses fails after the apps finalized it,
+	// so the block is not produced, and block production halts
+	StorageUpdateStoresError = "storage.UpdateStores.error"
+	// FinalizeBlock is sent to the apps only after a delay
+	FinalizeSlow = "finalize.slow"
+	// FinalizeBlock fails before it is sent to any app, so the block is not produced and can be tried again
+	FinalizeError = "finalize.error"
+	// Commit is sent to the apps only after a delay
+	CommitSlow = "commit.slow"
+	// Commit is not sent to the apps, as if the nodes crashed between FinalizeBlock and Commit
+	CommitDrop = "commit.drop"
+	// CheckTx fails, so transactions are rejected and not included in blocks
+	CheckTxError = "checktx.error"
+	// ABCI queries fail, unless they are answered from the query cache
+	QueryError = "query.error"
+)
+
+// Kind is what a failpoint does when it fires.
+type Kind string
+
+const (
+	// the failpoint returns an error, with the value of the failpoint as message
+	KindError Kind = "error"
+	// the failpoint delays the call it guards by the value of the failpoint, in milliseconds
+	KindSlow Kind = "slow"
+	// the failpoint drops the call it guards
+	KindDrop Kind = "drop"
+)
+
+// ErrInjected is wrapped by the errors of failpoints of KindError.
+var ErrInjected = errors.New("injected by failpoint")
+
+var kinds = map[string]Kind{
+	StorageUpdateStoresError: KindError,
+	FinalizeSlow:             KindSlow,
+	FinalizeError:            KindError,
+	CommitSlow:               KindSlow,
+	CommitDrop:               KindDrop,
+	CheckTxError:             KindError,
+	QueryError:               KindError,
+}
+
+// Failpoint is the state of a failpoint.
+type Failpoint struct {
+	Name    string `json:"name"`
+	Kind    Kind   `json:"kind"`
+	Enabled bool   `json:"enabled"`
+	// the message of the errors of failpoints of KindError, or the delay in milliseconds of failpoints of KindSlow
+	Value string `json:"value,omitempty"`
+	// how many more times the failpoint fires before it is disabled, or 0 if it fires until it is disabled
+	Remaining int64 `json:"remaining"`
+	// the probability with which the failpoint fires each time it is reached
+	Probability float64 `json:"probability"`
+	// how many times the failpoint fired since it was enabled
+	Hits int64 `json:"hits"`
+}
+
+// Registry holds the failpoints that are enabled.
+// The nil Registry has no failpoints enabled, so places that are reached without a registry never fail.
+// It is safe for use by multiple goroutines simultaneously.
+type Registry struct {
+	mutex   sync.Mutex
+	enabled map[string]*Failpoint
+	rand    *rand.Rand
+}
+
+// NewRegistry returns a registry without any failpoints enabled.
+func NewRegistry() *Registry {
+	return &Registry{
+		enabled: make(map[string]*Failpoint),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Enable enables the failpoint with the given name, replacing its settings if it is already enabled.
+// value is the message of the errors of failpoints of KindError, which may be empty,
+// and the delay in milliseconds of failpoints of KindSlow. count is how many times the failpoint fires
+// before it is disabled again, where 0 means until it is disabled, and probability is the probability
+// with which it fires each time it is reached.
+func (r *Registry) Enable(name string, value string, count int64, probability float64) error {
+	kind, ok := kinds[name]
+	if !ok {
+		return fmt.Errorf("unknown failpoint %q, must be one of %v", name, Names())
+	}
+	if count < 0 {
+		return fmt.Errorf("count must not be negative, but got %d", count)
+	}
+	if probability <= 0 || probability > 1 {
+		return fmt.Errorf("probability must be greater than 0 and at most 1, but got %v", probability)
+	}
+	switch kind {
+	case KindSlow:
+		if delay, err := strconv.ParseInt(value, 10, 64); err != nil || delay <= 0 {
+			return fmt.Errorf("the value of failpoint %v must be a positive delay in milliseconds, but got %q", name, value)
+		}
+	case KindDrop:
+		if value != "" {
+			return fmt.Errorf("failpoint %v takes no value, but got %q", name, value)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.enabled[name] = &Failpoint{
+		Name:        name,
+		Kind:        kind,
+		Enabled:     true,
+		Value:       value,
+		Remaining:   count,
+		Probability: probability,
+	}
+	return nil
+}
+
+// Disable disables the failpoint with the given name, which does nothing if it is not enabled.
+func (r *Registry) Disable(name string) error {
+	if _, ok := kinds[name]; !ok {
+		return fmt.Errorf("unknown failpoint %q, must be one of %v", name, Names())
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.enabled, name)
+	return nil
+}
+
+// List returns the states of all failpoints, ordered by name.
+func (r *Registry) List() []Failpoint {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	failpoints := make([]Failpoint, 0, len(kinds))
+	for _, name := range Names() {
+		if failpoint, ok := r.enabled[name]; ok {
+			failpoints = append(failpoints, *failpoint)
+		} else {
+			failpoints = append(failpoints, Failpoint{Name: name, Kind: kinds[name]})
+		}
+	}
+	return failpoints
+}
+
+// Names returns the names of all failpoints, ordered by name.
+func Names() []string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error returns the error of the failpoint of KindError with the given name if it fires, and nil otherwise.
+func (r *Registry) Error(name string) error {
+	value, fired := r.fire(name)
+	if !fired {
+		return nil
+	}
+	if value == "" {
+		return fmt.Errorf("%w %v", ErrInjected, name)
+	}
+	return fmt.Errorf("%w %v: %v", ErrInjected, name, value)
+}
+
+// Sleep delays the caller by the delay of the failpoint of KindSlow with the given name if it fires.
+func (r *Registry) Sleep(name string) {
+	if value, fired := r.fire(name); fired {
+		delay, _ := strconv.ParseInt(value, 10, 64)
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+	}
+}
+
+// Drop returns whether the failpoint of KindDrop with the given name fires, i.e. whether to drop the call it guards.
+func (r *Registry) Drop(name string) bool {
+	_, fired := r.fire(name)
+	return fired
+}
+
+// fire returns whether the failpoint with the given name fires now, and its value if it does.
+// A failpoint whose count is used up is disabled.
+func (r *Registry) fire(name string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	failpoint, ok := r.enabled[name]
+	if !ok || r.rand.Float64() >= failpoint.Probability {
+		return "", false
+	}
+	failpoint.Hits++
+	if failpoint.Remaining > 0 {
+		failpoint.Remaining--
+		if failpoint.Remaining == 0 {
+			delete(r.enabled, name)
+		}
+	}
+	return failpoint.Value, true
+}
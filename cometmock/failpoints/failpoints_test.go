@@ -0,0 +1,124 @@
+package failpoints
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listed returns the state of the failpoint with the given name from the list of the registry.
+func listed(t *testing.T, r *Registry, name string) Failpoint {
+	for _, failpoint := range r.List() {
+		if failpoint.Name == name {
+			return failpoint
+		}
+	}
+	t.Fatalf("failpoint %v is not listed", name)
+	return Failpoint{}
+}
+
+func TestNilRegistryNeverFires(t *testing.T) {
+	var r *Registry
+	require.NoError(t, r.Error(FinalizeError))
+	require.False(t, r.Drop(CommitDrop))
+
+	start := time.Now()
+	r.Sleep(FinalizeSlow)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestEnableValidatesTheSettings(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failpoint   string
+		value       string
+		count       int64
+		probability float64
+		expectedErr bool
+	}{
+		{name: "error with message", failpoint: FinalizeError, value: "boom", probability: 1},
+		{name: "error without message", failpoint: StorageUpdateStoresError, probability: 1},
+		{name: "slow with delay", failpoint: CommitSlow, value: "10", count: 2, probability: 0.5},
+		{name: "drop", failpoint: CommitDrop, probability: 1},
+		{name: "unknown failpoint", failpoint: "finalize.unknown", probability: 1, expectedErr: true},
+		{name: "negative count", failpoint: FinalizeError, count: -1, probability: 1, expectedErr: true},
+		{name: "zero probability", failpoint: FinalizeError, probability: 0, expectedErr: true},
+		{name: "probability above 1", failpoint: FinalizeError, probability: 1.5, expectedErr: true},
+		{name: "slow without delay", failpoint: FinalizeSlow, probability: 1, expectedErr: true},
+		{name: "slow with negative delay", failpoint: FinalizeSlow, value: "-5", probability: 1, expectedErr: true},
+		{name: "drop with value", failpoint: CommitDrop, value: "1", probability: 1, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewRegistry().Enable(tc.failpoint, tc.value, tc.count, tc.probability)
+			if tc.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestErrorWrapsErrInjected(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Error(FinalizeError))
+
+	require.NoError(t, r.Enable(FinalizeError, "boom", 0, 1))
+	err := r.Error(FinalizeError)
+	require.True(t, errors.Is(err, ErrInjected))
+	require.Contains(t, err.Error(), FinalizeError)
+	require.Contains(t, err.Error(), "boom")
+
+	// other failpoints are not affected
+	require.NoError(t, r.Error(CheckTxError))
+
+	require.NoError(t, r.Disable(FinalizeError))
+	require.NoError(t, r.Error(FinalizeError))
+}
+
+// Tests that failpoints with a count are disabled once they fired that many times.
+func TestCountDisablesTheFailpoint(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Enable(CommitDrop, "", 2, 1))
+
+	require.True(t, r.Drop(CommitDrop))
+	require.Equal(t, int64(1), listed(t, r, CommitDrop).Remaining)
+	require.True(t, r.Drop(CommitDrop))
+	require.False(t, r.Drop(CommitDrop))
+	require.False(t, listed(t, r, CommitDrop).Enabled)
+}
+
+func TestProbability(t *testing.T) {
+	r := NewRegistry()
+	r.rand = rand.New(rand.NewSource(1))
+	require.NoError(t, r.Enable(QueryError, "", 0, 0.25))
+
+	fired := 0
+	for i := 0; i < 1000; i++ {
+		if r.Error(QueryError) != nil {
+			fired++
+		}
+	}
+	require.InDelta(t, 250, fired, 50)
+	require.Equal(t, int64(fired), listed(t, r, QueryError).Hits)
+}
+
+func TestListHasAllFailpointsOrderedByName(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Enable(CheckTxError, "", 0, 1))
+
+	failpoints := r.List()
+	require.Len(t, failpoints, len(Names()))
+	for i, failpoint := range failpoints {
+		require.Equal(t, Names()[i], failpoint.Name)
+		require.Equal(t, kinds[failpoint.Name], failpoint.Kind)
+		require.Equal(t, failpoint.Name == CheckTxError, failpoint.Enabled)
+	}
+
+	require.Error(t, r.Disable("checktx.unknown"))
+}
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/logging"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+	"github.com/urfave/cli/v2"
+)
+
+// cometMockFlags returns the flags of cometmock.
+func cometMockFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name: "block-time",
+			Usage: `
+The number of milliseconds by which the block timestamp should advance from one block to the next.
+If this is <0, block timestamps will advance with the system time between the block productions.
+Even then, it is still possible to shift the block time from the system time, e.g. by setting an initial timestamp
+or by using the 'advance_time' endpoint.`,
+			Value: -1,
+		},
+		&cli.BoolFlag{
+			Name: "auto-tx",
+			Usage: `
+If this is true, transactions are included immediately
+after they are received via broadcast_tx, i.e. a new block
+is created when a BroadcastTx endpoint is hit.
+If this is false, transactions are still included
+upon creation of new blocks, but CometMock will not specifically produce
+a new block when a transaction is broadcast.`,
+			Value: true,
+		},
+		&cli.Int64Flag{
+			Name: "block-production-interval",
+			Usage: `
+Time to sleep between blocks in milliseconds.
+To disable block production, set to 0.
+This will not necessarily mean block production is this fast
+- it is just the sleep time between blocks.
+Setting this to a value < 0 disables automatic block production.
+In this case, blocks are only produced when instructed explicitly either by
+advancing blocks or broadcasting transactions.`,
+			Value: 1000,
+		},
+		&cli.Int64Flag{
+			Name: "starting-timestamp",
+			Usage: `
+The timestamp to use for the first block, given in milliseconds since the unix epoch.
+If this is < 0, the current system time is used.
+If this is >= 0, the system time is ignored and this timestamp is used for the first block instead.`,
+			Value: -1,
+		},
+		&cli.BoolFlag{
+			Name: "starting-timestamp-from-genesis",
+			Usage: `
+If this is true, it overrides the starting-timestamp, and instead
+bases the time for the first block on the genesis time, incremented by the block time
+or the system time between creating the genesis request and producing the first block.`,
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name: "grpc-listen-address",
+			Usage: `
+The address on which CometMock serves the gRPC control API,
+e.g. tcp://127.0.0.1:22332. The gRPC control API offers the
+CometMock specific control endpoints with typed clients generated
+from proto/cometmock/control/v1/control.proto.
+If this is empty, the gRPC control API is disabled.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "priv-validator-laddrs",
+			Usage: `
+A comma-separated list of addresses, one per node home, on which CometMock listens for
+remote signers (e.g. tmkms) for the respective validator,
+e.g. tcp://127.0.0.1:26659,,tcp://127.0.0.1:26679.
+For empty entries, the priv_validator_key.json from the node home is used for signing instead.
+If this is empty, all validators sign with the keys from their node homes.`,
+			Value: "",
+		},
+		&cli.Int64Flag{
+			Name: "app-connect-timeout",
+			Usage: `
+The time in milliseconds for which CometMock retries to connect to the apps at startup,
+with backoff, while they are not listening yet, e.g. because they are started at the same time.
+App addresses that are discovered via DNS are looked up again for the same time, while they do not have
+one record per node home yet.
+If this is 0, CometMock tries to connect only once.`,
+			Value: 30000,
+		},
+		&cli.BoolFlag{
+			Name: "degraded-startup",
+			Usage: `
+If this is true, CometMock starts without the apps that are still not listening after app-connect-timeout,
+as long as the validators with apps have more than 2/3 of the voting power.
+The validators of the missing apps do not sign until their apps are listening,
+at which point the apps are initialized, caught up with the blocks produced so far, and join.`,
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name: "allow-missing-keys",
+			Usage: `
+At startup, the keys from the node homes are matched with the validator set after InitChain,
+and CometMock exits with an error that lists the validators without keys, and the keys that match no validator.
+If this is true, CometMock starts anyway, and the validators without keys never sign.`,
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name: "validator-keys",
+			Usage: `
+The keys of the validators, one per app address, as a comma-separated list of sources in place of node homes,
+whose node homes argument then needs to be empty (""). Each source is one of
+home:<node home> for the key of a node home, whose signing state is persisted,
+file:<path> for a priv_validator_key.json file,
+seed:<seed> for a hex or base64 encoded ed25519 seed of 32 bytes, or private key of 64 bytes,
+env:<name> for an environment variable with a seed, a priv_validator_key.json document or a bundle,
+bundle:<path> for a JSON file with a bundle, which is an array of priv_validator_key.json documents and seeds, giving one key each.`,
+		},
+		&cli.StringFlag{
+			Name: "validator-manifest",
+			Usage: `
+A JSON or TOML (with the extension .toml) file that maps each validator to the address of its app and its key,
+in place of the app addresses and node homes arguments, which then need to be empty ("").
+Each entry names its validator by address or public key, and CometMock exits with an error if the key of an entry
+belongs to another validator. Entries can add a latency to the calls to their app, or add observers.`,
+		},
+		&cli.Int64Flag{
+			Name: "priv-validator-timeout",
+			Usage: `
+The time in milliseconds after which a signing request to a remote signer times out.
+If a remote signer is slow or unreachable, the validator does not sign the block,
+just like a real node would miss the block.`,
+			Value: 3000,
+		},
+		&cli.StringFlag{
+			Name: "fixed-proposer",
+			Usage: `
+The address of the private key of a validator that should propose all blocks.
+If this is empty, the proposer rotates according to the proposer priorities
+of the validators, exactly like in CometBFT.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "validator-names",
+			Usage: `
+A comma-separated list of name=validator pairs, e.g. alice=ABCD...,bob=cosmosvalcons1...,
+which name validators, so that control endpoints accept the names in place of the addresses,
+and the names are shown in the log output. The names of the validators in the genesis
+and the monikers of the validators in the app state of Cosmos SDK chains are used as well.`,
+			Value: "",
+		},
+		&cli.IntFlag{
+			Name: "commit-round",
+			Usage: `
+The round in which blocks are proposed and committed, so that apps observe commits with that round
+in DecidedLastCommit. It can be changed at runtime with set_commit_round, and overridden per block with run_block.`,
+			Value: 0,
+		},
+		&cli.StringFlag{
+			Name: "substitute-validators",
+			Usage: `
+Bootstraps the chain from the exported genesis of a live chain.
+This is a comma-separated list of addresses of validators in the genesis,
+one per node home, whose keys are substituted by the keys of the respective node homes
+(or remote signers) in the validator set and in the app state.
+If this is 'top', the validators with the highest voting power are substituted.
+The voting power of the substituted validators is scaled so that they hold more than 2/3 of the voting power.
+The remaining validators have no app, so they never propose and never sign.
+If this is empty, the genesis is used as is.`,
+			Value: "",
+		},
+		&cli.Int64Flag{
+			Name: "unresponsive-threshold",
+			Usage: `
+The time in milliseconds after which an app that does not respond to an ABCI call
+is marked as unresponsive. Unresponsive apps make /health return an error,
+are shown in the cometmock_status endpoint, and an AppUnresponsive event is emitted.
+If this is 0, apps are never marked as unresponsive.`,
+			Value: 5000,
+		},
+		&cli.Int64Flag{
+			Name: "liveness-probe-interval",
+			Usage: `
+The interval in milliseconds at which an Echo is sent to every app between blocks,
+so that apps that stopped responding, or whose connection failed, are found before the next block fails.
+Apps that are not alive make /health return an error, are left out of /net_info, are shown in the
+cometmock_status endpoint, and an AppLivenessChanged event is emitted when an app stops or starts responding.
+Apps whose connection failed are reconnected as soon as they accept connections again.
+If this is 0, apps are not probed.`,
+			Value: 1000,
+		},
+		&cli.StringFlag{
+			Name: "abci-connections",
+			Usage: `
+Decides how many connections are opened to each app.
+If this is 'per-purpose', like CometBFT, a connection is opened for the calls that produce blocks,
+one for CheckTx, one for Info and queries, and one for state sync, so that e.g. a slow query
+does not delay FinalizeBlock.
+If this is 'single', all calls to an app are sent over a single connection.`,
+			Value: string(abci_client.ConnectionModePerPurpose),
+		},
+		&cli.StringFlag{
+			Name: "query-mode",
+			Usage: `
+Decides which apps abci_query requests are sent to.
+If this is 'all', each query is sent to all apps, and an error is returned if they respond differently.
+If this is 'round-robin', each query is sent to a single app, cycling through the apps.
+If this is 'least-loaded', each query is sent to the app with the fewest ABCI calls in flight.
+In the latter two modes, a query that fails is retried on the other apps.`,
+			Value: string(abci_client.QueryModeAll),
+		},
+		&cli.Int64Flag{
+			Name: "query-cache-size",
+			Usage: `
+The number of abci_query responses to cache. Responses to queries for committed heights
+are cached until the cache is full, responses to queries for the latest height (height 0)
+until the next block is committed.
+If this is 0, responses are not cached.`,
+			Value: 0,
+		},
+		&cli.Int64Flag{
+			Name: "snapshot-interval",
+			Usage: `
+The number of blocks after which CometMock takes a snapshot of its state,
+which the chain can be rewound to with rewind_to_snapshot once the apps were restored to the same height.
+If this is 0, no snapshots are taken.`,
+			Value: 0,
+		},
+		&cli.IntFlag{
+			Name: "snapshot-retention",
+			Usage: `
+The number of snapshots to keep, see --snapshot-interval. Older snapshots are dropped.`,
+			Value: abci_client.DefaultSnapshotRetention,
+		},
+		&cli.IntFlag{
+			Name: "storage-max-heights",
+			Usage: `
+The maximal number of heights whose blocks, commits, states and responses are held in memory.
+The entries of older heights are written to --storage-overflow-dir, or dropped if it is not set,
+so that they cannot be queried anymore and apps cannot be reloaded from scratch.
+If this is 0, all heights are held in memory.`,
+			Value: 0,
+		},
+		&cli.StringFlag{
+			Name: "storage-overflow-dir",
+			Usage: `
+The folder that the entries of heights evicted from memory are written to, see --storage-max-heights.
+They are read back from there when they are requested.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "determinism-checks",
+			Usage: `
+Decides what happens when the apps respond differently to the same request, per ABCI method.
+This is a comma-separated list of method=check pairs, e.g. 'FinalizeBlock=strict,Info=off,CheckTx=warn'.
+The methods are Info, InitChain, CheckTx, Query, FinalizeBlock, Commit and Invariant (see --invariant-queries),
+and '*' for all methods that are not listed.
+With 'strict', an error is returned, with 'warn', an error is logged, and with 'off', the responses are not compared.
+With 'app_hash', only the app hash and the hash of the transaction results of FinalizeBlock are compared,
+and an error is returned if they differ, which is much cheaper, e.g. use '*=app_hash' for large validator sets.
+Methods that are not listed are checked strictly.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "invariant-queries",
+			Usage: `
+A comma-separated list of abci queries that are sent to all apps after each block,
+of the form path or path=data with hex encoded data, e.g. '/cosmos.bank.v1beta1.Query/TotalSupply'.
+The responses are compared according to the determinism check for the method Invariant,
+see --determinism-checks. This catches divergences in state that do not show up in the app hash right away.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "replay-archive",
+			Usage: `
+Replays the blocks recorded on a live chain through the apps instead of producing blocks.
+This is either the data directory of a CometBFT node, containing blockstore.db,
+or a JSON file with the responses of the block RPC endpoint, as a JSON array or one per line.
+The genesis file needs to be the genesis of the live chain, and the archive needs to start at its initial height.
+After each block, the app hash, results hash and validator sets are compared with the header of the next block.
+Replaying stops at the first mismatch. The progress is shown in the cometmock_status endpoint.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "import-data-dir",
+			Usage: `
+Continues the chain of a stopped CometBFT node from its data directory, containing blockstore.db and state.db,
+instead of starting it from the genesis: the apps are not initialized with InitChain,
+but need to be at the latest height of the node, e.g. the apps of the node itself.
+The next block is produced on top of the latest block of the node, by the validators of its latest state,
+and the blocks and results that the node stored are imported, so they can be queried.
+The genesis file needs to be the genesis of the chain, and the keys need to be those of its validators.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "compat-listen-address",
+			Usage: `
+An additional address on which CometMock serves the CometBFT RPC endpoints
+with responses in the JSON shapes of CometBFT v0.34, for tools that have not migrated yet.
+On the main address, the same can be requested per request with the header 'X-CometMock-Compat: v0.34'.
+If this is empty, no additional address is served.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "readonly-listen-address",
+			Usage: `
+An additional address on which CometMock serves only the RPC endpoints that read, e.g. abci_query,
+block, tx_search and cometmock_status, but none that broadcast transactions, produce blocks or control CometMock.
+Heavy query traffic, e.g. during load tests, can be directed to it, apart from the clients that drive the chain.
+If this is empty, no additional address is served.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "prometheus-listen-address",
+			Usage: `
+The address on which CometMock serves metrics in the Prometheus format under /metrics, e.g. :26660,
+like the prometheus_listen_addr of CometBFT. This includes histograms of the latencies of the ABCI calls
+to each app, by method, which are also returned by the abci_latencies endpoint.
+If this is empty, no metrics are served.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "genesis-misbehaviours",
+			Usage: `
+Evidence to include in the first block, for apps that need to handle evidence right after InitChain.
+This is a comma-separated list of address=type pairs, where address is the address of a genesis validator
+and type is DuplicateVote (the only type that can be constructed before the first block).
+The duplicate votes are nil votes at the initial height.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "observer-addresses",
+			Usage: `
+A comma-separated list of addresses of additional apps that execute all blocks like the apps of full nodes,
+but have no validator identity, so they never propose, process proposals or sign.
+They are connected with the same connection mode, and their responses are compared with those of the other apps.
+Observers are known by the ids observer-0, observer-1, ... in the order given here, e.g. to target them with abci_query.`,
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name: "enforce-signing-state",
+			Usage: `
+If this is true, the height, round and step of the last signature of each validator with a node home
+are persisted in data/cometmock_sign_state.json in the node home, and after a restart, CometMock refuses to sign
+at heights, rounds and steps up to the persisted ones, so that it does not accidentally double sign.
+Delete the file to start over from the genesis. By default, nothing is persisted and signatures are never refused.`,
+			Value: false,
+		},
+		&cli.BoolFlag{
+			Name: "skip-check-tx",
+			Usage: `
+If this is true, broadcast transactions are not sent to CheckTx,
+and are included in the next block as they are.
+This is useful for apps that validate transactions only in PrepareProposal
+or FinalizeBlock, and for benchmarking throughput.`,
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name: "block-jitter",
+			Usage: `
+Randomizes the intervals between blocks, given as distribution:milliseconds,
+where the distribution is one of uniform, normal or exponential, e.g. uniform:300.
+If the block time is fixed, the jitter is added to the block time of each block.
+Otherwise, it is added to the block production interval.`,
+			Value: "",
+		},
+		&cli.Int64Flag{
+			Name: "block-jitter-seed",
+			Usage: `
+The seed of the block jitter, so that runs with the same seed have the same intervals.
+If this is 0, a random seed is used, which is printed at startup.`,
+			Value: 0,
+		},
+		&cli.BoolFlag{
+			Name: "bft-time",
+			Usage: `
+If this is true, the timestamp of each block is the median of the timestamps of the votes
+for the previous block, weighted by voting power, like in CometBFT before proposer-based timestamps.
+Each validator timestamps its vote with its own clock, which is the time of CometMock plus
+the skew of the validator given with --clock-skews.`,
+			Value: false,
+		},
+		&cli.StringFlag{
+			Name: "clock-skews",
+			Usage: `
+A comma-separated list of address=milliseconds pairs, which give the offset of the clock
+of each validator from the time of CometMock, e.g. ABCD...=1500,EF01...=-300.
+Validators that are not listed have no skew. This is only used with --bft-time.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "double-execution",
+			Usage: `
+A comma-separated list of apps that execute each block twice, given by their validator address,
+their index in the app addresses (observers follow the validators), or all for all apps.
+The responses to both executions are compared to find nondeterminism within a single app.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "rpc-plugins",
+			Usage: `
+A comma-separated list of paths to Go plugins that add JSON-RPC routes.
+Each plugin needs to export a function Routes of type func(*abci_client.AbciClient) map[string]*rpc.RPCFunc.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "app-addresses-file",
+			Usage: `
+A file with the app addresses, separated by commas or newlines, in the same order as the app addresses argument,
+optionally followed by the addresses of new observers.
+When CometMock receives SIGHUP, or reload_apps is called without addresses, the apps are reloaded from this file.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "block-webhook-token",
+			Usage: `
+The bearer token of the block webhook, which produces a single block for each POST request to /webhook/block
+that sends the token in its Authorization header. Use env:<name> to read the token from the environment variable <name>,
+which keeps it out of the process list. If this is empty, the webhook is not served.
+Blocks can also be triggered by sending SIGUSR1 to CometMock, which needs no token.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "data-dir-export-root",
+			Usage: `
+The directory below which export_data_dir writes the history of the chain as the data directory of a CometBFT node.
+The directories given to export_data_dir are relative to it, and must not exist yet or be empty.
+If this is empty, export_data_dir is not served, since it writes to the disk of CometMock on behalf of any caller of the RPC.`,
+			Value: "",
+		},
+		&cli.Int64Flag{
+			Name: "broadcast-tx-commit-timeout",
+			Usage: `
+The time in milliseconds that broadcast_tx_commit waits for a transaction to be committed.
+If the transaction is not committed in time, the result of CheckTx is returned with a timeout error,
+like timeout_broadcast_tx_commit in the config of CometBFT.`,
+			Value: 10000,
+		},
+		&cli.IntFlag{
+			Name: "ws-max-subscription-clients",
+			Usage: `
+The maximum number of clients that can subscribe to events over websockets,
+like max_subscription_clients in the config of CometBFT.`,
+			Value: rpc_server.DefaultWebsocketConfig().MaxSubscriptionClients,
+		},
+		&cli.IntFlag{
+			Name: "ws-max-subscriptions-per-client",
+			Usage: `
+The maximum number of queries that a websocket connection can subscribe to,
+like max_subscriptions_per_client in the config of CometBFT.`,
+			Value: rpc_server.DefaultWebsocketConfig().MaxSubscriptionsPerClient,
+		},
+		&cli.IntFlag{
+			Name: "ws-buffer-size",
+			Usage: `
+The number of events buffered per websocket subscription, and the number of responses
+buffered per websocket connection. Subscriptions whose buffer is full are canceled.`,
+			Value: rpc_server.DefaultWebsocketConfig().SubscriptionBufferSize,
+		},
+		&cli.BoolFlag{
+			Name: "ws-close-on-slow-client",
+			Usage: `
+If this is true, websocket subscriptions whose events cannot be written within 10 seconds
+are canceled with the reason "slow client", like close_on_slow_client in the config of CometBFT.
+Otherwise, such events are dropped. Unlike in CometBFT, this is true by default.`,
+			Value: rpc_server.DefaultWebsocketConfig().CloseOnSlowClient,
+		},
+		&cli.Int64Flag{
+			Name: "ws-ping-period",
+			Usage: `
+The time in milliseconds after which websocket connections are pinged.
+Connections that do not answer within 10/9 of the period are closed, and their subscriptions are dropped.`,
+			Value: rpc_server.DefaultWebsocketConfig().PingPeriod.Milliseconds(),
+		},
+		&cli.IntFlag{
+			Name: "generate-validators",
+			Usage: `
+If this is >0, this many validators are generated and replace the validators in the genesis,
+instead of reading the validator keys from the node homes, which are then ignored.
+The first validators are connected to the apps at the given app addresses,
+and the remaining validators are placeholders without an app that sign blocks, but do not propose.`,
+			Value: 0,
+		},
+		&cli.StringFlag{
+			Name: "power-distribution",
+			Usage: `
+The voting powers of the generated validators, see generate-validators.
+Either equal, zipf (the power of the validator at index i is proportional to 1/(i+1)),
+or custom:p1,p2,... with one voting power per validator.`,
+			Value: "equal",
+		},
+		&cli.StringFlag{
+			Name: "executor-addresses",
+			Usage: `
+A comma-separated list of target=app_address pairs, which add redundant executors for validators.
+The target is the validator address or the index of the app of a validator in the app addresses.
+Executors are further instances of the app of the validator with the same validator identity:
+they execute every block and receive the same requests, but only the app of the validator signs,
+and the responses of the executors are compared with those of the app according to the determinism checks.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "interceptor-address",
+			Usage: `
+The address of an out-of-process interceptor plugin implementing the InterceptorService
+of proto/cometmock/interceptor/v1/interceptor.proto, e.g. tcp://127.0.0.1:22333.
+The plugin is called while each block is produced, and can mutate proposals, drop votes or veto blocks.`,
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name: "misbehaviour-rules",
+			Usage: `
+Rules by which validators misbehave automatically, e.g. to soak-test slashing over long runs.
+This is a comma-separated list of rules of the form validator=type@schedule, where validator is
+the address of a validator or random for a random validator whose key is known,
+type is one of DuplicateVote, Lunatic, Amnesia, Equivocation, and schedule is either every:N
+for every block whose height is a multiple of N, or p:P for each block with probability P,
+e.g. ABCD...=DuplicateVote@every:100,random=Equivocation@p:0.01.
+The rules can be changed at runtime with set_misbehaviour_rules.`,
+			Value: "",
+		},
+		&cli.Int64Flag{
+			Name: "misbehaviour-seed",
+			Usage: `
+The seed of the random misbehaviour rules, so that runs with the same seed misbehave at the same heights.
+If this is 0, a random seed is used, which is printed at startup.`,
+			Value: 0,
+		},
+		&cli.StringFlag{
+			Name: "log-level",
+			Usage: `
+The levels of the log lines that are logged, either debug, info, error or none for all modules,
+or a comma-separated list of module:level pairs, e.g. *:info,rpc-server:error,events:debug,
+where * stands for all modules that are not listed. The levels can be changed with set_log_level.`,
+			Value: logging.DefaultLevels,
+		},
+	}
+}
+
+// usage returns the usage of cometmock with the given flags, for the help and for errors about the arguments.
+func usage(flags []cli.Flag) string {
+	parts := make([]string, 0, len(flags)+5)
+	for _, flag := range flags {
+		parts = append(parts, fmt.Sprintf("[--%s=<value>]", flag.Names()[0]))
+	}
+	parts = append(parts, "<app-addresses>", "<genesis-file>", "<cometmock-listen-address>", "<node-homes>", "<abci-connection-mode>")
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cfg "github.com/cometbft/cometbft/config"
+	"github.com/spf13/viper"
+	"github.com/urfave/cli/v2"
+)
+
+// fromHomeDirsCommand runs CometMock over the node homes of a localnet, e.g. those created by `init`, Ignite
+// or `testnet init-files`, deriving the arguments of cometmock from the files in the homes.
+func fromHomeDirsCommand() *cli.Command {
+	argumentString := "[cometmock flags] <home-or-parent-folder>..."
+
+	return &cli.Command{
+		Name:  "from-home-dirs",
+		Usage: "Run CometMock over the node homes of a localnet, deriving its arguments from the files in the homes",
+		Description: `Takes node home folders, or folders that contain node home folders, e.g. the output folder of
+'simd testnet init-files' with its node0/simd, node1/simd, ... folders, and runs CometMock with one node per home:
+  - the genesis is read from the genesis_file in the config/config.toml of the first home, usually config/genesis.json,
+  - each home needs to have the priv_validator_key_file of its config, usually config/priv_validator_key.json,
+    unless it has a priv_validator_laddr for a remote signer,
+  - the address of the app of each home is the address in its config/app.toml, which Cosmos SDK apps listen on
+    when they are started without CometBFT, or the proxy_app of its config/config.toml if app.toml has none,
+  - the connection mode is the transport in the config/app.toml of the first home, or the abci of its config.toml,
+  - the RPC listens on the rpc.laddr of the first home.
+A folder is a home if it has a config/config.toml or config/genesis.json. The homes in a parent folder
+are found up to two levels deep, and are ordered by their paths.
+Flags of cometmock, e.g. --block-time, can be given in addition.`,
+		ArgsUsage: argumentString,
+		// the flags are passed on to startArgs, since the homes and the flags can be mixed
+		SkipFlagParsing: true,
+		Action: func(c *cli.Context) error {
+			for _, arg := range c.Args().Slice() {
+				if arg == "-h" || arg == "--help" {
+					return cli.ShowSubcommandHelp(c)
+				}
+			}
+
+			args, err := fromHomeDirsArgs(c.Args().Slice(), c.App.Flags)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("%v\nUsage: cometmock from-home-dirs %v", err, argumentString), 1)
+			}
+			fmt.Printf("Running cometmock %v\n", strings.Join(args, " "))
+			return c.App.RunContext(c.Context, append([]string{c.App.Name}, args...))
+		},
+	}
+}
+
+// fromHomeDirsArgs derives the arguments of cometmock from the node homes in the given folders,
+// where cometMockFlags are the flags of cometmock, which are passed on as they are.
+func fromHomeDirsArgs(args []string, cometMockFlags []cli.Flag) ([]string, error) {
+	cometMockFlagsByName := make(map[string]cli.Flag)
+	for _, flag := range cometMockFlags {
+		for _, name := range flag.Names() {
+			cometMockFlagsByName[name] = flag
+		}
+	}
+
+	var folders, passedOn []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			folders = append(folders, arg)
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		cometMockFlag, isCometMockFlag := cometMockFlagsByName[name]
+		if !isCometMockFlag {
+			return nil, fmt.Errorf("unknown flag %v", arg)
+		}
+		passedOn = append(passedOn, arg)
+		// boolean flags only have a value if it is given with =
+		if _, isBoolFlag := cometMockFlag.(*cli.BoolFlag); !hasValue && !isBoolFlag && i+1 < len(args) {
+			i++
+			passedOn = append(passedOn, args[i])
+		}
+	}
+	if len(folders) == 0 {
+		return nil, errors.New("no home folders given")
+	}
+
+	var homes []string
+	for _, folder := range folders {
+		found, err := findHomes(folder)
+		if err != nil {
+			return nil, err
+		}
+		homes = append(homes, found...)
+	}
+
+	var appAddresses []string
+	var transport string
+	for i, home := range homes {
+		config, err := readConfig(home)
+		if err != nil {
+			return nil, err
+		}
+		if config.PrivValidatorListenAddr == "" {
+			if _, err := os.Stat(config.PrivValidatorKeyFile()); err != nil {
+				return nil, fmt.Errorf("home %v has no key of a validator: %w", home, err)
+			}
+		}
+
+		appAddress, appTransport, err := readAppConfig(home)
+		if err != nil {
+			return nil, err
+		}
+		if appAddress == "" {
+			appAddress = config.ProxyApp
+		}
+		appAddresses = append(appAddresses, appAddress)
+		if i == 0 {
+			transport = appTransport
+		}
+	}
+
+	startCommandArgs := []string{"--home=" + strings.Join(homes, ","), "--proxy_app=" + strings.Join(appAddresses, ",")}
+	if transport != "" {
+		startCommandArgs = append(startCommandArgs, "--abci="+transport)
+	}
+	return startArgs(append(startCommandArgs, passedOn...), cometMockFlags)
+}
+
+// findHomes returns the folder if it is a node home, and otherwise the node homes in the folder
+// and in its subfolders, ordered by their paths.
+func findHomes(folder string) ([]string, error) {
+	info, err := os.Stat(folder)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%v is not a folder", folder)
+	}
+	if isHome(folder) {
+		return []string{folder}, nil
+	}
+
+	var homes []string
+	for _, pattern := range []string{"*", filepath.Join("*", "*")} {
+		matches, err := filepath.Glob(filepath.Join(folder, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if isHome(match) {
+				homes = append(homes, match)
+			}
+		}
+	}
+	if len(homes) == 0 {
+		return nil, fmt.Errorf("%v contains no node homes, which have a config/config.toml or config/genesis.json", folder)
+	}
+	sort.Strings(homes)
+	return homes, nil
+}
+
+// isHome returns whether the folder is a node home.
+func isHome(folder string) bool {
+	for _, file := range []string{cfg.DefaultConfigFileName, cfg.DefaultGenesisJSONName} {
+		if _, err := os.Stat(filepath.Join(folder, cfg.DefaultConfigDir, file)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readAppConfig reads the address and the transport that a Cosmos SDK app listens on for CometMock
+// from the config/app.toml of the home. They are empty if the home has no app.toml, or it does not set them.
+func readAppConfig(home string) (string, string, error) {
+	appConfigFile := filepath.Join(home, cfg.DefaultConfigDir, "app.toml")
+	if _, err := os.Stat(appConfigFile); errors.Is(err, os.ErrNotExist) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("error reading the app config of %v: %w", home, err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(appConfigFile)
+	if err := v.ReadInConfig(); err != nil {
+		return "", "", fmt.Errorf("error reading the app config of %v: %w", home, err)
+	}
+	return v.GetString("address"), v.GetString("transport"), nil
+}
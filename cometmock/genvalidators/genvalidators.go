@@ -0,0 +1,89 @@
+// Package genvalidators generates the keys and genesis validators of a mock network,
+// so that CometMock can be started with many validators without creating a node home for each of them.
+// The keys are derived from the index of the validator, so the validator addresses are the same in every run.
+package genvalidators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/types"
+)
+
+// PowerDistribution decides the voting powers of the generated validators.
+type PowerDistribution string
+
+const (
+	// all validators have the same voting power
+	PowerEqual PowerDistribution = "equal"
+	// the voting power of the validator at index i is proportional to 1/(i+1),
+	// which mimics the concentration of stake on real networks
+	PowerZipf PowerDistribution = "zipf"
+	// the voting powers are given explicitly, e.g. custom:100,50,25
+	PowerCustom PowerDistribution = "custom"
+)
+
+// the voting power of each validator with the equal distribution,
+// and of the first validator with the zipf distribution
+const basePower = 1000
+
+// ParsePowers parses a power distribution, which is either equal, zipf or custom:p1,p2,...,
+// and returns the voting powers of n validators.
+func ParsePowers(s string, n int) ([]int64, error) {
+	distribution, customPowers, _ := strings.Cut(s, ":")
+
+	powers := make([]int64, n)
+	switch PowerDistribution(distribution) {
+	case PowerEqual:
+		for i := range powers {
+			powers[i] = basePower
+		}
+	case PowerZipf:
+		for i := range powers {
+			// never let the power drop to 0, which would remove the validator
+			powers[i] = max(basePower/int64(i+1), 1)
+		}
+	case PowerCustom:
+		values := strings.Split(customPowers, ",")
+		if len(values) != n {
+			return nil, fmt.Errorf("got %d custom voting powers, but %d validators are generated", len(values), n)
+		}
+		for i, value := range values {
+			power, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || power <= 0 {
+				return nil, fmt.Errorf("invalid voting power %v, must be a positive integer", value)
+			}
+			powers[i] = power
+		}
+	default:
+		return nil, fmt.Errorf("invalid power distribution %v, must be one of %v, %v or %v:p1,p2,...", s, PowerEqual, PowerZipf, PowerCustom)
+	}
+	return powers, nil
+}
+
+// Generate generates n validators with the given voting powers.
+// It returns the keys of the validators, and the validators to put into the genesis.
+func Generate(n int, powers []int64) ([]types.PrivValidator, []types.GenesisValidator, error) {
+	if n <= 0 {
+		return nil, nil, fmt.Errorf("the number of validators to generate must be greater than 0, got %d", n)
+	}
+	if len(powers) != n {
+		return nil, nil, fmt.Errorf("got %d voting powers for %d validators", len(powers), n)
+	}
+
+	privVals := make([]types.PrivValidator, n)
+	genesisValidators := make([]types.GenesisValidator, n)
+	for i := 0; i < n; i++ {
+		privKey := ed25519.GenPrivKeyFromSecret([]byte(fmt.Sprintf("cometmock-validator-%d", i)))
+		privVals[i] = types.NewMockPVWithParams(privKey, false, false)
+		genesisValidators[i] = types.GenesisValidator{
+			Address: privKey.PubKey().Address(),
+			PubKey:  privKey.PubKey(),
+			Power:   powers[i],
+			Name:    fmt.Sprintf("validator-%d", i),
+		}
+	}
+	return privVals, genesisValidators, nil
+}
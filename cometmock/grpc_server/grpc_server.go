@@ -0,0 +1,183 @@
+package grpc_server
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/cometbft/cometbft/libs/log"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	controlv1 "github.com/informalsystems/CometMock/cometmock/proto/control/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ControlServer implements the gRPC ControlService
+// by forwarding the calls to an AbciClient.
+// It offers the same operations as the CometMock specific JSON-RPC endpoints.
+type ControlServer struct {
+	controlv1.UnimplementedControlServiceServer
+
+	client *abci_client.AbciClient
+}
+
+var _ controlv1.ControlServiceServer = (*ControlServer)(nil)
+
+func NewControlServer(client *abci_client.AbciClient) *ControlServer {
+	return &ControlServer{
+		client: client,
+	}
+}
+
+// StartGRPCServer starts serving the ControlService for the given client on the given address.
+// The address can optionally be prefixed by a protocol, e.g. tcp://127.0.0.1:22332.
+func StartGRPCServer(client *abci_client.AbciClient, listenAddr string, logger log.Logger) {
+	protocol, address := cmtnet.ProtocolAndAddress(listenAddr)
+	listener, err := net.Listen(protocol, address)
+	if err != nil {
+		panic(err)
+	}
+
+	server := grpc.NewServer()
+	controlv1.RegisterControlServiceServer(server, NewControlServer(client))
+
+	logger.Info("Starting gRPC control server on", "address", listenAddr)
+	if err := server.Serve(listener); err != nil {
+		logger.Error("Error serving gRPC server", "err", err)
+		panic(err)
+	}
+}
+
+func (s *ControlServer) AdvanceBlocks(ctx context.Context, req *controlv1.AdvanceBlocksRequest) (*controlv1.AdvanceBlocksResponse, error) {
+	if req.NumBlocks < 1 {
+		return nil, status.Error(codes.InvalidArgument, "num_blocks must be greater than 0")
+	}
+
+	err := s.client.RunEmptyBlocksWithOptions(int(req.NumBlocks), abci_client.BlockOptions{SkipEvents: req.SkipIndexing})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &controlv1.AdvanceBlocksResponse{
+		Height: s.client.LastBlockHeight(),
+	}, nil
+}
+
+func (s *ControlServer) AdvanceTime(ctx context.Context, req *controlv1.AdvanceTimeRequest) (*controlv1.AdvanceTimeResponse, error) {
+	duration := req.Duration.AsDuration()
+	if duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration to advance time by must not be negative")
+	}
+
+	newTime := s.client.TimeHandler.AdvanceTime(duration)
+	return &controlv1.AdvanceTimeResponse{
+		NewTime: timestamppb.New(newTime),
+	}, nil
+}
+
+func (s *ControlServer) SetSigningStatus(ctx context.Context, req *controlv1.SetSigningStatusRequest) (*controlv1.SetSigningStatusResponse, error) {
+	err := s.client.SetSigningStatus(req.PrivateKeyAddress, req.Signing)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &controlv1.SetSigningStatusResponse{
+		SigningStatus: s.client.GetSigningStatusMap(),
+	}, nil
+}
+
+func (s *ControlServer) GetSigningStatus(ctx context.Context, req *controlv1.GetSigningStatusRequest) (*controlv1.GetSigningStatusResponse, error) {
+	return &controlv1.GetSigningStatusResponse{
+		SigningStatus: s.client.GetSigningStatusMap(),
+	}, nil
+}
+
+func (s *ControlServer) CauseDoubleSign(ctx context.Context, req *controlv1.CauseDoubleSignRequest) (*controlv1.CauseDoubleSignResponse, error) {
+	err := s.client.CauseDoubleSign(req.PrivateKeyAddress)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &controlv1.CauseDoubleSignResponse{}, nil
+}
+
+func (s *ControlServer) CauseLightClientAttack(ctx context.Context, req *controlv1.CauseLightClientAttackRequest) (*controlv1.CauseLightClientAttackResponse, error) {
+	misbehaviourType, err := misbehaviourTypeFromProto(req.MisbehaviourType)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.client.CauseLightClientAttack(req.PrivateKeyAddress, misbehaviourType)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &controlv1.CauseLightClientAttackResponse{}, nil
+}
+
+func (s *ControlServer) GetValidators(ctx context.Context, req *controlv1.GetValidatorsRequest) (*controlv1.GetValidatorsResponse, error) {
+	height, validatorSet := s.client.NextValidators()
+	signingStatus := s.client.GetSigningStatusMap()
+	names := make(map[string]string)
+	for name, address := range s.client.ValidatorNames() {
+		names[address] = name
+	}
+
+	validators := make([]*controlv1.Validator, 0, validatorSet.Size())
+	for _, validator := range validatorSet.Validators {
+		address := validator.Address.String()
+		validators = append(validators, &controlv1.Validator{
+			Address:          address,
+			Name:             names[address],
+			VotingPower:      validator.VotingPower,
+			ProposerPriority: validator.ProposerPriority,
+			Signing:          signingStatus[address],
+		})
+	}
+	return &controlv1.GetValidatorsResponse{
+		Height:     height,
+		Validators: validators,
+	}, nil
+}
+
+func (s *ControlServer) GetTime(ctx context.Context, req *controlv1.GetTimeRequest) (*controlv1.GetTimeResponse, error) {
+	return &controlv1.GetTimeResponse{
+		Time: timestamppb.New(s.client.TimeHandler.PeekBlockTime(s.client.LastBlock.Time)),
+	}, nil
+}
+
+func (s *ControlServer) Resume(ctx context.Context, req *controlv1.ResumeRequest) (*controlv1.ResumeResponse, error) {
+	if err := s.client.Resume(req.AppAddresses); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &controlv1.ResumeResponse{}, nil
+}
+
+func (s *ControlServer) ReloadApps(ctx context.Context, req *controlv1.ReloadAppsRequest) (*controlv1.ReloadAppsResponse, error) {
+	var err error
+	if len(req.AppAddresses) == 0 {
+		err = s.client.ReloadAppsFromFile()
+	} else {
+		err = s.client.ReloadApps(req.AppAddresses)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &controlv1.ReloadAppsResponse{}, nil
+}
+
+// misbehaviourTypeFromProto converts the enum into the string representation
+// that is accepted by AbciClient.CauseLightClientAttack.
+func misbehaviourTypeFromProto(misbehaviourType controlv1.MisbehaviourType) (string, error) {
+	switch misbehaviourType {
+	case controlv1.MisbehaviourType_MISBEHAVIOUR_TYPE_EQUIVOCATION:
+		return "Equivocation", nil
+	case controlv1.MisbehaviourType_MISBEHAVIOUR_TYPE_LUNATIC:
+		return "Lunatic", nil
+	case controlv1.MisbehaviourType_MISBEHAVIOUR_TYPE_AMNESIA:
+		return "Amnesia", nil
+	default:
+		return "", errors.New("misbehaviour_type must be one of Equivocation, Lunatic, Amnesia")
+	}
+}
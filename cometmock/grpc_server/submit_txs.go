@@ -0,0 +1,263 @@
+package grpc_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	controlv1 "github.com/informalsystems/CometMock/cometmock/proto/control/v1"
+)
+
+// how long to wait for the accepted transactions to be included after the client closed its stream
+const submitTxsInclusionTimeout = 30 * time.Second
+
+// the capacity of the subscription to included transactions, per stream
+const submitTxsSubscriptionCapacity = 10000
+
+// counts the SubmitTxs streams, so that each stream subscribes with a unique name
+var submitTxsStreams uint64
+
+// txSubmitter handles a single SubmitTxs stream.
+type txSubmitter struct {
+	client *abci_client.AbciClient
+	stream controlv1.ControlService_SubmitTxsServer
+
+	// gRPC streams must not be sent to concurrently
+	sendMutex sync.Mutex
+
+	mutex sync.Mutex
+	// the indices of the accepted transactions that were not included yet, by hash
+	pending map[string][]uint64
+
+	// signals that transactions were queued and a block should be produced.
+	// Signals that arrive while a block is produced are coalesced into the next block.
+	blockNeeded chan struct{}
+}
+
+func (s *ControlServer) SubmitTxs(stream controlv1.ControlService_SubmitTxsServer) error {
+	client := s.client
+
+	subscriber := fmt.Sprintf("cometmock-grpc-submit-txs-%d", atomic.AddUint64(&submitTxsStreams, 1))
+	sub, err := client.EventBus.Subscribe(context.Background(), subscriber, types.EventQueryTx, submitTxsSubscriptionCapacity)
+	if err != nil {
+		return fmt.Errorf("error subscribing to transactions: %w", err)
+	}
+	defer func() {
+		if err := client.EventBus.UnsubscribeAll(context.Background(), subscriber); err != nil {
+			client.Logger.Error("Error unsubscribing SubmitTxs stream", "err", err)
+		}
+	}()
+
+	submitter := &txSubmitter{
+		client:      client,
+		stream:      stream,
+		pending:     make(map[string][]uint64),
+		blockNeeded: make(chan struct{}, 1),
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		submitter.watchInclusions(ctx, sub)
+	}()
+	if client.AutoIncludeTx {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			submitter.produceBlocks(ctx)
+		}()
+	}
+
+	err = submitter.receive()
+	if err == nil {
+		err = submitter.waitForInclusions(ctx)
+	}
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// receive checks and queues the transactions of the stream until the client closes it.
+func (t *txSubmitter) receive() error {
+	for index := uint64(0); ; index++ {
+		req, err := t.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := t.submit(index, req.Tx); err != nil {
+			return err
+		}
+	}
+}
+
+// submit checks the transaction with the given index in the stream and queues it if it is accepted.
+// Only errors from sending on the stream are returned, errors from CheckTx are sent to the client.
+func (t *txSubmitter) submit(index uint64, txBytes []byte) error {
+	tx := types.Tx(txBytes)
+
+	if err := t.client.CheckMempoolLimits(tx); err != nil {
+		return t.send(&controlv1.SubmitTxsResponse{
+			Index:  index,
+			Hash:   tx.Hash(),
+			Status: controlv1.TxStatus_TX_STATUS_ERROR,
+			Error:  err.Error(),
+		})
+	}
+
+	res := &abcitypes.ResponseCheckTx{Code: abcitypes.CodeTypeOK}
+	if !t.client.SkipCheckTx {
+		var err error
+		res, err = t.client.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+		if err != nil {
+			return t.send(&controlv1.SubmitTxsResponse{
+				Index:  index,
+				Hash:   tx.Hash(),
+				Status: controlv1.TxStatus_TX_STATUS_ERROR,
+				Error:  err.Error(),
+			})
+		}
+	}
+	if res.Code != abcitypes.CodeTypeOK {
+		return t.send(&controlv1.SubmitTxsResponse{
+			Index:  index,
+			Hash:   tx.Hash(),
+			Status: controlv1.TxStatus_TX_STATUS_REJECTED,
+			Code:   res.Code,
+			Log:    res.Log,
+		})
+	}
+
+	hash := string(tx.Hash())
+	t.mutex.Lock()
+	t.pending[hash] = append(t.pending[hash], index)
+	t.mutex.Unlock()
+
+	t.client.QueueTx(tx)
+	select {
+	case t.blockNeeded <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// produceBlocks produces a block whenever transactions were queued,
+// so that transactions received while a block is produced are included together in the next block.
+func (t *txSubmitter) produceBlocks(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.blockNeeded:
+			if err := t.client.RunBlock(); err != nil {
+				t.client.Logger.Error("Error producing block for SubmitTxs", "err", err)
+			}
+		}
+	}
+}
+
+// watchInclusions sends the results of the included transactions to the client.
+func (t *txSubmitter) watchInclusions(ctx context.Context, sub types.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Canceled():
+			if err := sub.Err(); err != nil {
+				t.client.Logger.Error("Stopped watching inclusions for SubmitTxs", "err", err)
+			}
+			return
+		case msg := <-sub.Out():
+			eventDataTx, ok := msg.Data().(types.EventDataTx)
+			if !ok {
+				continue
+			}
+			tx := types.Tx(eventDataTx.Tx)
+			hash := string(tx.Hash())
+
+			t.mutex.Lock()
+			indices := t.pending[hash]
+			delete(t.pending, hash)
+			t.mutex.Unlock()
+
+			for _, index := range indices {
+				err := t.send(&controlv1.SubmitTxsResponse{
+					Index:  index,
+					Hash:   tx.Hash(),
+					Status: controlv1.TxStatus_TX_STATUS_INCLUDED,
+					Code:   eventDataTx.Result.Code,
+					Log:    eventDataTx.Result.Log,
+					Height: eventDataTx.Height,
+				})
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// waitForInclusions waits until all accepted transactions were included,
+// and reports the transactions that were not included in time.
+func (t *txSubmitter) waitForInclusions(ctx context.Context) error {
+	deadline := time.NewTimer(submitTxsInclusionTimeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(100 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		t.mutex.Lock()
+		pending := len(t.pending)
+		t.mutex.Unlock()
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return t.reportNotIncluded()
+		case <-poll.C:
+		}
+	}
+}
+
+func (t *txSubmitter) reportNotIncluded() error {
+	t.mutex.Lock()
+	pending := t.pending
+	t.pending = make(map[string][]uint64)
+	t.mutex.Unlock()
+
+	for hash, indices := range pending {
+		for _, index := range indices {
+			err := t.send(&controlv1.SubmitTxsResponse{
+				Index:  index,
+				Hash:   []byte(hash),
+				Status: controlv1.TxStatus_TX_STATUS_NOT_INCLUDED,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *txSubmitter) send(res *controlv1.SubmitTxsResponse) error {
+	t.sendMutex.Lock()
+	defer t.sendMutex.Unlock()
+	return t.stream.Send(res)
+}
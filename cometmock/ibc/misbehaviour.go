@@ -0,0 +1,222 @@
+// Package ibc converts the artifacts of simulated attacks into the messages of ibc-go,
+// so that tests can submit them to the light client of the chain on a counterparty chain.
+package ibc
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/gogoproto/jsonpb"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MisbehaviourTypeURL is the type URL of the Misbehaviour of the 07-tendermint light client,
+// with which it is packed into an Any in MsgSubmitMisbehaviour or MsgUpdateClient.
+const MisbehaviourTypeURL = "/ibc.lightclients.tendermint.v1.Misbehaviour"
+
+// the format of chain ids with a revision number, like in ibc-go
+var revisionFormat = regexp.MustCompile(`^.*[^\n-]-{1}[1-9][0-9]*$`)
+
+// Height is the Height of ibc-go, which is a block height within a revision of the chain.
+type Height struct {
+	RevisionNumber uint64 `json:"revision_number,string"`
+	RevisionHeight uint64 `json:"revision_height,string"`
+}
+
+// NewHeight returns the height with the revision number of the chain id, like ibc-go.
+func NewHeight(chainID string, height int64) Height {
+	return Height{RevisionNumber: RevisionNumber(chainID), RevisionHeight: uint64(height)}
+}
+
+// RevisionNumber returns the revision number of the chain id, like ParseChainID of ibc-go:
+// chain ids of the form name-N have the revision number N, and all other chain ids have the revision number 0.
+func RevisionNumber(chainID string) uint64 {
+	if !revisionFormat.MatchString(chainID) {
+		return 0
+	}
+	revision, err := strconv.ParseUint(chainID[strings.LastIndex(chainID, "-")+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// Header is the Header of the 07-tendermint light client, which is a signed header with the validators
+// that signed it, and the height and validators that the light client trusts.
+type Header struct {
+	SignedHeader      *cmtproto.SignedHeader
+	ValidatorSet      *cmtproto.ValidatorSet
+	TrustedHeight     Height
+	TrustedValidators *cmtproto.ValidatorSet
+}
+
+// Misbehaviour is the Misbehaviour of the 07-tendermint light client, i.e. two conflicting headers at the same height.
+type Misbehaviour struct {
+	ClientID string
+	Header1  *Header
+	Header2  *Header
+}
+
+// NewMisbehaviour returns the misbehaviour for conflicting blocks of the chain with the given chain id,
+// for a light client that trusts the given height with the given validators,
+// i.e. the next validators of the header at that height.
+// The client id is deprecated in ibc-go, and may be empty.
+func NewMisbehaviour(
+	clientID string,
+	chainID string,
+	blocks *abci_client.ConflictingBlocks,
+	trustedHeight int64,
+	trustedValidators *types.ValidatorSet,
+) (*Misbehaviour, error) {
+	validatorSet, err := blocks.ValidatorSet.ToProto()
+	if err != nil {
+		return nil, fmt.Errorf("error converting validator set: %w", err)
+	}
+	trustedValidatorSet, err := trustedValidators.ToProto()
+	if err != nil {
+		return nil, fmt.Errorf("error converting trusted validator set: %w", err)
+	}
+
+	header := func(signedHeader *types.SignedHeader) *Header {
+		return &Header{
+			SignedHeader:      signedHeader.ToProto(),
+			ValidatorSet:      validatorSet,
+			TrustedHeight:     NewHeight(chainID, trustedHeight),
+			TrustedValidators: trustedValidatorSet,
+		}
+	}
+	return &Misbehaviour{
+		ClientID: clientID,
+		Header1:  header(blocks.SignedHeaderA),
+		Header2:  header(blocks.SignedHeaderB),
+	}, nil
+}
+
+// Marshal returns the protobuf encoding of the misbehaviour, which is the value of the Any that packs it:
+//
+//	message Misbehaviour {
+//	  string client_id = 1 [deprecated = true];
+//	  Header header_1 = 2;
+//	  Header header_2 = 3;
+//	}
+func (m *Misbehaviour) Marshal() ([]byte, error) {
+	var b []byte
+	if m.ClientID != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.ClientID)
+	}
+	for index, header := range []*Header{m.Header1, m.Header2} {
+		bz, err := header.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, protowire.Number(index+2), protowire.BytesType)
+		b = protowire.AppendBytes(b, bz)
+	}
+	return b, nil
+}
+
+// MarshalJSON returns the misbehaviour packed into an Any, in the JSON encoding of protobuf
+// that is used for the messages of transactions of Cosmos SDK chains, e.g. for the misbehaviour of MsgSubmitMisbehaviour.
+func (m *Misbehaviour) MarshalJSON() ([]byte, error) {
+	header1, err := m.Header1.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	header2, err := m.Header2.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Type     string          `json:"@type"`
+		ClientID string          `json:"client_id"`
+		Header1  json.RawMessage `json:"header_1"`
+		Header2  json.RawMessage `json:"header_2"`
+	}{
+		Type:     MisbehaviourTypeURL,
+		ClientID: m.ClientID,
+		Header1:  header1,
+		Header2:  header2,
+	})
+}
+
+// Marshal returns the protobuf encoding of the header:
+//
+//	message Header {
+//	  tendermint.types.SignedHeader signed_header = 1;
+//	  tendermint.types.ValidatorSet validator_set = 2;
+//	  ibc.core.client.v1.Height trusted_height = 3;
+//	  tendermint.types.ValidatorSet trusted_validators = 4;
+//	}
+//
+//	message Height {
+//	  uint64 revision_number = 1;
+//	  uint64 revision_height = 2;
+//	}
+func (h *Header) Marshal() ([]byte, error) {
+	signedHeader, err := h.SignedHeader.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	validatorSet, err := h.ValidatorSet.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	trustedValidators, err := h.TrustedValidators.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var height []byte
+	height = protowire.AppendTag(height, 1, protowire.VarintType)
+	height = protowire.AppendVarint(height, h.TrustedHeight.RevisionNumber)
+	height = protowire.AppendTag(height, 2, protowire.VarintType)
+	height = protowire.AppendVarint(height, h.TrustedHeight.RevisionHeight)
+
+	var b []byte
+	for index, bz := range [][]byte{signedHeader, validatorSet, height, trustedValidators} {
+		b = protowire.AppendTag(b, protowire.Number(index+1), protowire.BytesType)
+		b = protowire.AppendBytes(b, bz)
+	}
+	return b, nil
+}
+
+// MarshalJSON returns the header in the JSON encoding of protobuf.
+func (h *Header) MarshalJSON() ([]byte, error) {
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	marshal := func(msg proto.Message) (json.RawMessage, error) {
+		s, err := marshaler.MarshalToString(msg)
+		return json.RawMessage(s), err
+	}
+
+	signedHeader, err := marshal(h.SignedHeader)
+	if err != nil {
+		return nil, err
+	}
+	validatorSet, err := marshal(h.ValidatorSet)
+	if err != nil {
+		return nil, err
+	}
+	trustedValidators, err := marshal(h.TrustedValidators)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		SignedHeader      json.RawMessage `json:"signed_header"`
+		ValidatorSet      json.RawMessage `json:"validator_set"`
+		TrustedHeight     Height          `json:"trusted_height"`
+		TrustedValidators json.RawMessage `json:"trusted_validators"`
+	}{
+		SignedHeader:      signedHeader,
+		ValidatorSet:      validatorSet,
+		TrustedHeight:     h.TrustedHeight,
+		TrustedValidators: trustedValidators,
+	})
+}
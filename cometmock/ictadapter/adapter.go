@@ -0,0 +1,65 @@
+// Package ictadapter configures chains of interchaintest (https://github.com/strangelove-ventures/interchaintest)
+// to run CometMock in place of CometBFT, in images of apps built with Dockerfile.interchaintest,
+// whose binary runs `cometmock wrap`. It does not depend on interchaintest, so that suites can use it
+// with any version of interchaintest: the values it returns are assigned to the fields of the chain config,
+// see "Using CometMock with interchaintest" in the README.
+package ictadapter
+
+import (
+	"fmt"
+)
+
+// The defaults of the Adapter, which match the config that interchaintest writes for the nodes.
+const (
+	DefaultAppAddress     = "tcp://127.0.0.1:26658"
+	DefaultConnectionMode = "grpc"
+)
+
+// Adapter gives the settings of a chain of interchaintest that make its node run CometMock.
+// The zero value is ready to use.
+type Adapter struct {
+	// the flags of cometmock that CometMock is started with, e.g. --block-time=1000
+	Flags []string
+	// the address on which the app listens for CometMock inside the container of the node,
+	// DefaultAppAddress if empty
+	AppAddress string
+	// the connection mode of CometMock to the app, socket or grpc, DefaultConnectionMode if empty
+	ConnectionMode string
+}
+
+// AdditionalStartArgs returns the arguments to append to the AdditionalStartArgs of the chain config.
+// `cometmock wrap` passes the flags of cometmock to CometMock, and all other flags to the app.
+func (a Adapter) AdditionalStartArgs() []string {
+	return append([]string(nil), a.Flags...)
+}
+
+// ConfigToml returns the overrides of config/config.toml, to add to the ConfigFileOverrides of the chain config
+// as a testutil.Toml. They make the app listen on the address that CometMock connects to.
+func (a Adapter) ConfigToml() map[string]interface{} {
+	appAddress := a.AppAddress
+	if appAddress == "" {
+		appAddress = DefaultAppAddress
+	}
+	connectionMode := a.ConnectionMode
+	if connectionMode == "" {
+		connectionMode = DefaultConnectionMode
+	}
+	return map[string]interface{}{
+		"proxy_app": appAddress,
+		"abci":      connectionMode,
+	}
+}
+
+// Validate returns an error if a chain with the given numbers of validators and full nodes
+// cannot run CometMock. Each node runs its own CometMock, which mocks all validators of the chain,
+// so the chain needs exactly one validator and no full nodes.
+func (a Adapter) Validate(numValidators, numFullNodes int) error {
+	if numValidators != 1 || numFullNodes != 0 {
+		return fmt.Errorf("CometMock needs a chain with 1 validator and no full nodes, but got %d validators and %d full nodes",
+			numValidators, numFullNodes)
+	}
+	if a.ConnectionMode != "" && a.ConnectionMode != "socket" && a.ConnectionMode != "grpc" {
+		return fmt.Errorf("invalid connection mode %v, must be either socket or grpc", a.ConnectionMode)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package interceptor calls out-of-process plugins implementing the InterceptorService
+// from the block hooks of an AbciClient, so that plugins written in any language can
+// mutate proposals, drop votes or veto blocks, see proto/cometmock/interceptor/v1/interceptor.proto.
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	interceptorv1 "github.com/informalsystems/CometMock/cometmock/proto/interceptor/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// how long a call to the plugin may take before the block fails
+const callTimeout = 10 * time.Second
+
+// Interceptor is a connection to an interceptor plugin.
+type Interceptor struct {
+	conn   *grpc.ClientConn
+	client interceptorv1.InterceptorServiceClient
+}
+
+// Dial connects to the interceptor plugin at the given address.
+// The address can optionally be prefixed by a protocol, e.g. tcp://127.0.0.1:22333 or unix:///tmp/interceptor.sock.
+func Dial(address string) (*Interceptor, error) {
+	protocol, addr := cmtnet.ProtocolAndAddress(address)
+	target := addr
+	if protocol == "unix" {
+		target = "unix://" + addr
+	}
+
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to interceptor %v: %w", address, err)
+	}
+	return &Interceptor{
+		conn:   conn,
+		client: interceptorv1.NewInterceptorServiceClient(conn),
+	}, nil
+}
+
+// Close closes the connection to the plugin.
+func (i *Interceptor) Close() error {
+	return i.conn.Close()
+}
+
+// BlockHooks returns the block hooks that call the plugin, to be registered with AbciClient.AddBlockHooks.
+func (i *Interceptor) BlockHooks() abci_client.BlockHooks {
+	return abci_client.BlockHooks{
+		BeforeProposal:      i.beforeProposal,
+		FilterVotes:         i.filterVotes,
+		BeforeFinalizeBlock: i.beforeFinalizeBlock,
+		AfterCommit:         i.afterCommit,
+	}
+}
+
+// call calls a method of the plugin, and treats the Unimplemented status code
+// as a response that leaves the block untouched, which is signalled by returning false.
+func call[Req, Res any](method string, req *Req, send func(ctx context.Context, req *Req, opts ...grpc.CallOption) (*Res, error)) (*Res, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	res, err := send(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error calling %v of interceptor: %w", method, err)
+	}
+	return res, true, nil
+}
+
+func vetoError(method string, reason string) error {
+	return fmt.Errorf("%w by interceptor in %v: %v", abci_client.ErrBlockVetoed, method, reason)
+}
+
+func (i *Interceptor) beforeProposal(height int64, proposer *types.Validator, txs *types.Txs) error {
+	res, ok, err := call("BeforeProposal", &interceptorv1.BeforeProposalRequest{
+		Height:          height,
+		ProposerAddress: proposer.Address,
+		Txs:             txs.ToSliceOfBytes(),
+	}, i.client.BeforeProposal)
+	if err != nil || !ok {
+		return err
+	}
+
+	if res.Veto {
+		return vetoError("BeforeProposal", res.VetoReason)
+	}
+	if res.ReplaceTxs {
+		*txs = types.ToTxs(res.Txs)
+	}
+	return nil
+}
+
+func (i *Interceptor) filterVotes(block *types.Block, votes []*types.Vote) error {
+	res, ok, err := call("FilterVotes", &interceptorv1.FilterVotesRequest{
+		Height:          block.Height,
+		BlockHash:       block.Hash(),
+		SignerAddresses: signerAddresses(votes),
+	}, i.client.FilterVotes)
+	if err != nil || !ok {
+		return err
+	}
+
+	dropped := make(map[string]bool, len(res.DroppedAddresses))
+	for _, address := range res.DroppedAddresses {
+		dropped[string(address)] = true
+	}
+	for index, vote := range votes {
+		if vote != nil && dropped[string(vote.ValidatorAddress)] {
+			votes[index] = nil
+		}
+	}
+	return nil
+}
+
+func (i *Interceptor) beforeFinalizeBlock(block *types.Block, commit *types.ExtendedCommit) error {
+	signers := make([][]byte, 0, len(commit.ExtendedSignatures))
+	for _, sig := range commit.ExtendedSignatures {
+		if sig.BlockIDFlag == types.BlockIDFlagCommit {
+			signers = append(signers, sig.ValidatorAddress)
+		}
+	}
+
+	res, ok, err := call("BeforeFinalizeBlock", &interceptorv1.BeforeFinalizeBlockRequest{
+		Height:          block.Height,
+		BlockHash:       block.Hash(),
+		ProposerAddress: block.ProposerAddress,
+		Time:            timestamppb.New(block.Time),
+		Txs:             block.Txs.ToSliceOfBytes(),
+		SignerAddresses: signers,
+	}, i.client.BeforeFinalizeBlock)
+	if err != nil || !ok {
+		return err
+	}
+
+	if res.Veto {
+		return vetoError("BeforeFinalizeBlock", res.VetoReason)
+	}
+	return nil
+}
+
+func (i *Interceptor) afterCommit(block *types.Block, response *abcitypes.ResponseFinalizeBlock) error {
+	txCodes := make([]uint32, len(response.TxResults))
+	for index, txResult := range response.TxResults {
+		txCodes[index] = txResult.Code
+	}
+
+	_, _, err := call("AfterCommit", &interceptorv1.AfterCommitRequest{
+		Height:    block.Height,
+		BlockHash: block.Hash(),
+		AppHash:   response.AppHash,
+		TxCodes:   txCodes,
+	}, i.client.AfterCommit)
+	return err
+}
+
+// signerAddresses returns the addresses of the validators whose votes are not nil.
+func signerAddresses(votes []*types.Vote) [][]byte {
+	addresses := make([][]byte, 0, len(votes))
+	for _, vote := range votes {
+		if vote != nil {
+			addresses = append(addresses, vote.ValidatorAddress)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,260 @@
+package legacy_abci
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cometbft/cometbft/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the methods in the oneofs of Request and Response in Tendermint v0.34
+// that no longer exist in CometBFT v0.38. The messages of these methods are encoded by hand,
+// since their Go types are not part of CometBFT v0.38.
+const (
+	requestBeginBlockField = 7
+	requestDeliverTxField  = 9
+	requestEndBlockField   = 10
+
+	responseExceptionField  = 1
+	responseBeginBlockField = 8
+	responseDeliverTxField  = 10
+	responseEndBlockField   = 11
+	responseCommitField     = 12
+)
+
+// errException marks errors for exceptions returned by the app, which leave the connection intact.
+var errException = errors.New("app returned an exception")
+
+var noDeadline time.Time
+
+// appendMessage appends the encoded message as the given field.
+func appendMessage(b []byte, field int, msg []byte) []byte {
+	b = protowire.AppendTag(b, protowire.Number(field), protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// appendVarint appends the value as the given field, omitting it if it is the default value like proto3 does.
+func appendVarint(b []byte, field int, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, protowire.Number(field), protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// consumeFields calls handle for each field of the encoded message, with the value of varint fields
+// and the bytes of length-delimited fields. Fields of other types are skipped.
+func consumeFields(bz []byte, handle func(field int, value uint64, msg []byte) error) error {
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(bz)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			bz = bz[n:]
+			if err := handle(int(num), value, nil); err != nil {
+				return err
+			}
+		case protowire.BytesType:
+			msg, n := protowire.ConsumeBytes(bz)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			bz = bz[n:]
+			if err := handle(int(num), 0, msg); err != nil {
+				return err
+			}
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, bz)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			bz = bz[n:]
+		}
+	}
+	return nil
+}
+
+// consumeResponse returns the field number and the encoded message of the method in the oneof of a Response.
+// Exceptions are returned as errors.
+func consumeResponse(bz []byte) (int, []byte, error) {
+	field := 0
+	var res []byte
+	err := consumeFields(bz, func(num int, _ uint64, msg []byte) error {
+		field, res = num, msg
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if field == responseExceptionField {
+		exception := &abcitypes.ResponseException{}
+		if err := exception.Unmarshal(res); err != nil {
+			return 0, nil, err
+		}
+		return 0, nil, fmt.Errorf("%w: %v", errException, exception.Error)
+	}
+	return field, res, nil
+}
+
+// encodeRequestBeginBlock encodes the RequestBeginBlock of Tendermint v0.34 for the block:
+//
+//	message RequestBeginBlock {
+//	  bytes hash = 1;
+//	  tendermint.types.Header header = 2;
+//	  LastCommitInfo last_commit_info = 3;
+//	  repeated Evidence byzantine_validators = 4;
+//	}
+//
+// Evidence has the same fields as Misbehavior.
+func encodeRequestBeginBlock(req *abcitypes.RequestFinalizeBlock, header *types.Header) ([]byte, error) {
+	b := appendMessage(nil, 1, req.Hash)
+
+	headerBz, err := header.ToProto().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	b = appendMessage(b, 2, headerBz)
+	b = appendMessage(b, 3, encodeLastCommitInfo(req.DecidedLastCommit))
+
+	for _, misbehavior := range req.Misbehavior {
+		bz, err := misbehavior.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = appendMessage(b, 4, bz)
+	}
+	return b, nil
+}
+
+// encodeLastCommitInfo encodes the LastCommitInfo of Tendermint v0.34:
+//
+//	message LastCommitInfo {
+//	  int32 round = 1;
+//	  repeated VoteInfo votes = 2;
+//	}
+//
+//	message VoteInfo {
+//	  Validator validator = 1;
+//	  bool signed_last_block = 2;
+//	}
+//
+// Validator has the same fields as in CometBFT v0.38.
+func encodeLastCommitInfo(info abcitypes.CommitInfo) []byte {
+	b := appendVarint(nil, 1, uint64(info.Round))
+	for _, vote := range info.Votes {
+		var validator []byte
+		validator = appendMessage(validator, 1, vote.Validator.Address)
+		validator = appendVarint(validator, 3, uint64(vote.Validator.Power))
+
+		voteInfo := appendMessage(nil, 1, validator)
+		if vote.BlockIdFlag == cmtproto.BlockIDFlagCommit {
+			voteInfo = appendVarint(voteInfo, 2, 1)
+		}
+		b = appendMessage(b, 2, voteInfo)
+	}
+	return b
+}
+
+// encodeRequestDeliverTx encodes the RequestDeliverTx of Tendermint v0.34, which has the transaction as field 1.
+func encodeRequestDeliverTx(tx []byte) []byte {
+	return appendMessage(nil, 1, tx)
+}
+
+// encodeRequestEndBlock encodes the RequestEndBlock of Tendermint v0.34, which has the height as field 1.
+func encodeRequestEndBlock(height int64) []byte {
+	return appendVarint(nil, 1, uint64(height))
+}
+
+// decodeResponseBeginBlock decodes the ResponseBeginBlock of Tendermint v0.34, which has the events as field 1.
+func decodeResponseBeginBlock(bz []byte) ([]abcitypes.Event, error) {
+	events := make([]abcitypes.Event, 0)
+	err := consumeFields(bz, func(field int, _ uint64, msg []byte) error {
+		if field != 1 {
+			return nil
+		}
+		event := abcitypes.Event{}
+		if err := event.Unmarshal(msg); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+type legacyResponseEndBlock struct {
+	validatorUpdates      []abcitypes.ValidatorUpdate
+	consensusParamUpdates *cmtproto.ConsensusParams
+	events                []abcitypes.Event
+}
+
+// decodeResponseEndBlock decodes the ResponseEndBlock of Tendermint v0.34:
+//
+//	message ResponseEndBlock {
+//	  repeated ValidatorUpdate validator_updates = 1;
+//	  ConsensusParams consensus_param_updates = 2;
+//	  repeated Event events = 3;
+//	}
+//
+// The consensus params have the same fields as in CometBFT v0.38, which adds the ABCI params.
+func decodeResponseEndBlock(bz []byte) (*legacyResponseEndBlock, error) {
+	res := &legacyResponseEndBlock{
+		validatorUpdates: make([]abcitypes.ValidatorUpdate, 0),
+		events:           make([]abcitypes.Event, 0),
+	}
+	err := consumeFields(bz, func(field int, _ uint64, msg []byte) error {
+		switch field {
+		case 1:
+			update := abcitypes.ValidatorUpdate{}
+			if err := update.Unmarshal(msg); err != nil {
+				return err
+			}
+			res.validatorUpdates = append(res.validatorUpdates, update)
+		case 2:
+			res.consensusParamUpdates = &cmtproto.ConsensusParams{}
+			return res.consensusParamUpdates.Unmarshal(msg)
+		case 3:
+			event := abcitypes.Event{}
+			if err := event.Unmarshal(msg); err != nil {
+				return err
+			}
+			res.events = append(res.events, event)
+		}
+		return nil
+	})
+	return res, err
+}
+
+type legacyResponseCommit struct {
+	data         []byte
+	retainHeight int64
+}
+
+// decodeResponseCommit decodes the ResponseCommit of Tendermint v0.34,
+// which has the app hash as field 2 and the retain height as field 3.
+func decodeResponseCommit(bz []byte) (*legacyResponseCommit, error) {
+	res := &legacyResponseCommit{}
+	err := consumeFields(bz, func(field int, value uint64, msg []byte) error {
+		switch field {
+		case 2:
+			res.data = msg
+		case 3:
+			res.retainHeight = int64(value)
+		}
+		return nil
+	})
+	return res, err
+}
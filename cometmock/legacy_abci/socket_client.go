@@ -0,0 +1,528 @@
+// Package legacy_abci connects to apps that speak the ABCI socket protocol of Tendermint v0.34,
+// which has BeginBlock, DeliverTx and EndBlock instead of PrepareProposal, ProcessProposal and FinalizeBlock.
+// Its SocketClient implements the ABCI client interface of CometBFT v0.38, so that legacy apps
+// can be driven like any other app.
+package legacy_abci
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	abcicli "github.com/cometbft/cometbft/abci/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	"github.com/cometbft/cometbft/libs/service"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/utils"
+)
+
+// the maximum size of a response, like in the socket server of CometBFT
+const maxResponseSize = 104857600 // 100MB
+
+// SocketClient is an ABCI client for apps that speak the socket protocol of Tendermint v0.34.
+//
+// Methods whose messages did not change since v0.34, like Info, InitChain, Query, CheckTx and the snapshot methods,
+// are sent as they are. FinalizeBlock is translated into BeginBlock, DeliverTx for each transaction, EndBlock and Commit,
+// since the app hash is only returned by Commit in v0.34. The following Commit does not reach the app.
+// PrepareProposal returns the transactions of the request, and ProcessProposal and VerifyVoteExtension accept,
+// since legacy apps do not take part in proposals or vote extensions.
+//
+// Requests are sent one at a time: each request is followed by a flush, and the response is awaited
+// before the next request is sent.
+type SocketClient struct {
+	service.BaseService
+
+	addr string
+
+	mtx    sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	err    error
+	resCb  abcicli.Callback
+
+	// the chain id from InitChain, which is part of the header in BeginBlock
+	chainID string
+	// the app hash after the last block, for headers that are not passed in the context
+	lastAppHash []byte
+	// the retain height returned by the Commit that was sent as part of the last FinalizeBlock
+	retainHeight int64
+}
+
+var _ abcicli.Client = (*SocketClient)(nil)
+
+// NewSocketClient returns a client for the legacy app at the given address.
+// The address can optionally be prefixed by a protocol, e.g. tcp://127.0.0.1:26658 or unix:///tmp/app.sock.
+// Starting the client fails if the app is not reachable.
+func NewSocketClient(addr string) *SocketClient {
+	cli := &SocketClient{addr: addr}
+	cli.BaseService = *service.NewBaseService(nil, "legacySocketClient", cli)
+	return cli
+}
+
+// OnStart implements service.Service by connecting to the app.
+func (cli *SocketClient) OnStart() error {
+	conn, err := cmtnet.Connect(cli.addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to legacy app %v: %w", cli.addr, err)
+	}
+
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+	cli.conn = conn
+	cli.reader = bufio.NewReader(conn)
+	cli.writer = bufio.NewWriter(conn)
+	return nil
+}
+
+// OnStop implements service.Service by closing the connection to the app.
+func (cli *SocketClient) OnStop() {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+	if cli.conn != nil {
+		cli.conn.Close()
+	}
+}
+
+// Error returns the error that broke the connection to the app, if any.
+func (cli *SocketClient) Error() error {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+	return cli.err
+}
+
+// SetResponseCallback sets the callback that is invoked for the responses to CheckTxAsync.
+func (cli *SocketClient) SetResponseCallback(resCb abcicli.Callback) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+	cli.resCb = resCb
+}
+
+// Flush sends a flush request and waits for the response.
+func (cli *SocketClient) Flush(ctx context.Context) error {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	flush, err := abcitypes.ToRequestFlush().Marshal()
+	if err != nil {
+		return err
+	}
+	return cli.exchange(ctx, flush, false, func(_ []byte) error { return nil })
+}
+
+// exchange writes the encoded request, followed by a flush request if withFlush is true,
+// and reads the response, which is passed to handle, and the response to the flush.
+// It should only be called after locking the mutex.
+// Errors on the connection are stored, and make all later requests fail,
+// since the responses could not be matched to the requests anymore.
+func (cli *SocketClient) exchange(ctx context.Context, req []byte, withFlush bool, handle func(res []byte) error) error {
+	if cli.err != nil {
+		return cli.err
+	}
+	if cli.conn == nil {
+		return errors.New("legacy socket client is not started")
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		cli.conn.SetDeadline(deadline)
+		defer cli.conn.SetDeadline(noDeadline)
+	}
+
+	err := cli.roundTrip(req, withFlush, handle)
+	if err != nil && !errors.Is(err, errException) {
+		cli.err = fmt.Errorf("connection to legacy app %v broke: %w", cli.addr, err)
+		return cli.err
+	}
+	return err
+}
+
+func (cli *SocketClient) roundTrip(req []byte, withFlush bool, handle func(res []byte) error) error {
+	if err := writeDelimited(cli.writer, req); err != nil {
+		return err
+	}
+	if withFlush {
+		flush, err := abcitypes.ToRequestFlush().Marshal()
+		if err != nil {
+			return err
+		}
+		if err := writeDelimited(cli.writer, flush); err != nil {
+			return err
+		}
+	}
+	if err := cli.writer.Flush(); err != nil {
+		return err
+	}
+
+	res, err := readDelimited(cli.reader)
+	if err != nil {
+		return err
+	}
+	// an exception is returned instead of the response, but the flush is still answered
+	handleErr := handle(res)
+	if withFlush {
+		if _, err := readDelimited(cli.reader); err != nil {
+			return err
+		}
+	}
+	return handleErr
+}
+
+// request sends a request whose messages are the same in v0.34 and v0.38, and returns the response.
+func (cli *SocketClient) request(ctx context.Context, req *abcitypes.Request) (*abcitypes.Response, error) {
+	bz, err := req.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &abcitypes.Response{}
+	err = cli.exchange(ctx, bz, true, func(bz []byte) error {
+		if err := res.Unmarshal(bz); err != nil {
+			return err
+		}
+		if exception := res.GetException(); exception != nil {
+			return fmt.Errorf("%w: %v", errException, exception.Error)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// legacyRequest sends a request that only exists in v0.34, which is encoded as the given field of Request,
+// and returns the encoded message of the response, which is expected to be the given field of Response.
+func (cli *SocketClient) legacyRequest(ctx context.Context, reqField int, req []byte, resField int) ([]byte, error) {
+	var res []byte
+	err := cli.exchange(ctx, appendMessage(nil, reqField, req), true, func(bz []byte) error {
+		field, msg, err := consumeResponse(bz)
+		if err != nil {
+			return err
+		}
+		if field != resField {
+			return fmt.Errorf("expected response with field %d, got %d", resField, field)
+		}
+		res = msg
+		return nil
+	})
+	return res, err
+}
+
+func unexpectedResponse(method string, res *abcitypes.Response) error {
+	return fmt.Errorf("unexpected response to %v: %T", method, res.Value)
+}
+
+// Echo implements abcicli.Client.
+func (cli *SocketClient) Echo(ctx context.Context, msg string) (*abcitypes.ResponseEcho, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestEcho(msg))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetEcho() == nil {
+		return nil, unexpectedResponse("Echo", res)
+	}
+	return res.GetEcho(), nil
+}
+
+// Info implements abcicli.Client.
+func (cli *SocketClient) Info(ctx context.Context, req *abcitypes.RequestInfo) (*abcitypes.ResponseInfo, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestInfo(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetInfo() == nil {
+		return nil, unexpectedResponse("Info", res)
+	}
+	cli.lastAppHash = res.GetInfo().LastBlockAppHash
+	return res.GetInfo(), nil
+}
+
+// Query implements abcicli.Client.
+func (cli *SocketClient) Query(ctx context.Context, req *abcitypes.RequestQuery) (*abcitypes.ResponseQuery, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestQuery(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetQuery() == nil {
+		return nil, unexpectedResponse("Query", res)
+	}
+	return res.GetQuery(), nil
+}
+
+// CheckTx implements abcicli.Client.
+func (cli *SocketClient) CheckTx(ctx context.Context, req *abcitypes.RequestCheckTx) (*abcitypes.ResponseCheckTx, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestCheckTx(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetCheckTx() == nil {
+		return nil, unexpectedResponse("CheckTx", res)
+	}
+	return res.GetCheckTx(), nil
+}
+
+// CheckTxAsync implements abcicli.Client. The request is sent synchronously,
+// and the returned ReqRes is already done.
+func (cli *SocketClient) CheckTxAsync(ctx context.Context, req *abcitypes.RequestCheckTx) (*abcicli.ReqRes, error) {
+	res, err := cli.CheckTx(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	reqRes := abcicli.NewReqRes(abcitypes.ToRequestCheckTx(req))
+	reqRes.Response = abcitypes.ToResponseCheckTx(res)
+	reqRes.Done()
+
+	cli.mtx.Lock()
+	resCb := cli.resCb
+	cli.mtx.Unlock()
+	if resCb != nil {
+		resCb(reqRes.Request, reqRes.Response)
+	}
+	reqRes.InvokeCallback()
+	return reqRes, nil
+}
+
+// InitChain implements abcicli.Client.
+func (cli *SocketClient) InitChain(ctx context.Context, req *abcitypes.RequestInitChain) (*abcitypes.ResponseInitChain, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestInitChain(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetInitChain() == nil {
+		return nil, unexpectedResponse("InitChain", res)
+	}
+	cli.chainID = req.ChainId
+	cli.lastAppHash = res.GetInitChain().AppHash
+	return res.GetInitChain(), nil
+}
+
+// PrepareProposal implements abcicli.Client by returning the transactions of the request
+// that fit into the maximum size, like apps that do not change proposals.
+func (cli *SocketClient) PrepareProposal(_ context.Context, req *abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error) {
+	txs := make([][]byte, 0, len(req.Txs))
+	var size int64
+	for _, tx := range req.Txs {
+		size += int64(len(tx))
+		if req.MaxTxBytes > 0 && size > req.MaxTxBytes {
+			break
+		}
+		txs = append(txs, tx)
+	}
+	return &abcitypes.ResponsePrepareProposal{Txs: txs}, nil
+}
+
+// ProcessProposal implements abcicli.Client by accepting the proposal.
+func (cli *SocketClient) ProcessProposal(_ context.Context, _ *abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error) {
+	return &abcitypes.ResponseProcessProposal{Status: abcitypes.ResponseProcessProposal_ACCEPT}, nil
+}
+
+// ExtendVote implements abcicli.Client by returning an empty vote extension.
+func (cli *SocketClient) ExtendVote(_ context.Context, _ *abcitypes.RequestExtendVote) (*abcitypes.ResponseExtendVote, error) {
+	return &abcitypes.ResponseExtendVote{}, nil
+}
+
+// VerifyVoteExtension implements abcicli.Client by accepting the vote extension.
+func (cli *SocketClient) VerifyVoteExtension(_ context.Context, _ *abcitypes.RequestVerifyVoteExtension) (*abcitypes.ResponseVerifyVoteExtension, error) {
+	return &abcitypes.ResponseVerifyVoteExtension{Status: abcitypes.ResponseVerifyVoteExtension_ACCEPT}, nil
+}
+
+// FinalizeBlock implements abcicli.Client by sending BeginBlock, DeliverTx for each transaction, EndBlock and Commit.
+// The header in BeginBlock is taken from the context if it was added with utils.ContextWithHeader,
+// and is otherwise made up from the request and the state of the client.
+// Since the block is committed right away, the app cannot roll it back.
+func (cli *SocketClient) FinalizeBlock(ctx context.Context, req *abcitypes.RequestFinalizeBlock) (*abcitypes.ResponseFinalizeBlock, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	header := utils.HeaderFromContext(ctx)
+	if header == nil {
+		header = &types.Header{
+			ChainID:            cli.chainID,
+			Height:             req.Height,
+			Time:               req.Time,
+			NextValidatorsHash: req.NextValidatorsHash,
+			AppHash:            cli.lastAppHash,
+			ProposerAddress:    req.ProposerAddress,
+		}
+	}
+
+	beginBlock, err := encodeRequestBeginBlock(req, header)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := cli.legacyRequest(ctx, requestBeginBlockField, beginBlock, responseBeginBlockField)
+	if err != nil {
+		return nil, fmt.Errorf("error in BeginBlock: %w", err)
+	}
+	beginBlockEvents, err := decodeResponseBeginBlock(bz)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response to BeginBlock: %w", err)
+	}
+
+	txResults := make([]*abcitypes.ExecTxResult, len(req.Txs))
+	for index, tx := range req.Txs {
+		bz, err := cli.legacyRequest(ctx, requestDeliverTxField, encodeRequestDeliverTx(tx), responseDeliverTxField)
+		if err != nil {
+			return nil, fmt.Errorf("error in DeliverTx for tx %d: %w", index, err)
+		}
+		// ResponseDeliverTx has the same fields as ExecTxResult
+		txResults[index] = &abcitypes.ExecTxResult{}
+		if err := txResults[index].Unmarshal(bz); err != nil {
+			return nil, fmt.Errorf("error decoding response to DeliverTx for tx %d: %w", index, err)
+		}
+	}
+
+	bz, err = cli.legacyRequest(ctx, requestEndBlockField, encodeRequestEndBlock(req.Height), responseEndBlockField)
+	if err != nil {
+		return nil, fmt.Errorf("error in EndBlock: %w", err)
+	}
+	endBlock, err := decodeResponseEndBlock(bz)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response to EndBlock: %w", err)
+	}
+
+	commitRequest, err := abcitypes.ToRequestCommit().Marshal()
+	if err != nil {
+		return nil, err
+	}
+	var commit *legacyResponseCommit
+	err = cli.exchange(ctx, commitRequest, true, func(bz []byte) error {
+		field, msg, err := consumeResponse(bz)
+		if err != nil {
+			return err
+		}
+		if field != responseCommitField {
+			return fmt.Errorf("expected response with field %d, got %d", responseCommitField, field)
+		}
+		commit, err = decodeResponseCommit(msg)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error in Commit: %w", err)
+	}
+	cli.lastAppHash = commit.data
+	cli.retainHeight = commit.retainHeight
+
+	return &abcitypes.ResponseFinalizeBlock{
+		Events:                append(beginBlockEvents, endBlock.events...),
+		TxResults:             txResults,
+		ValidatorUpdates:      endBlock.validatorUpdates,
+		ConsensusParamUpdates: endBlock.consensusParamUpdates,
+		AppHash:               commit.data,
+	}, nil
+}
+
+// Commit implements abcicli.Client. The app already committed the block in FinalizeBlock,
+// so this returns the retain height from that commit without contacting the app.
+func (cli *SocketClient) Commit(_ context.Context, _ *abcitypes.RequestCommit) (*abcitypes.ResponseCommit, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+	return &abcitypes.ResponseCommit{RetainHeight: cli.retainHeight}, nil
+}
+
+// ListSnapshots implements abcicli.Client.
+func (cli *SocketClient) ListSnapshots(ctx context.Context, req *abcitypes.RequestListSnapshots) (*abcitypes.ResponseListSnapshots, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestListSnapshots(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetListSnapshots() == nil {
+		return nil, unexpectedResponse("ListSnapshots", res)
+	}
+	return res.GetListSnapshots(), nil
+}
+
+// OfferSnapshot implements abcicli.Client.
+func (cli *SocketClient) OfferSnapshot(ctx context.Context, req *abcitypes.RequestOfferSnapshot) (*abcitypes.ResponseOfferSnapshot, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestOfferSnapshot(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetOfferSnapshot() == nil {
+		return nil, unexpectedResponse("OfferSnapshot", res)
+	}
+	return res.GetOfferSnapshot(), nil
+}
+
+// LoadSnapshotChunk implements abcicli.Client.
+func (cli *SocketClient) LoadSnapshotChunk(ctx context.Context, req *abcitypes.RequestLoadSnapshotChunk) (*abcitypes.ResponseLoadSnapshotChunk, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestLoadSnapshotChunk(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetLoadSnapshotChunk() == nil {
+		return nil, unexpectedResponse("LoadSnapshotChunk", res)
+	}
+	return res.GetLoadSnapshotChunk(), nil
+}
+
+// ApplySnapshotChunk implements abcicli.Client.
+func (cli *SocketClient) ApplySnapshotChunk(ctx context.Context, req *abcitypes.RequestApplySnapshotChunk) (*abcitypes.ResponseApplySnapshotChunk, error) {
+	cli.mtx.Lock()
+	defer cli.mtx.Unlock()
+
+	res, err := cli.request(ctx, abcitypes.ToRequestApplySnapshotChunk(req))
+	if err != nil {
+		return nil, err
+	}
+	if res.GetApplySnapshotChunk() == nil {
+		return nil, unexpectedResponse("ApplySnapshotChunk", res)
+	}
+	return res.GetApplySnapshotChunk(), nil
+}
+
+func writeDelimited(w io.Writer, msg []byte) error {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(msg)))
+	if _, err := w.Write(length[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(msg)
+	return err
+}
+
+func readDelimited(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxResponseSize {
+		return nil, fmt.Errorf("response of %d bytes exceeds the maximum of %d bytes", length, maxResponseSize)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,147 @@
+package loadgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+// Generator produces the transactions submitted by the load generator.
+type Generator interface {
+	// Next returns the transaction with the given index, starting at 0.
+	// It is called from multiple goroutines simultaneously.
+	Next(index int64) (types.Tx, error)
+}
+
+// GeneratorFactory creates a generator from the argument given after the name of the generator,
+// e.g. the path for "file:/path/to/txs".
+type GeneratorFactory func(arg string) (Generator, error)
+
+var (
+	generatorsMutex sync.RWMutex
+	generators      = map[string]GeneratorFactory{
+		"file":     NewFileGenerator,
+		"template": NewTemplateGenerator,
+	}
+)
+
+// RegisterGenerator makes a generator available under the given name,
+// so that it can be selected with NewGenerator.
+// This allows builds of CometMock to generate transactions for their apps in Go,
+// e.g. signed Cosmos SDK transactions with increasing sequence numbers.
+func RegisterGenerator(name string, factory GeneratorFactory) {
+	generatorsMutex.Lock()
+	defer generatorsMutex.Unlock()
+
+	generators[name] = factory
+}
+
+// NewGenerator creates the generator selected by spec, which is of the form name:arg,
+// e.g. "file:/path/to/txs" or "template:key{{.Index}}=value".
+func NewGenerator(spec string) (Generator, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+
+	generatorsMutex.RLock()
+	factory, ok := generators[name]
+	names := make([]string, 0, len(generators))
+	for n := range generators {
+		names = append(names, n)
+	}
+	generatorsMutex.RUnlock()
+
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown generator %q, must be one of %v", name, names)
+	}
+	return factory(arg)
+}
+
+// FileGenerator cycles through the transactions read from a file.
+type FileGenerator struct {
+	txs []types.Tx
+}
+
+// NewFileGenerator reads the transactions from the file at the given path,
+// which contains one base64 encoded transaction per line, like the tx field returned by the tx RPC endpoint.
+// Empty lines are ignored.
+func NewFileGenerator(path string) (Generator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	txs := make([]types.Tx, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		tx, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding transaction in line %d of %v: %w", line, path, err)
+		}
+		txs = append(txs, tx)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("no transactions found in %v", path)
+	}
+	return &FileGenerator{txs: txs}, nil
+}
+
+func (g *FileGenerator) Next(index int64) (types.Tx, error) {
+	return g.txs[index%int64(len(g.txs))], nil
+}
+
+// TemplateGenerator renders a text/template for each transaction.
+type TemplateGenerator struct {
+	template *template.Template
+}
+
+// TemplateData is passed to the template of a TemplateGenerator.
+type TemplateData struct {
+	// the index of the transaction, starting at 0
+	Index int64
+	// the time at which the transaction is generated, in unix nanoseconds,
+	// to make transactions unique across runs
+	Time int64
+}
+
+// NewTemplateGenerator parses the given text/template, e.g. "key{{.Index}}=value{{.Time}}",
+// which is rendered with TemplateData for each transaction.
+// This suits apps with plain text transactions, like the kvstore app.
+func NewTemplateGenerator(text string) (Generator, error) {
+	if text == "" {
+		return nil, errors.New("the template must not be empty")
+	}
+	tmpl, err := template.New("tx").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+	return &TemplateGenerator{template: tmpl}, nil
+}
+
+func (g *TemplateGenerator) Next(index int64) (types.Tx, error) {
+	var buf bytes.Buffer
+	err := g.template.Execute(&buf, TemplateData{Index: index, Time: time.Now().UnixNano()})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,345 @@
+// Package loadgen submits generated transactions to the apps at a fixed rate,
+// and reports how many were accepted by CheckTx and how long it took until they were included in a block.
+// This allows performance testing apps against CometMock without a separate tool
+// that has to go through the broadcast endpoints.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+)
+
+// the maximal number of transactions that are submitted simultaneously.
+// When it is reached, transactions are dropped instead of being submitted, see Stats.Dropped
+const maxInFlight = 1000
+
+// the maximal rate in transactions per second
+const maxRate = 1_000_000
+
+// how long to wait for submitted transactions to be included after the load generator stops submitting
+const inclusionTimeout = 30 * time.Second
+
+// the capacity of the subscription to included transactions
+const subscriptionCapacity = 10000
+
+const subscriber = "cometmock-loadgen"
+
+// LatencyStats summarizes a set of latencies.
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+func newLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, latency := range sorted {
+		sum += latency
+	}
+
+	return LatencyStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   sorted[len(sorted)*50/100],
+		P99:   sorted[len(sorted)*99/100],
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// Stats describes the progress of the last run of the load generator.
+type Stats struct {
+	Running   bool   `json:"running"`
+	Generator string `json:"generator"`
+	// the targeted number of transactions per second
+	Rate     int64         `json:"rate"`
+	Duration time.Duration `json:"duration"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end,omitempty"`
+
+	// the number of generated transactions
+	Submitted int64 `json:"submitted"`
+	// the number of transactions that passed CheckTx, and were queued for inclusion
+	Accepted int64 `json:"accepted"`
+	// the number of transactions that CheckTx rejected with a non-zero code
+	Rejected int64 `json:"rejected"`
+	// the number of transactions that could not be generated or checked
+	Errors int64 `json:"errors"`
+	// the number of transactions that were not submitted because too many transactions were in flight
+	Dropped int64 `json:"dropped"`
+	// the number of accepted transactions that were included in a block
+	Included int64 `json:"included"`
+	// the last error that occurred, if any
+	LastError string `json:"last_error,omitempty"`
+
+	// the time it took the apps to respond to CheckTx
+	CheckTxLatency LatencyStats `json:"check_tx_latency"`
+	// the time from submitting a transaction until it was included in a block
+	InclusionLatency LatencyStats `json:"inclusion_latency"`
+}
+
+// LoadGenerator submits generated transactions to the apps of an AbciClient.
+// It runs at most once at a time.
+type LoadGenerator struct {
+	client *abci_client.AbciClient
+
+	mutex              sync.Mutex
+	stats              Stats
+	checkTxLatencies   []time.Duration
+	inclusionLatencies []time.Duration
+	// the submission times of the accepted transactions that were not included yet, by hash
+	pending map[string]time.Time
+	cancel  context.CancelFunc
+}
+
+func NewLoadGenerator(client *abci_client.AbciClient) *LoadGenerator {
+	return &LoadGenerator{
+		client: client,
+	}
+}
+
+// Start submits the transactions produced by the generator at the given rate, in transactions per second,
+// for the given duration, in the background. The name of the generator is only used for the stats.
+// Transactions are submitted like via broadcast_tx_sync, i.e. they are checked with CheckTx
+// and queued for inclusion in the next block if they are accepted.
+func (l *LoadGenerator) Start(generator Generator, name string, rate int64, duration time.Duration) error {
+	if rate <= 0 || rate > maxRate {
+		return fmt.Errorf("the rate must be between 1 and %d, got %d", maxRate, rate)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("the duration must be positive, got %v", duration)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.stats.Running {
+		return errors.New("the load generator is already running, stop it first")
+	}
+
+	sub, err := l.client.EventBus.Subscribe(context.Background(), subscriber, types.EventQueryTx, subscriptionCapacity)
+	if err != nil {
+		return fmt.Errorf("error subscribing to transactions: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.stats = Stats{
+		Running:   true,
+		Generator: name,
+		Rate:      rate,
+		Duration:  duration,
+		Start:     time.Now(),
+	}
+	l.checkTxLatencies = make([]time.Duration, 0)
+	l.inclusionLatencies = make([]time.Duration, 0)
+	l.pending = make(map[string]time.Time)
+
+	l.client.Logger.Info("Starting load generator", "generator", name, "rate", rate, "duration", duration)
+
+	go l.watchInclusions(ctx, sub)
+	go l.run(ctx, generator, rate, duration)
+	return nil
+}
+
+// Stop stops the load generator, if it is running.
+func (l *LoadGenerator) Stop() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
+
+// Stats returns the stats of the current or last run.
+func (l *LoadGenerator) Stats() Stats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	stats := l.stats
+	stats.CheckTxLatency = newLatencyStats(l.checkTxLatencies)
+	stats.InclusionLatency = newLatencyStats(l.inclusionLatencies)
+	return stats
+}
+
+func (l *LoadGenerator) run(ctx context.Context, generator Generator, rate int64, duration time.Duration) {
+	defer func() {
+		if err := l.client.EventBus.UnsubscribeAll(context.Background(), subscriber); err != nil {
+			l.client.Logger.Error("Error unsubscribing load generator", "err", err)
+		}
+
+		l.mutex.Lock()
+		l.cancel()
+		l.stats.Running = false
+		l.stats.End = time.Now()
+		l.mutex.Unlock()
+
+		stats := l.Stats()
+		l.client.Logger.Info("Load generator finished", "submitted", stats.Submitted, "accepted", stats.Accepted,
+			"included", stats.Included, "check_tx_p50", stats.CheckTxLatency.P50, "inclusion_p50", stats.InclusionLatency.P50)
+	}()
+
+	submitCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	inFlight := make(chan struct{}, maxInFlight)
+	var wg sync.WaitGroup
+
+submit:
+	for index := int64(0); ; index++ {
+		select {
+		case <-submitCtx.Done():
+			break submit
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			l.update(func(stats *Stats) { stats.Dropped++ })
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int64) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			l.submit(generator, index)
+		}(index)
+	}
+	wg.Wait()
+
+	// wait for the accepted transactions to be included
+	deadline := time.NewTimer(inclusionTimeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(100 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		l.mutex.Lock()
+		pending := len(l.pending)
+		l.mutex.Unlock()
+		if pending == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			l.client.Logger.Info("Stopped waiting for transactions to be included", "pending", pending)
+			return
+		case <-poll.C:
+		}
+	}
+}
+
+// submit generates the transaction with the given index, checks it and queues it if it is accepted.
+func (l *LoadGenerator) submit(generator Generator, index int64) {
+	l.update(func(stats *Stats) { stats.Submitted++ })
+
+	tx, err := generator.Next(index)
+	if err != nil {
+		l.recordError(fmt.Errorf("error generating transaction %d: %w", index, err))
+		return
+	}
+
+	start := time.Now()
+	// if CheckTx is skipped, the transaction counts as accepted and no CheckTx latency is recorded
+	res := &abcitypes.ResponseCheckTx{Code: abcitypes.CodeTypeOK}
+	if !l.client.SkipCheckTx {
+		txBytes := []byte(tx)
+		var err error
+		res, err = l.client.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+		if err != nil {
+			l.recordError(fmt.Errorf("error checking transaction %d: %w", index, err))
+			return
+		}
+	}
+	latency := time.Since(start)
+
+	l.mutex.Lock()
+	if !l.client.SkipCheckTx {
+		l.checkTxLatencies = append(l.checkTxLatencies, latency)
+	}
+	if res.Code != abcitypes.CodeTypeOK {
+		l.stats.Rejected++
+		l.mutex.Unlock()
+		return
+	}
+	l.stats.Accepted++
+	l.pending[string(tx.Hash())] = start
+	l.mutex.Unlock()
+
+	l.client.QueueTx(tx)
+	if l.client.AutoIncludeTx {
+		go l.client.RunBlock()
+	}
+}
+
+// watchInclusions records the inclusion latency of the submitted transactions
+// until the load generator stops.
+func (l *LoadGenerator) watchInclusions(ctx context.Context, sub types.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Canceled():
+			if err := sub.Err(); err != nil {
+				l.recordError(fmt.Errorf("stopped recording inclusions: %w", err))
+			}
+			return
+		case msg := <-sub.Out():
+			eventDataTx, ok := msg.Data().(types.EventDataTx)
+			if !ok {
+				continue
+			}
+			hash := string(types.Tx(eventDataTx.Tx).Hash())
+
+			l.mutex.Lock()
+			if start, ok := l.pending[hash]; ok {
+				delete(l.pending, hash)
+				l.inclusionLatencies = append(l.inclusionLatencies, time.Since(start))
+				l.stats.Included++
+			}
+			l.mutex.Unlock()
+		}
+	}
+}
+
+func (l *LoadGenerator) recordError(err error) {
+	l.client.Logger.Debug("Load generator error", "err", err)
+	l.update(func(stats *Stats) {
+		stats.Errors++
+		stats.LastError = err.Error()
+	})
+}
+
+func (l *LoadGenerator) update(update func(stats *Stats)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	update(&l.stats)
+}
@@ -0,0 +1,147 @@
+// Package logging filters the log lines of CometMock by levels that can be changed while it runs,
+// see the --log-level flag and the set_log_level endpoint.
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	cometlog "github.com/cometbft/cometbft/libs/log"
+)
+
+// The levels that log lines can be filtered by. Each level includes the levels above it.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelError = "error"
+	// no log lines at all
+	LevelNone = "none"
+)
+
+// DefaultLevels is the default of --log-level.
+const DefaultLevels = LevelInfo
+
+// defaultModule stands for the log lines of all modules that have no level of their own.
+const defaultModule = "*"
+
+var levelRanks = map[string]int{LevelDebug: 0, LevelInfo: 1, LevelError: 2, LevelNone: 3}
+
+// Levels are the levels of the log lines that are logged, by module, like the log_level of CometBFT.
+// They are safe for use by multiple goroutines simultaneously.
+type Levels struct {
+	mutex sync.RWMutex
+	// the levels by module, with the level of the modules without a level of their own under defaultModule
+	levels map[string]string
+}
+
+// NewLevels returns the levels given by spec, see Set.
+func NewLevels(spec string) (*Levels, error) {
+	levels := &Levels{}
+	if err := levels.Set(spec); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// Set replaces the levels with the ones given by spec, which is either a level for all modules, e.g. info,
+// or a comma-separated list of module:level pairs, e.g. *:info,rpc-server:error,events:debug,
+// where * stands for the modules that are not listed. The modules are the module of each log line,
+// e.g. rpc-server, events, txindex, privval or abci-client, and the log lines without a module count as *.
+func (l *Levels) Set(spec string) error {
+	levels := map[string]string{defaultModule: LevelInfo}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		module, level, found := strings.Cut(entry, ":")
+		if !found {
+			module, level = defaultModule, entry
+		}
+		if _, ok := levelRanks[level]; !ok {
+			return fmt.Errorf("invalid log level %q for module %v, must be one of %v, %v, %v or %v",
+				level, module, LevelDebug, LevelInfo, LevelError, LevelNone)
+		}
+		levels[module] = level
+	}
+
+	l.mutex.Lock()
+	l.levels = levels
+	l.mutex.Unlock()
+	return nil
+}
+
+// String returns the levels in the form that Set takes, with the levels of the modules ordered by module.
+func (l *Levels) String() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	modules := make([]string, 0, len(l.levels))
+	for module := range l.levels {
+		if module != defaultModule {
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+
+	entries := []string{defaultModule + ":" + l.levels[defaultModule]}
+	for _, module := range modules {
+		entries = append(entries, module+":"+l.levels[module])
+	}
+	return strings.Join(entries, ",")
+}
+
+// allows returns whether log lines of the module with the given level are logged.
+func (l *Levels) allows(module string, level string) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	moduleLevel, ok := l.levels[module]
+	if !ok {
+		moduleLevel = l.levels[defaultModule]
+	}
+	return levelRanks[level] >= levelRanks[moduleLevel]
+}
+
+// NewLogger returns a logger that passes the log lines that the levels allow on to next.
+// Since the levels are checked for each log line, changes of the levels take effect immediately.
+func NewLogger(next cometlog.Logger, levels *Levels) cometlog.Logger {
+	return &filter{next: next, levels: levels, module: defaultModule}
+}
+
+type filter struct {
+	next   cometlog.Logger
+	levels *Levels
+	// the module of the log lines, given by the last module key of With
+	module string
+}
+
+func (f *filter) Debug(msg string, keyvals ...interface{}) {
+	if f.levels.allows(f.module, LevelDebug) {
+		f.next.Debug(msg, keyvals...)
+	}
+}
+
+func (f *filter) Info(msg string, keyvals ...interface{}) {
+	if f.levels.allows(f.module, LevelInfo) {
+		f.next.Info(msg, keyvals...)
+	}
+}
+
+func (f *filter) Error(msg string, keyvals ...interface{}) {
+	if f.levels.allows(f.module, LevelError) {
+		f.next.Error(msg, keyvals...)
+	}
+}
+
+func (f *filter) With(keyvals ...interface{}) cometlog.Logger {
+	module := f.module
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if keyvals[i] == "module" {
+			module = fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return &filter{next: f.next.With(keyvals...), levels: f.levels, module: module}
+}
@@ -1,259 +1,357 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
-	"strings"
+	"path/filepath"
+	"sync"
 	"time"
 
 	comet_abciclient "github.com/cometbft/cometbft/abci/client"
+	"github.com/cometbft/cometbft/crypto/ed25519"
 	cometlog "github.com/cometbft/cometbft/libs/log"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
 	"github.com/cometbft/cometbft/privval"
-	"github.com/cometbft/cometbft/state"
 	"github.com/cometbft/cometbft/types"
-	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
 	"github.com/informalsystems/CometMock/cometmock/abci_client"
-	"github.com/informalsystems/CometMock/cometmock/rpc_server"
-	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/informalsystems/CometMock/cometmock/legacy_abci"
+	"github.com/informalsystems/CometMock/cometmock/logging"
+	"github.com/informalsystems/CometMock/cometmock/version"
 	"github.com/urfave/cli/v2"
 )
 
-const version = "v0.38.x"
+// remoteSignerConnectTimeout is the maximal time to wait for a remote signer
+// to connect to CometMock at startup.
+const remoteSignerConnectTimeout = 60 * time.Second
 
 // GetMockPVsFromNodeHomes returns a list of MockPVs, created with the priv_validator_key's from the specified node homes
 // We use MockPV because they do not do sanity checks that would e.g. prevent double signing
-func GetMockPVsFromNodeHomes(nodeHomes []string) []types.PrivValidator {
+func GetMockPVsFromNodeHomes(nodeHomes []string) ([]types.PrivValidator, error) {
 	mockPVs := make([]types.PrivValidator, 0)
 
 	for _, nodeHome := range nodeHomes {
-		privValidatorKeyFile := nodeHome + "/config/priv_validator_key.json"
-		privValidatorStateFile := nodeHome + "/data/priv_validator_state.json"
-		validator := privval.LoadFilePV(privValidatorKeyFile, privValidatorStateFile)
+		privValidatorKeyFile, privValidatorStateFile, err := privValidatorFiles(nodeHome)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(privValidatorKeyFile); err != nil {
+			return nil, fmt.Errorf("node home %v has no priv validator key: %w", nodeHome, err)
+		}
+		// the state of CometBFT is never used, see signStateFile
+		validator := privval.LoadFilePVEmptyState(privValidatorKeyFile, privValidatorStateFile)
 
 		mockPV := types.NewMockPVWithParams(validator.Key.PrivKey, false, false)
 		mockPVs = append(mockPVs, mockPV)
 	}
 
-	return mockPVs
+	return mockPVs, nil
 }
 
-func main() {
-	logger := cometlog.NewTMLogger(cometlog.NewSyncWriter(os.Stdout))
-
-	argumentString := "[--block-time=value] [--auto-tx=<value>] [--block-production-interval=<value>] [--starting-timestamp=<value>] [--starting-timestamp-from-genesis=<value>] <app-addresses> <genesis-file> <cometmock-listen-address> <node-homes> <abci-connection-mode>"
-
-	app := &cli.App{
-		Name:            "cometmock",
-		HideHelpCommand: true,
-		Commands: []*cli.Command{
-			{
-				Name:  "version",
-				Usage: "Print the version of cometmock",
-				Action: func(c *cli.Context) error {
-					fmt.Printf("%s\n", version)
-					return nil
-				},
-			},
-		},
-		Flags: []cli.Flag{
-			&cli.Int64Flag{
-				Name: "block-time",
-				Usage: `
-The number of milliseconds by which the block timestamp should advance from one block to the next.
-If this is <0, block timestamps will advance with the system time between the block productions.
-Even then, it is still possible to shift the block time from the system time, e.g. by setting an initial timestamp
-or by using the 'advance_time' endpoint.`,
-				Value: -1,
-			},
-			&cli.BoolFlag{
-				Name: "auto-tx",
-				Usage: `
-If this is true, transactions are included immediately
-after they are received via broadcast_tx, i.e. a new block
-is created when a BroadcastTx endpoint is hit.
-If this is false, transactions are still included
-upon creation of new blocks, but CometMock will not specifically produce
-a new block when a transaction is broadcast.`,
-				Value: true,
-			},
-			&cli.Int64Flag{
-				Name: "block-production-interval",
-				Usage: `
-Time to sleep between blocks in milliseconds.
-To disable block production, set to 0.
-This will not necessarily mean block production is this fast
-- it is just the sleep time between blocks.
-Setting this to a value < 0 disables automatic block production.
-In this case, blocks are only produced when instructed explicitly either by
-advancing blocks or broadcasting transactions.`,
-				Value: 1000,
-			},
-			&cli.Int64Flag{
-				Name: "starting-timestamp",
-				Usage: `
-The timestamp to use for the first block, given in milliseconds since the unix epoch.
-If this is < 0, the current system time is used.
-If this is >= 0, the system time is ignored and this timestamp is used for the first block instead.`,
-				Value: -1,
-			},
-			&cli.BoolFlag{
-				Name: "starting-timestamp-from-genesis",
-				Usage: `
-If this is true, it overrides the starting-timestamp, and instead
-bases the time for the first block on the genesis time, incremented by the block time
-or the system time between creating the genesis request and producing the first block.`,
-				Value: false,
-			},
-		},
-		ArgsUsage: argumentString,
-		Action: func(c *cli.Context) error {
-			if c.NArg() < 5 {
-				return cli.Exit("Not enough arguments.\nUsage: "+argumentString, 1)
-			}
-
-			appAddresses := strings.Split(c.Args().Get(0), ",")
-			genesisFile := c.Args().Get(1)
-			cometMockListenAddress := c.Args().Get(2)
-			nodeHomesString := c.Args().Get(3)
-			connectionMode := c.Args().Get(4)
+// privValidatorFiles returns the key file and the state file of the validator of the node home,
+// which are the priv_validator_key_file and priv_validator_state_file of its config/config.toml, like for CometBFT,
+// by default config/priv_validator_key.json and data/priv_validator_state.json.
+func privValidatorFiles(nodeHome string) (string, string, error) {
+	config, err := readConfig(nodeHome)
+	if err != nil {
+		return "", "", err
+	}
+	return config.PrivValidatorKeyFile(), config.PrivValidatorStateFile(), nil
+}
 
-			if connectionMode != "socket" && connectionMode != "grpc" {
-				return cli.Exit(fmt.Sprintf("Invalid connection mode: %s. Connection mode must be either 'socket' or 'grpc'.\nUsage: %s", connectionMode, argumentString), 1)
-			}
+// signStateFile returns the file in which CometMock persists the sign state of the validator of the node home
+// with --enforce-signing-state, which is cometmock_sign_state.json next to its priv_validator_state_file.
+// The priv_validator_state_file itself belongs to CometBFT, so CometMock leaves it alone.
+func signStateFile(nodeHome string) (string, error) {
+	_, privValidatorStateFile, err := privValidatorFiles(nodeHome)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(privValidatorStateFile), "cometmock_sign_state.json"), nil
+}
 
-			blockProductionInterval := c.Int("block-production-interval")
-			fmt.Printf("Block production interval: %d\n", blockProductionInterval)
+// privValidatorKeyFile returns the key file of the validator of the node home, see privValidatorFiles.
+func privValidatorKeyFile(nodeHome string) (string, error) {
+	privValidatorKeyFile, _, err := privValidatorFiles(nodeHome)
+	return privValidatorKeyFile, err
+}
 
-			// read node homes from args
-			nodeHomes := strings.Split(nodeHomesString, ",")
+// GetRemotePrivValidator listens on the given address for a remote signer, e.g. tmkms,
+// and returns a PrivValidator that forwards signing requests to the signer once it connected.
+// timeoutReadWrite is the time after which a signing request to the remote signer times out.
+func GetRemotePrivValidator(listenAddr, chainID string, timeoutReadWrite time.Duration, logger cometlog.Logger) (types.PrivValidator, error) {
+	protocol, address := cmtnet.ProtocolAndAddress(listenAddr)
+	ln, err := net.Listen(protocol, address)
+	if err != nil {
+		return nil, err
+	}
 
-			// get priv validators from node Homes
-			privVals := GetMockPVsFromNodeHomes(nodeHomes)
+	var listener net.Listener
+	switch protocol {
+	case "unix":
+		unixListener := privval.NewUnixListener(ln)
+		privval.UnixListenerTimeoutReadWrite(timeoutReadWrite)(unixListener)
+		listener = unixListener
+	case "tcp":
+		tcpListener := privval.NewTCPListener(ln, ed25519.GenPrivKey())
+		privval.TCPListenerTimeoutReadWrite(timeoutReadWrite)(tcpListener)
+		listener = tcpListener
+	default:
+		return nil, fmt.Errorf("wrong listen address: expected either 'tcp' or 'unix' protocols, got %s", protocol)
+	}
 
-			appGenesis, err := genutiltypes.AppGenesisFromFile(genesisFile)
-			if err != nil {
-				logger.Error(err.Error())
-			}
+	endpoint := privval.NewSignerListenerEndpoint(
+		logger.With("module", "privval"),
+		listener,
+		privval.SignerListenerEndpointTimeoutReadWrite(timeoutReadWrite),
+	)
 
-			genesisDoc, err := appGenesis.ToGenesisDoc()
-			if err != nil {
-				logger.Error(err.Error())
-				panic(err)
-			}
+	signerClient, err := privval.NewSignerClient(endpoint, chainID)
+	if err != nil {
+		return nil, err
+	}
 
-			curState, err := state.MakeGenesisState(genesisDoc)
-			if err != nil {
-				logger.Error(err.Error())
-				panic(err)
-			}
+	logger.Info("Waiting for remote signer to connect", "address", listenAddr)
+	err = signerClient.WaitForConnection(remoteSignerConnectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer did not connect on %v: %w", listenAddr, err)
+	}
 
-			// read starting timestamp from args
-			// if starting timestamp should be taken from genesis,
-			// read it from there
-			var startingTime time.Time
-			if c.Bool("starting-timestamp-from-genesis") {
-				startingTime = genesisDoc.GenesisTime
-			} else {
-				if c.Int64("starting-timestamp") < 0 {
-					startingTime = time.Now()
-				} else {
-					dur := time.Duration(c.Int64("starting-timestamp")) * time.Millisecond
-					startingTime = time.Unix(0, 0).Add(dur)
-				}
-			}
-			fmt.Printf("Starting time: %s\n", startingTime.Format(time.RFC3339))
+	return signerClient, nil
+}
 
-			// read block time from args
-			blockTime := time.Duration(c.Int64("block-time")) * time.Millisecond
-			fmt.Printf("Block time: %d\n", blockTime.Milliseconds())
+// NewConnectClient returns a function that connects to an app at the given address,
+// using the given connection mode, i.e. socket, grpc or legacy-socket.
+func NewConnectClient(connectionMode string, logger cometlog.Logger) func(appAddress string) (comet_abciclient.Client, error) {
+	return func(appAddress string) (comet_abciclient.Client, error) {
+		logger.Info("Connecting to client at %v", appAddress)
+
+		var client comet_abciclient.Client
+		if connectionMode == "grpc" {
+			client = comet_abciclient.NewGRPCClient(appAddress, true)
+		} else if connectionMode == "legacy-socket" {
+			client = legacy_abci.NewSocketClient(appAddress)
+		} else {
+			client = comet_abciclient.NewSocketClient(appAddress, true)
+		}
+		client.SetLogger(logger.With("module", "abci-client"))
+		return client, client.Start()
+	}
+}
 
-			clientMap := make(map[string]abci_client.AbciCounterpartyClient)
+// RetryConnectClient returns a function that connects to an app like connectClient, but retries with backoff
+// while the app is not listening yet, e.g. because it is still starting up, until the timeout has passed.
+// If the timeout is 0, connectClient is returned, which tries to connect only once.
+func RetryConnectClient(
+	connectClient func(appAddress string) (comet_abciclient.Client, error),
+	timeout time.Duration,
+	logger cometlog.Logger,
+) func(appAddress string) (comet_abciclient.Client, error) {
+	if timeout <= 0 {
+		return connectClient
+	}
 
-			for i, appAddress := range appAddresses {
-				logger.Info("Connecting to client at %v", appAddress)
+	type connectResult struct {
+		client comet_abciclient.Client
+		err    error
+	}
 
-				var client comet_abciclient.Client
-				if connectionMode == "grpc" {
-					client = comet_abciclient.NewGRPCClient(appAddress, true)
-				} else {
-					client = comet_abciclient.NewSocketClient(appAddress, true)
+	return func(appAddress string) (comet_abciclient.Client, error) {
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+
+		backoff := 100 * time.Millisecond
+		for {
+			// grpc clients wait for the app instead of failing, so they are only stopped by the deadline
+			connected := make(chan connectResult, 1)
+			go func() {
+				client, err := connectClient(appAddress)
+				connected <- connectResult{client, err}
+			}()
+
+			var err error
+			select {
+			case result := <-connected:
+				if result.err == nil {
+					return result.client, nil
 				}
-				client.SetLogger(logger)
-				client.Start()
+				err = result.err
+			case <-deadline.C:
+				return nil, fmt.Errorf("app at %v did not accept a connection within %v", appAddress, timeout)
+			}
 
-				privVal := privVals[i]
+			logger.Info("App is not listening yet, retrying", "address", appAddress, "err", err, "backoff", backoff)
+			select {
+			case <-time.After(backoff):
+			case <-deadline.C:
+				return nil, fmt.Errorf("app at %v did not accept a connection within %v: %w", appAddress, timeout, err)
+			}
+			backoff = min(2*backoff, 5*time.Second)
+		}
+	}
+}
 
-				pubkey, err := privVal.GetPubKey()
-				if err != nil {
-					logger.Error(err.Error())
-					panic(err)
-				}
-				validatorAddress := pubkey.Address()
-				counterpartyClient := abci_client.NewAbciCounterpartyClient(client, appAddress, validatorAddress.String(), privVal)
+// ExpandAppAddresses expands the app addresses that stand for apps discovered via DNS, see abci_client.ExpandAppAddresses.
+// While the apps are starting up, their records might not be published yet, e.g. a headless service only
+// has records for pods that are ready, so the lookups are retried with backoff until they return the expected
+// number of addresses, or until the timeout has passed. If expected is 0, any number of addresses is accepted.
+func ExpandAppAddresses(appAddresses []string, expected int, timeout time.Duration, logger cometlog.Logger) ([]string, error) {
+	if !abci_client.HasDNSAppAddresses(appAddresses) {
+		return appAddresses, nil
+	}
 
-				clientMap[validatorAddress.String()] = *counterpartyClient
-			}
+	deadline := time.Now().Add(timeout)
+	backoff := 100 * time.Millisecond
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), abci_client.DNSLookupTimeout)
+		expanded, err := abci_client.ExpandAppAddresses(ctx, net.DefaultResolver, appAddresses)
+		cancel()
+		if err == nil && expected > 0 && len(expanded) != expected {
+			err = fmt.Errorf("discovered %d app addresses, but expected %d", len(expanded), expected)
+		}
+		if err == nil {
+			logger.Info("Discovered apps", "addresses", expanded)
+			return expanded, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+		logger.Info("Apps are not discovered yet, retrying", "err", err, "backoff", backoff)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, 5*time.Second)
+	}
+}
 
-			var timeHandler abci_client.TimeHandler
-			if blockTime < 0 {
-				timeHandler = abci_client.NewSystemClockTimeHandler(startingTime)
-			} else {
-				timeHandler = abci_client.NewFixedBlockTimeHandler(blockTime)
-			}
+// ConnectApps connects to the apps at the given addresses, where the app at appAddresses[i]
+// belongs to the validator with privVals[i]. It returns the clients by validator address,
+// and the validator addresses in the order of the app addresses.
+func ConnectApps(
+	appAddresses []string,
+	privVals []types.PrivValidator,
+	connectClient func(appAddress string) (comet_abciclient.Client, error),
+	logger cometlog.Logger,
+) (map[string]abci_client.AbciCounterpartyClient, []string, error) {
+	clientMap := make(map[string]abci_client.AbciCounterpartyClient)
+	clientOrder := make([]string, 0, len(appAddresses))
+
+	for i, appAddress := range appAddresses {
+		client, err := connectClient(appAddress)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		privVal := privVals[i]
+
+		pubkey, err := privVal.GetPubKey()
+		if err != nil {
+			logger.Error(err.Error())
+			panic(err)
+		}
+		validatorAddress := pubkey.Address()
+		counterpartyClient := abci_client.NewAbciCounterpartyClient(client, appAddress, validatorAddress.String(), privVal)
+
+		clientMap[validatorAddress.String()] = *counterpartyClient
+		clientOrder = append(clientOrder, validatorAddress.String())
+	}
+	return clientMap, clientOrder, nil
+}
 
-			abci_client.GlobalClient = abci_client.NewAbciClient(
-				clientMap,
-				logger,
-				curState,
-				&types.Block{},
-				&types.ExtendedCommit{},
-				&storage.MapStorage{},
-				timeHandler,
-				true,
-			)
-
-			abci_client.GlobalClient.AutoIncludeTx = c.Bool("auto-tx")
-			fmt.Printf("Auto include tx: %t\n", abci_client.GlobalClient.AutoIncludeTx)
-
-			// initialize chain
-			err = abci_client.GlobalClient.SendInitChain(curState, genesisDoc)
-			if err != nil {
-				logger.Error(err.Error())
-				panic(err)
-			}
+// ConnectAvailableApps connects to the apps at the given addresses like ConnectApps, but in parallel,
+// and leaves out the apps that cannot be connected. It returns the indices of those apps in appAddresses.
+func ConnectAvailableApps(
+	appAddresses []string,
+	privVals []types.PrivValidator,
+	connectClient func(appAddress string) (comet_abciclient.Client, error),
+	logger cometlog.Logger,
+) (map[string]abci_client.AbciCounterpartyClient, []string, []int) {
+	type connectResult struct {
+		clientMap   map[string]abci_client.AbciCounterpartyClient
+		clientOrder []string
+		err         error
+	}
 
-			var firstBlockTime time.Time
-			if blockTime < 0 {
-				firstBlockTime = startingTime
-			} else {
-				firstBlockTime = startingTime.Add(blockTime)
-			}
+	results := make([]connectResult, len(appAddresses))
+	var wg sync.WaitGroup
+	for i := range appAddresses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clientMap, clientOrder, err := ConnectApps(appAddresses[i:i+1], privVals[i:i+1], connectClient, logger)
+			results[i] = connectResult{clientMap, clientOrder, err}
+		}(i)
+	}
+	wg.Wait()
+
+	clientMap := make(map[string]abci_client.AbciCounterpartyClient)
+	clientOrder := make([]string, 0, len(appAddresses))
+	missing := make([]int, 0)
+	for i, result := range results {
+		if result.err != nil {
+			logger.Error("Could not connect to app, starting without it", "address", appAddresses[i], "err", result.err)
+			missing = append(missing, i)
+			continue
+		}
+		for address, client := range result.clientMap {
+			clientMap[address] = client
+		}
+		clientOrder = append(clientOrder, result.clientOrder...)
+	}
+	return clientMap, clientOrder, missing
+}
 
-			// run an empty block
-			err = abci_client.GlobalClient.RunBlockWithTime(firstBlockTime)
-			if err != nil {
-				logger.Error(err.Error())
-				panic(err)
-			}
+func main() {
+	// the levels are set from --log-level, and can be changed with set_log_level
+	logLevels, _ := logging.NewLevels(logging.DefaultLevels)
+	logger := logging.NewLogger(cometlog.NewTMLogger(cometlog.NewSyncWriter(os.Stdout)), logLevels)
 
-			go rpc_server.StartRPCServerWithDefaultConfig(cometMockListenAddress, logger)
+	flags := cometMockFlags()
 
-			if blockProductionInterval > 0 {
-				// produce blocks according to blockTime
-				for {
-					err := abci_client.GlobalClient.RunBlock()
-					if err != nil {
-						logger.Error(err.Error())
-						panic(err)
+	app := &cli.App{
+		Name:            "cometmock",
+		HideHelpCommand: true,
+		Commands: []*cli.Command{
+			{
+				Name:  "version",
+				Usage: "Print the version of cometmock",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "long",
+						Usage: "Also print the commit, the versions of CometBFT and ABCI, and the version of Go",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if !c.Bool("long") {
+						fmt.Printf("%s\n", version.Version)
+						return nil
 					}
-					time.Sleep(time.Millisecond * time.Duration(blockProductionInterval))
-				}
-			} else {
-				// wait forever
-				time.Sleep(time.Hour * 24 * 365 * 100) // 100 years
-			}
-			return nil
+					info := version.Get()
+					fmt.Printf("version: %s\n", info.Version)
+					fmt.Printf("git commit: %s\n", info.GitCommit)
+					fmt.Printf("cometbft: %s\n", info.CometBFTVersion)
+					fmt.Printf("abci: %s\n", info.ABCIVersion)
+					fmt.Printf("block protocol: %d\n", info.BlockProtocol)
+					fmt.Printf("p2p protocol: %d\n", info.P2PProtocol)
+					fmt.Printf("go: %s\n", info.GoVersion)
+					return nil
+				},
+			},
+			replayCommand(logger),
+			diffCommand(logger),
+			startCommand(),
+			selfcheckCommand(logger),
+			exportCommand(),
+			wrapCommand(),
+			fromHomeDirsCommand(),
+			dashboardCommand(),
+			exportDataDirCommand(),
+		},
+		Flags:     flags,
+		ArgsUsage: usage(flags),
+		Action: func(c *cli.Context) error {
+			return run(c, logLevels, logger)
 		},
 	}
 
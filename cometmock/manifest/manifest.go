@@ -0,0 +1,157 @@
+// Package manifest reads a manifest file that maps each validator to the address of its app and to its key,
+// together with settings of the app, see --validator-manifest. Unlike the app addresses and node homes given
+// as arguments, which are paired by their position, each entry of a manifest names its validator,
+// so that a key that belongs to another validator than the entry says is reported when CometMock starts.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/informalsystems/CometMock/cometmock/utils"
+	"github.com/informalsystems/CometMock/cometmock/validatorkeys"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Manifest is the content of a manifest file, in JSON or TOML.
+type Manifest struct {
+	Validators []Entry `json:"validators" toml:"validators"`
+}
+
+// Entry is an entry of a manifest, which is either the app of a validator, or an observer.
+type Entry struct {
+	// the validator, in any of the formats accepted by utils.ValidatorAddress, e.g. its address or public key.
+	// Empty for observers
+	Validator string `json:"validator" toml:"validator"`
+	// the address of the app, e.g. tcp://127.0.0.1:26658
+	AppAddress string `json:"app_address" toml:"app_address"`
+	// the key of the validator is read from exactly one of the following, unless this is an observer:
+	// the path to a priv_validator_key.json file
+	PrivValidatorKey string `json:"priv_validator_key" toml:"priv_validator_key"`
+	// a node home, whose signing state is persisted, like for node homes given as argument
+	Home string `json:"home" toml:"home"`
+	// a source of validatorkeys, e.g. env:<name> or seed:<seed>, which gives a single key
+	Key string `json:"key" toml:"key"`
+	// the latency that is added to each call to the app, e.g. 50ms, to simulate an app on a remote machine
+	Latency string `json:"latency" toml:"latency"`
+	// whether the app is an observer, which executes all blocks but has no validator identity, see --observer-addresses
+	Observer bool `json:"observer" toml:"observer"`
+}
+
+// App is an app of a manifest, with the key of its validator unless it is an observer.
+type App struct {
+	AppAddress string
+	// the zero Key for observers
+	Key      validatorkeys.Key
+	Latency  time.Duration
+	Observer bool
+}
+
+// Read reads the manifest file at the given path, which is TOML if its extension is .toml and JSON otherwise,
+// and returns its apps in order. The keys are read like validatorkeys.Parse does, with getenv and homeKeyFile,
+// and it is an error if the key of an entry belongs to another validator than the entry names.
+func Read(path string, getenv func(string) string, homeKeyFile func(home string) (string, error)) ([]App, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		decoder := toml.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&manifest)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading the manifest %v: %w", path, err)
+	}
+	if len(manifest.Validators) == 0 {
+		return nil, fmt.Errorf("the manifest %v has no validators", path)
+	}
+
+	apps := make([]App, len(manifest.Validators))
+	appAddresses := make(map[string]int)
+	validators := make(map[string]int)
+	for i, entry := range manifest.Validators {
+		app, err := entry.resolve(getenv, homeKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error in entry %d of the manifest %v: %w", i, path, err)
+		}
+		if previous, ok := appAddresses[app.AppAddress]; ok {
+			return nil, fmt.Errorf("entries %d and %d of the manifest %v have the same app address %v", previous, i, path, app.AppAddress)
+		}
+		appAddresses[app.AppAddress] = i
+		if !app.Observer {
+			address := app.Key.PrivKey.PubKey().Address().String()
+			if previous, ok := validators[address]; ok {
+				return nil, fmt.Errorf("entries %d and %d of the manifest %v are for the same validator %v", previous, i, path, address)
+			}
+			validators[address] = i
+		}
+		apps[i] = app
+	}
+	return apps, nil
+}
+
+// resolve checks the entry and reads its key.
+func (e Entry) resolve(getenv func(string) string, homeKeyFile func(home string) (string, error)) (App, error) {
+	if e.AppAddress == "" {
+		return App{}, fmt.Errorf("app_address is missing")
+	}
+	app := App{AppAddress: e.AppAddress, Observer: e.Observer}
+	if e.Latency != "" {
+		latency, err := time.ParseDuration(e.Latency)
+		if err != nil || latency < 0 {
+			return App{}, fmt.Errorf("latency must be a non-negative duration, e.g. 50ms, but got %q", e.Latency)
+		}
+		app.Latency = latency
+	}
+
+	var sources []string
+	if e.PrivValidatorKey != "" {
+		sources = append(sources, validatorkeys.SourceFile+e.PrivValidatorKey)
+	}
+	if e.Home != "" {
+		sources = append(sources, validatorkeys.SourceHome+e.Home)
+	}
+	if e.Key != "" {
+		sources = append(sources, e.Key)
+	}
+
+	if e.Observer {
+		if e.Validator != "" || len(sources) > 0 {
+			return App{}, fmt.Errorf("observers have no validator identity, so they take no validator, priv_validator_key, home or key")
+		}
+		return app, nil
+	}
+
+	if e.Validator == "" {
+		return App{}, fmt.Errorf("validator is missing, which is the address or public key of the validator of the app")
+	}
+	if len(sources) != 1 {
+		return App{}, fmt.Errorf("exactly one of priv_validator_key, home or key must be given for validator %v", e.Validator)
+	}
+	keys, err := validatorkeys.Parse(sources[0], getenv, homeKeyFile)
+	if err != nil {
+		return App{}, err
+	}
+	if len(keys) != 1 {
+		return App{}, fmt.Errorf("the key of validator %v must be a single key, but got %d keys", e.Validator, len(keys))
+	}
+	app.Key = keys[0]
+
+	expected := utils.ValidatorAddress(e.Validator)
+	if actual := app.Key.PrivKey.PubKey().Address().String(); actual != expected {
+		return App{}, fmt.Errorf("the key given for validator %v belongs to validator %v", e.Validator, actual)
+	}
+	return app, nil
+}
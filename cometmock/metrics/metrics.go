@@ -0,0 +1,128 @@
+// Package metrics serves metrics of CometMock in the Prometheus format.
+package metrics
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/cometbft/cometbft/libs/log"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the namespace of the metrics of CometMock.
+const Namespace = "cometmock"
+
+// ABCILatencyCollector collects the latency histograms of the ABCI methods called on each app,
+// see AbciClient.ABCILatencies.
+type ABCILatencyCollector struct {
+	client      *abci_client.AbciClient
+	description *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*ABCILatencyCollector)(nil)
+
+func NewABCILatencyCollector(client *abci_client.AbciClient) *ABCILatencyCollector {
+	return &ABCILatencyCollector{
+		client: client,
+		description: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "abci", "method_duration_seconds"),
+			"The latency of the calls of an ABCI method to the app of a validator, an observer or an executor.",
+			[]string{"validator", "name", "app_address", "method"},
+			nil,
+		),
+	}
+}
+
+func (c *ABCILatencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.description
+}
+
+func (c *ABCILatencyCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, client := range c.client.ABCILatencies() {
+		for method, histogram := range client.Methods {
+			buckets := make(map[float64]uint64, len(histogram.Buckets))
+			for _, bucket := range histogram.Buckets {
+				buckets[bucket.UpperBound] = bucket.Count
+			}
+			ch <- prometheus.MustNewConstHistogram(
+				c.description,
+				histogram.Count,
+				histogram.Sum,
+				buckets,
+				client.ValidatorAddress, client.Name, client.NetworkAddress, method,
+			)
+		}
+	}
+}
+
+// StorageCollector collects the number of heights held by the storage of the client,
+// and the estimated memory they take, see storage.Info.
+type StorageCollector struct {
+	client      *abci_client.AbciClient
+	heights     *prometheus.Desc
+	memoryBytes *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*StorageCollector)(nil)
+
+func NewStorageCollector(client *abci_client.AbciClient) *StorageCollector {
+	return &StorageCollector{
+		client: client,
+		heights: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "storage", "heights"),
+			"The number of heights whose blocks, commits, states and responses are stored, in memory or on disk.",
+			[]string{"location"},
+			nil,
+		),
+		memoryBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "storage", "memory_bytes"),
+			"The estimated size in bytes of the blocks, commits, states and responses held in memory.",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (c *StorageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.heights
+	ch <- c.memoryBytes
+}
+
+func (c *StorageCollector) Collect(ch chan<- prometheus.Metric) {
+	info := c.client.Storage.Info()
+	ch <- prometheus.MustNewConstMetric(c.heights, prometheus.GaugeValue, float64(info.HeightsInMemory), "memory")
+	ch <- prometheus.MustNewConstMetric(c.heights, prometheus.GaugeValue, float64(info.HeightsOnDisk), "disk")
+	ch <- prometheus.MustNewConstMetric(c.memoryBytes, prometheus.GaugeValue, float64(info.MemoryBytes))
+}
+
+// StartMetricsServer starts serving the metrics of the given client on the given address, under /metrics.
+// Next to the ABCI latencies and the storage, the metrics of the Go runtime and of the process are served.
+// The address can optionally be prefixed by a protocol, e.g. tcp://127.0.0.1:26660.
+func StartMetricsServer(client *abci_client.AbciClient, listenAddr string, logger log.Logger) {
+	protocol, address := cmtnet.ProtocolAndAddress(listenAddr)
+	listener, err := net.Listen(protocol, address)
+	if err != nil {
+		panic(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		NewABCILatencyCollector(client),
+		NewStorageCollector(client),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	logger.Info("Starting metrics server on", "address", listenAddr)
+	if err := http.Serve(listener, mux); err != nil {
+		logger.Error("Error serving metrics server", "err", err)
+		panic(err)
+	}
+}
@@ -0,0 +1,554 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: cometmock/control/v1/control.proto
+
+package controlv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ControlService_AdvanceBlocks_FullMethodName          = "/cometmock.control.v1.ControlService/AdvanceBlocks"
+	ControlService_AdvanceTime_FullMethodName            = "/cometmock.control.v1.ControlService/AdvanceTime"
+	ControlService_SetSigningStatus_FullMethodName       = "/cometmock.control.v1.ControlService/SetSigningStatus"
+	ControlService_GetSigningStatus_FullMethodName       = "/cometmock.control.v1.ControlService/GetSigningStatus"
+	ControlService_CauseDoubleSign_FullMethodName        = "/cometmock.control.v1.ControlService/CauseDoubleSign"
+	ControlService_CauseLightClientAttack_FullMethodName = "/cometmock.control.v1.ControlService/CauseLightClientAttack"
+	ControlService_SubmitTxs_FullMethodName              = "/cometmock.control.v1.ControlService/SubmitTxs"
+	ControlService_GetValidators_FullMethodName          = "/cometmock.control.v1.ControlService/GetValidators"
+	ControlService_GetTime_FullMethodName                = "/cometmock.control.v1.ControlService/GetTime"
+	ControlService_Resume_FullMethodName                 = "/cometmock.control.v1.ControlService/Resume"
+	ControlService_ReloadApps_FullMethodName             = "/cometmock.control.v1.ControlService/ReloadApps"
+)
+
+// ControlServiceClient is the client API for ControlService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ControlServiceClient interface {
+	// AdvanceBlocks runs the given number of empty blocks in succession.
+	AdvanceBlocks(ctx context.Context, in *AdvanceBlocksRequest, opts ...grpc.CallOption) (*AdvanceBlocksResponse, error)
+	// AdvanceTime advances the timestamps of all following blocks by the given duration.
+	AdvanceTime(ctx context.Context, in *AdvanceTimeRequest, opts ...grpc.CallOption) (*AdvanceTimeResponse, error)
+	// SetSigningStatus decides whether the given validator signs blocks.
+	SetSigningStatus(ctx context.Context, in *SetSigningStatusRequest, opts ...grpc.CallOption) (*SetSigningStatusResponse, error)
+	// GetSigningStatus returns the signing status of all validators.
+	GetSigningStatus(ctx context.Context, in *GetSigningStatusRequest, opts ...grpc.CallOption) (*GetSigningStatusResponse, error)
+	// CauseDoubleSign produces DuplicateVoteEvidence for the given validator
+	// and includes it in the next block.
+	CauseDoubleSign(ctx context.Context, in *CauseDoubleSignRequest, opts ...grpc.CallOption) (*CauseDoubleSignResponse, error)
+	// CauseLightClientAttack produces LightClientAttackEvidence for the given validator
+	// and includes it in the next block.
+	CauseLightClientAttack(ctx context.Context, in *CauseLightClientAttackRequest, opts ...grpc.CallOption) (*CauseLightClientAttackResponse, error)
+	// SubmitTxs accepts a stream of transactions, which are checked with CheckTx and queued for inclusion
+	// like via broadcast_tx_sync, and streams back the result of each transaction:
+	// whether CheckTx rejected it, or in which block it was included.
+	// This avoids a round trip per transaction for high-rate load tests.
+	// The response stream ends after the client closed its stream and all accepted transactions
+	// were included, or were not included in time.
+	SubmitTxs(ctx context.Context, opts ...grpc.CallOption) (ControlService_SubmitTxsClient, error)
+	// GetValidators returns the validators of the next block with their voting power and signing status.
+	// The validator set changes through the validator updates that the apps return from FinalizeBlock,
+	// so this is how test frameworks follow validator changes, e.g. after a delegation or jailing.
+	GetValidators(ctx context.Context, in *GetValidatorsRequest, opts ...grpc.CallOption) (*GetValidatorsResponse, error)
+	// GetTime returns the timestamp that the next block would have if it was produced now.
+	GetTime(ctx context.Context, in *GetTimeRequest, opts ...grpc.CallOption) (*GetTimeResponse, error)
+	// Resume resumes block production after it was halted, e.g. because the apps stopped for an upgrade.
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error)
+	// ReloadApps reconnects to the apps whose addresses changed or whose connection failed,
+	// while blocks are produced.
+	ReloadApps(ctx context.Context, in *ReloadAppsRequest, opts ...grpc.CallOption) (*ReloadAppsResponse, error)
+}
+
+type controlServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewControlServiceClient(cc grpc.ClientConnInterface) ControlServiceClient {
+	return &controlServiceClient{cc}
+}
+
+func (c *controlServiceClient) AdvanceBlocks(ctx context.Context, in *AdvanceBlocksRequest, opts ...grpc.CallOption) (*AdvanceBlocksResponse, error) {
+	out := new(AdvanceBlocksResponse)
+	err := c.cc.Invoke(ctx, ControlService_AdvanceBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) AdvanceTime(ctx context.Context, in *AdvanceTimeRequest, opts ...grpc.CallOption) (*AdvanceTimeResponse, error) {
+	out := new(AdvanceTimeResponse)
+	err := c.cc.Invoke(ctx, ControlService_AdvanceTime_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) SetSigningStatus(ctx context.Context, in *SetSigningStatusRequest, opts ...grpc.CallOption) (*SetSigningStatusResponse, error) {
+	out := new(SetSigningStatusResponse)
+	err := c.cc.Invoke(ctx, ControlService_SetSigningStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetSigningStatus(ctx context.Context, in *GetSigningStatusRequest, opts ...grpc.CallOption) (*GetSigningStatusResponse, error) {
+	out := new(GetSigningStatusResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetSigningStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) CauseDoubleSign(ctx context.Context, in *CauseDoubleSignRequest, opts ...grpc.CallOption) (*CauseDoubleSignResponse, error) {
+	out := new(CauseDoubleSignResponse)
+	err := c.cc.Invoke(ctx, ControlService_CauseDoubleSign_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) CauseLightClientAttack(ctx context.Context, in *CauseLightClientAttackRequest, opts ...grpc.CallOption) (*CauseLightClientAttackResponse, error) {
+	out := new(CauseLightClientAttackResponse)
+	err := c.cc.Invoke(ctx, ControlService_CauseLightClientAttack_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) SubmitTxs(ctx context.Context, opts ...grpc.CallOption) (ControlService_SubmitTxsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ControlService_ServiceDesc.Streams[0], ControlService_SubmitTxs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controlServiceSubmitTxsClient{stream}
+	return x, nil
+}
+
+type ControlService_SubmitTxsClient interface {
+	Send(*SubmitTxsRequest) error
+	Recv() (*SubmitTxsResponse, error)
+	grpc.ClientStream
+}
+
+type controlServiceSubmitTxsClient struct {
+	grpc.ClientStream
+}
+
+func (x *controlServiceSubmitTxsClient) Send(m *SubmitTxsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *controlServiceSubmitTxsClient) Recv() (*SubmitTxsResponse, error) {
+	m := new(SubmitTxsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controlServiceClient) GetValidators(ctx context.Context, in *GetValidatorsRequest, opts ...grpc.CallOption) (*GetValidatorsResponse, error) {
+	out := new(GetValidatorsResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetValidators_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) GetTime(ctx context.Context, in *GetTimeRequest, opts ...grpc.CallOption) (*GetTimeResponse, error) {
+	out := new(GetTimeResponse)
+	err := c.cc.Invoke(ctx, ControlService_GetTime_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeResponse, error) {
+	out := new(ResumeResponse)
+	err := c.cc.Invoke(ctx, ControlService_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlServiceClient) ReloadApps(ctx context.Context, in *ReloadAppsRequest, opts ...grpc.CallOption) (*ReloadAppsResponse, error) {
+	out := new(ReloadAppsResponse)
+	err := c.cc.Invoke(ctx, ControlService_ReloadApps_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ControlServiceServer is the server API for ControlService service.
+// All implementations must embed UnimplementedControlServiceServer
+// for forward compatibility
+type ControlServiceServer interface {
+	// AdvanceBlocks runs the given number of empty blocks in succession.
+	AdvanceBlocks(context.Context, *AdvanceBlocksRequest) (*AdvanceBlocksResponse, error)
+	// AdvanceTime advances the timestamps of all following blocks by the given duration.
+	AdvanceTime(context.Context, *AdvanceTimeRequest) (*AdvanceTimeResponse, error)
+	// SetSigningStatus decides whether the given validator signs blocks.
+	SetSigningStatus(context.Context, *SetSigningStatusRequest) (*SetSigningStatusResponse, error)
+	// GetSigningStatus returns the signing status of all validators.
+	GetSigningStatus(context.Context, *GetSigningStatusRequest) (*GetSigningStatusResponse, error)
+	// CauseDoubleSign produces DuplicateVoteEvidence for the given validator
+	// and includes it in the next block.
+	CauseDoubleSign(context.Context, *CauseDoubleSignRequest) (*CauseDoubleSignResponse, error)
+	// CauseLightClientAttack produces LightClientAttackEvidence for the given validator
+	// and includes it in the next block.
+	CauseLightClientAttack(context.Context, *CauseLightClientAttackRequest) (*CauseLightClientAttackResponse, error)
+	// SubmitTxs accepts a stream of transactions, which are checked with CheckTx and queued for inclusion
+	// like via broadcast_tx_sync, and streams back the result of each transaction:
+	// whether CheckTx rejected it, or in which block it was included.
+	// This avoids a round trip per transaction for high-rate load tests.
+	// The response stream ends after the client closed its stream and all accepted transactions
+	// were included, or were not included in time.
+	SubmitTxs(ControlService_SubmitTxsServer) error
+	// GetValidators returns the validators of the next block with their voting power and signing status.
+	// The validator set changes through the validator updates that the apps return from FinalizeBlock,
+	// so this is how test frameworks follow validator changes, e.g. after a delegation or jailing.
+	GetValidators(context.Context, *GetValidatorsRequest) (*GetValidatorsResponse, error)
+	// GetTime returns the timestamp that the next block would have if it was produced now.
+	GetTime(context.Context, *GetTimeRequest) (*GetTimeResponse, error)
+	// Resume resumes block production after it was halted, e.g. because the apps stopped for an upgrade.
+	Resume(context.Context, *ResumeRequest) (*ResumeResponse, error)
+	// ReloadApps reconnects to the apps whose addresses changed or whose connection failed,
+	// while blocks are produced.
+	ReloadApps(context.Context, *ReloadAppsRequest) (*ReloadAppsResponse, error)
+	mustEmbedUnimplementedControlServiceServer()
+}
+
+// UnimplementedControlServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedControlServiceServer struct {
+}
+
+func (UnimplementedControlServiceServer) AdvanceBlocks(context.Context, *AdvanceBlocksRequest) (*AdvanceBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdvanceBlocks not implemented")
+}
+func (UnimplementedControlServiceServer) AdvanceTime(context.Context, *AdvanceTimeRequest) (*AdvanceTimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdvanceTime not implemented")
+}
+func (UnimplementedControlServiceServer) SetSigningStatus(context.Context, *SetSigningStatusRequest) (*SetSigningStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSigningStatus not implemented")
+}
+func (UnimplementedControlServiceServer) GetSigningStatus(context.Context, *GetSigningStatusRequest) (*GetSigningStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSigningStatus not implemented")
+}
+func (UnimplementedControlServiceServer) CauseDoubleSign(context.Context, *CauseDoubleSignRequest) (*CauseDoubleSignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CauseDoubleSign not implemented")
+}
+func (UnimplementedControlServiceServer) CauseLightClientAttack(context.Context, *CauseLightClientAttackRequest) (*CauseLightClientAttackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CauseLightClientAttack not implemented")
+}
+func (UnimplementedControlServiceServer) SubmitTxs(ControlService_SubmitTxsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitTxs not implemented")
+}
+func (UnimplementedControlServiceServer) GetValidators(context.Context, *GetValidatorsRequest) (*GetValidatorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidators not implemented")
+}
+func (UnimplementedControlServiceServer) GetTime(context.Context, *GetTimeRequest) (*GetTimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTime not implemented")
+}
+func (UnimplementedControlServiceServer) Resume(context.Context, *ResumeRequest) (*ResumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedControlServiceServer) ReloadApps(context.Context, *ReloadAppsRequest) (*ReloadAppsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadApps not implemented")
+}
+func (UnimplementedControlServiceServer) mustEmbedUnimplementedControlServiceServer() {}
+
+// UnsafeControlServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ControlServiceServer will
+// result in compilation errors.
+type UnsafeControlServiceServer interface {
+	mustEmbedUnimplementedControlServiceServer()
+}
+
+func RegisterControlServiceServer(s grpc.ServiceRegistrar, srv ControlServiceServer) {
+	s.RegisterService(&ControlService_ServiceDesc, srv)
+}
+
+func _ControlService_AdvanceBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdvanceBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).AdvanceBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_AdvanceBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).AdvanceBlocks(ctx, req.(*AdvanceBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_AdvanceTime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdvanceTimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).AdvanceTime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_AdvanceTime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).AdvanceTime(ctx, req.(*AdvanceTimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_SetSigningStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSigningStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).SetSigningStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_SetSigningStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).SetSigningStatus(ctx, req.(*SetSigningStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetSigningStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSigningStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetSigningStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetSigningStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetSigningStatus(ctx, req.(*GetSigningStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_CauseDoubleSign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CauseDoubleSignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).CauseDoubleSign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_CauseDoubleSign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).CauseDoubleSign(ctx, req.(*CauseDoubleSignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_CauseLightClientAttack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CauseLightClientAttackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).CauseLightClientAttack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_CauseLightClientAttack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).CauseLightClientAttack(ctx, req.(*CauseLightClientAttackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_SubmitTxs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ControlServiceServer).SubmitTxs(&controlServiceSubmitTxsServer{stream})
+}
+
+type ControlService_SubmitTxsServer interface {
+	Send(*SubmitTxsResponse) error
+	Recv() (*SubmitTxsRequest, error)
+	grpc.ServerStream
+}
+
+type controlServiceSubmitTxsServer struct {
+	grpc.ServerStream
+}
+
+func (x *controlServiceSubmitTxsServer) Send(m *SubmitTxsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *controlServiceSubmitTxsServer) Recv() (*SubmitTxsRequest, error) {
+	m := new(SubmitTxsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ControlService_GetValidators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidatorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetValidators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetValidators_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetValidators(ctx, req.(*GetValidatorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_GetTime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).GetTime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_GetTime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).GetTime(ctx, req.(*GetTimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlService_ReloadApps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadAppsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServiceServer).ReloadApps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlService_ReloadApps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServiceServer).ReloadApps(ctx, req.(*ReloadAppsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ControlService_ServiceDesc is the grpc.ServiceDesc for ControlService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ControlService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cometmock.control.v1.ControlService",
+	HandlerType: (*ControlServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AdvanceBlocks",
+			Handler:    _ControlService_AdvanceBlocks_Handler,
+		},
+		{
+			MethodName: "AdvanceTime",
+			Handler:    _ControlService_AdvanceTime_Handler,
+		},
+		{
+			MethodName: "SetSigningStatus",
+			Handler:    _ControlService_SetSigningStatus_Handler,
+		},
+		{
+			MethodName: "GetSigningStatus",
+			Handler:    _ControlService_GetSigningStatus_Handler,
+		},
+		{
+			MethodName: "CauseDoubleSign",
+			Handler:    _ControlService_CauseDoubleSign_Handler,
+		},
+		{
+			MethodName: "CauseLightClientAttack",
+			Handler:    _ControlService_CauseLightClientAttack_Handler,
+		},
+		{
+			MethodName: "GetValidators",
+			Handler:    _ControlService_GetValidators_Handler,
+		},
+		{
+			MethodName: "GetTime",
+			Handler:    _ControlService_GetTime_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _ControlService_Resume_Handler,
+		},
+		{
+			MethodName: "ReloadApps",
+			Handler:    _ControlService_ReloadApps_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubmitTxs",
+			Handler:       _ControlService_SubmitTxs_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "cometmock/control/v1/control.proto",
+}
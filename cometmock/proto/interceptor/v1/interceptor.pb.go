@@ -0,0 +1,808 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: cometmock/interceptor/v1/interceptor.proto
+
+package interceptorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type BeforeProposalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The height of the block.
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// The address of the proposer of the block.
+	ProposerAddress []byte `protobuf:"bytes,2,opt,name=proposer_address,json=proposerAddress,proto3" json:"proposer_address,omitempty"`
+	// The transactions that are proposed.
+	Txs [][]byte `protobuf:"bytes,3,rep,name=txs,proto3" json:"txs,omitempty"`
+}
+
+func (x *BeforeProposalRequest) Reset() {
+	*x = BeforeProposalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BeforeProposalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeforeProposalRequest) ProtoMessage() {}
+
+func (x *BeforeProposalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeforeProposalRequest.ProtoReflect.Descriptor instead.
+func (*BeforeProposalRequest) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BeforeProposalRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BeforeProposalRequest) GetProposerAddress() []byte {
+	if x != nil {
+		return x.ProposerAddress
+	}
+	return nil
+}
+
+func (x *BeforeProposalRequest) GetTxs() [][]byte {
+	if x != nil {
+		return x.Txs
+	}
+	return nil
+}
+
+type BeforeProposalResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If true, txs are proposed instead of the transactions in the request.
+	ReplaceTxs bool     `protobuf:"varint,1,opt,name=replace_txs,json=replaceTxs,proto3" json:"replace_txs,omitempty"`
+	Txs        [][]byte `protobuf:"bytes,2,rep,name=txs,proto3" json:"txs,omitempty"`
+	// If true, the block is not produced.
+	Veto bool `protobuf:"varint,3,opt,name=veto,proto3" json:"veto,omitempty"`
+	// Why the block was vetoed, which is part of the error returned by CometMock.
+	VetoReason string `protobuf:"bytes,4,opt,name=veto_reason,json=vetoReason,proto3" json:"veto_reason,omitempty"`
+}
+
+func (x *BeforeProposalResponse) Reset() {
+	*x = BeforeProposalResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BeforeProposalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeforeProposalResponse) ProtoMessage() {}
+
+func (x *BeforeProposalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeforeProposalResponse.ProtoReflect.Descriptor instead.
+func (*BeforeProposalResponse) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BeforeProposalResponse) GetReplaceTxs() bool {
+	if x != nil {
+		return x.ReplaceTxs
+	}
+	return false
+}
+
+func (x *BeforeProposalResponse) GetTxs() [][]byte {
+	if x != nil {
+		return x.Txs
+	}
+	return nil
+}
+
+func (x *BeforeProposalResponse) GetVeto() bool {
+	if x != nil {
+		return x.Veto
+	}
+	return false
+}
+
+func (x *BeforeProposalResponse) GetVetoReason() string {
+	if x != nil {
+		return x.VetoReason
+	}
+	return ""
+}
+
+type FilterVotesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The height of the block.
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// The hash of the block.
+	BlockHash []byte `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	// The addresses of the validators that signed the block.
+	SignerAddresses [][]byte `protobuf:"bytes,3,rep,name=signer_addresses,json=signerAddresses,proto3" json:"signer_addresses,omitempty"`
+}
+
+func (x *FilterVotesRequest) Reset() {
+	*x = FilterVotesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterVotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterVotesRequest) ProtoMessage() {}
+
+func (x *FilterVotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterVotesRequest.ProtoReflect.Descriptor instead.
+func (*FilterVotesRequest) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FilterVotesRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *FilterVotesRequest) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *FilterVotesRequest) GetSignerAddresses() [][]byte {
+	if x != nil {
+		return x.SignerAddresses
+	}
+	return nil
+}
+
+type FilterVotesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The addresses of the validators whose votes are dropped, so that they miss the block.
+	DroppedAddresses [][]byte `protobuf:"bytes,1,rep,name=dropped_addresses,json=droppedAddresses,proto3" json:"dropped_addresses,omitempty"`
+}
+
+func (x *FilterVotesResponse) Reset() {
+	*x = FilterVotesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterVotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterVotesResponse) ProtoMessage() {}
+
+func (x *FilterVotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterVotesResponse.ProtoReflect.Descriptor instead.
+func (*FilterVotesResponse) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FilterVotesResponse) GetDroppedAddresses() [][]byte {
+	if x != nil {
+		return x.DroppedAddresses
+	}
+	return nil
+}
+
+type BeforeFinalizeBlockRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The height of the block.
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// The hash of the block.
+	BlockHash []byte `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	// The address of the proposer of the block.
+	ProposerAddress []byte `protobuf:"bytes,3,opt,name=proposer_address,json=proposerAddress,proto3" json:"proposer_address,omitempty"`
+	// The time of the block.
+	Time *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	// The transactions in the block, after PrepareProposal.
+	Txs [][]byte `protobuf:"bytes,5,rep,name=txs,proto3" json:"txs,omitempty"`
+	// The addresses of the validators that signed the block.
+	SignerAddresses [][]byte `protobuf:"bytes,6,rep,name=signer_addresses,json=signerAddresses,proto3" json:"signer_addresses,omitempty"`
+}
+
+func (x *BeforeFinalizeBlockRequest) Reset() {
+	*x = BeforeFinalizeBlockRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BeforeFinalizeBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeforeFinalizeBlockRequest) ProtoMessage() {}
+
+func (x *BeforeFinalizeBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeforeFinalizeBlockRequest.ProtoReflect.Descriptor instead.
+func (*BeforeFinalizeBlockRequest) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BeforeFinalizeBlockRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BeforeFinalizeBlockRequest) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *BeforeFinalizeBlockRequest) GetProposerAddress() []byte {
+	if x != nil {
+		return x.ProposerAddress
+	}
+	return nil
+}
+
+func (x *BeforeFinalizeBlockRequest) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *BeforeFinalizeBlockRequest) GetTxs() [][]byte {
+	if x != nil {
+		return x.Txs
+	}
+	return nil
+}
+
+func (x *BeforeFinalizeBlockRequest) GetSignerAddresses() [][]byte {
+	if x != nil {
+		return x.SignerAddresses
+	}
+	return nil
+}
+
+type BeforeFinalizeBlockResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If true, the block is not produced.
+	Veto bool `protobuf:"varint,1,opt,name=veto,proto3" json:"veto,omitempty"`
+	// Why the block was vetoed, which is part of the error returned by CometMock.
+	VetoReason string `protobuf:"bytes,2,opt,name=veto_reason,json=vetoReason,proto3" json:"veto_reason,omitempty"`
+}
+
+func (x *BeforeFinalizeBlockResponse) Reset() {
+	*x = BeforeFinalizeBlockResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BeforeFinalizeBlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeforeFinalizeBlockResponse) ProtoMessage() {}
+
+func (x *BeforeFinalizeBlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeforeFinalizeBlockResponse.ProtoReflect.Descriptor instead.
+func (*BeforeFinalizeBlockResponse) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BeforeFinalizeBlockResponse) GetVeto() bool {
+	if x != nil {
+		return x.Veto
+	}
+	return false
+}
+
+func (x *BeforeFinalizeBlockResponse) GetVetoReason() string {
+	if x != nil {
+		return x.VetoReason
+	}
+	return ""
+}
+
+type AfterCommitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The height of the block.
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// The hash of the block.
+	BlockHash []byte `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	// The app hash after executing the block.
+	AppHash []byte `protobuf:"bytes,3,opt,name=app_hash,json=appHash,proto3" json:"app_hash,omitempty"`
+	// The result codes of the transactions in the block.
+	TxCodes []uint32 `protobuf:"varint,4,rep,packed,name=tx_codes,json=txCodes,proto3" json:"tx_codes,omitempty"`
+}
+
+func (x *AfterCommitRequest) Reset() {
+	*x = AfterCommitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AfterCommitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AfterCommitRequest) ProtoMessage() {}
+
+func (x *AfterCommitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AfterCommitRequest.ProtoReflect.Descriptor instead.
+func (*AfterCommitRequest) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AfterCommitRequest) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *AfterCommitRequest) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *AfterCommitRequest) GetAppHash() []byte {
+	if x != nil {
+		return x.AppHash
+	}
+	return nil
+}
+
+func (x *AfterCommitRequest) GetTxCodes() []uint32 {
+	if x != nil {
+		return x.TxCodes
+	}
+	return nil
+}
+
+type AfterCommitResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AfterCommitResponse) Reset() {
+	*x = AfterCommitResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AfterCommitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AfterCommitResponse) ProtoMessage() {}
+
+func (x *AfterCommitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cometmock_interceptor_v1_interceptor_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AfterCommitResponse.ProtoReflect.Descriptor instead.
+func (*AfterCommitResponse) Descriptor() ([]byte, []int) {
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP(), []int{7}
+}
+
+var File_cometmock_interceptor_v1_interceptor_proto protoreflect.FileDescriptor
+
+var file_cometmock_interceptor_v1_interceptor_proto_rawDesc = []byte{
+	0x0a, 0x2a, 0x63, 0x6f, 0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x18, 0x63, 0x6f,
+	0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70,
+	0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6c, 0x0a, 0x15, 0x42, 0x65, 0x66, 0x6f, 0x72,
+	0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x03, 0x74, 0x78, 0x73, 0x22, 0x80, 0x01, 0x0a, 0x16, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x5f, 0x74, 0x78, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x54, 0x78,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x03,
+	0x74, 0x78, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x65, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x04, 0x76, 0x65, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x65, 0x74, 0x6f, 0x5f,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x76, 0x65,
+	0x74, 0x6f, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x76, 0x0a, 0x12, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
+	0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x0f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73,
+	0x22, 0x42, 0x0a, 0x13, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0c, 0x52, 0x10, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x22, 0xeb, 0x01, 0x0a, 0x1a, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x46,
+	0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x78, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0c, 0x52, 0x03, 0x74, 0x78, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x69, 0x67, 0x6e, 0x65,
+	0x72, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0c, 0x52, 0x0f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x22, 0x52, 0x0a, 0x1b, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6e, 0x61,
+	0x6c, 0x69, 0x7a, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x65, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x04, 0x76, 0x65, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x65, 0x74, 0x6f, 0x5f, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x76, 0x65, 0x74, 0x6f,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x81, 0x01, 0x0a, 0x12, 0x41, 0x66, 0x74, 0x65, 0x72,
+	0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x68,
+	0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x48, 0x61, 0x73, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x70, 0x70, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x70, 0x70, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x78, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x07, 0x74, 0x78, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x15, 0x0a, 0x13, 0x41, 0x66,
+	0x74, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x32, 0xe6, 0x03, 0x0a, 0x12, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x6f,
+	0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x73, 0x0a, 0x0e, 0x42, 0x65, 0x66, 0x6f,
+	0x72, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x2f, 0x2e, 0x63, 0x6f, 0x6d,
+	0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74,
+	0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x63, 0x6f,
+	0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70,
+	0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x50, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6a, 0x0a,
+	0x0b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x2c, 0x2e, 0x63,
+	0x6f, 0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65,
+	0x70, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x56, 0x6f,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x63, 0x6f, 0x6d,
+	0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74,
+	0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x82, 0x01, 0x0a, 0x13, 0x42, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x12, 0x34, 0x2e, 0x63, 0x6f, 0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x63, 0x6f, 0x6d, 0x65, 0x74, 0x6d,
+	0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a,
+	0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6a,
+	0x0a, 0x0b, 0x41, 0x66, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x2c, 0x2e,
+	0x63, 0x6f, 0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63,
+	0x65, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x63, 0x6f,
+	0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70,
+	0x74, 0x6f, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x53, 0x5a, 0x51, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6e, 0x66, 0x6f, 0x72, 0x6d, 0x61,
+	0x6c, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73, 0x2f, 0x43, 0x6f, 0x6d, 0x65, 0x74, 0x4d, 0x6f,
+	0x63, 0x6b, 0x2f, 0x63, 0x6f, 0x6d, 0x65, 0x74, 0x6d, 0x6f, 0x63, 0x6b, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x2f, 0x76,
+	0x31, 0x3b, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x63, 0x65, 0x70, 0x74, 0x6f, 0x72, 0x76, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_cometmock_interceptor_v1_interceptor_proto_rawDescOnce sync.Once
+	file_cometmock_interceptor_v1_interceptor_proto_rawDescData = file_cometmock_interceptor_v1_interceptor_proto_rawDesc
+)
+
+func file_cometmock_interceptor_v1_interceptor_proto_rawDescGZIP() []byte {
+	file_cometmock_interceptor_v1_interceptor_proto_rawDescOnce.Do(func() {
+		file_cometmock_interceptor_v1_interceptor_proto_rawDescData = protoimpl.X.CompressGZIP(file_cometmock_interceptor_v1_interceptor_proto_rawDescData)
+	})
+	return file_cometmock_interceptor_v1_interceptor_proto_rawDescData
+}
+
+var file_cometmock_interceptor_v1_interceptor_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cometmock_interceptor_v1_interceptor_proto_goTypes = []interface{}{
+	(*BeforeProposalRequest)(nil),       // 0: cometmock.interceptor.v1.BeforeProposalRequest
+	(*BeforeProposalResponse)(nil),      // 1: cometmock.interceptor.v1.BeforeProposalResponse
+	(*FilterVotesRequest)(nil),          // 2: cometmock.interceptor.v1.FilterVotesRequest
+	(*FilterVotesResponse)(nil),         // 3: cometmock.interceptor.v1.FilterVotesResponse
+	(*BeforeFinalizeBlockRequest)(nil),  // 4: cometmock.interceptor.v1.BeforeFinalizeBlockRequest
+	(*BeforeFinalizeBlockResponse)(nil), // 5: cometmock.interceptor.v1.BeforeFinalizeBlockResponse
+	(*AfterCommitRequest)(nil),          // 6: cometmock.interceptor.v1.AfterCommitRequest
+	(*AfterCommitResponse)(nil),         // 7: cometmock.interceptor.v1.AfterCommitResponse
+	(*timestamppb.Timestamp)(nil),       // 8: google.protobuf.Timestamp
+}
+var file_cometmock_interceptor_v1_interceptor_proto_depIdxs = []int32{
+	8, // 0: cometmock.interceptor.v1.BeforeFinalizeBlockRequest.time:type_name -> google.protobuf.Timestamp
+	0, // 1: cometmock.interceptor.v1.InterceptorService.BeforeProposal:input_type -> cometmock.interceptor.v1.BeforeProposalRequest
+	2, // 2: cometmock.interceptor.v1.InterceptorService.FilterVotes:input_type -> cometmock.interceptor.v1.FilterVotesRequest
+	4, // 3: cometmock.interceptor.v1.InterceptorService.BeforeFinalizeBlock:input_type -> cometmock.interceptor.v1.BeforeFinalizeBlockRequest
+	6, // 4: cometmock.interceptor.v1.InterceptorService.AfterCommit:input_type -> cometmock.interceptor.v1.AfterCommitRequest
+	1, // 5: cometmock.interceptor.v1.InterceptorService.BeforeProposal:output_type -> cometmock.interceptor.v1.BeforeProposalResponse
+	3, // 6: cometmock.interceptor.v1.InterceptorService.FilterVotes:output_type -> cometmock.interceptor.v1.FilterVotesResponse
+	5, // 7: cometmock.interceptor.v1.InterceptorService.BeforeFinalizeBlock:output_type -> cometmock.interceptor.v1.BeforeFinalizeBlockResponse
+	7, // 8: cometmock.interceptor.v1.InterceptorService.AfterCommit:output_type -> cometmock.interceptor.v1.AfterCommitResponse
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_cometmock_interceptor_v1_interceptor_proto_init() }
+func file_cometmock_interceptor_v1_interceptor_proto_init() {
+	if File_cometmock_interceptor_v1_interceptor_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BeforeProposalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BeforeProposalResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterVotesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterVotesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BeforeFinalizeBlockRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BeforeFinalizeBlockResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AfterCommitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cometmock_interceptor_v1_interceptor_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AfterCommitResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_cometmock_interceptor_v1_interceptor_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cometmock_interceptor_v1_interceptor_proto_goTypes,
+		DependencyIndexes: file_cometmock_interceptor_v1_interceptor_proto_depIdxs,
+		MessageInfos:      file_cometmock_interceptor_v1_interceptor_proto_msgTypes,
+	}.Build()
+	File_cometmock_interceptor_v1_interceptor_proto = out.File
+	file_cometmock_interceptor_v1_interceptor_proto_rawDesc = nil
+	file_cometmock_interceptor_v1_interceptor_proto_goTypes = nil
+	file_cometmock_interceptor_v1_interceptor_proto_depIdxs = nil
+}
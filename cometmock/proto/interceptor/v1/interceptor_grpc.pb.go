@@ -0,0 +1,234 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: cometmock/interceptor/v1/interceptor.proto
+
+package interceptorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	InterceptorService_BeforeProposal_FullMethodName      = "/cometmock.interceptor.v1.InterceptorService/BeforeProposal"
+	InterceptorService_FilterVotes_FullMethodName         = "/cometmock.interceptor.v1.InterceptorService/FilterVotes"
+	InterceptorService_BeforeFinalizeBlock_FullMethodName = "/cometmock.interceptor.v1.InterceptorService/BeforeFinalizeBlock"
+	InterceptorService_AfterCommit_FullMethodName         = "/cometmock.interceptor.v1.InterceptorService/AfterCommit"
+)
+
+// InterceptorServiceClient is the client API for InterceptorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InterceptorServiceClient interface {
+	// BeforeProposal is called before the proposer runs PrepareProposal.
+	// The plugin can replace the transactions to propose, or veto the block.
+	BeforeProposal(ctx context.Context, in *BeforeProposalRequest, opts ...grpc.CallOption) (*BeforeProposalResponse, error)
+	// FilterVotes is called with the validators that signed the block, before their votes are added to the commit.
+	// The plugin can drop votes, as long as more than 2/3 of the voting power still signs the block.
+	FilterVotes(ctx context.Context, in *FilterVotesRequest, opts ...grpc.CallOption) (*FilterVotesResponse, error)
+	// BeforeFinalizeBlock is called before FinalizeBlock is sent to the apps.
+	// The plugin can veto the block.
+	BeforeFinalizeBlock(ctx context.Context, in *BeforeFinalizeBlockRequest, opts ...grpc.CallOption) (*BeforeFinalizeBlockResponse, error)
+	// AfterCommit notifies the plugin that the apps committed the block.
+	AfterCommit(ctx context.Context, in *AfterCommitRequest, opts ...grpc.CallOption) (*AfterCommitResponse, error)
+}
+
+type interceptorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInterceptorServiceClient(cc grpc.ClientConnInterface) InterceptorServiceClient {
+	return &interceptorServiceClient{cc}
+}
+
+func (c *interceptorServiceClient) BeforeProposal(ctx context.Context, in *BeforeProposalRequest, opts ...grpc.CallOption) (*BeforeProposalResponse, error) {
+	out := new(BeforeProposalResponse)
+	err := c.cc.Invoke(ctx, InterceptorService_BeforeProposal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interceptorServiceClient) FilterVotes(ctx context.Context, in *FilterVotesRequest, opts ...grpc.CallOption) (*FilterVotesResponse, error) {
+	out := new(FilterVotesResponse)
+	err := c.cc.Invoke(ctx, InterceptorService_FilterVotes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interceptorServiceClient) BeforeFinalizeBlock(ctx context.Context, in *BeforeFinalizeBlockRequest, opts ...grpc.CallOption) (*BeforeFinalizeBlockResponse, error) {
+	out := new(BeforeFinalizeBlockResponse)
+	err := c.cc.Invoke(ctx, InterceptorService_BeforeFinalizeBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interceptorServiceClient) AfterCommit(ctx context.Context, in *AfterCommitRequest, opts ...grpc.CallOption) (*AfterCommitResponse, error) {
+	out := new(AfterCommitResponse)
+	err := c.cc.Invoke(ctx, InterceptorService_AfterCommit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InterceptorServiceServer is the server API for InterceptorService service.
+// All implementations must embed UnimplementedInterceptorServiceServer
+// for forward compatibility
+type InterceptorServiceServer interface {
+	// BeforeProposal is called before the proposer runs PrepareProposal.
+	// The plugin can replace the transactions to propose, or veto the block.
+	BeforeProposal(context.Context, *BeforeProposalRequest) (*BeforeProposalResponse, error)
+	// FilterVotes is called with the validators that signed the block, before their votes are added to the commit.
+	// The plugin can drop votes, as long as more than 2/3 of the voting power still signs the block.
+	FilterVotes(context.Context, *FilterVotesRequest) (*FilterVotesResponse, error)
+	// BeforeFinalizeBlock is called before FinalizeBlock is sent to the apps.
+	// The plugin can veto the block.
+	BeforeFinalizeBlock(context.Context, *BeforeFinalizeBlockRequest) (*BeforeFinalizeBlockResponse, error)
+	// AfterCommit notifies the plugin that the apps committed the block.
+	AfterCommit(context.Context, *AfterCommitRequest) (*AfterCommitResponse, error)
+	mustEmbedUnimplementedInterceptorServiceServer()
+}
+
+// UnimplementedInterceptorServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedInterceptorServiceServer struct {
+}
+
+func (UnimplementedInterceptorServiceServer) BeforeProposal(context.Context, *BeforeProposalRequest) (*BeforeProposalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeforeProposal not implemented")
+}
+func (UnimplementedInterceptorServiceServer) FilterVotes(context.Context, *FilterVotesRequest) (*FilterVotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FilterVotes not implemented")
+}
+func (UnimplementedInterceptorServiceServer) BeforeFinalizeBlock(context.Context, *BeforeFinalizeBlockRequest) (*BeforeFinalizeBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeforeFinalizeBlock not implemented")
+}
+func (UnimplementedInterceptorServiceServer) AfterCommit(context.Context, *AfterCommitRequest) (*AfterCommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AfterCommit not implemented")
+}
+func (UnimplementedInterceptorServiceServer) mustEmbedUnimplementedInterceptorServiceServer() {}
+
+// UnsafeInterceptorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InterceptorServiceServer will
+// result in compilation errors.
+type UnsafeInterceptorServiceServer interface {
+	mustEmbedUnimplementedInterceptorServiceServer()
+}
+
+func RegisterInterceptorServiceServer(s grpc.ServiceRegistrar, srv InterceptorServiceServer) {
+	s.RegisterService(&InterceptorService_ServiceDesc, srv)
+}
+
+func _InterceptorService_BeforeProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeforeProposalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterceptorServiceServer).BeforeProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InterceptorService_BeforeProposal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterceptorServiceServer).BeforeProposal(ctx, req.(*BeforeProposalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InterceptorService_FilterVotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterVotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterceptorServiceServer).FilterVotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InterceptorService_FilterVotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterceptorServiceServer).FilterVotes(ctx, req.(*FilterVotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InterceptorService_BeforeFinalizeBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeforeFinalizeBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterceptorServiceServer).BeforeFinalizeBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InterceptorService_BeforeFinalizeBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterceptorServiceServer).BeforeFinalizeBlock(ctx, req.(*BeforeFinalizeBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InterceptorService_AfterCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AfterCommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterceptorServiceServer).AfterCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InterceptorService_AfterCommit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterceptorServiceServer).AfterCommit(ctx, req.(*AfterCommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InterceptorService_ServiceDesc is the grpc.ServiceDesc for InterceptorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InterceptorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cometmock.interceptor.v1.InterceptorService",
+	HandlerType: (*InterceptorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BeforeProposal",
+			Handler:    _InterceptorService_BeforeProposal_Handler,
+		},
+		{
+			MethodName: "FilterVotes",
+			Handler:    _InterceptorService_FilterVotes_Handler,
+		},
+		{
+			MethodName: "BeforeFinalizeBlock",
+			Handler:    _InterceptorService_BeforeFinalizeBlock_Handler,
+		},
+		{
+			MethodName: "AfterCommit",
+			Handler:    _InterceptorService_AfterCommit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cometmock/interceptor/v1/interceptor.proto",
+}
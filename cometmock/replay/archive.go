@@ -0,0 +1,183 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbm "github.com/cometbft/cometbft-db"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/store"
+	"github.com/cometbft/cometbft/types"
+)
+
+// Archive is a sequence of blocks recorded on a live chain.
+type Archive interface {
+	// Next returns the next block of the archive, or io.EOF if there are no more blocks.
+	Next() (*types.Block, error)
+
+	Close() error
+}
+
+// OpenArchive opens the archive at the given path, which is either
+// the data directory of a CometBFT node, containing the block store (blockstore.db),
+// or a JSON file with the blocks in the format returned by the block RPC endpoint,
+// either as a JSON array or as one block per line, see JSONArchive.
+func OpenArchive(path string) (Archive, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return OpenBlockStoreArchive(path)
+	}
+	return OpenJSONArchive(path)
+}
+
+// BlockStoreArchive reads blocks from the block store of a CometBFT node.
+type BlockStoreArchive struct {
+	store  *store.BlockStore
+	height int64
+}
+
+var _ Archive = (*BlockStoreArchive)(nil)
+
+// OpenBlockStoreArchive opens the block store (blockstore.db) in the given data directory of a CometBFT node.
+// The node must not be running, since the block store can only be opened by one process.
+func OpenBlockStoreArchive(dataDir string) (*BlockStoreArchive, error) {
+	if _, err := os.Stat(filepath.Join(dataDir, "blockstore.db")); err != nil {
+		return nil, fmt.Errorf("no block store found in %v: %w", dataDir, err)
+	}
+
+	db, err := dbm.NewDB("blockstore", dbm.GoLevelDBBackend, dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening block store in %v: %w", dataDir, err)
+	}
+
+	blockStore := store.NewBlockStore(db)
+	return &BlockStoreArchive{
+		store:  blockStore,
+		height: blockStore.Base(),
+	}, nil
+}
+
+func (a *BlockStoreArchive) Next() (*types.Block, error) {
+	if a.height == 0 || a.height > a.store.Height() {
+		return nil, io.EOF
+	}
+
+	block := a.store.LoadBlock(a.height)
+	if block == nil {
+		return nil, fmt.Errorf("block at height %d not found in block store", a.height)
+	}
+	a.height++
+	return block, nil
+}
+
+func (a *BlockStoreArchive) Close() error {
+	return a.store.Close()
+}
+
+// JSONArchive reads blocks from a JSON file.
+// Each block is either the result of the block RPC endpoint, i.e. {"block_id": ..., "block": ...},
+// or the whole JSON-RPC response, i.e. {"jsonrpc": "2.0", "result": {"block_id": ..., "block": ...}},
+// so the output of curl can be used as is.
+// The blocks are either given as a JSON array, or as a sequence of JSON values, e.g. one per line.
+type JSONArchive struct {
+	file    *os.File
+	decoder *json.Decoder
+	isArray bool
+}
+
+var _ Archive = (*JSONArchive)(nil)
+
+func OpenJSONArchive(path string) (*JSONArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(file)
+	isArray, err := startsWithArray(reader)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading archive %v: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(reader)
+	if isArray {
+		// consume the opening bracket
+		if _, err := decoder.Token(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error reading archive %v: %w", path, err)
+		}
+	}
+
+	return &JSONArchive{
+		file:    file,
+		decoder: decoder,
+		isArray: isArray,
+	}, nil
+}
+
+// startsWithArray returns whether the first non-whitespace character is the start of a JSON array.
+func startsWithArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			return b[0] == '[', nil
+		}
+		if _, err := reader.ReadByte(); err != nil {
+			return false, err
+		}
+	}
+}
+
+// jsonBlock matches both the result of the block RPC endpoint and the whole JSON-RPC response.
+type jsonBlock struct {
+	Block  *types.Block `json:"block"`
+	Result *struct {
+		Block *types.Block `json:"block"`
+	} `json:"result"`
+}
+
+func (a *JSONArchive) Next() (*types.Block, error) {
+	if a.isArray && !a.decoder.More() {
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := a.decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var value jsonBlock
+	if err := cmtjson.Unmarshal(bytes.TrimSpace(raw), &value); err != nil {
+		return nil, fmt.Errorf("error decoding block: %w", err)
+	}
+
+	block := value.Block
+	if value.Result != nil {
+		block = value.Result.Block
+	}
+	if block == nil {
+		return nil, fmt.Errorf("no block found in %s", raw)
+	}
+	return block, nil
+}
+
+func (a *JSONArchive) Close() error {
+	return a.file.Close()
+}
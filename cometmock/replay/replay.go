@@ -0,0 +1,178 @@
+// Package replay feeds blocks that were recorded on a live chain through the apps
+// connected to CometMock, and compares the resulting app hashes, results hashes and
+// validator sets with the ones recorded in the headers of the following blocks.
+// This allows checking whether a new version of an app replays a live chain correctly
+// without running a full node.
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+)
+
+// Mismatch describes a block after which the state of the apps differs
+// from the state recorded in the header of the next block.
+type Mismatch struct {
+	// the height of the block after which the state differs
+	Height int64 `json:"height"`
+	// the field of the header of the next block that differs, e.g. app_hash
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("%v after block %d differs: expected %v, got %v", m.Field, m.Height, m.Expected, m.Actual)
+}
+
+// Status describes the progress of a replay.
+type Status struct {
+	Running bool `json:"running"`
+	// the height of the last block that was replayed
+	Height         int64 `json:"height"`
+	BlocksReplayed int64 `json:"blocks_replayed"`
+	// the first mismatch that was found, after which the replay stops
+	Mismatch *Mismatch `json:"mismatch,omitempty"`
+	// the error that stopped the replay, if any
+	Error string    `json:"error,omitempty"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitempty"`
+}
+
+// Replayer replays the blocks of an archive through the apps of an AbciClient.
+type Replayer struct {
+	client  *abci_client.AbciClient
+	archive Archive
+
+	mutex  sync.RWMutex
+	status Status
+}
+
+func NewReplayer(client *abci_client.AbciClient, archive Archive) *Replayer {
+	return &Replayer{
+		client:  client,
+		archive: archive,
+	}
+}
+
+// Status returns the progress of the replay.
+func (r *Replayer) Status() Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.status
+}
+
+// Run replays all blocks of the archive, and checks the state of the apps after each block
+// against the header of the next block. The state after the last block cannot be checked.
+// It stops at the first mismatch, which is returned as a *Mismatch error.
+func (r *Replayer) Run() error {
+	r.updateStatus(func(status *Status) {
+		status.Running = true
+		status.Start = time.Now()
+	})
+
+	err := r.run()
+
+	r.updateStatus(func(status *Status) {
+		status.Running = false
+		status.End = time.Now()
+		var mismatch *Mismatch
+		if errors.As(err, &mismatch) {
+			status.Mismatch = mismatch
+		} else if err != nil {
+			status.Error = err.Error()
+		}
+	})
+	return err
+}
+
+func (r *Replayer) run() error {
+	block, err := r.archive.Next()
+	if err == io.EOF {
+		return errors.New("the archive contains no blocks")
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		next, err := r.archive.Next()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("error reading block after height %d: %w", block.Height, err)
+		}
+		if next != nil && next.Height != block.Height+1 {
+			return fmt.Errorf("the archive skips from height %d to height %d", block.Height, next.Height)
+		}
+
+		// the commit for the block is only known from the next block
+		var commit *types.Commit
+		if next != nil {
+			commit = next.LastCommit
+		}
+
+		if _, err := r.client.ReplayBlock(block, commit); err != nil {
+			return err
+		}
+
+		r.updateStatus(func(status *Status) {
+			status.Height = block.Height
+			status.BlocksReplayed++
+		})
+
+		if next == nil {
+			r.client.Logger.Info("Replayed all blocks, the state after the last block cannot be checked", "height", block.Height)
+			return nil
+		}
+
+		if err := r.check(block.Height, &next.Header); err != nil {
+			return err
+		}
+
+		block = next
+	}
+}
+
+// check compares the state of the apps after the block at the given height
+// with the state recorded in the header of the next block.
+func (r *Replayer) check(height int64, nextHeader *types.Header) error {
+	curState := r.client.CurState
+
+	checks := []struct {
+		field    string
+		expected cmtbytes.HexBytes
+		actual   cmtbytes.HexBytes
+	}{
+		{"app_hash", nextHeader.AppHash, curState.AppHash},
+		{"last_results_hash", nextHeader.LastResultsHash, curState.LastResultsHash},
+		{"validators_hash", nextHeader.ValidatorsHash, curState.Validators.Hash()},
+		{"next_validators_hash", nextHeader.NextValidatorsHash, curState.NextValidators.Hash()},
+	}
+
+	for _, check := range checks {
+		if !bytes.Equal(check.expected, check.actual) {
+			return &Mismatch{
+				Height:   height,
+				Field:    check.field,
+				Expected: check.expected.String(),
+				Actual:   check.actual.String(),
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Replayer) updateStatus(update func(status *Status)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	update(&r.status)
+}
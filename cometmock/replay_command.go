@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/replay"
+	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/urfave/cli/v2"
+)
+
+// replayCommand replays a block archive through a set of apps and exits, for regression checks in CI.
+func replayCommand(logger cometlog.Logger) *cli.Command {
+	argumentString := "[--determinism-checks=<value>] <archive> <app-addresses> <genesis-file> <abci-connection-mode>"
+
+	return &cli.Command{
+		Name:  "replay",
+		Usage: "Replay a block archive of a live chain through the apps and exit non-zero if they diverge",
+		Description: `Replays the blocks in the archive through the apps at the given comma-separated addresses,
+like --replay-archive, but exits once the archive was replayed instead of serving the RPC.
+The archive is either the data directory of a CometBFT node, containing blockstore.db,
+or a JSON file with the responses of the block RPC endpoint.
+The genesis file needs to be the genesis of the live chain, and the archive needs to start at its initial height.
+After each block, the app hash, results hash and validator sets are compared with the header of the next block,
+and the responses of the apps are compared with each other according to --determinism-checks.
+If they differ, the first differing height and field are printed, and the exit code is 1.`,
+		ArgsUsage: argumentString,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "determinism-checks",
+				Usage: `
+Decides what happens when the apps respond differently to the same request, per ABCI method,
+see the flag of the same name of cometmock.`,
+				Value: "",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 4 {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock replay "+argumentString, 1)
+			}
+
+			archivePath := c.Args().Get(0)
+			appAddresses := strings.Split(c.Args().Get(1), ",")
+			genesisFile := c.Args().Get(2)
+			connectionMode := c.Args().Get(3)
+
+			if connectionMode != "socket" && connectionMode != "grpc" {
+				return cli.Exit(fmt.Sprintf("Invalid connection mode: %s. Connection mode must be either 'socket' or 'grpc'.", connectionMode), 1)
+			}
+
+			determinismChecks, err := abci_client.ParseDeterminismChecks(c.String("determinism-checks"))
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+
+			appGenesis, err := genutiltypes.AppGenesisFromFile(genesisFile)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+			}
+			genesisDoc, err := appGenesis.ToGenesisDoc()
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+			}
+			curState, err := state.MakeGenesisState(genesisDoc)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading genesis: %v", err), 1)
+			}
+
+			archive, err := replay.OpenArchive(archivePath)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error opening archive: %v", err), 1)
+			}
+			defer archive.Close()
+
+			// the blocks of the archive are signed by the validators of the live chain,
+			// so the apps only need keys to be told apart
+			privVals := make([]types.PrivValidator, len(appAddresses))
+			for i := range privVals {
+				privVals[i] = types.NewMockPV()
+			}
+
+			connectClient := NewConnectClient(connectionMode, logger)
+			clientMap, clientOrder, err := ConnectApps(appAddresses, privVals, connectClient, logger)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error connecting to apps: %v", err), 1)
+			}
+
+			client := abci_client.NewAbciClient(
+				clientMap,
+				logger,
+				curState,
+				&types.Block{},
+				&types.ExtendedCommit{},
+				&storage.MapStorage{},
+				abci_client.NewFixedBlockTimeHandler(0),
+				determinismChecks,
+			)
+			client.ClientOrder = clientOrder
+			client.ConnectClient = connectClient
+
+			err = client.SendInitChain(curState, genesisDoc)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error from InitChain: %v", err), 1)
+			}
+
+			replayer := replay.NewReplayer(client, archive)
+			err = replayer.Run()
+
+			var mismatch *replay.Mismatch
+			if errors.As(err, &mismatch) {
+				return cli.Exit(fmt.Sprintf("Diverged at height %d: %v expected %v, got %v",
+					mismatch.Height, mismatch.Field, mismatch.Expected, mismatch.Actual), 1)
+			} else if err != nil {
+				return cli.Exit(fmt.Sprintf("Error replaying archive after height %d: %v", replayer.Status().Height, err), 1)
+			}
+
+			status := replayer.Status()
+			fmt.Printf("Replayed %d blocks up to height %d without divergence\n", status.BlocksReplayed, status.Height)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,252 @@
+package rpc_server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cometbft/cometbft/libs/log"
+	cometmockversion "github.com/informalsystems/CometMock/cometmock/version"
+)
+
+// CompatHeader is the HTTP header that selects the version of CometBFT
+// whose JSON responses are rendered, see CompatHandler.
+const CompatHeader = "X-CometMock-Compat"
+
+// CompatV034 renders responses like CometBFT v0.34.
+const CompatV034 = "v0.34"
+
+// the version of CometBFT reported by the status endpoint in v0.34 compatibility mode
+const compatV034NodeVersion = "0.34.29"
+
+// CompatHandler wraps an HTTP handler, rendering the JSON-RPC responses in the shapes of an older CometBFT version,
+// for tools that have not migrated yet. The version is taken from the CompatHeader of the request,
+// or defaultVersion if the header is not set. If the version is empty, responses are not changed.
+// Only CompatV034 is supported, which changes responses as follows:
+//   - event attributes are base64 encoded
+//   - block_results has begin_block_events and end_block_events instead of finalize_block_events,
+//     which are split by the mode attribute that the Cosmos SDK adds to events
+//   - broadcast_tx_commit has deliver_tx instead of tx_result
+//   - status reports a v0.34 node version
+//
+// Websocket connections are not changed.
+func CompatHandler(handler http.Handler, defaultVersion string, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := r.Header.Get(CompatHeader)
+		if version == "" {
+			version = defaultVersion
+		}
+		if version == "" || r.URL.Path == "/websocket" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if version != CompatV034 {
+			http.Error(w, fmt.Sprintf("unsupported compatibility version %q, only %q is supported", version, CompatV034), http.StatusBadRequest)
+			return
+		}
+
+		methods, err := requestMethods(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		recorder := &responseRecorder{header: make(http.Header), statusCode: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+
+		body := recorder.body.Bytes()
+		if strings.HasPrefix(recorder.header.Get("Content-Type"), "application/json") {
+			if converted, err := convertResponsesToV034(body, methods); err != nil {
+				logger.Error("failed to convert response for compatibility, returning it unchanged", "err", err)
+			} else {
+				body = converted
+			}
+		}
+
+		for name, values := range recorder.header {
+			w.Header()[name] = values
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(recorder.statusCode)
+		_, _ = w.Write(body)
+	})
+}
+
+// requestMethods returns the methods of a JSON-RPC request, keyed by request id,
+// or keyed by "" for requests via URI.
+func requestMethods(r *http.Request) (map[string]string, error) {
+	if r.Method == http.MethodGet {
+		return map[string]string{"": strings.TrimPrefix(r.URL.Path, "/")}, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	type request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+
+	var requests []request
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(body, &requests); err != nil {
+			// leave it to the JSON-RPC server to report invalid requests
+			return map[string]string{}, nil
+		}
+	} else {
+		var single request
+		if err := json.Unmarshal(body, &single); err != nil {
+			return map[string]string{}, nil
+		}
+		requests = []request{single}
+	}
+
+	methods := make(map[string]string, len(requests))
+	for _, req := range requests {
+		methods[string(req.ID)] = req.Method
+	}
+	return methods, nil
+}
+
+// convertResponsesToV034 converts a single JSON-RPC response or a batch of them.
+func convertResponsesToV034(body []byte, methods map[string]string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, response := range v {
+			convertResponseToV034(response, methods)
+		}
+	default:
+		convertResponseToV034(v, methods)
+	}
+
+	return json.Marshal(value)
+}
+
+func convertResponseToV034(response interface{}, methods map[string]string) {
+	responseMap, ok := response.(map[string]interface{})
+	if !ok {
+		return
+	}
+	result, ok := responseMap["result"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	method, ok := methods[""]
+	if !ok {
+		id, err := json.Marshal(responseMap["id"])
+		if err != nil {
+			return
+		}
+		method = methods[string(id)]
+	}
+
+	switch method {
+	case "block_results":
+		beginBlockEvents := make([]interface{}, 0)
+		endBlockEvents := make([]interface{}, 0)
+		if events, ok := result["finalize_block_events"].([]interface{}); ok {
+			for _, event := range events {
+				if eventMode(event) == "BeginBlock" {
+					beginBlockEvents = append(beginBlockEvents, event)
+				} else {
+					endBlockEvents = append(endBlockEvents, event)
+				}
+			}
+		}
+		delete(result, "finalize_block_events")
+		delete(result, "app_hash")
+		result["begin_block_events"] = beginBlockEvents
+		result["end_block_events"] = endBlockEvents
+	case "broadcast_tx_commit":
+		result["deliver_tx"] = result["tx_result"]
+		delete(result, "tx_result")
+	case "status":
+		if nodeInfo, ok := result["node_info"].(map[string]interface{}); ok {
+			nodeInfo["version"] = cometmockversion.NodeVersion(compatV034NodeVersion)
+		}
+	}
+
+	encodeEventAttributes(result)
+}
+
+// encodeEventAttributes base64 encodes the keys and values of all event attributes in the value,
+// since they were bytes in v0.34.
+func encodeEventAttributes(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if attributes, ok := v["attributes"].([]interface{}); ok {
+			for _, attribute := range attributes {
+				if attributeMap, ok := attribute.(map[string]interface{}); ok {
+					for _, field := range []string{"key", "value"} {
+						if s, ok := attributeMap[field].(string); ok {
+							attributeMap[field] = base64.StdEncoding.EncodeToString([]byte(s))
+						}
+					}
+				}
+			}
+			return
+		}
+		for _, elem := range v {
+			encodeEventAttributes(elem)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			encodeEventAttributes(elem)
+		}
+	}
+}
+
+// eventMode returns the value of the mode attribute of an event whose attributes are not base64 encoded yet.
+func eventMode(event interface{}) string {
+	eventMap, ok := event.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	attributes, ok := eventMap["attributes"].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, attribute := range attributes {
+		attributeMap, ok := attribute.(map[string]interface{})
+		if ok && attributeMap["key"] == "mode" {
+			value, _ := attributeMap["value"].(string)
+			return value
+		}
+	}
+	return ""
+}
+
+// responseRecorder buffers a response so that it can be converted before it is written.
+type responseRecorder struct {
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+}
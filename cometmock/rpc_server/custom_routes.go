@@ -0,0 +1,51 @@
+package rpc_server
+
+import (
+	"fmt"
+	"plugin"
+
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+)
+
+// routesSymbol is the name of the function that route plugins export, see LoadRoutePlugin.
+const routesSymbol = "Routes"
+
+// RegisterRoute adds a JSON-RPC route to the routes served for the environment,
+// so that projects embedding CometMock can add their own control endpoints.
+// It needs to be called before the RPC server is started,
+// and returns an error if there already is a route with the name.
+func (env *Environment) RegisterRoute(name string, fn *rpc.RPCFunc) error {
+	if _, ok := env.routeMap[name]; ok {
+		return fmt.Errorf("there already is a route named %v", name)
+	}
+	env.routeMap[name] = fn
+	return nil
+}
+
+// LoadRoutePlugin registers the routes of the Go plugin at the given path, see RegisterRoute.
+// The plugin needs to export a function Routes of type func(*abci_client.AbciClient) map[string]*rpc.RPCFunc,
+// which is called with the client of the environment,
+// and be built with the same versions of the dependencies as CometMock.
+func (env *Environment) LoadRoutePlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening route plugin %v: %w", path, err)
+	}
+
+	symbol, err := p.Lookup(routesSymbol)
+	if err != nil {
+		return fmt.Errorf("error loading route plugin %v: %w", path, err)
+	}
+	routes, ok := symbol.(func(*abci_client.AbciClient) map[string]*rpc.RPCFunc)
+	if !ok {
+		return fmt.Errorf("error loading route plugin %v: %v has type %T, expected func(*abci_client.AbciClient) map[string]*rpc.RPCFunc", path, routesSymbol, symbol)
+	}
+
+	for name, fn := range routes(env.Client) {
+		if err := env.RegisterRoute(name, fn); err != nil {
+			return fmt.Errorf("error loading route plugin %v: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package rpc_server
+
+import (
+	"time"
+
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/loadgen"
+	"github.com/informalsystems/CometMock/cometmock/logging"
+	"github.com/informalsystems/CometMock/cometmock/replay"
+)
+
+// Environment contains the objects that the routes operate on, like the Environment of the RPC of CometBFT.
+// The routes are methods of the environment, so that several chains can be served in one process,
+// each by its own environment.
+type Environment struct {
+	// the client whose chain is served. Blocks, commits and states are read from its storage
+	Client *abci_client.AbciClient
+	// the load generator controlled by start_load, stop_load and load_stats
+	LoadGenerator *loadgen.LoadGenerator
+	// the replayer if the client replays an archive, and nil otherwise
+	Replayer *replay.Replayer
+
+	// how long BroadcastTxCommit waits for a transaction to be committed
+	TimeoutBroadcastTxCommit time.Duration
+
+	// the limits of websocket connections and their subscriptions
+	Websocket WebsocketConfig
+
+	// the levels of the log lines, which set_log_level changes. If this is nil, they cannot be changed
+	LogLevels *logging.Levels
+
+	// the bearer token of the block webhook. If this is empty, the webhook is not served
+	BlockWebhookToken string
+
+	// the directory below which export_data_dir writes, see EnableDataDirExport
+	dataDirExportRoot string
+
+	// the built-in routes and those added with RegisterRoute
+	routeMap map[string]*rpc.RPCFunc
+
+	// the chunks of the genesis served by genesis_chunked
+	genesisChunks genesisChunks
+}
+
+// NewEnvironment returns an environment serving the chain of the given client.
+func NewEnvironment(client *abci_client.AbciClient) *Environment {
+	env := &Environment{
+		Client:                   client,
+		LoadGenerator:            loadgen.NewLoadGenerator(client),
+		TimeoutBroadcastTxCommit: DefaultTimeoutBroadcastTxCommit,
+		Websocket:                DefaultWebsocketConfig(),
+	}
+	env.routeMap = env.routes()
+	return env
+}
+
+// Routes returns the JSON-RPC routes served for the environment.
+func (env *Environment) Routes() map[string]*rpc.RPCFunc {
+	return env.routeMap
+}
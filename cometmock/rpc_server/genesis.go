@@ -0,0 +1,49 @@
+package rpc_server
+
+import (
+	"encoding/base64"
+	"sync"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/types"
+)
+
+// genesisChunkSize is the size in bytes of the chunks that genesis_chunked splits the genesis into,
+// like in CometBFT. Genesis files that need more than one chunk are too large for genesis.
+const genesisChunkSize = 16 * 1024 * 1024 // 16 MB
+
+// genesisChunks holds the base64 encoded chunks of the JSON of a genesis, see GenesisChunked.
+type genesisChunks struct {
+	mutex sync.Mutex
+	// the genesis that the chunks were made from. The genesis changes when the history of a node is imported,
+	// so the chunks are made again then
+	genesisDoc *types.GenesisDoc
+	chunks     []string
+}
+
+// get returns the chunks of the given genesis, which are made at the first request for the genesis.
+func (c *genesisChunks) get(genesisDoc *types.GenesisDoc) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.genesisDoc == genesisDoc {
+		return c.chunks, nil
+	}
+
+	data, err := cmtjson.Marshal(genesisDoc)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]string, 0, len(data)/genesisChunkSize+1)
+	for i := 0; i < len(data); i += genesisChunkSize {
+		end := i + genesisChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, base64.StdEncoding.EncodeToString(data[i:end]))
+	}
+
+	c.genesisDoc = genesisDoc
+	c.chunks = chunks
+	return chunks, nil
+}
@@ -0,0 +1,67 @@
+package rpc_server
+
+import (
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+)
+
+// readOnlyRouteNames are the names of the routes that only read the chain and the state of CometMock,
+// and neither produce blocks nor change how they are produced, see ReadOnlyRoutes.
+var readOnlyRouteNames = []string{
+	// websocket
+	"subscribe",
+	"unsubscribe",
+	"unsubscribe_all",
+
+	// info API
+	"health",
+	"status",
+	"net_info",
+	"genesis",
+	"genesis_chunked",
+	"validators",
+	"block",
+	"block_by_hash",
+	"consensus_params",
+	"header",
+	"header_by_hash",
+	"commit",
+	"block_results",
+	"tx",
+	"tx_search",
+	"block_search",
+
+	// abci API
+	"abci_query",
+	"abci_info",
+
+	// cometmock specific API
+	"abci_query_batch",
+	"misbehaviour_rules",
+	"validator_names",
+	"cometmock_status",
+	"version",
+	"get_time",
+	"get_time_offset",
+	"log_level",
+	"failpoints",
+	"snapshots",
+	"vote_extensions",
+	"extended_commit",
+	"load_stats",
+	"app_hash_history",
+	"abci_latencies",
+	"export_index",
+}
+
+// ReadOnlyRoutes returns the routes of the environment that only read, e.g. queries and the history of the chain.
+// Routes that broadcast transactions, produce or simulate blocks, or control CometMock are left out,
+// and so are the routes added with RegisterRoute, since it is not known what they do.
+func (env *Environment) ReadOnlyRoutes() map[string]*rpc.RPCFunc {
+	routes := make(map[string]*rpc.RPCFunc, len(readOnlyRouteNames))
+	for _, name := range readOnlyRouteNames {
+		if fn, ok := env.routeMap[name]; ok {
+			routes[name] = fn
+		}
+	}
+	return routes
+}
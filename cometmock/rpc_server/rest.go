@@ -0,0 +1,804 @@
+package rpc_server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/libs/log"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/cometbft/cometbft/types"
+)
+
+// The REST facade offers the CometMock specific control endpoints
+// as plain HTTP endpoints that accept and return JSON, so that tools without
+// a JSON-RPC library can drive CometMock. Requests and responses are encoded with cmtjson,
+// like the params and results of JSON-RPC, so e.g. 64-bit integers are strings.
+// Each endpoint is served under restPathPrefix and only accepts POST requests.
+// An OpenAPI spec describing the endpoints is generated from the
+// request and response types and served under openAPIPath.
+const (
+	restPathPrefix = "/v1/"
+	openAPIPath    = "/openapi.json"
+)
+
+// restEndpoint describes a single endpoint of the REST facade.
+// Request and Response are zero values of the request and response types,
+// and are used to generate the OpenAPI spec.
+type restEndpoint struct {
+	Name     string
+	Summary  string
+	Request  interface{}
+	Response interface{}
+	// Handle is called with a pointer to a decoded value of the type of Request.
+	Handle func(ctx *rpctypes.Context, req interface{}) (interface{}, error)
+}
+
+type restAdvanceBlocksRequest struct {
+	NumBlocks    int  `json:"num_blocks" description:"The number of empty blocks to produce. Must be greater than 0."`
+	SkipIndexing bool `json:"skip_indexing" description:"If true, the blocks are not indexed and no events are published for them, which is faster."`
+}
+
+type restStartLoadRequest struct {
+	Generator         string `json:"generator" description:"The generator of the transactions, e.g. file:/path/to/txs or template:key{{.Index}}=value."`
+	Rate              int64  `json:"rate" description:"The number of transactions to submit per second."`
+	DurationInSeconds int64  `json:"duration_in_seconds" description:"For how many seconds to submit transactions."`
+}
+
+type restStopLoadRequest struct{}
+
+type restVoteExtensionsRequest struct{}
+
+type restSetVoteExtensionsEnableHeightRequest struct {
+	Height int64 `json:"height" description:"The height from which on vote extensions are enabled. Must be after the latest block."`
+}
+
+type restExtendedCommitRequest struct {
+	Height int64 `json:"height" description:"The height of the block of the commit. If this is 0, the commit of the latest block is returned."`
+}
+
+type restAppHashHistoryRequest struct {
+	From int64 `json:"from" description:"The first height of the history. If this is 0, the history starts at the initial height."`
+	To   int64 `json:"to" description:"The last height of the history. If this is 0, the history ends at the latest height."`
+}
+
+type restABCILatenciesRequest struct{}
+
+type restExportIndexRequest struct {
+	From int64 `json:"from" description:"The first height of the export. If this is 0, the export starts at the initial height."`
+	To   int64 `json:"to" description:"The last height of the export. If this is 0, the export ends at the latest height."`
+}
+
+type restSetLogLevelRequest struct {
+	Level       string `json:"level" description:"The levels of the log lines, e.g. debug or *:info,rpc-server:error. If this is empty, the levels are kept."`
+	TraceBlocks *int   `json:"trace_blocks" description:"For how many of the next blocks to log the full ABCI requests and responses. 0 stops tracing."`
+}
+
+type restLogLevelRequest struct{}
+
+type restEnableFailpointRequest struct {
+	Name        string   `json:"name" description:"The name of the failpoint, e.g. storage.UpdateStores.error or finalize.slow."`
+	Value       string   `json:"value" description:"The message of the errors of failpoints that return errors, or the delay in milliseconds of slow failpoints."`
+	Count       int64    `json:"count" description:"After how many times the failpoint is disabled again. 0 keeps it enabled until it is disabled."`
+	Probability *float64 `json:"probability" description:"The probability with which the failpoint fires each time it is reached. By default it always fires."`
+}
+
+type restDisableFailpointRequest struct {
+	Name string `json:"name" description:"The name of the failpoint. If this is empty, all failpoints are disabled."`
+}
+
+type restFailpointsRequest struct{}
+
+type restOverrideVoteExtensionRequest struct {
+	PrivateKeyAddress string `json:"private_key_address" description:"The address of the private key of the validator."`
+	Extension         string `json:"extension" description:"The hex encoded vote extension to use instead of the one from ExtendVote."`
+	NumBlocks         int64  `json:"num_blocks" description:"For how many blocks to use the extension. 0 removes the override."`
+}
+
+type restLoadStatsRequest struct{}
+
+type restAdvanceTimeRequest struct {
+	DurationInSeconds int64 `json:"duration_in_seconds" description:"The number of seconds to advance the time by. Must not be negative."`
+}
+
+type restSetSigningStatusRequest struct {
+	PrivateKeyAddress string `json:"private_key_address" description:"The address of the private key of the validator."`
+	Status            string `json:"status" description:"Either up to have the validator sign, or down to have it not sign."`
+}
+
+type restCauseDoubleSignRequest struct {
+	PrivateKeyAddress string `json:"private_key_address" description:"The address of the private key of the validator that should double sign."`
+}
+
+type restCauseLightClientAttackRequest struct {
+	PrivateKeyAddress string `json:"private_key_address" description:"The address of the private key of the misbehaving validator."`
+	MisbehaviourType  string `json:"misbehaviour_type" description:"One of Equivocation, Lunatic, Amnesia."`
+}
+
+type restProduceConflictingBlocksRequest struct{}
+
+type restIBCMisbehaviourRequest struct {
+	ClientID      string `json:"client_id" description:"The id of the light client of the chain on the counterparty chain. May be empty, since it is deprecated in the misbehaviour."`
+	TrustedHeight int64  `json:"trusted_height" description:"The height that the light client trusts. If this is 0, the height before the conflicting blocks."`
+}
+
+type restSetMisbehaviourRulesRequest struct {
+	Rules string `json:"rules" description:"The rules in the format of --misbehaviour-rules, e.g. random=DuplicateVote@p:0.01. Empty removes all rules."`
+	Seed  int64  `json:"seed" description:"The seed of the random rules. If this is 0, the seed is left unchanged."`
+}
+
+type restMisbehaviourRulesRequest struct{}
+
+type restValidatorNamesRequest struct{}
+
+type restVersionRequest struct{}
+
+type restSetCommitRoundRequest struct {
+	Round int `json:"round" description:"The round in which blocks are committed, unless run_block is called with another round."`
+}
+
+type restCometMockStatusRequest struct{}
+
+type restGetTimeRequest struct{}
+
+type restGetTimeOffsetRequest struct{}
+
+type restRunBlockRequest struct {
+	Time          string         `json:"time" description:"The timestamp of the block in RFC3339 format. If empty, it is decided like for any other block."`
+	Proposer      string         `json:"proposer" description:"The address of the private key of the proposer. If empty, the proposer is selected by the proposer rotation."`
+	Round         int            `json:"round" description:"The round in which the block is committed."`
+	Txs           [][]byte       `json:"txs" description:"The base64 encoded transactions to propose. If absent, the transactions waiting to be included are proposed."`
+	Misbehaviours []Misbehaviour `json:"misbehaviours" description:"The misbehaviours to include evidence for."`
+	Signers       []string       `json:"signers" description:"The addresses of the private keys of the validators that sign the block. If absent, the validators whose signing status is up sign."`
+}
+
+type restSimulateBlockRequest struct {
+	Target string   `json:"target" description:"The validator address of the app that runs the block, or its index in the app addresses."`
+	Txs    [][]byte `json:"txs" description:"The base64 encoded transactions to propose. If absent, the transactions waiting to be included are proposed."`
+}
+
+type restSimulateTxRequest struct {
+	Tx     []byte `json:"tx" description:"The base64 encoded transaction."`
+	Target string `json:"target" description:"The validator address of the app that runs the transaction, or its index in the app addresses."`
+}
+
+type restBroadcastTxsRequest struct {
+	Txs [][]byte `json:"txs" description:"The base64 encoded transactions, which are checked in order and included together in the next block."`
+}
+
+type restABCIQueryBatchRequest struct {
+	Queries    []BatchQuery `json:"queries" description:"The queries, each with the path, data, height and prove parameters of abci_query."`
+	Target     string       `json:"target" description:"The validator address of the app to query, or its index in the app addresses. If empty, the apps are queried like for abci_query."`
+	SameHeight bool         `json:"same_height" description:"Whether queries without a height are answered at the height of the last block when the batch started."`
+}
+
+type restResumeRequest struct {
+	AppAddresses []string `json:"app_addresses" description:"The addresses of the apps, in the same order as at startup. If empty, the apps are reconnected at their previous addresses."`
+}
+
+type restReloadAppsRequest struct {
+	AppAddresses []string `json:"app_addresses" description:"The addresses of the apps, in the same order as at startup, followed by new observers. If empty, they are read from the app addresses file."`
+}
+
+type restResetChainRequest struct {
+	AppAddresses []string `json:"app_addresses" description:"The addresses of the reset apps, in the same order as at startup. If empty, the apps are reconnected at their previous addresses."`
+}
+
+type restSnapshotsRequest struct{}
+
+type restRewindToSnapshotRequest struct {
+	Height       int64    `json:"height" description:"The height of the snapshot. If this is 0, the chain is rewound to the most recent snapshot."`
+	AppAddresses []string `json:"app_addresses" description:"The addresses of the restored apps, in the same order as at startup. If empty, the apps are reconnected at their previous addresses."`
+}
+
+type restReplayFromSnapshotRequest struct {
+	Height       int64         `json:"height" description:"The height of the snapshot. If this is 0, the blocks after the most recent snapshot are replayed."`
+	AppAddresses []string      `json:"app_addresses" description:"The addresses of the restored apps, in the same order as at startup. If empty, the apps are reconnected at their previous addresses."`
+	Changes      []BlockChange `json:"changes" description:"The changes to the replayed blocks, by height. Blocks without changes are replayed like the original blocks."`
+}
+
+type restScheduleUpgradeRequest struct {
+	Height       int64    `json:"height" description:"The height of the upgrade. Must be after the last block."`
+	AppAddresses []string `json:"app_addresses" description:"The addresses of the upgraded apps, in the same order as at startup. Empty addresses mean the previous address."`
+}
+
+// restEndpoints returns the endpoints of the REST facade, which operate on the environment.
+func (env *Environment) restEndpoints() []restEndpoint {
+	return []restEndpoint{
+		{
+			Name:     "advance_blocks",
+			Summary:  "Runs the given number of empty blocks in succession.",
+			Request:  restAdvanceBlocksRequest{},
+			Response: ResultAdvanceBlocks{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restAdvanceBlocksRequest)
+				return env.AdvanceBlocks(ctx, r.NumBlocks, r.SkipIndexing)
+			},
+		},
+		{
+			Name:     "advance_time",
+			Summary:  "Advances the timestamps of all following blocks.",
+			Request:  restAdvanceTimeRequest{},
+			Response: ResultAdvanceTime{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.AdvanceTime(ctx, time.Duration(req.(*restAdvanceTimeRequest).DurationInSeconds))
+			},
+		},
+		{
+			Name:     "set_signing_status",
+			Summary:  "Decides whether a validator signs blocks.",
+			Request:  restSetSigningStatusRequest{},
+			Response: ResultSetSigningStatus{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restSetSigningStatusRequest)
+				return env.SetSigningStatus(ctx, r.PrivateKeyAddress, r.Status)
+			},
+		},
+		{
+			Name:     "cause_double_sign",
+			Summary:  "Includes DuplicateVoteEvidence for a validator in the next block.",
+			Request:  restCauseDoubleSignRequest{},
+			Response: ResultCauseDoubleSign{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.CauseDoubleSign(ctx, req.(*restCauseDoubleSignRequest).PrivateKeyAddress)
+			},
+		},
+		{
+			Name:     "cause_light_client_attack",
+			Summary:  "Includes LightClientAttackEvidence for a validator in the next block.",
+			Request:  restCauseLightClientAttackRequest{},
+			Response: ResultCauseLightClientAttack{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restCauseLightClientAttackRequest)
+				return env.CauseLightClientAttack(ctx, r.PrivateKeyAddress, r.MisbehaviourType)
+			},
+		},
+		{
+			Name:     "produce_conflicting_blocks",
+			Summary:  "Signs two different blocks at the next height and returns their signed headers, without committing either.",
+			Request:  restProduceConflictingBlocksRequest{},
+			Response: ResultProduceConflictingBlocks{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.ProduceConflictingBlocks(ctx)
+			},
+		},
+		{
+			Name:     "ibc_misbehaviour",
+			Summary:  "Returns the last conflicting blocks as the Misbehaviour of the 07-tendermint light client of ibc-go.",
+			Request:  restIBCMisbehaviourRequest{},
+			Response: ResultIBCMisbehaviour{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restIBCMisbehaviourRequest)
+				return env.IBCMisbehaviour(ctx, r.ClientID, r.TrustedHeight)
+			},
+		},
+		{
+			Name:     "set_misbehaviour_rules",
+			Summary:  "Replaces the rules by which validators misbehave automatically.",
+			Request:  restSetMisbehaviourRulesRequest{},
+			Response: ResultMisbehaviourRules{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restSetMisbehaviourRulesRequest)
+				return env.SetMisbehaviourRules(ctx, r.Rules, r.Seed)
+			},
+		},
+		{
+			Name:     "misbehaviour_rules",
+			Summary:  "Returns the rules by which validators misbehave automatically.",
+			Request:  restMisbehaviourRulesRequest{},
+			Response: ResultMisbehaviourRules{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.MisbehaviourRules(ctx)
+			},
+		},
+		{
+			Name:     "validator_names",
+			Summary:  "Returns the names of the validators, which control endpoints accept in place of their addresses.",
+			Request:  restValidatorNamesRequest{},
+			Response: ResultValidatorNames{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.ValidatorNames(ctx)
+			},
+		},
+		{
+			Name:     "version",
+			Summary:  "Returns the version of CometMock, the commit it was built from, and the versions of CometBFT and ABCI that it mocks.",
+			Request:  restVersionRequest{},
+			Response: ResultVersion{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.Version(ctx)
+			},
+		},
+		{
+			Name:     "set_commit_round",
+			Summary:  "Sets the round in which blocks are proposed and committed.",
+			Request:  restSetCommitRoundRequest{},
+			Response: ResultSetCommitRound{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.SetCommitRound(ctx, req.(*restSetCommitRoundRequest).Round)
+			},
+		},
+		{
+			Name:     "cometmock_status",
+			Summary:  "Returns diagnostic information about the internal state of CometMock.",
+			Request:  restCometMockStatusRequest{},
+			Response: ResultCometMockStatus{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.CometMockStatus(ctx)
+			},
+		},
+		{
+			Name:     "get_time",
+			Summary:  "Returns the timestamp that the next block would have if it was produced now.",
+			Request:  restGetTimeRequest{},
+			Response: ResultGetTime{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.GetTime(ctx)
+			},
+		},
+		{
+			Name:     "get_time_offset",
+			Summary:  "Returns the offset by which the block timestamps are shifted.",
+			Request:  restGetTimeOffsetRequest{},
+			Response: ResultGetTimeOffset{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.GetTimeOffset(ctx)
+			},
+		},
+		{
+			Name:     "run_block",
+			Summary:  "Produces a single block with explicit overrides for how it is produced.",
+			Request:  restRunBlockRequest{},
+			Response: ResultRunBlock{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restRunBlockRequest)
+				var txs []types.Tx
+				if r.Txs != nil {
+					txs = make([]types.Tx, len(r.Txs))
+					for i, tx := range r.Txs {
+						txs[i] = tx
+					}
+				}
+				return env.RunBlock(ctx, r.Time, r.Proposer, r.Round, txs, r.Misbehaviours, r.Signers)
+			},
+		},
+		{
+			Name:     "simulate_block",
+			Summary:  "Runs the next block against a single app without committing it, and returns its results.",
+			Request:  restSimulateBlockRequest{},
+			Response: ResultSimulateBlock{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restSimulateBlockRequest)
+				var txs []types.Tx
+				if r.Txs != nil {
+					txs = make([]types.Tx, len(r.Txs))
+					for i, tx := range r.Txs {
+						txs[i] = tx
+					}
+				}
+				return env.SimulateBlock(ctx, r.Target, txs)
+			},
+		},
+		{
+			Name:     "simulate_tx",
+			Summary:  "Checks and executes a transaction in the next block against a single app without committing it.",
+			Request:  restSimulateTxRequest{},
+			Response: ResultSimulateTx{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restSimulateTxRequest)
+				return env.SimulateTx(ctx, r.Tx, r.Target)
+			},
+		},
+		{
+			Name:     "broadcast_txs",
+			Summary:  "Broadcasts a list of transactions like broadcast_tx_sync and returns the result of CheckTx for each.",
+			Request:  restBroadcastTxsRequest{},
+			Response: ResultBroadcastTxs{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restBroadcastTxsRequest)
+				txs := make([]types.Tx, len(r.Txs))
+				for i, tx := range r.Txs {
+					txs[i] = tx
+				}
+				return env.BroadcastTxs(ctx, txs)
+			},
+		},
+		{
+			Name:     "abci_query_batch",
+			Summary:  "Sends a list of queries to the apps and returns the responses in order.",
+			Request:  restABCIQueryBatchRequest{},
+			Response: ResultABCIQueryBatch{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restABCIQueryBatchRequest)
+				return env.ABCIQueryBatch(ctx, r.Queries, r.Target, r.SameHeight)
+			},
+		},
+		{
+			Name:     "resume",
+			Summary:  "Resumes block production after it was halted, e.g. for an upgrade.",
+			Request:  restResumeRequest{},
+			Response: ResultResume{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.Resume(ctx, req.(*restResumeRequest).AppAddresses)
+			},
+		},
+		{
+			Name:     "reload_apps",
+			Summary:  "Reconnects to apps whose addresses changed or whose connection failed, and catches them up.",
+			Request:  restReloadAppsRequest{},
+			Response: ResultReloadApps{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.ReloadApps(ctx, req.(*restReloadAppsRequest).AppAddresses)
+			},
+		},
+		{
+			Name:     "reset_chain",
+			Summary:  "Wipes the chain and starts it again from the genesis, with apps that were reset or freshly started.",
+			Request:  restResetChainRequest{},
+			Response: ResultResetChain{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.ResetChain(ctx, req.(*restResetChainRequest).AppAddresses)
+			},
+		},
+		{
+			Name:     "snapshots",
+			Summary:  "Returns the snapshots of the state that the chain can be rewound to.",
+			Request:  restSnapshotsRequest{},
+			Response: ResultSnapshots{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.Snapshots(ctx)
+			},
+		},
+		{
+			Name:     "rewind_to_snapshot",
+			Summary:  "Rewinds the chain to a snapshot, with apps that were restored to the height of the snapshot.",
+			Request:  restRewindToSnapshotRequest{},
+			Response: ResultRewindToSnapshot{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restRewindToSnapshotRequest)
+				return env.RewindToSnapshot(ctx, r.Height, r.AppAddresses)
+			},
+		},
+		{
+			Name:     "replay_from_snapshot",
+			Summary:  "Rewinds the chain to a snapshot and replays the later blocks with changes, comparing the outcomes with the original blocks.",
+			Request:  restReplayFromSnapshotRequest{},
+			Response: ResultReplayFromSnapshot{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restReplayFromSnapshotRequest)
+				return env.ReplayFromSnapshot(ctx, r.Height, r.AppAddresses, r.Changes)
+			},
+		},
+		{
+			Name:     "schedule_upgrade",
+			Summary:  "Announces an upgrade, after which the upgraded apps take over and must agree on the app hash.",
+			Request:  restScheduleUpgradeRequest{},
+			Response: ResultScheduleUpgrade{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restScheduleUpgradeRequest)
+				return env.ScheduleUpgrade(ctx, r.Height, r.AppAddresses)
+			},
+		},
+		{
+			Name:     "override_vote_extension",
+			Summary:  "Replaces the vote extension of a validator for the next blocks.",
+			Request:  restOverrideVoteExtensionRequest{},
+			Response: ResultOverrideVoteExtension{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restOverrideVoteExtensionRequest)
+				extension, err := hex.DecodeString(r.Extension)
+				if err != nil {
+					return nil, fmt.Errorf("invalid extension: %w", err)
+				}
+				return env.OverrideVoteExtension(ctx, r.PrivateKeyAddress, extension, r.NumBlocks)
+			},
+		},
+		{
+			Name:     "vote_extensions",
+			Summary:  "Returns the vote extensions in the last commit, per validator.",
+			Request:  restVoteExtensionsRequest{},
+			Response: ResultVoteExtensions{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.VoteExtensions(ctx)
+			},
+		},
+		{
+			Name:     "set_vote_extensions_enable_height",
+			Summary:  "Enables vote extensions from a future height on.",
+			Request:  restSetVoteExtensionsEnableHeightRequest{},
+			Response: ResultSetVoteExtensionsEnableHeight{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.SetVoteExtensionsEnableHeight(ctx, req.(*restSetVoteExtensionsEnableHeightRequest).Height)
+			},
+		},
+		{
+			Name:     "extended_commit",
+			Summary:  "Returns the commit for the block at a height, including the vote extensions.",
+			Request:  restExtendedCommitRequest{},
+			Response: ResultExtendedCommit{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restExtendedCommitRequest)
+				if r.Height == 0 {
+					return env.ExtendedCommit(ctx, nil)
+				}
+				return env.ExtendedCommit(ctx, &r.Height)
+			},
+		},
+		{
+			Name:     "app_hash_history",
+			Summary:  "Returns the app hash after each block in a range of heights, per app where the apps diverged.",
+			Request:  restAppHashHistoryRequest{},
+			Response: ResultAppHashHistory{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restAppHashHistoryRequest)
+				return env.AppHashHistory(ctx, r.From, r.To)
+			},
+		},
+		{
+			Name:     "abci_latencies",
+			Summary:  "Returns histograms of the latencies of the ABCI calls to each app, by method.",
+			Request:  restABCILatenciesRequest{},
+			Response: ResultABCILatencies{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.ABCILatencies(ctx)
+			},
+		},
+		{
+			Name:     "export_index",
+			Summary:  "Returns the blocks in a range of heights with the events and transaction results that are indexed for them.",
+			Request:  restExportIndexRequest{},
+			Response: ResultExportIndex{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restExportIndexRequest)
+				return env.ExportIndex(ctx, r.From, r.To)
+			},
+		},
+		{
+			Name:     "set_log_level",
+			Summary:  "Changes the levels of the log lines, and traces the ABCI calls of the next blocks.",
+			Request:  restSetLogLevelRequest{},
+			Response: ResultLogLevel{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restSetLogLevelRequest)
+				return env.SetLogLevel(ctx, r.Level, r.TraceBlocks)
+			},
+		},
+		{
+			Name:     "log_level",
+			Summary:  "Returns the levels of the log lines and the number of blocks that are still to be traced.",
+			Request:  restLogLevelRequest{},
+			Response: ResultLogLevel{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.LogLevel(ctx)
+			},
+		},
+		{
+			Name:     "enable_failpoint",
+			Summary:  "Enables a failpoint, which injects a fault each time it fires.",
+			Request:  restEnableFailpointRequest{},
+			Response: ResultFailpoints{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restEnableFailpointRequest)
+				return env.EnableFailpoint(ctx, r.Name, r.Value, r.Count, r.Probability)
+			},
+		},
+		{
+			Name:     "disable_failpoint",
+			Summary:  "Disables a failpoint, or all failpoints.",
+			Request:  restDisableFailpointRequest{},
+			Response: ResultFailpoints{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restDisableFailpointRequest)
+				return env.DisableFailpoint(ctx, r.Name)
+			},
+		},
+		{
+			Name:     "failpoints",
+			Summary:  "Returns the states of all failpoints.",
+			Request:  restFailpointsRequest{},
+			Response: ResultFailpoints{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.Failpoints(ctx)
+			},
+		},
+		{
+			Name:     "start_load",
+			Summary:  "Starts submitting generated transactions at a fixed rate.",
+			Request:  restStartLoadRequest{},
+			Response: ResultStartLoad{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				r := req.(*restStartLoadRequest)
+				return env.StartLoad(ctx, r.Generator, r.Rate, r.DurationInSeconds)
+			},
+		},
+		{
+			Name:     "stop_load",
+			Summary:  "Stops submitting generated transactions.",
+			Request:  restStopLoadRequest{},
+			Response: ResultStopLoad{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.StopLoad(ctx)
+			},
+		},
+		{
+			Name:     "load_stats",
+			Summary:  "Returns the acceptance and latency statistics of the generated transactions.",
+			Request:  restLoadStatsRequest{},
+			Response: ResultLoadStats{},
+			Handle: func(ctx *rpctypes.Context, req interface{}) (interface{}, error) {
+				return env.LoadStats(ctx)
+			},
+		},
+	}
+}
+
+type restError struct {
+	Error string `json:"error"`
+}
+
+// RegisterRESTHandlers registers the REST facade for the environment and the OpenAPI spec on the given mux.
+func (env *Environment) RegisterRESTHandlers(mux *http.ServeMux, logger log.Logger) {
+	for _, endpoint := range env.restEndpoints() {
+		mux.HandleFunc(restPathPrefix+endpoint.Name, makeRESTHandler(endpoint, logger))
+	}
+
+	// the spec is a generic map, which cmtjson cannot encode, and is not a result of CometMock anyway
+	specBytes, err := json.Marshal(GenerateOpenAPISpec())
+	if err != nil {
+		panic(err)
+	}
+	mux.HandleFunc(openAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(specBytes)
+	})
+}
+
+func makeRESTHandler(endpoint restEndpoint, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeRESTResponse(w, http.StatusMethodNotAllowed, restError{Error: "only POST is supported"})
+			return
+		}
+
+		req := reflect.New(reflect.TypeOf(endpoint.Request)).Interface()
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = cmtjson.Unmarshal(body, req)
+		}
+		if err != nil {
+			writeRESTResponse(w, http.StatusBadRequest, restError{Error: fmt.Sprintf("error decoding request body: %v", err)})
+			return
+		}
+
+		res, err := endpoint.Handle(&rpctypes.Context{HTTPReq: r}, req)
+		if err != nil {
+			logger.Error("Error serving REST request", "endpoint", endpoint.Name, "err", err)
+			writeRESTResponse(w, http.StatusInternalServerError, restError{Error: err.Error()})
+			return
+		}
+		writeRESTResponse(w, http.StatusOK, res)
+	}
+}
+
+func writeRESTResponse(w http.ResponseWriter, httpCode int, res interface{}) {
+	jsonBytes, err := cmtjson.Marshal(res)
+	if err != nil {
+		httpCode = http.StatusInternalServerError
+		jsonBytes = []byte(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	_, _ = w.Write(jsonBytes)
+}
+
+// GenerateOpenAPISpec generates an OpenAPI 3 spec for the REST facade
+// from the request and response types of the endpoints.
+func GenerateOpenAPISpec() map[string]interface{} {
+	// the spec only depends on the types of the endpoints, their handlers are not called
+	restEndpoints := new(Environment).restEndpoints()
+	paths := make(map[string]interface{}, len(restEndpoints))
+	for _, endpoint := range restEndpoints {
+		jsonContent := func(schema map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schema},
+			}
+		}
+
+		paths[restPathPrefix+endpoint.Name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": endpoint.Name,
+				"summary":     endpoint.Summary,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content":  jsonContent(schemaForType(reflect.TypeOf(endpoint.Request))),
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "The operation was successful.",
+						"content":     jsonContent(schemaForType(reflect.TypeOf(endpoint.Response))),
+					},
+					"default": map[string]interface{}{
+						"description": "The operation failed.",
+						"content":     jsonContent(schemaForType(reflect.TypeOf(restError{}))),
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "CometMock control API",
+			"description": "REST facade over the CometMock specific control endpoints.",
+			"version":     "v1",
+		},
+		"paths": paths,
+	}
+}
+
+// jsonMarshalerType is the type of json.Marshaler, see schemaForType.
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// schemaForType returns an OpenAPI schema for the given type.
+// It supports the kinds that are used in the request and response types
+// of the REST facade.
+func schemaForType(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	// the types with their own JSON encoding are encoded as strings, e.g. HexBytes as hex
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+	// byte slices are encoded as base64 strings
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	// like in cmtjson, 64-bit integers are encoded as strings, since JavaScript cannot represent all of them as numbers
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "string", "format": "int64"}
+	case reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "string", "format": "uint64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaForType(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			schema := schemaForType(field.Type)
+			if description := field.Tag.Get("description"); description != "" {
+				schema["description"] = description
+			}
+			properties[name] = schema
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		return map[string]interface{}{}
+	}
+}
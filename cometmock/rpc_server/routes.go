@@ -1,24 +1,38 @@
 package rpc_server
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cometbft/cometbft/libs/bytes"
 	cmtmath "github.com/cometbft/cometbft/libs/math"
+	cmtnet "github.com/cometbft/cometbft/libs/net"
 	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
 	"github.com/cometbft/cometbft/p2p"
 
 	abcitypes "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	cometstate "github.com/cometbft/cometbft/state"
 	"github.com/cometbft/cometbft/types"
 	"github.com/cometbft/cometbft/version"
 	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/failpoints"
+	"github.com/informalsystems/CometMock/cometmock/ibc"
+	"github.com/informalsystems/CometMock/cometmock/loadgen"
+	"github.com/informalsystems/CometMock/cometmock/replay"
+	"github.com/informalsystems/CometMock/cometmock/storage"
 	"github.com/informalsystems/CometMock/cometmock/utils"
+	cometmockversion "github.com/informalsystems/CometMock/cometmock/version"
 )
 
 const (
@@ -26,53 +40,974 @@ const (
 	maxPerPage     = 100
 )
 
-var Routes = map[string]*rpc.RPCFunc{
-	// websocket
-	"subscribe":       rpc.NewWSRPCFunc(Subscribe, "query"),
-	"unsubscribe":     rpc.NewWSRPCFunc(Unsubscribe, "query"),
-	"unsubscribe_all": rpc.NewWSRPCFunc(UnsubscribeAll, ""),
-
-	// info API
-	"health":           rpc.NewRPCFunc(Health, ""),
-	"status":           rpc.NewRPCFunc(Status, ""),
-	"validators":       rpc.NewRPCFunc(Validators, "height,page,per_page"),
-	"block":            rpc.NewRPCFunc(Block, "height", rpc.Cacheable("height")),
-	"consensus_params": rpc.NewRPCFunc(ConsensusParams, "height", rpc.Cacheable("height")),
-	// "header":           rpc.NewRPCFunc(Header, "height", rpc.Cacheable("height")), // not available in 0.34.x
-	"commit":        rpc.NewRPCFunc(Commit, "height", rpc.Cacheable("height")),
-	"block_results": rpc.NewRPCFunc(BlockResults, "height", rpc.Cacheable("height")),
-	"tx":            rpc.NewRPCFunc(Tx, "hash,prove", rpc.Cacheable()),
-	"tx_search":     rpc.NewRPCFunc(TxSearch, "query,prove,page,per_page,order_by"),
-	"block_search":  rpc.NewRPCFunc(BlockSearch, "query,page,per_page,order_by"),
-
-	// tx broadcast API
-	"broadcast_tx_commit": rpc.NewRPCFunc(BroadcastTxCommit, "tx"),
-	"broadcast_tx_sync":   rpc.NewRPCFunc(BroadcastTxSync, "tx"),
-	"broadcast_tx_async":  rpc.NewRPCFunc(BroadcastTxAsync, "tx"),
-
-	// abci API
-	"abci_query": rpc.NewRPCFunc(ABCIQuery, "path,data,height,prove"),
-	"abci_info":  rpc.NewRPCFunc(ABCIInfo, ""),
-
-	// cometmock specific API
-	"advance_blocks":            rpc.NewRPCFunc(AdvanceBlocks, "num_blocks"),
-	"set_signing_status":        rpc.NewRPCFunc(SetSigningStatus, "private_key_address,status"),
-	"advance_time":              rpc.NewRPCFunc(AdvanceTime, "duration_in_seconds"),
-	"cause_double_sign":         rpc.NewRPCFunc(CauseDoubleSign, "private_key_address"),
-	"cause_light_client_attack": rpc.NewRPCFunc(CauseLightClientAttack, "private_key_address,misbehaviour_type"),
+// routes returns the built-in routes, which operate on the environment.
+func (env *Environment) routes() map[string]*rpc.RPCFunc {
+	return map[string]*rpc.RPCFunc{
+		// websocket
+		"subscribe":       rpc.NewWSRPCFunc(env.Subscribe, "query"),
+		"unsubscribe":     rpc.NewWSRPCFunc(env.Unsubscribe, "query"),
+		"unsubscribe_all": rpc.NewWSRPCFunc(env.UnsubscribeAll, ""),
+
+		// info API
+		"health":           rpc.NewRPCFunc(env.Health, ""),
+		"status":           rpc.NewRPCFunc(env.Status, ""),
+		"net_info":         rpc.NewRPCFunc(env.NetInfo, ""),
+		"genesis":          rpc.NewRPCFunc(env.Genesis, "", rpc.Cacheable()),
+		"genesis_chunked":  rpc.NewRPCFunc(env.GenesisChunked, "chunk", rpc.Cacheable()),
+		"validators":       rpc.NewRPCFunc(env.Validators, "height,page,per_page"),
+		"block":            rpc.NewRPCFunc(env.Block, "height", rpc.Cacheable("height")),
+		"block_by_hash":    rpc.NewRPCFunc(env.BlockByHash, "hash", rpc.Cacheable()),
+		"consensus_params": rpc.NewRPCFunc(env.ConsensusParams, "height", rpc.Cacheable("height")),
+		"header":           rpc.NewRPCFunc(env.Header, "height", rpc.Cacheable("height")),
+		"header_by_hash":   rpc.NewRPCFunc(env.HeaderByHash, "hash", rpc.Cacheable()),
+		"commit":           rpc.NewRPCFunc(env.Commit, "height", rpc.Cacheable("height")),
+		"block_results":    rpc.NewRPCFunc(env.BlockResults, "height", rpc.Cacheable("height")),
+		"tx":               rpc.NewRPCFunc(env.Tx, "hash,prove", rpc.Cacheable()),
+		"tx_search":        rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by"),
+		"block_search":     rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by"),
+
+		// tx broadcast API
+		"broadcast_tx_commit": rpc.NewRPCFunc(env.BroadcastTxCommit, "tx"),
+		"broadcast_tx_sync":   rpc.NewRPCFunc(env.BroadcastTxSync, "tx"),
+		"broadcast_tx_async":  rpc.NewRPCFunc(env.BroadcastTxAsync, "tx"),
+		"broadcast_txs":       rpc.NewRPCFunc(env.BroadcastTxs, "txs"),
+
+		// abci API
+		"abci_query": rpc.NewRPCFunc(env.ABCIQuery, "path,data,height,prove,target"),
+		"abci_info":  rpc.NewRPCFunc(env.ABCIInfo, ""),
+
+		// cometmock specific API
+		"advance_blocks":                    rpc.NewRPCFunc(env.AdvanceBlocks, "num_blocks,skip_indexing"),
+		"set_signing_status":                rpc.NewRPCFunc(env.SetSigningStatus, "private_key_address,status"),
+		"advance_time":                      rpc.NewRPCFunc(env.AdvanceTime, "duration_in_seconds"),
+		"cause_double_sign":                 rpc.NewRPCFunc(env.CauseDoubleSign, "private_key_address"),
+		"cause_light_client_attack":         rpc.NewRPCFunc(env.CauseLightClientAttack, "private_key_address,misbehaviour_type"),
+		"produce_conflicting_blocks":        rpc.NewRPCFunc(env.ProduceConflictingBlocks, ""),
+		"ibc_misbehaviour":                  rpc.NewRPCFunc(env.IBCMisbehaviour, "client_id,trusted_height"),
+		"set_misbehaviour_rules":            rpc.NewRPCFunc(env.SetMisbehaviourRules, "rules,seed"),
+		"misbehaviour_rules":                rpc.NewRPCFunc(env.MisbehaviourRules, ""),
+		"validator_names":                   rpc.NewRPCFunc(env.ValidatorNames, ""),
+		"set_commit_round":                  rpc.NewRPCFunc(env.SetCommitRound, "round"),
+		"cometmock_status":                  rpc.NewRPCFunc(env.CometMockStatus, ""),
+		"version":                           rpc.NewRPCFunc(env.Version, ""),
+		"get_time":                          rpc.NewRPCFunc(env.GetTime, ""),
+		"get_time_offset":                   rpc.NewRPCFunc(env.GetTimeOffset, ""),
+		"run_block":                         rpc.NewRPCFunc(env.RunBlock, "time,proposer,round,txs,misbehaviours,signers"),
+		"simulate_block":                    rpc.NewRPCFunc(env.SimulateBlock, "target,txs"),
+		"simulate_tx":                       rpc.NewRPCFunc(env.SimulateTx, "tx,target"),
+		"abci_query_batch":                  rpc.NewRPCFunc(env.ABCIQueryBatch, "queries,target,same_height"),
+		"resume":                            rpc.NewRPCFunc(env.Resume, "app_addresses"),
+		"reload_apps":                       rpc.NewRPCFunc(env.ReloadApps, "app_addresses"),
+		"reset_chain":                       rpc.NewRPCFunc(env.ResetChain, "app_addresses"),
+		"snapshots":                         rpc.NewRPCFunc(env.Snapshots, ""),
+		"rewind_to_snapshot":                rpc.NewRPCFunc(env.RewindToSnapshot, "height,app_addresses"),
+		"replay_from_snapshot":              rpc.NewRPCFunc(env.ReplayFromSnapshot, "height,app_addresses,changes"),
+		"schedule_upgrade":                  rpc.NewRPCFunc(env.ScheduleUpgrade, "height,app_addresses"),
+		"start_load":                        rpc.NewRPCFunc(env.StartLoad, "generator,rate,duration_in_seconds"),
+		"override_vote_extension":           rpc.NewRPCFunc(env.OverrideVoteExtension, "private_key_address,extension,num_blocks"),
+		"vote_extensions":                   rpc.NewRPCFunc(env.VoteExtensions, ""),
+		"extended_commit":                   rpc.NewRPCFunc(env.ExtendedCommit, "height", rpc.Cacheable("height")),
+		"set_vote_extensions_enable_height": rpc.NewRPCFunc(env.SetVoteExtensionsEnableHeight, "height"),
+		"stop_load":                         rpc.NewRPCFunc(env.StopLoad, ""),
+		"load_stats":                        rpc.NewRPCFunc(env.LoadStats, ""),
+		"app_hash_history":                  rpc.NewRPCFunc(env.AppHashHistory, "from,to"),
+		"abci_latencies":                    rpc.NewRPCFunc(env.ABCILatencies, ""),
+		"export_index":                      rpc.NewRPCFunc(env.ExportIndex, "from,to"),
+		"set_log_level":                     rpc.NewRPCFunc(env.SetLogLevel, "level,trace_blocks"),
+		"log_level":                         rpc.NewRPCFunc(env.LogLevel, ""),
+		"enable_failpoint":                  rpc.NewRPCFunc(env.EnableFailpoint, "name,value,count,probability"),
+		"disable_failpoint":                 rpc.NewRPCFunc(env.DisableFailpoint, "name"),
+		"failpoints":                        rpc.NewRPCFunc(env.Failpoints, ""),
+	}
+}
+
+type ResultOverrideVoteExtension struct{}
+
+// OverrideVoteExtension makes the validator with the given private key address use the given
+// hex encoded vote extension for the next num_blocks blocks, instead of calling ExtendVote on its app.
+// If another app rejects the extension, the vote is dropped, so the validator misses the block.
+// If num_blocks is 0, the override is removed.
+// This API is specific to CometMock.
+func (env *Environment) OverrideVoteExtension(ctx *rpctypes.Context, privateKeyAddress string, extension bytes.HexBytes, numBlocks int64) (*ResultOverrideVoteExtension, error) {
+	err := env.Client.OverrideVoteExtension(privateKeyAddress, extension, numBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultOverrideVoteExtension{}, nil
+}
+
+type ResultVoteExtensions struct {
+	// the height of the last commit
+	Height         int64                       `json:"height"`
+	VoteExtensions []abci_client.VoteExtension `json:"vote_extensions"`
+}
+
+// VoteExtensions returns the vote extensions in the last commit, which are passed to the proposer
+// of the next block in PrepareProposal, per validator in the order of the validator set.
+// This API is specific to CometMock.
+func (env *Environment) VoteExtensions(ctx *rpctypes.Context) (*ResultVoteExtensions, error) {
+	height, extensions := env.Client.LastVoteExtensions()
+	return &ResultVoteExtensions{Height: height, VoteExtensions: extensions}, nil
+}
+
+type ResultSetVoteExtensionsEnableHeight struct{}
+
+// SetVoteExtensionsEnableHeight enables vote extensions from the given height on,
+// which must be after the latest block. Once vote extensions are enabled, the height cannot be changed.
+// The apps are not told about the change.
+// This API is specific to CometMock.
+func (env *Environment) SetVoteExtensionsEnableHeight(ctx *rpctypes.Context, height int64) (*ResultSetVoteExtensionsEnableHeight, error) {
+	err := env.Client.SetVoteExtensionsEnableHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultSetVoteExtensionsEnableHeight{}, nil
+}
+
+type ResultExtendedCommit struct {
+	ExtendedCommit *types.ExtendedCommit `json:"extended_commit"`
+}
+
+// ExtendedCommit gets the commit for the block at a given height, including the vote extensions
+// of the validators. If no height is provided, it will fetch the commit for the latest block.
+// For blocks that were replayed from an archive, the extensions are not known and are empty.
+// This API is specific to CometMock.
+func (env *Environment) ExtendedCommit(ctx *rpctypes.Context, heightPtr *int64) (*ResultExtendedCommit, error) {
+	height, err := getHeight(env.Client.LastBlock.Height, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	extendedCommit, err := env.Client.Storage.GetExtendedCommit(height)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultExtendedCommit{ExtendedCommit: extendedCommit}, nil
+}
+
+type ResultAppHashHistory struct {
+	Entries []abci_client.AppHashEntry `json:"entries"`
+}
+
+// AppHashHistory returns the app hash after each block with a height from from to to, inclusive.
+// If from is 0, the history starts at the initial height, and if to is 0, it ends at the latest height.
+// At heights where the apps computed different app hashes, the app hash of each app is included,
+// also for blocks that were not committed because of the divergence.
+// This API is specific to CometMock.
+func (env *Environment) AppHashHistory(ctx *rpctypes.Context, from, to int64) (*ResultAppHashHistory, error) {
+	entries, err := env.Client.AppHashHistory(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultAppHashHistory{Entries: entries}, nil
+}
+
+type ResultExportIndex struct {
+	Blocks []abci_client.ExportedBlock `json:"blocks"`
+}
+
+// ExportIndex returns the blocks with heights from from to to, inclusive, with the events of the blocks
+// and the results and events of their transactions, i.e. what tx_search and block_search search in.
+// If from is 0, the export starts at the initial height, and if to is 0, it ends at the latest height.
+// This API is specific to CometMock.
+func (env *Environment) ExportIndex(ctx *rpctypes.Context, from, to int64) (*ResultExportIndex, error) {
+	blocks, err := env.Client.ExportIndex(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultExportIndex{Blocks: blocks}, nil
+}
+
+type ResultExportDataDir struct {
+	// the exported data directory on the machine of CometMock
+	Dir string `json:"dir"`
+	// the lowest and the highest height of the exported blocks
+	Base   int64 `json:"base"`
+	Height int64 `json:"height"`
+}
+
+// EnableDataDirExport serves export_data_dir, which writes data directories below the given root directory.
+// Since it writes to the disk of CometMock on behalf of any caller, it is not served otherwise.
+// It needs to be called before the RPC server is started.
+func (env *Environment) EnableDataDirExport(root string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	env.dataDirExportRoot = root
+	return env.RegisterRoute("export_data_dir", rpc.NewRPCFunc(env.ExportDataDir, "dir"))
+}
+
+// ExportDataDir writes the stored history of the chain into the given directory below the export root
+// on the machine of CometMock, see EnableDataDirExport, in the format of the data directory of a CometBFT node,
+// i.e. blockstore.db, state.db and priv_validator_state.json, so that CometBFT nodes can take over the chain
+// from the latest height. The directory must not exist yet, or be empty.
+// This API is specific to CometMock.
+func (env *Environment) ExportDataDir(ctx *rpctypes.Context, dir string) (*ResultExportDataDir, error) {
+	if env.dataDirExportRoot == "" {
+		return nil, errors.New("exporting data directories is not enabled, see --data-dir-export-root")
+	}
+	if !filepath.IsLocal(dir) {
+		return nil, fmt.Errorf("dir must be a relative path below the export root, but got %q", dir)
+	}
+	exported, err := env.Client.ExportDataDir(filepath.Join(env.dataDirExportRoot, dir))
+	if err != nil {
+		return nil, err
+	}
+	return &ResultExportDataDir{Dir: exported.Dir, Base: exported.Base, Height: exported.Height}, nil
+}
+
+type ResultABCILatencies struct {
+	// the latencies per app, in the order of the apps at startup
+	Clients []abci_client.ClientLatencies `json:"clients"`
+}
+
+// ABCILatencies returns histograms of the latencies of the ABCI calls to each app, by method,
+// e.g. to find the app that slows down block production. The same histograms are served as metrics,
+// see --prometheus-listen-address.
+// This API is specific to CometMock.
+func (env *Environment) ABCILatencies(ctx *rpctypes.Context) (*ResultABCILatencies, error) {
+	return &ResultABCILatencies{Clients: env.Client.ABCILatencies()}, nil
+}
+
+type ResultStartLoad struct{}
+
+// StartLoad starts submitting transactions produced by the given generator at the given rate,
+// in transactions per second, for the given number of seconds.
+// The generator is of the form name:arg, e.g. file:/path/to/txs with one base64 encoded
+// transaction per line, or template:key{{.Index}}=value, see loadgen.NewGenerator.
+// The progress is shown by load_stats.
+// This API is specific to CometMock.
+func (env *Environment) StartLoad(ctx *rpctypes.Context, generator string, rate int64, duration_in_seconds int64) (*ResultStartLoad, error) {
+	gen, err := loadgen.NewGenerator(generator)
+	if err != nil {
+		return nil, err
+	}
+	err = env.LoadGenerator.Start(gen, generator, rate, time.Duration(duration_in_seconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultStartLoad{}, nil
+}
+
+type ResultStopLoad struct{}
+
+// StopLoad stops submitting transactions started with start_load.
+// This API is specific to CometMock.
+func (env *Environment) StopLoad(ctx *rpctypes.Context) (*ResultStopLoad, error) {
+	env.LoadGenerator.Stop()
+	return &ResultStopLoad{}, nil
+}
+
+type ResultLoadStats struct {
+	Stats loadgen.Stats `json:"stats"`
+}
+
+// LoadStats returns how many transactions submitted by start_load were accepted and included,
+// and how long that took.
+// This API is specific to CometMock.
+func (env *Environment) LoadStats(ctx *rpctypes.Context) (*ResultLoadStats, error) {
+	return &ResultLoadStats{Stats: env.LoadGenerator.Stats()}, nil
+}
+
+type ResultScheduleUpgrade struct{}
+
+// ScheduleUpgrade announces an upgrade at the given height. The apps running the pre-upgrade version
+// must halt at that height, after which CometMock reconnects to the upgraded apps at the given addresses,
+// given in the same order as at startup, and checks that the app hashes of all apps agree after the upgrade.
+// The progress of the upgrade is shown in cometmock_status.
+// This API is specific to CometMock.
+func (env *Environment) ScheduleUpgrade(ctx *rpctypes.Context, height int64, appAddresses []string) (*ResultScheduleUpgrade, error) {
+	err := env.Client.ScheduleUpgrade(height, appAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultScheduleUpgrade{}, nil
+}
+
+type ResultResume struct{}
+
+// Resume resumes block production after it was halted, e.g. because the apps stopped for an upgrade.
+// The apps are reconnected at the given addresses, given in the same order as at startup,
+// or at their previous addresses if none are given.
+// This API is specific to CometMock.
+func (env *Environment) Resume(ctx *rpctypes.Context, appAddresses []string) (*ResultResume, error) {
+	err := env.Client.Resume(appAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultResume{}, nil
+}
+
+type ResultReloadApps struct{}
+
+// ReloadApps reconnects to the apps whose addresses changed or whose connection failed, while blocks are produced.
+// The app addresses are given in the same order as at startup, followed by new observers.
+// If none are given, they are read from the app addresses file given at startup.
+// Apps that are behind are caught up by replaying the stored blocks.
+// This API is specific to CometMock.
+func (env *Environment) ReloadApps(ctx *rpctypes.Context, appAddresses []string) (*ResultReloadApps, error) {
+	var err error
+	if len(appAddresses) == 0 {
+		err = env.Client.ReloadAppsFromFile()
+	} else {
+		err = env.Client.ReloadApps(appAddresses)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ResultReloadApps{}, nil
+}
+
+type ResultResetChain struct {
+	// the height and the time of the first block of the reset chain
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// ResetChain wipes the chain and starts it again from the genesis, with apps that were reset or freshly started.
+// The app addresses are given in the same order as at startup. If none are given, the apps are reconnected
+// at their previous addresses.
+// This API is specific to CometMock.
+func (env *Environment) ResetChain(ctx *rpctypes.Context, appAddresses []string) (*ResultResetChain, error) {
+	if env.Replayer != nil {
+		return nil, errors.New("cannot reset the chain while replaying an archive")
+	}
+	if err := env.Client.ResetChain(appAddresses); err != nil {
+		return nil, err
+	}
+	lastBlock := env.Client.LastBlock
+	return &ResultResetChain{Height: lastBlock.Height, Time: lastBlock.Time}, nil
+}
+
+type ResultSnapshots struct {
+	// the snapshots, from the oldest to the most recent
+	Snapshots []abci_client.StateSnapshot `json:"snapshots"`
+}
+
+// Snapshots returns the snapshots of the state that the chain can be rewound to, see --snapshot-interval.
+// This API is specific to CometMock.
+func (env *Environment) Snapshots(ctx *rpctypes.Context) (*ResultSnapshots, error) {
+	return &ResultSnapshots{Snapshots: env.Client.Snapshots()}, nil
+}
+
+type ResultRewindToSnapshot struct {
+	// the snapshot that the chain was rewound to
+	Snapshot abci_client.StateSnapshot `json:"snapshot"`
+}
+
+// RewindToSnapshot rewinds the chain to the snapshot at the given height, or to the most recent snapshot
+// if the height is 0. The apps need to have been restored to the height of the snapshot.
+// The app addresses are given in the same order as at startup. If none are given, the apps are reconnected
+// at their previous addresses.
+// This API is specific to CometMock.
+func (env *Environment) RewindToSnapshot(ctx *rpctypes.Context, height int64, appAddresses []string) (*ResultRewindToSnapshot, error) {
+	if env.Replayer != nil {
+		return nil, errors.New("cannot rewind the chain while replaying an archive")
+	}
+	snapshot, err := env.Client.RewindToSnapshot(height, appAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultRewindToSnapshot{Snapshot: *snapshot}, nil
+}
+
+// BlockChange changes a block when the blocks after a snapshot are replayed, see ReplayFromSnapshot.
+type BlockChange struct {
+	Height int64 `json:"height"`
+	// the transactions to propose instead of the transactions of the original block, unless this is null
+	Txs []types.Tx `json:"txs"`
+	// the timestamp of the block in RFC3339 format, instead of the timestamp of the original block, unless this is empty
+	Time string `json:"time"`
+}
+
+type ResultReplayFromSnapshot struct {
+	// the outcome of each replayed block, next to the outcome of the original block at its height
+	Blocks []abci_client.ReplayedBlock `json:"blocks"`
+}
+
+// ReplayFromSnapshot rewinds the chain to the snapshot at the given height, or to the most recent snapshot
+// if the height is 0, and produces the blocks that followed the snapshot again, with the given changes.
+// The apps need to have been restored to the height of the snapshot, like for RewindToSnapshot.
+// It returns the app hashes and results hashes of the replayed blocks and of the original blocks.
+// This API is specific to CometMock.
+func (env *Environment) ReplayFromSnapshot(ctx *rpctypes.Context, height int64, appAddresses []string, changes []BlockChange) (*ResultReplayFromSnapshot, error) {
+	if env.Replayer != nil {
+		return nil, errors.New("cannot rewind the chain while replaying an archive")
+	}
+
+	blockChanges := make([]abci_client.BlockChange, len(changes))
+	for i, change := range changes {
+		blockChanges[i].Height = change.Height
+		if change.Txs != nil {
+			txs := types.Txs(change.Txs)
+			blockChanges[i].Txs = &txs
+		}
+		if change.Time != "" {
+			t, err := time.Parse(time.RFC3339Nano, change.Time)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing the time of the block at height %d: %w", change.Height, err)
+			}
+			blockChanges[i].Time = t
+		}
+	}
+
+	blocks, err := env.Client.ReplayFromSnapshot(height, appAddresses, blockChanges)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultReplayFromSnapshot{Blocks: blocks}, nil
+}
+
+type Misbehaviour struct {
+	// the address of the private key of the misbehaving validator
+	ValidatorAddress string `json:"validator_address"`
+	// one of DuplicateVote, Equivocation, Lunatic, Amnesia
+	Type string `json:"type"`
+}
+
+type ResultRunBlock struct {
+	Height   int64          `json:"height"`
+	Hash     bytes.HexBytes `json:"hash"`
+	Time     time.Time      `json:"time"`
+	Proposer bytes.HexBytes `json:"proposer"`
+	Round    int32          `json:"round"`
+}
+
+// RunBlock produces a single block, with explicit overrides for how it is produced:
+// the timestamp (RFC3339, by default decided like for any other block),
+// the address of the proposer (by default selected by the proposer rotation),
+// the round in which the block is committed (by default the round set with set_commit_round), the transactions to propose
+// (by default the transactions waiting to be included), the misbehaviours
+// to include evidence for, and the addresses of the validators that sign
+// (by default the validators whose signing status is up).
+// This API is specific to CometMock.
+func (env *Environment) RunBlock(
+	ctx *rpctypes.Context,
+	timestamp string,
+	proposer string,
+	round int,
+	txs []types.Tx,
+	misbehaviours []Misbehaviour,
+	signers []string,
+) (*ResultRunBlock, error) {
+	client := env.Client
+	opts := abci_client.BlockOptions{}
+
+	if signers != nil {
+		opts.Signers = make([]string, len(signers))
+		for i, signer := range signers {
+			opts.Signers[i] = client.ResolveValidator(signer)
+		}
+	}
+
+	if timestamp != "" {
+		t, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing time: %w", err)
+		}
+		opts.Time = t
+	}
+
+	if proposer != "" {
+		validator, err := client.GetValidatorFromAddress(client.ResolveValidator(proposer))
+		if err != nil {
+			return nil, err
+		}
+		opts.Proposer = validator
+	}
+
+	if round < 0 || round > math.MaxInt32 {
+		return nil, fmt.Errorf("round must be between 0 and %d", math.MaxInt32)
+	}
+	opts.Round = int32(round)
+
+	if txs != nil {
+		blockTxs := types.Txs(txs)
+		opts.Txs = &blockTxs
+	}
+
+	if len(misbehaviours) > 0 {
+		opts.MisbehavingValidators = make(map[*types.Validator]abci_client.MisbehaviourType, len(misbehaviours))
+		for _, misbehaviour := range misbehaviours {
+			validator, err := client.GetValidatorFromAddress(client.ResolveValidator(misbehaviour.ValidatorAddress))
+			if err != nil {
+				return nil, err
+			}
+			misbehaviourType, err := abci_client.ParseMisbehaviourType(misbehaviour.Type)
+			if err != nil {
+				return nil, err
+			}
+			opts.MisbehavingValidators[validator] = misbehaviourType
+		}
+	}
+
+	err := client.RunBlockWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	block := client.LastBlock
+	return &ResultRunBlock{
+		Height:   block.Height,
+		Hash:     block.Hash(),
+		Time:     block.Time,
+		Proposer: block.ProposerAddress,
+		Round:    client.LastCommit.Round,
+	}, nil
+}
+
+type ResultSimulateBlock struct {
+	Height                int64                       `json:"height"`
+	Txs                   []types.Tx                  `json:"txs"`
+	Accepted              bool                        `json:"accepted"`
+	TxsResults            []*abcitypes.ExecTxResult   `json:"txs_results"`
+	FinalizeBlockEvents   []abcitypes.Event           `json:"finalize_block_events"`
+	ValidatorUpdates      []abcitypes.ValidatorUpdate `json:"validator_updates"`
+	ConsensusParamUpdates *cmtproto.ConsensusParams   `json:"consensus_param_updates"`
+	AppHash               bytes.HexBytes              `json:"app_hash"`
+}
+
+// SimulateBlock runs PrepareProposal, ProcessProposal and FinalizeBlock for the next block against a single app,
+// selected by target like for abci_query, and returns the results the block would have, without committing it.
+// The transactions to propose are txs, or the transactions waiting to be included if txs is absent.
+// The chain, the apps and the transactions waiting to be included are left as they were,
+// e.g. to preview the outcome of a large batch of transactions.
+// If the app rejects the block in ProcessProposal, accepted is false and there are no results.
+// This API is specific to CometMock.
+func (env *Environment) SimulateBlock(ctx *rpctypes.Context, target string, txs []types.Tx) (*ResultSimulateBlock, error) {
+	var blockTxs *types.Txs
+	if txs != nil {
+		simulatedTxs := types.Txs(txs)
+		blockTxs = &simulatedTxs
+	}
+
+	simulated, err := env.Client.SimulateBlock(target, blockTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResultSimulateBlock{
+		Height:   simulated.Height,
+		Txs:      simulated.Txs,
+		Accepted: simulated.Accepted,
+	}
+	if res := simulated.Response; res != nil {
+		result.TxsResults = res.TxResults
+		result.FinalizeBlockEvents = res.Events
+		result.ValidatorUpdates = res.ValidatorUpdates
+		result.ConsensusParamUpdates = res.ConsensusParamUpdates
+		result.AppHash = res.AppHash
+	}
+	return result, nil
+}
+
+type ResultSimulateTx struct {
+	Height   int64                     `json:"height"`
+	Hash     bytes.HexBytes            `json:"hash"`
+	CheckTx  abcitypes.ResponseCheckTx `json:"check_tx"`
+	TxResult abcitypes.ExecTxResult    `json:"tx_result"`
+}
+
+// SimulateTx runs CheckTx for the transaction against a single app, selected by target like for abci_query,
+// and if it passes, executes it alone in the next block without committing the block.
+// It returns the results like broadcast_tx_commit, with the gas and events of the execution in tx_result,
+// which is empty if the transaction failed CheckTx.
+// Unlike the simulate query of the Cosmos SDK, the transaction is executed like in a real block.
+// The chain is left as it was, but the app keeps the state of CheckTx until the next block is committed.
+// This API is specific to CometMock.
+func (env *Environment) SimulateTx(ctx *rpctypes.Context, tx types.Tx, target string) (*ResultSimulateTx, error) {
+	simulated, err := env.Client.SimulateTx(target, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResultSimulateTx{
+		Height:  simulated.Height,
+		Hash:    tx.Hash(),
+		CheckTx: *simulated.CheckTx,
+	}
+	if simulated.TxResult != nil {
+		result.TxResult = *simulated.TxResult
+	}
+	return result, nil
+}
+
+type ResultSetCommitRound struct {
+	Round int32 `json:"round"`
+}
+
+// SetCommitRound sets the round in which blocks are proposed and committed, unless run_block is called with another round,
+// so that apps observe commits with that round in DecidedLastCommit.
+// This API is specific to CometMock.
+func (env *Environment) SetCommitRound(ctx *rpctypes.Context, round int) (*ResultSetCommitRound, error) {
+	if round < 0 || round > math.MaxInt32 {
+		return nil, fmt.Errorf("round must be between 0 and %d", math.MaxInt32)
+	}
+	if err := env.Client.SetCommitRound(int32(round)); err != nil {
+		return nil, err
+	}
+	return &ResultSetCommitRound{Round: env.Client.CommitRound()}, nil
+}
+
+type ResultLogLevel struct {
+	// the levels of the log lines by module, see the --log-level flag
+	Level string `json:"level"`
+	// the number of blocks whose ABCI calls are still to be traced
+	TracedBlocks int `json:"traced_blocks"`
+}
+
+// SetLogLevel changes the levels of the log lines while CometMock runs, in the format of the --log-level flag,
+// e.g. debug or *:info,rpc-server:error. If level is empty, the levels are kept.
+// If traceBlocks is given, the full requests and responses of the ABCI calls to all apps are logged
+// while the next traceBlocks blocks are produced, and 0 stops tracing.
+// This API is specific to CometMock.
+func (env *Environment) SetLogLevel(ctx *rpctypes.Context, level string, traceBlocks *int) (*ResultLogLevel, error) {
+	if level != "" {
+		if env.LogLevels == nil {
+			return nil, errors.New("the log levels cannot be changed")
+		}
+		if err := env.LogLevels.Set(level); err != nil {
+			return nil, err
+		}
+	}
+	if traceBlocks != nil {
+		if *traceBlocks < 0 {
+			return nil, fmt.Errorf("trace_blocks must not be negative, but got %d", *traceBlocks)
+		}
+		env.Client.TraceBlocks(*traceBlocks)
+	}
+	return env.LogLevel(ctx)
+}
+
+// LogLevel returns the levels of the log lines, and the number of blocks that are still to be traced.
+// This API is specific to CometMock.
+func (env *Environment) LogLevel(ctx *rpctypes.Context) (*ResultLogLevel, error) {
+	result := &ResultLogLevel{TracedBlocks: env.Client.TracedBlocks()}
+	if env.LogLevels != nil {
+		result.Level = env.LogLevels.String()
+	}
+	return result, nil
+}
+
+type ResultFailpoints struct {
+	// the states of all failpoints, ordered by name
+	Failpoints []failpoints.Failpoint `json:"failpoints"`
+}
+
+// EnableFailpoint enables the failpoint with the given name, which injects a fault each time it fires,
+// e.g. storage.UpdateStores.error or finalize.slow. value is the message of the errors of failpoints that return errors,
+// and the delay in milliseconds of slow failpoints. If count is positive, the failpoint is disabled after
+// it fired count times. If probability is given, the failpoint fires with this probability each time it is reached,
+// and otherwise always.
+// This API is specific to CometMock.
+func (env *Environment) EnableFailpoint(ctx *rpctypes.Context, name string, value string, count int64, probability *float64) (*ResultFailpoints, error) {
+	if env.Client.Failpoints == nil {
+		return nil, errors.New("failpoints are not available")
+	}
+	p := 1.0
+	if probability != nil {
+		p = *probability
+	}
+	if err := env.Client.Failpoints.Enable(name, value, count, p); err != nil {
+		return nil, err
+	}
+	return env.Failpoints(ctx)
+}
+
+// DisableFailpoint disables the failpoint with the given name, or all failpoints if name is empty.
+// This API is specific to CometMock.
+func (env *Environment) DisableFailpoint(ctx *rpctypes.Context, name string) (*ResultFailpoints, error) {
+	if env.Client.Failpoints == nil {
+		return nil, errors.New("failpoints are not available")
+	}
+	names := []string{name}
+	if name == "" {
+		names = failpoints.Names()
+	}
+	for _, name := range names {
+		if err := env.Client.Failpoints.Disable(name); err != nil {
+			return nil, err
+		}
+	}
+	return env.Failpoints(ctx)
+}
+
+// Failpoints returns the states of all failpoints, with how many times the enabled ones fired.
+// This API is specific to CometMock.
+func (env *Environment) Failpoints(ctx *rpctypes.Context) (*ResultFailpoints, error) {
+	if env.Client.Failpoints == nil {
+		return &ResultFailpoints{Failpoints: []failpoints.Failpoint{}}, nil
+	}
+	return &ResultFailpoints{Failpoints: env.Client.Failpoints.List()}, nil
+}
+
+type ResultGetTime struct {
+	Time time.Time `json:"time"`
+}
+
+// GetTime returns the timestamp that the next block would have if it was produced now.
+// This API is specific to CometMock.
+func (env *Environment) GetTime(ctx *rpctypes.Context) (*ResultGetTime, error) {
+	client := env.Client
+	return &ResultGetTime{client.TimeHandler.PeekBlockTime(client.LastBlock.Time)}, nil
+}
+
+type ResultGetTimeOffset struct {
+	// the offset in nanoseconds
+	Offset time.Duration `json:"offset"`
+	// the offset in seconds, for convenience when computing durations for advance_time
+	OffsetSeconds float64 `json:"offset_seconds"`
+}
+
+// GetTimeOffset returns the offset by which the block times are shifted,
+// see abci_client.TimeHandler.TimeOffset.
+// This API is specific to CometMock.
+func (env *Environment) GetTimeOffset(ctx *rpctypes.Context) (*ResultGetTimeOffset, error) {
+	offset := env.Client.TimeHandler.TimeOffset()
+	return &ResultGetTimeOffset{
+		Offset:        offset,
+		OffsetSeconds: offset.Seconds(),
+	}, nil
+}
+
+type ResultBlockProduction struct {
+	// "interval" if blocks are produced periodically, "on_demand" if blocks are only produced when instructed
+	Mode string `json:"mode"`
+	// the time to sleep between blocks, if blocks are produced periodically
+	Interval time.Duration `json:"interval"`
+	// "system_clock" if block times follow the system time, "fixed" if they advance by a fixed block time
+	BlockTimeMode string `json:"block_time_mode"`
+	// the random jitter of the block times or block production interval, if any
+	Jitter        string `json:"jitter,omitempty"`
+	AutoIncludeTx bool   `json:"auto_include_tx"`
+	// the address of the validator that proposes all blocks, if any
+	FixedProposer string `json:"fixed_proposer,omitempty"`
+}
+
+type ResultCometMockStatus struct {
+	LatestBlockHeight int64     `json:"latest_block_height"`
+	LatestBlockTime   time.Time `json:"latest_block_time"`
+	// the offset by which block times are shifted by advancing the time
+	TimeOffset      time.Duration              `json:"time_offset"`
+	BlockProduction ResultBlockProduction      `json:"block_production"`
+	Validators      []abci_client.ClientStatus `json:"validators"`
+	LastDivergence  *abci_client.Divergence    `json:"last_divergence"`
+	// why block production is halted, or null if it is not
+	Halt *abci_client.HaltInfo `json:"halt"`
+	// the number of transactions waiting to be included, -1 while a block is produced
+	MempoolSize int          `json:"mempool_size"`
+	Storage     storage.Info `json:"storage"`
+	// the last upgrade scheduled with schedule_upgrade, if any
+	Upgrade *abci_client.UpgradeStatus `json:"upgrade,omitempty"`
+	// the progress of replaying an archive, if CometMock replays one
+	Replay *replay.Status `json:"replay,omitempty"`
+}
+
+// CometMockStatus returns diagnostic information about the internal state of CometMock.
+// This API is specific to CometMock.
+func (env *Environment) CometMockStatus(ctx *rpctypes.Context) (*ResultCometMockStatus, error) {
+	client := env.Client
+
+	blockProduction := ResultBlockProduction{
+		Mode:          "on_demand",
+		AutoIncludeTx: client.AutoIncludeTx,
+		FixedProposer: client.FixedProposerAddress,
+	}
+	if client.BlockProductionInterval > 0 {
+		blockProduction.Mode = "interval"
+		blockProduction.Interval = client.BlockProductionInterval
+		if client.BlockProductionJitter != nil {
+			blockProduction.Jitter = client.BlockProductionJitter.String()
+		}
+	}
+	switch timeHandler := client.TimeHandler.(type) {
+	case *abci_client.SystemClockTimeHandler:
+		blockProduction.BlockTimeMode = "system_clock"
+	case *abci_client.FixedBlockTimeHandler:
+		blockProduction.BlockTimeMode = "fixed"
+		if jitter := timeHandler.Jitter(); jitter != nil {
+			blockProduction.Jitter = jitter.String()
+		}
+	}
+
+	var replayStatus *replay.Status
+	if env.Replayer != nil {
+		status := env.Replayer.Status()
+		replayStatus = &status
+	}
+
+	return &ResultCometMockStatus{
+		LatestBlockHeight: client.LastBlock.Height,
+		LatestBlockTime:   client.LastBlock.Time,
+		TimeOffset:        client.TimeHandler.TimeOffset(),
+		BlockProduction:   blockProduction,
+		Validators:        client.GetClientStatuses(),
+		LastDivergence:    client.LastDivergence(),
+		Halt:              client.Halted(),
+		MempoolSize:       client.MempoolSize(),
+		Storage:           client.Storage.Info(),
+		Upgrade:           client.GetUpgradeStatus(),
+		Replay:            replayStatus,
+	}, nil
 }
 
 type ResultCauseLightClientAttack struct{}
 
-func CauseLightClientAttack(ctx *rpctypes.Context, privateKeyAddress, misbehaviourType string) (*ResultCauseLightClientAttack, error) {
-	err := abci_client.GlobalClient.CauseLightClientAttack(privateKeyAddress, misbehaviourType)
+func (env *Environment) CauseLightClientAttack(ctx *rpctypes.Context, privateKeyAddress, misbehaviourType string) (*ResultCauseLightClientAttack, error) {
+	err := env.Client.CauseLightClientAttack(privateKeyAddress, misbehaviourType)
 	return &ResultCauseLightClientAttack{}, err
 }
 
+type ResultProduceConflictingBlocks struct {
+	Height        int64               `json:"height"`
+	SignedHeaderA *types.SignedHeader `json:"signed_header_a"`
+	SignedHeaderB *types.SignedHeader `json:"signed_header_b"`
+	ValidatorSet  *types.ValidatorSet `json:"validator_set"`
+}
+
+// ProduceConflictingBlocks signs two different blocks at the next height with all validators whose keys are known,
+// and returns their signed headers and the validator set, without committing either of them.
+// This API is specific to CometMock.
+func (env *Environment) ProduceConflictingBlocks(ctx *rpctypes.Context) (*ResultProduceConflictingBlocks, error) {
+	blocks, err := env.Client.ProduceConflictingBlocks()
+	if err != nil {
+		return nil, err
+	}
+	return &ResultProduceConflictingBlocks{
+		Height:        blocks.Height,
+		SignedHeaderA: blocks.SignedHeaderA,
+		SignedHeaderB: blocks.SignedHeaderB,
+		ValidatorSet:  blocks.ValidatorSet,
+	}, nil
+}
+
+type ResultIBCMisbehaviour struct {
+	// the height of the conflicting headers
+	Height int64 `json:"height"`
+	// the misbehaviour packed into an Any, in the JSON encoding of protobuf
+	Misbehaviour *ibc.Misbehaviour `json:"misbehaviour"`
+	// the protobuf encoding of the misbehaviour, i.e. the value of the Any
+	MisbehaviourBytes []byte `json:"misbehaviour_bytes"`
+}
+
+// IBCMisbehaviour returns the blocks from the last produce_conflicting_blocks as the Misbehaviour
+// of the 07-tendermint light client of ibc-go, for a client on a counterparty chain that trusts the given height.
+// If trustedHeight is 0, the light client is assumed to trust the height before the conflicting blocks.
+// This API is specific to CometMock.
+func (env *Environment) IBCMisbehaviour(ctx *rpctypes.Context, clientID string, trustedHeight int64) (*ResultIBCMisbehaviour, error) {
+	blocks := env.Client.LastConflictingBlocks()
+	if blocks == nil {
+		return nil, errors.New("no conflicting blocks were produced yet, call produce_conflicting_blocks first")
+	}
+
+	if trustedHeight == 0 {
+		trustedHeight = blocks.Height - 1
+	}
+	if trustedHeight < env.Client.CurState.InitialHeight || trustedHeight >= blocks.Height {
+		return nil, fmt.Errorf("trusted height must be at least the initial height %d and below the height %d of the conflicting blocks, but got %d",
+			env.Client.CurState.InitialHeight, blocks.Height, trustedHeight)
+	}
+	// the light client trusts the next validators of the header at the trusted height,
+	// which are the validators in the state after that height
+	trustedState, err := env.Client.Storage.GetState(trustedHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	misbehaviour, err := ibc.NewMisbehaviour(clientID, blocks.SignedHeaderA.ChainID, blocks, trustedHeight, trustedState.Validators)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := misbehaviour.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &ResultIBCMisbehaviour{
+		Height:            blocks.Height,
+		Misbehaviour:      misbehaviour,
+		MisbehaviourBytes: bz,
+	}, nil
+}
+
+type ResultMisbehaviourRules struct {
+	// the rules in the format of --misbehaviour-rules
+	Rules []string `json:"rules"`
+	// the seed of the random rules
+	Seed int64 `json:"seed"`
+}
+
+// SetMisbehaviourRules replaces the rules by which validators misbehave automatically,
+// given in the format of --misbehaviour-rules. An empty string removes all rules.
+// If seed is 0, the seed of the random rules is left unchanged.
+// This API is specific to CometMock.
+func (env *Environment) SetMisbehaviourRules(ctx *rpctypes.Context, rules string, seed int64) (*ResultMisbehaviourRules, error) {
+	parsedRules, err := abci_client.ParseMisbehaviourRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	if seed == 0 {
+		_, seed = env.Client.MisbehaviourRules()
+	}
+	env.Client.SetMisbehaviourRules(parsedRules, seed)
+	return env.MisbehaviourRules(ctx)
+}
+
+// MisbehaviourRules returns the rules by which validators misbehave automatically, and the seed of the random rules.
+// This API is specific to CometMock.
+func (env *Environment) MisbehaviourRules(ctx *rpctypes.Context) (*ResultMisbehaviourRules, error) {
+	rules, seed := env.Client.MisbehaviourRules()
+	ruleStrings := make([]string, len(rules))
+	for index, rule := range rules {
+		ruleStrings[index] = rule.String()
+	}
+	return &ResultMisbehaviourRules{Rules: ruleStrings, Seed: seed}, nil
+}
+
+type ResultValidatorNames struct {
+	// the validator addresses by name
+	Names map[string]string `json:"names"`
+}
+
+// ValidatorNames returns the names of the validators, which control endpoints accept in place of their addresses.
+// The names are given with --validator-names, or taken from the genesis.
+// This API is specific to CometMock.
+func (env *Environment) ValidatorNames(ctx *rpctypes.Context) (*ResultValidatorNames, error) {
+	return &ResultValidatorNames{Names: env.Client.ValidatorNames()}, nil
+}
+
+// ResultVersion has the fields of cometmockversion.Info. They are not embedded,
+// since the JSON encoding of CometBFT does not flatten embedded structs.
+type ResultVersion struct {
+	Version         string `json:"version"`
+	GitCommit       string `json:"git_commit"`
+	CometBFTVersion string `json:"cometbft_version"`
+	ABCIVersion     string `json:"abci_version"`
+	BlockProtocol   uint64 `json:"block_protocol"`
+	P2PProtocol     uint64 `json:"p2p_protocol"`
+	GoVersion       string `json:"go_version"`
+}
+
+// Version returns the version of CometMock, the git commit it was built from, and the versions of CometBFT
+// and ABCI that it mocks.
+// This API is specific to CometMock.
+func (env *Environment) Version(ctx *rpctypes.Context) (*ResultVersion, error) {
+	info := cometmockversion.Get()
+	return &ResultVersion{
+		Version:         info.Version,
+		GitCommit:       info.GitCommit,
+		CometBFTVersion: info.CometBFTVersion,
+		ABCIVersion:     info.ABCIVersion,
+		BlockProtocol:   info.BlockProtocol,
+		P2PProtocol:     info.P2PProtocol,
+		GoVersion:       info.GoVersion,
+	}, nil
+}
+
 type ResultCauseDoubleSign struct{}
 
-func CauseDoubleSign(ctx *rpctypes.Context, privateKeyAddress string) (*ResultCauseDoubleSign, error) {
-	err := abci_client.GlobalClient.CauseDoubleSign(privateKeyAddress)
+func (env *Environment) CauseDoubleSign(ctx *rpctypes.Context, privateKeyAddress string) (*ResultCauseDoubleSign, error) {
+	err := env.Client.CauseDoubleSign(privateKeyAddress)
 	return &ResultCauseDoubleSign{}, err
 }
 
@@ -82,12 +1017,12 @@ type ResultAdvanceTime struct {
 
 // AdvanceTime advances the block time by the given duration.
 // This API is specific to CometMock.
-func AdvanceTime(ctx *rpctypes.Context, duration_in_seconds time.Duration) (*ResultAdvanceTime, error) {
+func (env *Environment) AdvanceTime(ctx *rpctypes.Context, duration_in_seconds time.Duration) (*ResultAdvanceTime, error) {
 	if duration_in_seconds < 0 {
 		return nil, errors.New("duration to advance time by must be greater than 0")
 	}
 
-	res := abci_client.GlobalClient.TimeHandler.AdvanceTime(duration_in_seconds * time.Second)
+	res := env.Client.TimeHandler.AdvanceTime(duration_in_seconds * time.Second)
 	return &ResultAdvanceTime{res}, nil
 }
 
@@ -95,37 +1030,39 @@ type ResultSetSigningStatus struct {
 	NewSigningStatusMap map[string]bool `json:"new_signing_status_map"`
 }
 
-func SetSigningStatus(ctx *rpctypes.Context, privateKeyAddress string, status string) (*ResultSetSigningStatus, error) {
+func (env *Environment) SetSigningStatus(ctx *rpctypes.Context, privateKeyAddress string, status string) (*ResultSetSigningStatus, error) {
 	if status != "down" && status != "up" {
 		return nil, errors.New("status must be either `up` to have the validator sign, or `down` to have the validator not sign")
 	}
 
-	err := abci_client.GlobalClient.SetSigningStatus(privateKeyAddress, status == "up")
+	err := env.Client.SetSigningStatus(privateKeyAddress, status == "up")
 
 	return &ResultSetSigningStatus{
-		NewSigningStatusMap: abci_client.GlobalClient.GetSigningStatusMap(),
+		NewSigningStatusMap: env.Client.GetSigningStatusMap(),
 	}, err
 }
 
 type ResultAdvanceBlocks struct{}
 
 // AdvanceBlocks advances the block height by numBlocks, running empty blocks.
+// If skipIndexing is true, no events are published for the blocks, so they
+// cannot be found with block_search or tx_search, which makes advancing faster.
 // This API is specific to CometMock.
-func AdvanceBlocks(ctx *rpctypes.Context, numBlocks int) (*ResultAdvanceBlocks, error) {
+func (env *Environment) AdvanceBlocks(ctx *rpctypes.Context, numBlocks int, skipIndexing bool) (*ResultAdvanceBlocks, error) {
 	if numBlocks < 1 {
 		return nil, errors.New("num_blocks must be greater than 0")
 	}
 
-	err := abci_client.GlobalClient.RunEmptyBlocks(numBlocks)
+	err := env.Client.RunEmptyBlocksWithOptions(numBlocks, abci_client.BlockOptions{SkipEvents: skipIndexing})
 	if err != nil {
 		return nil, err
 	}
 	return &ResultAdvanceBlocks{}, nil
 }
 
-// BlockSearch searches for a paginated set of blocks matching BeginBlock and
-// EndBlock event search criteria.
-func BlockSearch(
+// BlockSearch searches for a paginated set of blocks matching FinalizeBlock
+// event search criteria.
+func (env *Environment) BlockSearch(
 	ctx *rpctypes.Context,
 	query string,
 	pagePtr, perPagePtr *int,
@@ -136,7 +1073,7 @@ func BlockSearch(
 		return nil, err
 	}
 
-	results, err := abci_client.GlobalClient.BlockIndex.Search(ctx.Context(), q)
+	results, err := env.Client.BlockIndex.Search(ctx.Context(), q)
 	if err != nil {
 		return nil, err
 	}
@@ -167,24 +1104,20 @@ func BlockSearch(
 
 	apiResults := make([]*ctypes.ResultBlock, 0, pageSize)
 	for i := skipCount; i < skipCount+pageSize; i++ {
-		block, err := abci_client.GlobalClient.Storage.GetBlock(results[i])
+		// like CometBFT, blocks that are indexed but not stored are skipped
+		block, err := env.Client.Storage.GetBlock(results[i])
+		if err != nil || block == nil {
+			continue
+		}
+		blockId, err := utils.GetBlockIdFromBlock(block)
 		if err != nil {
 			return nil, err
 		}
-		if block != nil {
-			if err != nil {
-				return nil, err
-			}
-			blockId, err := utils.GetBlockIdFromBlock(block)
-			if err != nil {
-				return nil, err
-			}
 
-			apiResults = append(apiResults, &ctypes.ResultBlock{
-				Block:   block,
-				BlockID: *blockId,
-			})
-		}
+		apiResults = append(apiResults, &ctypes.ResultBlock{
+			Block:   block,
+			BlockID: *blockId,
+		})
 	}
 
 	return &ctypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount}, nil
@@ -194,8 +1127,8 @@ func BlockSearch(
 // transaction is in the mempool, invalidated, or was not sent in the first
 // place.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/tx
-func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
-	txIndexer := abci_client.GlobalClient.TxIndex
+func (env *Environment) Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	txIndexer := env.Client.TxIndex
 
 	r, err := txIndexer.Get(hash)
 	if err != nil {
@@ -211,7 +1144,7 @@ func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error
 
 	var proof types.TxProof
 	if prove {
-		block, err := abci_client.GlobalClient.Storage.GetBlock(height)
+		block, err := env.Client.Storage.GetBlock(height)
 		if err != nil {
 			return nil, err
 		}
@@ -231,7 +1164,7 @@ func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error
 // TxSearch allows you to query for multiple transactions results. It returns a
 // list of transactions (maximum ?per_page entries) and the total count.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/tx_search
-func TxSearch(
+func (env *Environment) TxSearch(
 	ctx *rpctypes.Context,
 	query string,
 	prove bool,
@@ -247,7 +1180,7 @@ func TxSearch(
 		return nil, err
 	}
 
-	results, err := abci_client.GlobalClient.TxIndex.Search(ctx.Context(), q)
+	results, err := env.Client.TxIndex.Search(ctx.Context(), q)
 	if err != nil {
 		return nil, err
 	}
@@ -290,7 +1223,7 @@ func TxSearch(
 
 		var proof types.TxProof
 		if prove {
-			block, err := abci_client.GlobalClient.Storage.GetBlock(r.Height)
+			block, err := env.Client.Storage.GetBlock(r.Height)
 			if err != nil {
 				return nil, err
 			}
@@ -325,61 +1258,54 @@ func getHeight(latestHeight int64, heightPtr *int64) (int64, error) {
 	return latestHeight, nil
 }
 
-// // Header gets block header at a given height.
-// // If no height is provided, it will fetch the latest header.
-// // More: https://docs.cometbft.com/v0.37/rpc/#/Info/header
-// func Header(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultHeader, error) {
-// 	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	block, err := abci_client.GlobalClient.Storage.GetBlock(height)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	return &ctypes.ResultHeader{Header: &block.Header}, nil
-// }
-
 // Commit gets block commit at a given height.
 // If no height is provided, it will fetch the commit for the latest block.
+// Like in CometBFT, the commit of the latest block is not canonical, since it is not
+// included in a block yet.
 // More: https://docs.cometbft.com/main/rpc/#/Info/commit
-func Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
-	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
+func (env *Environment) Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
+	latestHeight := env.Client.LastBlock.Height
+	height, err := getHeight(latestHeight, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	commit, err := abci_client.GlobalClient.Storage.GetCommit(height)
+	commit, err := env.Client.Storage.GetCommit(height)
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := abci_client.GlobalClient.Storage.GetBlock(height)
+	block, err := env.Client.Storage.GetBlock(height)
 	if err != nil {
 		return nil, err
 	}
 
-	return ctypes.NewResultCommit(&block.Header, commit, true), nil
+	return ctypes.NewResultCommit(&block.Header, commit, height < latestHeight), nil
 }
 
 // ConsensusParams gets the consensus parameters at the given block height.
 // If no height is provided, it will fetch the latest consensus params.
+// Like in CometBFT, the latest consensus params are those after the last block, i.e. at the height of the next block,
+// so that the updates that the apps returned from FinalizeBlock for the last block are included.
 // More: https://docs.cometbft.com/v0.37/rpc/#/Info/consensus_params
-func ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultConsensusParams, error) {
-	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
+func (env *Environment) ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultConsensusParams, error) {
+	curState := env.Client.CurState
+	nextHeight := curState.LastBlockHeight + 1
+	height, err := getHeight(nextHeight, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	stateForHeight, err := abci_client.GlobalClient.Storage.GetState(height)
-	if err != nil {
-		return nil, err
+	// the stored state of a height is the state before its block, which holds the params for the block
+	consensusParams := curState.ConsensusParams
+	if height < nextHeight {
+		stateForHeight, err := env.Client.Storage.GetState(height)
+		if err != nil {
+			return nil, cometstate.ErrNoConsensusParamsForHeight{Height: height}
+		}
+		consensusParams = stateForHeight.ConsensusParams
 	}
 
-	consensusParams := stateForHeight.ConsensusParams
-
 	return &ctypes.ResultConsensusParams{
 		BlockHeight:     height,
 		ConsensusParams: consensusParams,
@@ -389,18 +1315,27 @@ func ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCon
 // Status returns CometBFT status including node info, pubkey, latest block
 // hash, app hash, block height and time.
 // More: https://docs.cometbft.com/v0.37/rpc/#/Info/status
-func Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
+func (env *Environment) Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
 	// return status as if we are the first validator
-	curState := abci_client.GlobalClient.CurState
+	curState := env.Client.CurState
 	validator := curState.Validators.Validators[0]
+	// like in NetInfo, the node is named after its validator
+	moniker := validator.Address.String()
+	for name, address := range env.Client.ValidatorNames() {
+		if address == moniker {
+			moniker = name
+		}
+	}
 
 	nodeInfo := p2p.DefaultNodeInfo{
 		DefaultNodeID: p2p.PubKeyToID(validator.PubKey),
-		Network:       abci_client.GlobalClient.CurState.ChainID,
+		Network:       env.Client.CurState.ChainID,
+		Moniker:       moniker,
 		Other: p2p.DefaultNodeInfoOther{
 			TxIndex: "on",
 		},
-		Version: "0.38.0",
+		// the version of CometBFT, with the version of CometMock as build metadata
+		Version: cometmockversion.NodeVersion(version.TMCoreSemVer),
 		ProtocolVersion: p2p.NewProtocolVersion(
 			version.P2PProtocol, // global
 			curState.Version.Consensus.Block,
@@ -408,12 +1343,22 @@ func Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
 		),
 	}
 	syncInfo := ctypes.SyncInfo{
-		LatestBlockHash:   abci_client.GlobalClient.LastBlock.Hash(),
-		LatestAppHash:     abci_client.GlobalClient.LastBlock.AppHash,
-		LatestBlockHeight: abci_client.GlobalClient.LastBlock.Height,
-		LatestBlockTime:   abci_client.GlobalClient.CurState.LastBlockTime,
+		LatestBlockHash:   env.Client.LastBlock.Hash(),
+		LatestAppHash:     env.Client.LastBlock.AppHash,
+		LatestBlockHeight: env.Client.LastBlock.Height,
+		LatestBlockTime:   env.Client.CurState.LastBlockTime,
 		CatchingUp:        false,
 	}
+	// like CometBFT, report the earliest stored block, which is after the initial height once older blocks were pruned
+	base := cmtmath.MaxInt64(curState.InitialHeight, env.Client.Storage.Info().PrunedBelow)
+	if base <= env.Client.LastBlock.Height {
+		if earliest, err := env.Client.Storage.GetBlock(base); err == nil {
+			syncInfo.EarliestBlockHash = earliest.Hash()
+			syncInfo.EarliestAppHash = earliest.AppHash
+			syncInfo.EarliestBlockHeight = earliest.Height
+			syncInfo.EarliestBlockTime = earliest.Time
+		}
+	}
 	validatorInfo := ctypes.ValidatorInfo{
 		Address:     validator.Address,
 		PubKey:      validator.PubKey,
@@ -428,30 +1373,222 @@ func Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
 	return result, nil
 }
 
+// NetInfo returns the network info of the node. CometMock mocks all nodes in one process, so it listens on no p2p address,
+// and returns the apps that are connected and alive as its peers, with their validator address as node id,
+// their name as moniker and their network address as listen address.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/net_info
+func (env *Environment) NetInfo(ctx *rpctypes.Context) (*ctypes.ResultNetInfo, error) {
+	// the apps take the place of the peers, as long as they are connected and alive, see --liveness-probe-interval
+	names := make(map[string]string)
+	for name, address := range env.Client.ValidatorNames() {
+		names[address] = name
+	}
+	peers := make([]ctypes.Peer, 0)
+	for _, status := range env.Client.GetClientStatuses() {
+		if !status.Connected || status.Missing || (status.Liveness != nil && !status.Liveness.Alive) {
+			continue
+		}
+		remoteIP := ""
+		if _, address := cmtnet.ProtocolAndAddress(status.NetworkAddress); address != "" {
+			if host, _, err := net.SplitHostPort(address); err == nil {
+				remoteIP = host
+			}
+		}
+		moniker := status.ValidatorAddress
+		if name, ok := names[status.ValidatorAddress]; ok {
+			moniker = name
+		}
+		peers = append(peers, ctypes.Peer{
+			NodeInfo: p2p.DefaultNodeInfo{
+				DefaultNodeID: p2p.ID(strings.ToLower(status.ValidatorAddress)),
+				ListenAddr:    status.NetworkAddress,
+				Network:       env.Client.CurState.ChainID,
+				Moniker:       moniker,
+			},
+			IsOutbound: true,
+			RemoteIP:   remoteIP,
+		})
+	}
+
+	return &ctypes.ResultNetInfo{
+		Listening: true,
+		Listeners: []string{},
+		NPeers:    len(peers),
+		Peers:     peers,
+	}, nil
+}
+
+// Genesis returns the genesis that the chain was started from. For chains that were bootstrapped
+// from a live chain, this is the genesis with the substituted validators, see --substitute-validators.
+// Like in CometBFT, genesis files that are larger than a chunk of GenesisChunked are only served by GenesisChunked.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/genesis
+func (env *Environment) Genesis(ctx *rpctypes.Context) (*ctypes.ResultGenesis, error) {
+	genesisDoc := env.Client.GenesisDoc()
+	if genesisDoc == nil {
+		return nil, errors.New("the chain was not initialized yet")
+	}
+	chunks, err := env.genesisChunks.get(genesisDoc)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) > 1 {
+		return nil, errors.New("genesis response is large, please use the genesis_chunked API instead")
+	}
+	return &ctypes.ResultGenesis{Genesis: genesisDoc}, nil
+}
+
+// GenesisChunked returns the given chunk of the JSON of the genesis, base64 encoded,
+// for genesis files that are too large to be served by Genesis. Chunks are numbered from 0.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/genesis_chunked
+func (env *Environment) GenesisChunked(ctx *rpctypes.Context, chunk uint) (*ctypes.ResultGenesisChunk, error) {
+	genesisDoc := env.Client.GenesisDoc()
+	if genesisDoc == nil {
+		return nil, errors.New("the chain was not initialized yet")
+	}
+	chunks, err := env.genesisChunks.get(genesisDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	id := int(chunk)
+	if id > len(chunks)-1 {
+		return nil, fmt.Errorf("there are %d chunks, %d is invalid", len(chunks)-1, id)
+	}
+	return &ctypes.ResultGenesisChunk{
+		TotalChunks: len(chunks),
+		ChunkNumber: id,
+		Data:        chunks[id],
+	}, nil
+}
+
 // Health gets node health. Returns empty result (200 OK) on success, no
 // response - in case of an error.
-func Health(ctx *rpctypes.Context) (*ctypes.ResultHealth, error) {
+// Returns an error if an app is unresponsive, see the --unresponsive-threshold flag.
+func (env *Environment) Health(ctx *rpctypes.Context) (*ctypes.ResultHealth, error) {
+	if err := env.Client.CheckHealth(); err != nil {
+		return nil, err
+	}
 	return &ctypes.ResultHealth{}, nil
 }
 
-// CURRENTLY UNSUPPORTED - THIS IS BECAUSE IT IS DISCOURAGED TO USE THIS BY COMETBFT
-// needs some major changes to work with ABCI++
-// BroadcastTxCommit broadcasts a transaction,
-// and wait until it is included in a block and and comitted.
-// In our case, this means running a block with just the the transition,
-// then return.
-func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
-	return nil, errors.New("BroadcastTxCommit is currently not supported. Try BroadcastTxSync or BroadcastTxAsync instead")
+// DefaultTimeoutBroadcastTxCommit is how long BroadcastTxCommit waits for a transaction to be committed
+// by default, like timeout_broadcast_tx_commit in the config of CometBFT.
+const DefaultTimeoutBroadcastTxCommit = 10 * time.Second
+
+// how often BroadcastTxCommit looks for the transaction in the committed blocks,
+// for blocks that were produced without publishing events
+const broadcastTxCommitPollInterval = 100 * time.Millisecond
+
+// BroadcastTxCommit broadcasts a transaction, and waits until it is included in a block and committed.
+// Like in CometBFT, if CheckTx fails, the result of CheckTx is returned right away,
+// and if the transaction is not committed within the TimeoutBroadcastTxCommit of the environment,
+// the result of CheckTx is returned together with a timeout error.
+// The result of executing the transaction includes the events it emitted. It is taken from the Tx event,
+// or, for blocks produced without publishing events, found by the index of the transaction in the committed block.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Tx/broadcast_tx_commit
+func (env *Environment) BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	client := env.Client
+	client.Logger.Info(
+		"BroadcastTxCommit called", "tx", tx)
+
+	// subscribe to the tx being committed in a block before it can be included
+	subscriber := ctx.RemoteAddr()
+	subCtx, cancel := context.WithTimeout(ctx.Context(), SubscribeTimeout)
+	defer cancel()
+	q := types.EventQueryTxFor(tx)
+	txSub, err := client.EventBus.Subscribe(subCtx, subscriber, q)
+	if err != nil {
+		err = fmt.Errorf("failed to subscribe to tx: %w", err)
+		client.Logger.Error("Error on broadcast_tx_commit", "err", err)
+		return nil, err
+	}
+	defer func() {
+		if err := client.EventBus.Unsubscribe(context.Background(), subscriber, q); err != nil {
+			client.Logger.Error("Error unsubscribing from eventBus", "err", err)
+		}
+	}()
+
+	// the transaction can only be included in blocks after this one
+	checkedHeight := client.LastBlockHeight()
+
+	resBroadcastTx, err := env.BroadcastTx(&tx)
+	if err != nil {
+		client.Logger.Error("Error on broadcastTxCommit", "err", err)
+		return nil, fmt.Errorf("error on broadcastTxCommit: %v", err)
+	}
+	checkTxRes := resBroadcastTx.CheckTx
+	if checkTxRes.Code != abcitypes.CodeTypeOK {
+		return &ctypes.ResultBroadcastTxCommit{
+			CheckTx:  checkTxRes,
+			TxResult: abcitypes.ExecTxResult{},
+			Hash:     tx.Hash(),
+		}, nil
+	}
+
+	// wait for the tx to be included in a block or time out
+	poll := time.NewTicker(broadcastTxCommitPollInterval)
+	defer poll.Stop()
+	timeout := time.After(env.TimeoutBroadcastTxCommit)
+	for {
+		select {
+		case msg := <-txSub.Out():
+			txResultEvent := msg.Data().(types.EventDataTx)
+			return &ctypes.ResultBroadcastTxCommit{
+				CheckTx:  checkTxRes,
+				TxResult: txResultEvent.Result,
+				Hash:     tx.Hash(),
+				Height:   txResultEvent.Height,
+			}, nil
+		case <-poll.C:
+			for lastHeight := client.LastBlockHeight(); checkedHeight < lastHeight; checkedHeight++ {
+				txResult, err := client.CommittedTxResult(checkedHeight+1, tx)
+				if err != nil {
+					client.Logger.Error("Error looking for tx in committed block", "height", checkedHeight+1, "err", err)
+					break
+				}
+				if txResult != nil {
+					return &ctypes.ResultBroadcastTxCommit{
+						CheckTx:  checkTxRes,
+						TxResult: txResult.Result,
+						Hash:     tx.Hash(),
+						Height:   txResult.Height,
+					}, nil
+				}
+			}
+		case <-txSub.Canceled():
+			reason := "CometMock exited"
+			if txSub.Err() != nil {
+				reason = txSub.Err().Error()
+			}
+			err = fmt.Errorf("txSub was canceled (reason: %s)", reason)
+			client.Logger.Error("Error on broadcastTxCommit", "err", err)
+			return &ctypes.ResultBroadcastTxCommit{
+				CheckTx:  checkTxRes,
+				TxResult: abcitypes.ExecTxResult{},
+				Hash:     tx.Hash(),
+			}, err
+		case <-ctx.Context().Done():
+			return nil, fmt.Errorf("broadcast confirmation not received: %w", ctx.Context().Err())
+		case <-timeout:
+			err = errors.New("timed out waiting for tx to be included in a block")
+			client.Logger.Error("Error on broadcastTxCommit", "err", err)
+			return &ctypes.ResultBroadcastTxCommit{
+				CheckTx:  checkTxRes,
+				TxResult: abcitypes.ExecTxResult{},
+				Hash:     tx.Hash(),
+			}, err
+		}
+	}
 }
 
 // BroadcastTxSync would normally broadcast a transaction and wait until it gets the result from CheckTx.
 // In our case, we run a block with just the transition in it,
 // then return.
-func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
-	abci_client.GlobalClient.Logger.Info(
+func (env *Environment) BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	env.Client.Logger.Info(
 		"BroadcastTxSync called", "tx", tx)
 
-	resBroadcastTx, err := BroadcastTx(&tx)
+	resBroadcastTx, err := env.BroadcastTx(&tx)
 	if err != nil {
 		return nil, err
 	}
@@ -468,11 +1605,11 @@ func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcas
 // BroadcastTxAsync would normally broadcast a transaction and return immediately.
 // In our case, we always include the transition in the next block, and return when that block is committed.
 // ResultBroadcastTx is empty, since we do not return the result of CheckTx nor DeliverTx.
-func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
-	abci_client.GlobalClient.Logger.Info(
+func (env *Environment) BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	env.Client.Logger.Info(
 		"BroadcastTxAsync called", "tx", tx)
 
-	_, err := BroadcastTx(&tx)
+	_, err := env.BroadcastTx(&tx)
 	if err != nil {
 		return nil, err
 	}
@@ -480,67 +1617,207 @@ func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadca
 	return &ctypes.ResultBroadcastTx{}, nil
 }
 
-func BroadcastTx(tx *types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
-	abci_client.GlobalClient.Logger.Info(
+func (env *Environment) BroadcastTx(tx *types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	env.Client.Logger.Info(
 		"BroadcastTxs called", "tx", tx)
 
-	txBytes := []byte(*tx)
-	checkTxResponse, err := abci_client.GlobalClient.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+	checkTxResponse, err := env.checkAndQueueTx(*tx)
 	if err != nil {
 		return nil, err
 	}
-	abci_client.GlobalClient.QueueTx(*tx)
 
-	if abci_client.GlobalClient.AutoIncludeTx {
-		go abci_client.GlobalClient.RunBlock()
+	if env.Client.AutoIncludeTx {
+		go env.Client.RunBlock()
 	}
 
 	return &ctypes.ResultBroadcastTxCommit{
 		CheckTx: *checkTxResponse,
 		Hash:    tx.Hash(),
-		Height:  abci_client.GlobalClient.CurState.LastBlockHeight,
-	}, err
+		Height:  env.Client.LastBlockHeight(),
+	}, nil
+}
+
+// checkAndQueueTx checks the transaction against the mempool limits and with CheckTx,
+// and queues it for the next block. Transactions that CheckTx rejected are queued as well,
+// they are dropped if CheckTx still rejects them before the next block.
+func (env *Environment) checkAndQueueTx(tx types.Tx) (*abcitypes.ResponseCheckTx, error) {
+	if err := env.Client.CheckMempoolLimits(tx); err != nil {
+		return nil, err
+	}
+
+	// if CheckTx is skipped, the transaction is reported as accepted
+	checkTxResponse := &abcitypes.ResponseCheckTx{Code: abcitypes.CodeTypeOK}
+	if !env.Client.SkipCheckTx {
+		txBytes := []byte(tx)
+		var err error
+		checkTxResponse, err = env.Client.SendCheckTx(abcitypes.CheckTxType_New, &txBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	env.Client.QueueTx(tx)
+	return checkTxResponse, nil
+}
+
+// BroadcastTxsResult is the result of broadcasting one of the transactions of broadcast_txs.
+// It has the fields of the result of broadcast_tx_sync.
+type BroadcastTxsResult struct {
+	Code      uint32         `json:"code"`
+	Data      bytes.HexBytes `json:"data"`
+	Log       string         `json:"log"`
+	Codespace string         `json:"codespace"`
+	Hash      bytes.HexBytes `json:"hash"`
+	// the error if the transaction could not be checked, e.g. because the mempool is full
+	Error string `json:"error,omitempty"`
+}
+
+type ResultBroadcastTxs struct {
+	// the results, in the order of the transactions
+	Results []BroadcastTxsResult `json:"results"`
+	// the number of transactions that CheckTx accepted
+	Accepted int `json:"accepted"`
 }
 
-func ABCIInfo(ctx *rpctypes.Context) (*ctypes.ResultABCIInfo, error) {
-	abci_client.GlobalClient.Logger.Info(
+// BroadcastTxs broadcasts the transactions like broadcast_tx_sync, one after the other, and returns the result of CheckTx
+// for each of them. Transactions that cannot be checked, e.g. because they are too large, do not stop the others,
+// and their results have an error instead. The accepted transactions are included together in the next block,
+// which is produced right away with --auto-tx.
+// This API is specific to CometMock.
+func (env *Environment) BroadcastTxs(ctx *rpctypes.Context, txs []types.Tx) (*ResultBroadcastTxs, error) {
+	env.Client.Logger.Info(
+		"BroadcastTxs called", "num_txs", len(txs))
+
+	if len(txs) == 0 {
+		return nil, errors.New("no transactions given")
+	}
+
+	result := &ResultBroadcastTxs{Results: make([]BroadcastTxsResult, len(txs))}
+	for i, tx := range txs {
+		result.Results[i].Hash = tx.Hash()
+		checkTxResponse, err := env.checkAndQueueTx(tx)
+		if err != nil {
+			result.Results[i].Error = err.Error()
+			continue
+		}
+		result.Results[i].Code = checkTxResponse.Code
+		result.Results[i].Data = checkTxResponse.Data
+		result.Results[i].Log = checkTxResponse.Log
+		result.Results[i].Codespace = checkTxResponse.Codespace
+		if checkTxResponse.Code == abcitypes.CodeTypeOK {
+			result.Accepted++
+		}
+	}
+
+	if env.Client.AutoIncludeTx && result.Accepted > 0 {
+		go env.Client.RunBlock()
+	}
+	return result, nil
+}
+
+func (env *Environment) ABCIInfo(ctx *rpctypes.Context) (*ctypes.ResultABCIInfo, error) {
+	env.Client.Logger.Info(
 		"ABCIInfo called")
 
-	response, err := abci_client.GlobalClient.SendAbciInfo()
+	response, err := env.Client.SendAbciInfo()
 	return &ctypes.ResultABCIInfo{Response: *response}, err
 }
 
-func ABCIQuery(
+// ABCIQuery queries the apps.
+// If target is empty, all apps are queried and must respond the same.
+// Otherwise, only the app selected by target is queried, which is either
+// the validator address of the app or its index in the app addresses given at startup.
+// The target is specific to CometMock.
+func (env *Environment) ABCIQuery(
 	ctx *rpctypes.Context,
 	path string,
 	data bytes.HexBytes,
 	height int64,
 	prove bool,
+	target string,
 ) (*ctypes.ResultABCIQuery, error) {
-	abci_client.GlobalClient.Logger.Info(
-		"ABCIQuery called", "path", "data", "height", "prove", path, data, height, prove)
+	env.Client.Logger.Info(
+		"ABCIQuery called", "path", "data", "height", "prove", "target", path, data, height, prove, target)
 
-	response, err := abci_client.GlobalClient.SendAbciQuery(data, path, height, prove)
+	response, err := env.query(path, data, height, prove, target)
 	if err != nil {
 		return nil, err
 	}
 
-	abci_client.GlobalClient.Logger.Info(
+	env.Client.Logger.Info(
 		"Response to ABCI query", response.String())
 	return &ctypes.ResultABCIQuery{Response: *response}, err
 }
 
-func Validators(ctx *rpctypes.Context, heightPtr *int64, pagePtr, perPagePtr *int) (*ctypes.ResultValidators, error) {
-	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
+// query sends the query to the apps selected by target, see ABCIQuery.
+func (env *Environment) query(path string, data bytes.HexBytes, height int64, prove bool, target string) (*abcitypes.ResponseQuery, error) {
+	if target == "" {
+		return env.Client.SendAbciQuery(data, path, height, prove)
+	}
+	client, err := env.Client.GetCounterpartyFromTarget(target)
 	if err != nil {
 		return nil, err
 	}
+	return env.Client.SendAbciQueryToClient(client, data, path, height, prove)
+}
+
+// BatchQuery is a query of abci_query_batch, with the parameters of abci_query.
+type BatchQuery struct {
+	Path   string         `json:"path"`
+	Data   bytes.HexBytes `json:"data"`
+	Height int64          `json:"height"`
+	Prove  bool           `json:"prove"`
+}
+
+type ResultABCIQueryBatch struct {
+	// the height at which queries without a height were answered if same_height was set, and 0 otherwise
+	Height    int64                     `json:"height"`
+	Responses []abcitypes.ResponseQuery `json:"responses"`
+}
+
+// ABCIQueryBatch sends the queries to the apps selected by target like ABCIQuery, one after the other,
+// and returns the responses in the same order, e.g. to read many store keys in a single round trip.
+// If sameHeight is set, queries without a height are answered at the height of the last block
+// when the batch started, so that all of them see the same state even if blocks are produced meanwhile.
+// This API is specific to CometMock.
+func (env *Environment) ABCIQueryBatch(
+	ctx *rpctypes.Context,
+	queries []BatchQuery,
+	target string,
+	sameHeight bool,
+) (*ResultABCIQueryBatch, error) {
+	env.Client.Logger.Info(
+		"ABCIQueryBatch called", "queries", len(queries), "target", target, "same_height", sameHeight)
+
+	result := &ResultABCIQueryBatch{Responses: make([]abcitypes.ResponseQuery, 0, len(queries))}
+	if sameHeight {
+		result.Height = env.Client.LastBlock.Height
+	}
+
+	for index, query := range queries {
+		height := query.Height
+		if height == 0 {
+			height = result.Height
+		}
+		response, err := env.query(query.Path, query.Data, height, query.Prove, target)
+		if err != nil {
+			return nil, fmt.Errorf("error in query %d with path %v: %w", index, query.Path, err)
+		}
+		result.Responses = append(result.Responses, *response)
+	}
+	return result, nil
+}
 
-	pastState, err := abci_client.GlobalClient.Storage.GetState(height)
+func (env *Environment) Validators(ctx *rpctypes.Context, heightPtr *int64, pagePtr, perPagePtr *int) (*ctypes.ResultValidators, error) {
+	height, err := getHeight(env.Client.LastBlock.Height, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
+	pastState, err := env.Client.Storage.GetState(height)
+	if err != nil {
+		return nil, cometstate.ErrNoValSetForHeight{Height: height}
+	}
+
 	validators := pastState.Validators
 
 	totalCount := len(validators.Validators)
@@ -609,13 +1886,16 @@ func validateSkipCount(page, perPage int) int {
 	return skipCount
 }
 
-func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
-	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
+// Block gets the stored block at a given height, together with its block ID.
+// If no height is provided, it will fetch the latest block.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/block
+func (env *Environment) Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
+	height, err := getHeight(env.Client.LastBlock.Height, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := abci_client.GlobalClient.Storage.GetBlock(height)
+	block, err := env.Client.Storage.GetBlock(height)
 	if err != nil {
 		return nil, err
 	}
@@ -628,6 +1908,52 @@ func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error)
 	return &ctypes.ResultBlock{BlockID: *blockID, Block: block}, nil
 }
 
+// Header gets the header of the block at a given height.
+// If no height is provided, it will fetch the latest header.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/header
+func (env *Environment) Header(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultHeader, error) {
+	height, err := getHeight(env.Client.LastBlock.Height, heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := env.Client.Storage.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctypes.ResultHeader{Header: &block.Header}, nil
+}
+
+// BlockByHash gets the stored block with the given hash, together with its block ID.
+// Like in CometBFT, the result is empty if no block with the hash is stored.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/block_by_hash
+func (env *Environment) BlockByHash(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultBlock, error) {
+	block, err := env.Client.Storage.GetBlockByHash(hash)
+	if err != nil {
+		return &ctypes.ResultBlock{BlockID: types.BlockID{}, Block: nil}, nil
+	}
+
+	blockID, err := utils.GetBlockIdFromBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctypes.ResultBlock{BlockID: *blockID, Block: block}, nil
+}
+
+// HeaderByHash gets the header of the stored block with the given hash.
+// Like in CometBFT, the result is empty if no block with the hash is stored.
+// More: https://docs.cometbft.com/v0.38/rpc/#/Info/header_by_hash
+func (env *Environment) HeaderByHash(ctx *rpctypes.Context, hash bytes.HexBytes) (*ctypes.ResultHeader, error) {
+	block, err := env.Client.Storage.GetBlockByHash(hash)
+	if err != nil {
+		return &ctypes.ResultHeader{}, nil
+	}
+
+	return &ctypes.ResultHeader{Header: &block.Header}, nil
+}
+
 // BlockResults gets ABCIResults at a given height.
 // If no height is provided, it will fetch results for the latest block.
 //
@@ -635,13 +1961,13 @@ func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error)
 // Thus response.results.deliver_tx[5] is the results of executing
 // getBlock(h).Txs[5]
 // More: https://docs.cometbft.com/v0.37/rpc/#/Info/block_results
-func BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
-	height, err := getHeight(abci_client.GlobalClient.LastBlock.Height, heightPtr)
+func (env *Environment) BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
+	height, err := getHeight(env.Client.LastBlock.Height, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := abci_client.GlobalClient.Storage.GetResponses(height)
+	results, err := env.Client.Storage.GetResponses(height)
 	if err != nil {
 		return nil, err
 	}
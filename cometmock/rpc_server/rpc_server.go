@@ -7,23 +7,51 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/cometbft/cometbft/libs/log"
 	rpcserver "github.com/cometbft/cometbft/rpc/jsonrpc/server"
 	"github.com/cometbft/cometbft/rpc/jsonrpc/types"
 )
 
-func StartRPCServer(listenAddr string, logger log.Logger, config *rpcserver.Config) {
+// StartRPCServer starts an RPC server serving the routes of the given environment.
+func StartRPCServer(env *Environment, listenAddr string, logger log.Logger, config *rpcserver.Config) {
+	startRPCServer(env, listenAddr, logger, config, "", false)
+}
+
+// StartCompatRPCServer starts an RPC server that renders responses like the given version of CometBFT
+// unless requests select another version, see CompatHandler.
+func StartCompatRPCServer(env *Environment, listenAddr string, logger log.Logger, compatVersion string) {
+	startRPCServer(env, listenAddr, logger, rpcserver.DefaultConfig(), compatVersion, false)
+}
+
+// StartReadOnlyRPCServer starts an RPC server that only serves the read-only routes of the given environment,
+// see ReadOnlyRoutes, so that heavy query traffic, e.g. during load tests, can be directed to it and kept apart
+// from the clients that control block production. The REST control API is not served.
+func StartReadOnlyRPCServer(env *Environment, listenAddr string, logger log.Logger) {
+	startRPCServer(env, listenAddr, logger.With("mode", "read-only"), rpcserver.DefaultConfig(), "", true)
+}
+
+func startRPCServer(env *Environment, listenAddr string, logger log.Logger, config *rpcserver.Config, compatVersion string, readOnly bool) {
+	// like CometBFT, make sure responses of broadcast_tx_commit can be written after waiting for the tx
+	if config.WriteTimeout <= env.TimeoutBroadcastTxCommit {
+		config.WriteTimeout = env.TimeoutBroadcastTxCommit + 1*time.Second
+	}
+
 	mux := http.NewServeMux()
 	logger.Info("Starting RPC HTTP server on", "address", listenAddr)
 	rpcLogger := logger.With("module", "rpc-server")
 	wmLogger := rpcLogger.With("protocol", "websocket")
-	wm := rpcserver.NewWebsocketManager(Routes,
-		rpcserver.ReadLimit(config.MaxBodyBytes),
-	)
+	wm := env.newWebsocketManager(config.MaxBodyBytes)
 	wm.SetLogger(wmLogger)
 	mux.HandleFunc("/websocket", wm.WebsocketHandler)
-	rpcserver.RegisterRPCFuncs(mux, Routes, rpcLogger)
+	if readOnly {
+		rpcserver.RegisterRPCFuncs(mux, env.ReadOnlyRoutes(), rpcLogger)
+	} else {
+		rpcserver.RegisterRPCFuncs(mux, env.Routes(), rpcLogger)
+		env.RegisterRESTHandlers(mux, rpcLogger.With("protocol", "rest"))
+		env.registerBlockWebhook(mux, rpcLogger.With("protocol", "webhook"))
+	}
 	listener, err := rpcserver.Listen(
 		listenAddr,
 		config.MaxOpenConnections,
@@ -32,7 +60,7 @@ func StartRPCServer(listenAddr string, logger log.Logger, config *rpcserver.Conf
 		panic(err)
 	}
 
-	var rootHandler http.Handler = mux
+	var rootHandler http.Handler = CompatHandler(mux, compatVersion, rpcLogger)
 	if err := rpcserver.Serve(
 		listener,
 		ExtraLogHandler(rootHandler, rpcLogger),
@@ -44,8 +72,8 @@ func StartRPCServer(listenAddr string, logger log.Logger, config *rpcserver.Conf
 	}
 }
 
-func StartRPCServerWithDefaultConfig(listenAddr string, logger log.Logger) {
-	StartRPCServer(listenAddr, logger, rpcserver.DefaultConfig())
+func StartRPCServerWithDefaultConfig(env *Environment, listenAddr string, logger log.Logger) {
+	StartRPCServer(env, listenAddr, logger, rpcserver.DefaultConfig())
 }
 
 // RecoverAndLogHandler wraps an HTTP handler, adding error logging.
@@ -0,0 +1,52 @@
+package rpc_server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+)
+
+// The block webhook produces a single block for each POST request, so that shell scripts and other tools
+// can trigger blocks without constructing JSON-RPC payloads. It is only served if a token is configured,
+// which requests need to send as bearer token in their Authorization header.
+const blockWebhookPath = "/webhook/block"
+
+// ResultBlockWebhook is the response of the block webhook.
+type ResultBlockWebhook struct {
+	// the height of the produced block
+	Height int64 `json:"height"`
+}
+
+// registerBlockWebhook serves the block webhook, unless no token is configured.
+func (env *Environment) registerBlockWebhook(mux *http.ServeMux, logger log.Logger) {
+	if env.BlockWebhookToken == "" {
+		return
+	}
+	logger.Info("Serving the block webhook", "path", blockWebhookPath)
+	mux.HandleFunc(blockWebhookPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeRESTResponse(w, http.StatusMethodNotAllowed, restError{Error: "only POST is supported"})
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(env.BlockWebhookToken)) != 1 {
+			writeRESTResponse(w, http.StatusUnauthorized, restError{Error: "missing or wrong bearer token"})
+			return
+		}
+
+		if err := env.Client.RunBlock(); err != nil {
+			logger.Error("Error producing a block for the block webhook", "err", err)
+			code := http.StatusInternalServerError
+			if errors.Is(err, abci_client.ErrHalted) {
+				code = http.StatusConflict
+			}
+			writeRESTResponse(w, code, restError{Error: err.Error()})
+			return
+		}
+		writeRESTResponse(w, http.StatusOK, ResultBlockWebhook{Height: env.Client.LastBlockHeight()})
+	})
+}
@@ -11,8 +11,8 @@ import (
 	cmtpubsub "github.com/cometbft/cometbft/libs/pubsub"
 	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpcserver "github.com/cometbft/cometbft/rpc/jsonrpc/server"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
-	"github.com/informalsystems/CometMock/cometmock/abci_client"
 )
 
 const (
@@ -23,12 +23,74 @@ const (
 	SubscriptionBufferSize = 100
 )
 
+// WebsocketConfig limits the websocket connections and their subscriptions, like the websocket settings
+// of the RPC config of CometBFT, so that a stalled subscriber cannot hold up the events of other subscribers.
+type WebsocketConfig struct {
+	// the maximum number of clients that can subscribe to events, including internal subscribers like the indexer
+	MaxSubscriptionClients int
+	// the maximum number of queries that a connection can subscribe to
+	MaxSubscriptionsPerClient int
+	// the number of events buffered per subscription. Subscriptions whose buffer is full are canceled
+	SubscriptionBufferSize int
+	// the number of responses buffered per connection until they are written
+	WriteBufferSize int
+	// how long writing a response to a connection may take, including waiting for room in the write buffer
+	WriteWait time.Duration
+	// If this is true, subscriptions whose events cannot be written within WriteWait are canceled with the reason
+	// "slow client". Otherwise, such events are dropped.
+	CloseOnSlowClient bool
+	// how often connections are pinged. Connections that do not answer within 10/9 of the period are closed
+	PingPeriod time.Duration
+}
+
+// DefaultWebsocketConfig returns the default limits of websocket connections, which are those of CometBFT,
+// except that slow clients are dropped.
+func DefaultWebsocketConfig() WebsocketConfig {
+	return WebsocketConfig{
+		MaxSubscriptionClients:    100,
+		MaxSubscriptionsPerClient: 5,
+		SubscriptionBufferSize:    SubscriptionBufferSize,
+		WriteBufferSize:           100,
+		WriteWait:                 10 * time.Second,
+		CloseOnSlowClient:         true,
+		PingPeriod:                27 * time.Second,
+	}
+}
+
+// newWebsocketManager returns the manager of the websocket connections of the RPC, see WebsocketConfig.
+// Messages from clients may be at most readLimit bytes.
+func (env *Environment) newWebsocketManager(readLimit int64) *rpcserver.WebsocketManager {
+	config := env.Websocket
+	return rpcserver.NewWebsocketManager(env.Routes(),
+		rpcserver.ReadLimit(readLimit),
+		rpcserver.WriteChanCapacity(config.WriteBufferSize),
+		rpcserver.WriteWait(config.WriteWait),
+		rpcserver.PingPeriod(config.PingPeriod),
+		rpcserver.ReadWait(config.PingPeriod*10/9),
+		// like CometBFT, drop the subscriptions of connections that are gone, which would otherwise fill up
+		rpcserver.OnDisconnect(func(remoteAddr string) {
+			if err := env.Client.EventBus.UnsubscribeAll(context.Background(), remoteAddr); err != nil && err != cmtpubsub.ErrSubscriptionNotFound {
+				env.Client.Logger.Error("Error unsubscribing disconnected websocket client", "remote", remoteAddr, "err", err)
+			}
+		}),
+	)
+}
+
 // Subscribe for events via WebSocket.
 // More: https://docs.cometbft.com/v0.38.x/rpc/#/Websocket/subscribe
-func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
+func (env *Environment) Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
 	addr := ctx.RemoteAddr()
 
-	client := abci_client.GlobalClient
+	client := env.Client
+	config := env.Websocket
+
+	if client.EventBus.NumClients() >= config.MaxSubscriptionClients {
+		return nil, fmt.Errorf("max_subscription_clients %d reached", config.MaxSubscriptionClients)
+	} else if client.EventBus.NumClientSubscriptions(addr) >= config.MaxSubscriptionsPerClient {
+		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", config.MaxSubscriptionsPerClient)
+	} else if len(query) > maxQueryLength {
+		return nil, errors.New("maximum query length exceeded")
+	}
 
 	client.Logger.Info("Subscribe to query", "remote", addr, "query", query)
 
@@ -40,12 +102,12 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 	subCtx, cancel := context.WithTimeout(ctx.Context(), SubscribeTimeout)
 	defer cancel()
 
-	sub, err := client.EventBus.Subscribe(subCtx, addr, q, SubscriptionBufferSize)
+	sub, err := client.EventBus.Subscribe(subCtx, addr, q, config.SubscriptionBufferSize)
 	if err != nil {
 		return nil, err
 	}
 
-	closeIfSlow := false
+	closeIfSlow := config.CloseOnSlowClient
 
 	// Capture the current ID, since it can change in the future.
 	subscriptionID := ctx.JSONReq.ID
@@ -57,9 +119,10 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 					resultEvent = &ctypes.ResultEvent{Query: query, Data: msg.Data(), Events: msg.Events()}
 					resp        = rpctypes.NewRPCSuccessResponse(subscriptionID, resultEvent)
 				)
-				writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
-				if err := ctx.WSConn.WriteRPCResponse(writeCtx, resp); err != nil {
+				writeCtx, cancel := context.WithTimeout(context.Background(), config.WriteWait)
+				err := ctx.WSConn.WriteRPCResponse(writeCtx, resp)
+				cancel()
+				if err != nil {
 					client.Logger.Info("Can't write response (slow client)",
 						"to", addr, "subscriptionID", subscriptionID, "err", err)
 
@@ -72,6 +135,10 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 							client.Logger.Info("Can't write response (slow client)",
 								"to", addr, "subscriptionID", subscriptionID, "err", err)
 						}
+						// stop buffering events for the dropped subscription, and free its slot
+						if err := client.EventBus.Unsubscribe(context.Background(), addr, q); err != nil && err != cmtpubsub.ErrSubscriptionNotFound {
+							client.Logger.Error("Error unsubscribing slow client", "remote", addr, "query", query, "err", err)
+						}
 						return
 					}
 				}
@@ -102,14 +169,14 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 
 // Unsubscribe from events via WebSocket.
 // More: https://docs.cometbft.com/v0.38.x/rpc/#/Websocket/unsubscribe
-func Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
+func (env *Environment) Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
 	addr := ctx.RemoteAddr()
-	abci_client.GlobalClient.Logger.Info("Unsubscribe from query", "remote", addr, "query", query)
+	env.Client.Logger.Info("Unsubscribe from query", "remote", addr, "query", query)
 	q, err := cmtquery.New(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
-	err = abci_client.GlobalClient.EventBus.Unsubscribe(context.Background(), addr, q)
+	err = env.Client.EventBus.Unsubscribe(context.Background(), addr, q)
 	if err != nil {
 		return nil, err
 	}
@@ -118,10 +185,10 @@ func Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe
 
 // UnsubscribeAll from all events via WebSocket.
 // More: https://docs.cometbft.com/v0.38.x/rpc/#/Websocket/unsubscribe_all
-func UnsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
+func (env *Environment) UnsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
 	addr := ctx.RemoteAddr()
-	abci_client.GlobalClient.Logger.Info("Unsubscribe from all", "remote", addr)
-	err := abci_client.GlobalClient.EventBus.UnsubscribeAll(context.Background(), addr)
+	env.Client.Logger.Info("Unsubscribe from all", "remote", addr)
+	err := env.Client.EventBus.UnsubscribeAll(context.Background(), addr)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,869 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto"
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/bootstrap"
+	"github.com/informalsystems/CometMock/cometmock/failpoints"
+	"github.com/informalsystems/CometMock/cometmock/genvalidators"
+	"github.com/informalsystems/CometMock/cometmock/grpc_server"
+	"github.com/informalsystems/CometMock/cometmock/interceptor"
+	"github.com/informalsystems/CometMock/cometmock/logging"
+	"github.com/informalsystems/CometMock/cometmock/manifest"
+	"github.com/informalsystems/CometMock/cometmock/metrics"
+	"github.com/informalsystems/CometMock/cometmock/replay"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/informalsystems/CometMock/cometmock/validatorkeys"
+	"github.com/urfave/cli/v2"
+)
+
+// options are the arguments and the flags of cometmock that can be checked before the genesis is read.
+// The other flags are read where they are used.
+type options struct {
+	appAddresses   []string
+	genesisFile    string
+	listenAddress  string
+	nodeHomes      string
+	connectionMode string
+
+	abciConnections      abci_client.ConnectionMode
+	queryMode            abci_client.QueryMode
+	determinismChecks    abci_client.DeterminismChecks
+	invariantQueries     []abci_client.InvariantQuery
+	genesisMisbehaviours map[string]abci_client.MisbehaviourType
+	importDataDir        string
+	misbehaviourRules    []abci_client.MisbehaviourRule
+	misbehaviourSeed     int64
+
+	blockProductionInterval int
+	jitter                  *abci_client.Jitter
+	validatorNames          map[string]string
+	clockSkews              map[string]time.Duration
+	commitRound             int32
+	livenessProbeInterval   int64
+	appConnectTimeout       time.Duration
+	degradedStartup         bool
+	storageMaxHeights       int
+	storageOverflowDir      string
+
+	broadcastTxCommitTimeout int64
+	websocketConfig          rpc_server.WebsocketConfig
+}
+
+// validatorSetup holds the keys of the validators and the addresses of their apps, see setupValidators.
+type validatorSetup struct {
+	appAddresses []string
+	// the keys of the validators with an app, in the order of the app addresses
+	privVals []types.PrivValidator
+	// the generated validators without an app
+	placeholderVals []types.PrivValidator
+	// the addresses of the validators of the genesis whose keys were substituted by ours, see substitute-validators
+	substitutedAddresses []string
+	manifestObservers    []string
+	appLatencies         map[string]time.Duration
+}
+
+// appConnections are the connections to the apps and the observers, see connectAppsAndObservers.
+type appConnections struct {
+	connectClient abci_client.ClientConnector
+	clients       map[string]abci_client.AbciCounterpartyClient
+	order         []string
+	// the indices of the apps that were not listening at startup, see degraded-startup
+	missingApps []int
+}
+
+// run runs cometmock with the arguments and flags of the given context.
+// It only returns when the arguments are invalid or the chain cannot be started.
+func run(c *cli.Context, logLevels *logging.Levels, logger cometlog.Logger) error {
+	opts, err := parseOptions(c)
+	if err != nil {
+		return err
+	}
+	if err := logLevels.Set(c.String("log-level")); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid log level: %v", err), 1)
+	}
+
+	appGenesis, err := genutiltypes.AppGenesisFromFile(opts.genesisFile)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+
+	genesisDoc, err := appGenesis.ToGenesisDoc()
+	if err != nil {
+		logger.Error(err.Error())
+		panic(err)
+	}
+
+	validators, err := setupValidators(c, opts, genesisDoc, logger)
+	if err != nil {
+		return err
+	}
+
+	curState, err := state.MakeGenesisState(genesisDoc)
+	if err != nil {
+		logger.Error(err.Error())
+		panic(err)
+	}
+
+	apps, err := connectAppsAndObservers(c, opts, validators, logger)
+	if err != nil {
+		return err
+	}
+
+	abciClient, err := newAbciClient(c, opts, validators, apps, curState, genesisDoc, logger)
+	if err != nil {
+		return err
+	}
+	if interceptorAddress := c.String("interceptor-address"); interceptorAddress != "" {
+		blockInterceptor, err := interceptor.Dial(interceptorAddress)
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+		defer blockInterceptor.Close()
+		abciClient.AddBlockHooks(blockInterceptor.BlockHooks())
+	}
+
+	env, err := newEnvironment(c, opts, abciClient, logLevels)
+	if err != nil {
+		return err
+	}
+
+	if unresponsiveThreshold := c.Int64("unresponsive-threshold"); unresponsiveThreshold > 0 {
+		abciClient.StartWatchdog(time.Duration(unresponsiveThreshold) * time.Millisecond)
+	}
+
+	if err := startChain(c, opts, validators, apps, abciClient, curState, genesisDoc, logger); err != nil {
+		return err
+	}
+
+	if replayArchive := c.String("replay-archive"); replayArchive != "" {
+		return runReplayArchive(replayArchive, opts.listenAddress, env, abciClient, logger)
+	}
+
+	// run an empty block, unless the chain continues from the blocks of the node
+	if opts.importDataDir == "" {
+		firstBlockOptions, err := abciClient.FirstBlockOptions()
+		if err != nil {
+			logger.Error(err.Error())
+			panic(err)
+		}
+		err = abciClient.RunBlockWithOptions(firstBlockOptions)
+		if err != nil {
+			logger.Error(err.Error())
+			panic(err)
+		}
+	}
+
+	startServers(c, opts, env, abciClient, logger)
+
+	if opts.blockProductionInterval > 0 {
+		produceBlocks(abciClient, time.Duration(opts.blockProductionInterval)*time.Millisecond, logger)
+	} else {
+		// wait forever
+		time.Sleep(time.Hour * 24 * 365 * 100) // 100 years
+	}
+	return nil
+}
+
+// parseOptions parses and checks the arguments and the flags of cometmock that do not depend on the genesis.
+func parseOptions(c *cli.Context) (*options, error) {
+	argumentString := usage(c.App.Flags)
+	if c.NArg() < 5 {
+		return nil, cli.Exit("Not enough arguments.\nUsage: "+argumentString, 1)
+	}
+
+	opts := &options{
+		appAddresses:   strings.Split(c.Args().Get(0), ","),
+		genesisFile:    c.Args().Get(1),
+		listenAddress:  c.Args().Get(2),
+		nodeHomes:      c.Args().Get(3),
+		connectionMode: c.Args().Get(4),
+	}
+
+	if opts.connectionMode != "socket" && opts.connectionMode != "grpc" && opts.connectionMode != "legacy-socket" {
+		return nil, cli.Exit(fmt.Sprintf("Invalid connection mode: %s. Connection mode must be either 'socket', 'grpc' or 'legacy-socket'.\nUsage: %s", opts.connectionMode, argumentString), 1)
+	}
+	// legacy apps commit in FinalizeBlock, so executing a block twice would commit it twice
+	if opts.connectionMode == "legacy-socket" && c.String("double-execution") != "" {
+		return nil, cli.Exit("Double execution is not supported with the legacy-socket connection mode.", 1)
+	}
+
+	var err error
+	opts.abciConnections, err = abci_client.ParseConnectionMode(c.String("abci-connections"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	opts.queryMode, err = abci_client.ParseQueryMode(c.String("query-mode"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	opts.determinismChecks, err = abci_client.ParseDeterminismChecks(c.String("determinism-checks"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	opts.invariantQueries, err = abci_client.ParseInvariantQueries(c.String("invariant-queries"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	opts.genesisMisbehaviours, err = abci_client.ParseMisbehaviours(c.String("genesis-misbehaviours"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	for address, misbehaviourType := range opts.genesisMisbehaviours {
+		if misbehaviourType != abci_client.DuplicateVote {
+			return nil, cli.Exit(fmt.Sprintf("only DuplicateVote misbehaviours can be included in the first block, got another type for %v", address), 1)
+		}
+	}
+
+	opts.importDataDir = c.String("import-data-dir")
+	if opts.importDataDir != "" && (c.String("replay-archive") != "" || c.String("substitute-validators") != "" || len(opts.genesisMisbehaviours) > 0) {
+		return nil, cli.Exit("import-data-dir cannot be combined with replay-archive, substitute-validators or genesis-misbehaviours.", 1)
+	}
+
+	opts.misbehaviourRules, err = abci_client.ParseMisbehaviourRules(c.String("misbehaviour-rules"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	opts.misbehaviourSeed = c.Int64("misbehaviour-seed")
+	if opts.misbehaviourSeed == 0 {
+		opts.misbehaviourSeed = time.Now().UnixNano()
+	}
+	if len(opts.misbehaviourRules) > 0 {
+		fmt.Printf("Misbehaviour rules: %v (seed %d)\n", opts.misbehaviourRules, opts.misbehaviourSeed)
+	}
+
+	opts.blockProductionInterval = c.Int("block-production-interval")
+	fmt.Printf("Block production interval: %d\n", opts.blockProductionInterval)
+
+	jitterSeed := c.Int64("block-jitter-seed")
+	if jitterSeed == 0 {
+		jitterSeed = time.Now().UnixNano()
+	}
+	opts.jitter, err = abci_client.ParseJitter(c.String("block-jitter"), jitterSeed)
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	if opts.jitter != nil {
+		fmt.Printf("Block jitter: %v\n", opts.jitter)
+	}
+
+	opts.validatorNames, err = abci_client.ParseValidatorNames(c.String("validator-names"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+
+	opts.clockSkews, err = abci_client.ParseClockSkews(c.String("clock-skews"))
+	if err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	if len(opts.clockSkews) > 0 && !c.Bool("bft-time") {
+		return nil, cli.Exit("clock-skews can only be used with bft-time", 1)
+	}
+
+	commitRound := c.Int("commit-round")
+	if commitRound < 0 || commitRound > math.MaxInt32 {
+		return nil, cli.Exit(fmt.Sprintf("commit-round must be between 0 and %d", math.MaxInt32), 1)
+	}
+	opts.commitRound = int32(commitRound)
+
+	opts.livenessProbeInterval = c.Int64("liveness-probe-interval")
+	if opts.livenessProbeInterval < 0 {
+		return nil, cli.Exit("liveness-probe-interval must not be negative", 1)
+	}
+
+	opts.appConnectTimeout = time.Duration(c.Int64("app-connect-timeout")) * time.Millisecond
+	if opts.appConnectTimeout < 0 {
+		return nil, cli.Exit("app-connect-timeout must not be negative", 1)
+	}
+	opts.degradedStartup = c.Bool("degraded-startup")
+
+	opts.storageMaxHeights = c.Int("storage-max-heights")
+	opts.storageOverflowDir = c.String("storage-overflow-dir")
+	if opts.storageMaxHeights < 0 {
+		return nil, cli.Exit("storage-max-heights must not be negative", 1)
+	}
+	if opts.storageOverflowDir != "" && opts.storageMaxHeights == 0 {
+		return nil, cli.Exit("storage-overflow-dir needs storage-max-heights to be set", 1)
+	}
+
+	opts.broadcastTxCommitTimeout = c.Int64("broadcast-tx-commit-timeout")
+	if opts.broadcastTxCommitTimeout <= 0 {
+		return nil, cli.Exit("broadcast-tx-commit-timeout must be greater than 0", 1)
+	}
+
+	opts.websocketConfig = rpc_server.DefaultWebsocketConfig()
+	opts.websocketConfig.MaxSubscriptionClients = c.Int("ws-max-subscription-clients")
+	opts.websocketConfig.MaxSubscriptionsPerClient = c.Int("ws-max-subscriptions-per-client")
+	opts.websocketConfig.SubscriptionBufferSize = c.Int("ws-buffer-size")
+	opts.websocketConfig.WriteBufferSize = c.Int("ws-buffer-size")
+	opts.websocketConfig.CloseOnSlowClient = c.Bool("ws-close-on-slow-client")
+	opts.websocketConfig.PingPeriod = time.Duration(c.Int64("ws-ping-period")) * time.Millisecond
+	if opts.websocketConfig.MaxSubscriptionClients <= 0 || opts.websocketConfig.MaxSubscriptionsPerClient <= 0 ||
+		opts.websocketConfig.SubscriptionBufferSize <= 0 || opts.websocketConfig.PingPeriod <= 0 {
+		return nil, cli.Exit("ws-max-subscription-clients, ws-max-subscriptions-per-client, ws-buffer-size and ws-ping-period must be greater than 0", 1)
+	}
+
+	return opts, nil
+}
+
+// setupValidators reads the keys of the validators, from the node homes given as argument, from validator-keys
+// or, with their app addresses, from the validator manifest, or generates them, and replaces them by
+// remote signers and substitutes the validators of the genesis with them where asked to.
+// The validators of the genesis are changed accordingly.
+func setupValidators(c *cli.Context, opts *options, genesisDoc *types.GenesisDoc, logger cometlog.Logger) (*validatorSetup, error) {
+	appAddresses := opts.appAddresses
+	var validatorKeys []validatorkeys.Key
+	var manifestObservers []string
+	appLatencies := make(map[string]time.Duration)
+	var err error
+	if manifestFile := c.String("validator-manifest"); manifestFile != "" {
+		if c.Args().Get(0) != "" || opts.nodeHomes != "" || c.String("validator-keys") != "" || c.Int("generate-validators") > 0 {
+			return nil, cli.Exit("validator-manifest cannot be combined with app addresses, node homes, validator-keys or generate-validators.", 1)
+		}
+		apps, err := manifest.Read(manifestFile, os.Getenv, privValidatorKeyFile)
+		if err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+		appAddresses = nil
+		for _, app := range apps {
+			if abci_client.HasDNSAppAddresses([]string{app.AppAddress}) {
+				return nil, cli.Exit(fmt.Sprintf("The app address %v of the manifest stands for several apps, but each entry of the manifest is a single app.", app.AppAddress), 1)
+			}
+			if app.Latency > 0 {
+				appLatencies[app.AppAddress] = app.Latency
+			}
+			if app.Observer {
+				manifestObservers = append(manifestObservers, app.AppAddress)
+				continue
+			}
+			appAddresses = append(appAddresses, app.AppAddress)
+			validatorKeys = append(validatorKeys, app.Key)
+		}
+	} else if validatorKeySources := c.String("validator-keys"); validatorKeySources != "" {
+		if opts.nodeHomes != "" || c.Int("generate-validators") > 0 {
+			return nil, cli.Exit("validator-keys cannot be combined with node homes or generate-validators. Use home:<node home> entries of validator-keys for keys in node homes.", 1)
+		}
+		validatorKeys, err = validatorkeys.Parse(validatorKeySources, os.Getenv, privValidatorKeyFile)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error reading the keys of the validators: %v", err), 1)
+		}
+	} else if c.Int("generate-validators") == 0 {
+		validatorKeys, err = validatorkeys.Parse(validatorkeys.HomeSources(strings.Split(opts.nodeHomes, ",")), os.Getenv, privValidatorKeyFile)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error reading the keys of the validators: %v", err), 1)
+		}
+	}
+
+	// there is one app per key, unless the validators are generated
+	expectedApps := len(validatorKeys)
+	appAddresses, err = ExpandAppAddresses(appAddresses, expectedApps, opts.appConnectTimeout, logger)
+	if err != nil {
+		return nil, cli.Exit(fmt.Sprintf("Error discovering apps: %v", err), 1)
+	}
+
+	var privVals []types.PrivValidator
+	var placeholderVals []types.PrivValidator
+	if numValidators := c.Int("generate-validators"); numValidators > 0 {
+		if c.String("priv-validator-laddrs") != "" || c.String("substitute-validators") != "" {
+			return nil, cli.Exit("generate-validators cannot be combined with priv-validator-laddrs or substitute-validators", 1)
+		}
+		if len(appAddresses) > numValidators {
+			return nil, cli.Exit(fmt.Sprintf("Got %d app addresses, but only %d validators are generated.", len(appAddresses), numValidators), 1)
+		}
+
+		powers, err := genvalidators.ParsePowers(c.String("power-distribution"), numValidators)
+		if err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+		generatedVals, genesisValidators, err := genvalidators.Generate(numValidators, powers)
+		if err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+		genesisDoc.Validators = genesisValidators
+		privVals = generatedVals[:len(appAddresses)]
+		placeholderVals = generatedVals[len(appAddresses):]
+		fmt.Printf("Generated validators: %d, of which placeholders: %d\n", numValidators, len(placeholderVals))
+	} else {
+		if len(validatorKeys) < len(appAddresses) {
+			return nil, cli.Exit(fmt.Sprintf("Got %d app addresses, but only %d validator keys. There must be one key per app.", len(appAddresses), len(validatorKeys)), 1)
+		}
+		// we use MockPVs because they do not do sanity checks that would e.g. prevent double signing,
+		// and persist the signing state of the keys from node homes if asked to
+		privVals = make([]types.PrivValidator, len(validatorKeys))
+		for i, key := range validatorKeys {
+			privVals[i] = types.NewMockPVWithParams(key.PrivKey, false, false)
+			if key.Home == "" || !c.Bool("enforce-signing-state") {
+				continue
+			}
+			signStateFile, err := signStateFile(key.Home)
+			if err != nil {
+				return nil, cli.Exit(err.Error(), 1)
+			}
+			privVals[i], err = abci_client.NewSignStatePV(privVals[i], signStateFile)
+			if err != nil {
+				logger.Error(err.Error())
+				panic(err)
+			}
+		}
+	}
+
+	// replace the priv validators by remote signers where specified
+	if privValidatorLaddrs := c.String("priv-validator-laddrs"); privValidatorLaddrs != "" {
+		laddrs := strings.Split(privValidatorLaddrs, ",")
+		if len(laddrs) != len(privVals) {
+			return nil, cli.Exit(fmt.Sprintf("Got %d priv validator listen addresses, but %d validator keys. There must be one address per node home or validator key (entries may be empty).", len(laddrs), len(privVals)), 1)
+		}
+
+		timeout := time.Duration(c.Int64("priv-validator-timeout")) * time.Millisecond
+		for i, laddr := range laddrs {
+			if laddr == "" {
+				continue
+			}
+			privVals[i], err = GetRemotePrivValidator(laddr, genesisDoc.ChainID, timeout, logger)
+			if err != nil {
+				logger.Error(err.Error())
+				panic(err)
+			}
+		}
+	}
+
+	// substitute the keys of the given validators by our keys
+	var substitutedAddresses []string
+	if substituteValidators := c.String("substitute-validators"); substituteValidators != "" {
+		if substituteValidators == bootstrap.SubstituteTopValidators {
+			substitutedAddresses, err = bootstrap.SelectTopValidators(genesisDoc, len(privVals))
+			if err != nil {
+				return nil, cli.Exit(err.Error(), 1)
+			}
+		} else {
+			substitutedAddresses = strings.Split(substituteValidators, ",")
+			if len(substitutedAddresses) != len(privVals) {
+				return nil, cli.Exit(fmt.Sprintf("Got %d validators to substitute, but %d node homes. There must be one validator per node home.", len(substitutedAddresses), len(privVals)), 1)
+			}
+		}
+
+		pubKeys := make([]crypto.PubKey, len(privVals))
+		for i, privVal := range privVals {
+			pubKeys[i], err = privVal.GetPubKey()
+			if err != nil {
+				logger.Error(err.Error())
+				panic(err)
+			}
+		}
+
+		err = bootstrap.SubstituteValidators(genesisDoc, substitutedAddresses, pubKeys)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error substituting validators: %v", err), 1)
+		}
+
+		// from here on, the substituted validators are known by our addresses
+		for i, pubKey := range pubKeys {
+			logger.Info("Substituted validator", "validator", substitutedAddresses[i], "address", pubKey.Address().String())
+			substitutedAddresses[i] = pubKey.Address().String()
+		}
+	}
+
+	return &validatorSetup{
+		appAddresses:         appAddresses,
+		privVals:             privVals,
+		placeholderVals:      placeholderVals,
+		substitutedAddresses: substitutedAddresses,
+		manifestObservers:    manifestObservers,
+		appLatencies:         appLatencies,
+	}, nil
+}
+
+// connectAppsAndObservers connects to the apps of the validators and to the observers.
+func connectAppsAndObservers(c *cli.Context, opts *options, validators *validatorSetup, logger cometlog.Logger) (*appConnections, error) {
+	var connectClient abci_client.ClientConnector = NewConnectClient(opts.connectionMode, logger)
+	if opts.abciConnections == abci_client.ConnectionModePerPurpose {
+		connectClient = abci_client.ConnectPerPurpose(connectClient)
+	}
+	if len(validators.appLatencies) > 0 {
+		connectClient = abci_client.ConnectWithLatencies(connectClient, validators.appLatencies)
+	}
+	apps := &appConnections{connectClient: connectClient}
+
+	// at startup, the apps may still be starting up, e.g. when they are started at the same time as CometMock
+	startupConnectClient := RetryConnectClient(connectClient, opts.appConnectTimeout, logger)
+	var err error
+	if opts.degradedStartup {
+		apps.clients, apps.order, apps.missingApps = ConnectAvailableApps(validators.appAddresses, validators.privVals, startupConnectClient, logger)
+		if len(apps.clients) == 0 {
+			return nil, cli.Exit("Error connecting to apps: none of the apps is listening", 1)
+		}
+	} else {
+		apps.clients, apps.order, err = ConnectApps(validators.appAddresses, validators.privVals, startupConnectClient, logger)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error connecting to apps: %v", err), 1)
+		}
+	}
+
+	var observerAddresses []string
+	if addresses := c.String("observer-addresses"); addresses != "" {
+		observerAddresses, err = ExpandAppAddresses(strings.Split(addresses, ","), 0, opts.appConnectTimeout, logger)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error discovering observers: %v", err), 1)
+		}
+	}
+	// the observers of the manifest come after those given with observer-addresses
+	observerAddresses = append(observerAddresses, validators.manifestObservers...)
+	for i, observerAddress := range observerAddresses {
+		client, err := startupConnectClient(observerAddress)
+		if err != nil {
+			return nil, cli.Exit(fmt.Sprintf("Error connecting to observers: %v", err), 1)
+		}
+
+		observer := abci_client.NewObserverClient(client, observerAddress, i)
+		apps.clients[observer.ValidatorAddress] = *observer
+		apps.order = append(apps.order, observer.ValidatorAddress)
+	}
+	return apps, nil
+}
+
+// newAbciClient creates the client that produces the blocks for the connected apps,
+// and configures it according to the flags.
+func newAbciClient(
+	c *cli.Context,
+	opts *options,
+	validators *validatorSetup,
+	apps *appConnections,
+	curState state.State,
+	genesisDoc *types.GenesisDoc,
+	logger cometlog.Logger,
+) (*abci_client.AbciClient, error) {
+	// read starting timestamp from args
+	// if starting timestamp should be taken from genesis,
+	// read it from there
+	var startingTime time.Time
+	if c.Bool("starting-timestamp-from-genesis") {
+		startingTime = genesisDoc.GenesisTime
+	} else {
+		if c.Int64("starting-timestamp") < 0 {
+			startingTime = time.Now()
+		} else {
+			dur := time.Duration(c.Int64("starting-timestamp")) * time.Millisecond
+			startingTime = time.Unix(0, 0).Add(dur)
+		}
+	}
+	fmt.Printf("Starting time: %s\n", startingTime.Format(time.RFC3339))
+
+	// read block time from args
+	blockTime := time.Duration(c.Int64("block-time")) * time.Millisecond
+	fmt.Printf("Block time: %d\n", blockTime.Milliseconds())
+
+	// the time handler is created again when the chain is reset
+	newTimeHandler := func() abci_client.TimeHandler {
+		if blockTime < 0 {
+			return abci_client.NewSystemClockTimeHandler(startingTime)
+		}
+		fixedTimeHandler := abci_client.NewFixedBlockTimeHandler(blockTime)
+		if opts.jitter != nil {
+			fixedTimeHandler.SetJitter(opts.jitter)
+		}
+		return fixedTimeHandler
+	}
+
+	// the failpoints are shared by the client and its storage, see enable_failpoint
+	failpointRegistry := failpoints.NewRegistry()
+	abciClient := abci_client.NewAbciClient(
+		apps.clients,
+		logger,
+		curState,
+		&types.Block{},
+		&types.ExtendedCommit{},
+		&storage.MapStorage{
+			Failpoints:  failpointRegistry,
+			MaxHeights:  opts.storageMaxHeights,
+			OverflowDir: opts.storageOverflowDir,
+		},
+		newTimeHandler(),
+		opts.determinismChecks,
+	)
+	abciClient.Failpoints = failpointRegistry
+
+	abciClient.ClientOrder = apps.order
+	for _, privVal := range validators.placeholderVals {
+		if err := abciClient.AddPlaceholderValidator(privVal); err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+	}
+	for _, i := range apps.missingApps {
+		if err := abciClient.AddMissingApp(i, validators.appAddresses[i], validators.privVals[i]); err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+	}
+	if executorAddresses := c.String("executor-addresses"); executorAddresses != "" {
+		for _, pair := range strings.Split(executorAddresses, ",") {
+			target, executorAddress, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, cli.Exit(fmt.Sprintf("invalid executor %q, must be of the form target=app_address", pair), 1)
+			}
+			client, err := apps.connectClient(executorAddress)
+			if err != nil {
+				logger.Error(err.Error())
+			}
+			if _, err := abciClient.AddExecutor(target, client, executorAddress); err != nil {
+				return nil, cli.Exit(err.Error(), 1)
+			}
+		}
+	}
+	firstBlockTime := startingTime
+	if blockTime >= 0 {
+		firstBlockTime = startingTime.Add(blockTime)
+	}
+	abciClient.ChainStart = &abci_client.ChainStart{
+		FirstBlockTime: firstBlockTime,
+		NewTimeHandler: newTimeHandler,
+		Misbehaviours:  opts.genesisMisbehaviours,
+	}
+	if len(validators.substitutedAddresses) > 0 {
+		// give the substituted validators more than 2/3 of the voting power
+		abciClient.ChainStart.AdjustValidators = func(validatorSet *types.ValidatorSet) (*types.ValidatorSet, error) {
+			return bootstrap.ScaleVotingPower(validatorSet, validators.substitutedAddresses)
+		}
+	}
+	abciClient.ConnectClient = apps.connectClient
+	abciClient.AppAddressesFile = c.String("app-addresses-file")
+	abciClient.AutoIncludeTx = c.Bool("auto-tx")
+	abciClient.SkipCheckTx = c.Bool("skip-check-tx")
+	abciClient.CommitsInFinalizeBlock = opts.connectionMode == "legacy-socket"
+	// names from the config take precedence over names from the genesis,
+	// also for validators that have another name in the genesis
+	configuredAddresses := make(map[string]bool, len(opts.validatorNames))
+	for _, address := range opts.validatorNames {
+		configuredAddresses[address] = true
+	}
+	validatorNames := make(map[string]string)
+	for name, address := range abci_client.GenesisValidatorNames(genesisDoc) {
+		if !configuredAddresses[address] {
+			validatorNames[name] = address
+		}
+	}
+	for name, address := range opts.validatorNames {
+		validatorNames[name] = address
+	}
+	abciClient.SetValidatorNames(validatorNames)
+	if fixedProposer := c.String("fixed-proposer"); fixedProposer != "" {
+		abciClient.FixedProposerAddress = abciClient.ResolveValidator(fixedProposer)
+	}
+	abciClient.BFTTime = c.Bool("bft-time")
+	abciClient.ClockSkews = opts.clockSkews
+	if err := abciClient.SetCommitRound(opts.commitRound); err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	abciClient.QueryMode = opts.queryMode
+	abciClient.InvariantQueries = opts.invariantQueries
+	if queryCacheSize := c.Int64("query-cache-size"); queryCacheSize > 0 {
+		abciClient.QueryCache = abci_client.NewQueryCache(int(queryCacheSize))
+	}
+	if err := abciClient.SetSnapshotPolicy(c.Int64("snapshot-interval"), c.Int("snapshot-retention")); err != nil {
+		return nil, cli.Exit(err.Error(), 1)
+	}
+	abciClient.BlockProductionInterval = time.Duration(opts.blockProductionInterval) * time.Millisecond
+	if blockTime < 0 {
+		// with system clock block times, the jitter of the interval shows in the block times
+		abciClient.BlockProductionJitter = opts.jitter
+	}
+	fmt.Printf("Auto include tx: %t\n", abciClient.AutoIncludeTx)
+
+	if doubleExecution := c.String("double-execution"); doubleExecution != "" {
+		err := abciClient.SetDoubleExecution(strings.Split(doubleExecution, ","))
+		if err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+	}
+
+	abciClient.SetMisbehaviourRules(opts.misbehaviourRules, opts.misbehaviourSeed)
+	return abciClient, nil
+}
+
+// newEnvironment creates the environment of the RPC servers, and configures it according to the flags.
+func newEnvironment(c *cli.Context, opts *options, abciClient *abci_client.AbciClient, logLevels *logging.Levels) (*rpc_server.Environment, error) {
+	env := rpc_server.NewEnvironment(abciClient)
+	env.TimeoutBroadcastTxCommit = time.Duration(opts.broadcastTxCommitTimeout) * time.Millisecond
+	env.Websocket = opts.websocketConfig
+	env.LogLevels = logLevels
+	blockWebhookToken := c.String("block-webhook-token")
+	if name, ok := strings.CutPrefix(blockWebhookToken, validatorkeys.SourceEnv); ok {
+		blockWebhookToken = os.Getenv(name)
+		if blockWebhookToken == "" {
+			return nil, cli.Exit(fmt.Sprintf("The environment variable %v with the token of the block webhook is not set", name), 1)
+		}
+	}
+	env.BlockWebhookToken = blockWebhookToken
+	if dataDirExportRoot := c.String("data-dir-export-root"); dataDirExportRoot != "" {
+		if err := env.EnableDataDirExport(dataDirExportRoot); err != nil {
+			return nil, cli.Exit(err.Error(), 1)
+		}
+	}
+	if rpcPlugins := c.String("rpc-plugins"); rpcPlugins != "" {
+		for _, path := range strings.Split(rpcPlugins, ",") {
+			if err := env.LoadRoutePlugin(path); err != nil {
+				return nil, cli.Exit(err.Error(), 1)
+			}
+		}
+	}
+	return env, nil
+}
+
+// startChain initializes the apps with InitChain, or continues from the imported data directory,
+// and checks that the validators with apps and keys can produce blocks.
+func startChain(
+	c *cli.Context,
+	opts *options,
+	validators *validatorSetup,
+	apps *appConnections,
+	abciClient *abci_client.AbciClient,
+	curState state.State,
+	genesisDoc *types.GenesisDoc,
+	logger cometlog.Logger,
+) error {
+	if opts.importDataDir != "" {
+		// the apps continue from the latest height of the node instead of being initialized
+		base, err := abciClient.ImportDataDir(opts.importDataDir, genesisDoc)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Error importing the data directory: %v", err), 1)
+		}
+		fmt.Printf("Imported the blocks from height %d to %d\n", base, abciClient.CurState.LastBlockHeight)
+	} else {
+		// initialize chain
+		err := abciClient.SendInitChain(curState, genesisDoc)
+		if err != nil {
+			logger.Error(err.Error())
+			panic(err)
+		}
+	}
+
+	// the validator set is only known after InitChain, since apps can change it
+	if len(apps.missingApps) > 0 {
+		connectedPower, totalPower := abciClient.ConnectedVotingPower()
+		if 3*connectedPower <= 2*totalPower {
+			return cli.Exit(fmt.Sprintf("Error connecting to apps: the validators with apps have %d of %d voting power, which is not more than 2/3", connectedPower, totalPower), 1)
+		}
+		logger.Info("Starting without some apps", "missing", len(apps.missingApps), "connected_power", connectedPower, "total_power", totalPower)
+		abciClient.JoinMissingApps(time.Second)
+	}
+
+	if err := abciClient.AdjustValidatorsAfterInitChain(); err != nil {
+		logger.Error(err.Error())
+		panic(err)
+	}
+
+	// the validators of a live chain that are not substituted have no keys on purpose
+	keyCheck := abciClient.CheckValidatorKeys()
+	if err := abciClient.MissingKeysError(keyCheck); err != nil {
+		if !c.Bool("allow-missing-keys") && len(validators.substitutedAddresses) == 0 {
+			return cli.Exit(fmt.Sprintf("Error matching the keys with the validators: %v. Run with --allow-missing-keys to start anyway, with the validators without keys never signing.", err), 1)
+		}
+		// blocks can only be committed while validators with more than 2/3 of the voting power sign
+		if 3*keyCheck.PowerWithKeys <= 2*keyCheck.TotalPower {
+			return cli.Exit(fmt.Sprintf("Error matching the keys with the validators: %v. The validators with keys have %d of %d voting power, which is not more than 2/3", err, keyCheck.PowerWithKeys, keyCheck.TotalPower), 1)
+		}
+		logger.Info("Starting without the keys of some validators, which never sign", "missing", len(keyCheck.MissingKeys),
+			"power_with_keys", keyCheck.PowerWithKeys, "total_power", keyCheck.TotalPower)
+	}
+	return nil
+}
+
+// runReplayArchive replays the blocks of the given archive while serving the RPC,
+// and keeps serving queries afterwards to inspect the state of the apps.
+func runReplayArchive(path string, listenAddress string, env *rpc_server.Environment, abciClient *abci_client.AbciClient, logger cometlog.Logger) error {
+	archive, err := replay.OpenArchive(path)
+	if err != nil {
+		logger.Error(err.Error())
+		panic(err)
+	}
+	defer archive.Close()
+
+	env.Replayer = replay.NewReplayer(abciClient, archive)
+
+	go rpc_server.StartRPCServerWithDefaultConfig(env, listenAddress, logger)
+
+	err = env.Replayer.Run()
+	var mismatch *replay.Mismatch
+	if errors.As(err, &mismatch) {
+		logger.Error("Replay diverged from the archive", "height", mismatch.Height, "field", mismatch.Field, "expected", mismatch.Expected, "actual", mismatch.Actual)
+	} else if err != nil {
+		logger.Error("Error replaying archive", "err", err)
+	} else {
+		logger.Info("Replayed archive", "height", abciClient.CurState.LastBlockHeight)
+	}
+
+	// keep serving queries to inspect the state of the apps
+	time.Sleep(time.Hour * 24 * 365 * 100) // 100 years
+	return nil
+}
+
+// startServers starts the RPC servers, the signal handlers and the background tasks that are enabled by the flags.
+func startServers(c *cli.Context, opts *options, env *rpc_server.Environment, abciClient *abci_client.AbciClient, logger cometlog.Logger) {
+	go rpc_server.StartRPCServerWithDefaultConfig(env, opts.listenAddress, logger)
+
+	if opts.livenessProbeInterval > 0 {
+		abciClient.StartLivenessProbes(time.Duration(opts.livenessProbeInterval) * time.Millisecond)
+	}
+
+	if abciClient.AppAddressesFile != "" {
+		go reloadAppsOnSIGHUP(abciClient, logger)
+	}
+
+	go runBlockOnSIGUSR1(abciClient, logger)
+
+	if compatListenAddress := c.String("compat-listen-address"); compatListenAddress != "" {
+		go rpc_server.StartCompatRPCServer(env, compatListenAddress, logger, rpc_server.CompatV034)
+	}
+
+	if grpcListenAddress := c.String("grpc-listen-address"); grpcListenAddress != "" {
+		go grpc_server.StartGRPCServer(abciClient, grpcListenAddress, logger)
+	}
+
+	if readOnlyListenAddress := c.String("readonly-listen-address"); readOnlyListenAddress != "" {
+		go rpc_server.StartReadOnlyRPCServer(env, readOnlyListenAddress, logger)
+	}
+
+	if prometheusListenAddress := c.String("prometheus-listen-address"); prometheusListenAddress != "" {
+		go metrics.StartMetricsServer(abciClient, prometheusListenAddress, logger)
+	}
+}
+
+// produceBlocks produces a block after each interval, forever.
+func produceBlocks(abciClient *abci_client.AbciClient, interval time.Duration, logger cometlog.Logger) {
+	for {
+		err := abciClient.RunBlock()
+		if errors.Is(err, abci_client.ErrHalted) {
+			// wait until block production is resumed
+			logger.Debug(err.Error())
+		} else if errors.Is(err, abci_client.ErrBlockVetoed) {
+			// try again with the next block
+			logger.Info(err.Error())
+		} else if errors.Is(err, failpoints.ErrInjected) {
+			// faults injected by failpoints before the apps finalized the block are expected, so try again
+			// with the next block. Faults injected after that halt block production instead
+			logger.Error(err.Error())
+		} else if err != nil {
+			logger.Error(err.Error())
+			panic(err)
+		}
+		sleep := interval
+		if jitter := abciClient.BlockProductionJitter; jitter != nil {
+			sleep = jitter.Apply(interval, 0)
+		}
+		time.Sleep(sleep)
+	}
+}
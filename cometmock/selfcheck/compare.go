@@ -0,0 +1,201 @@
+package selfcheck
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// the responses of CometBFT to the requests of Exercise, by route, recorded with the record command
+//
+//go:embed golden/*.json
+var goldenFiles embed.FS
+
+// the directory of the golden responses, relative to this package
+const goldenDir = "golden"
+
+// Status is the outcome of the check of a route.
+type Status string
+
+const (
+	// the response has the same shape as the response of CometBFT
+	StatusOK Status = "ok"
+	// the response has another shape than the response of CometBFT
+	StatusDrift Status = "drift"
+	// the route failed, but CometBFT responded, or the other way around
+	StatusError Status = "error"
+	// CometMock does not serve the route
+	StatusNotImplemented Status = "not implemented"
+	// the route is not exercised, see SkippedRoutes
+	StatusSkipped Status = "skipped"
+)
+
+// Difference is a difference between the shape of a response of CometMock and that of CometBFT,
+// at the given path into the response, e.g. result.block.header.height.
+// The kinds are the JSON types, e.g. string or object, or missing if there is no such field.
+type Difference struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%v: expected %v, got %v", d.Path, d.Expected, d.Actual)
+}
+
+// Result is the outcome of the check of a route.
+type Result struct {
+	Route       string
+	Status      Status
+	Differences []Difference
+	// why the route failed, or why it was skipped
+	Reason string
+}
+
+// GoldenResponses returns the recorded responses of CometBFT by route.
+func GoldenResponses() (map[string]Response, error) {
+	entries, err := goldenFiles.ReadDir(goldenDir)
+	if err != nil {
+		return nil, err
+	}
+
+	golden := make(map[string]Response, len(entries))
+	for _, entry := range entries {
+		content, err := goldenFiles.ReadFile(path.Join(goldenDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var response Response
+		if err := json.Unmarshal(content, &response); err != nil {
+			return nil, fmt.Errorf("error reading golden response %v: %w", entry.Name(), err)
+		}
+		golden[strings.TrimSuffix(entry.Name(), ".json")] = response
+	}
+	return golden, nil
+}
+
+// Check compares the responses of CometMock, see Exercise, with the golden responses of CometBFT,
+// where implemented holds the routes that CometMock serves.
+// It returns the results for all routes of CometBFT, sorted by route.
+func Check(golden map[string]Response, responses map[string]Response, implemented map[string]bool) []Result {
+	results := make([]Result, 0, len(golden)+len(SkippedRoutes))
+	for route, reason := range SkippedRoutes {
+		results = append(results, Result{Route: route, Status: StatusSkipped, Reason: reason})
+	}
+
+	for route, expected := range golden {
+		result := Result{Route: route}
+		actual, exercised := responses[route]
+		switch {
+		case !implemented[route]:
+			result.Status = StatusNotImplemented
+		case !exercised:
+			result.Status = StatusError
+			result.Reason = "the route was not exercised"
+		case actual.Error != "" && expected.Error == "":
+			result.Status = StatusError
+			result.Reason = actual.Error
+		case actual.Error == "" && expected.Error != "":
+			result.Status = StatusError
+			result.Reason = fmt.Sprintf("CometBFT fails with %q, but CometMock responds", expected.Error)
+		case actual.Error != "":
+			// both fail, which is faithful enough
+			result.Status = StatusOK
+		default:
+			result.Differences = CompareShapes(expected.Result, actual.Result)
+			result.Status = StatusOK
+			if len(result.Differences) > 0 {
+				result.Status = StatusDrift
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Route < results[j].Route
+	})
+	return results
+}
+
+// CompareShapes returns the differences between the shapes of two JSON values:
+// the fields that are missing on either side, and the fields whose JSON types differ, e.g. numbers
+// that CometBFT encodes as strings. The values themselves are not compared.
+// Arrays are compared by their first elements, and null matches any type,
+// since the type of an empty field cannot be told.
+func CompareShapes(expected, actual json.RawMessage) []Difference {
+	var expectedValue, actualValue interface{}
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		return []Difference{{Path: "result", Expected: "valid JSON", Actual: err.Error()}}
+	}
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return []Difference{{Path: "result", Expected: "valid JSON", Actual: err.Error()}}
+	}
+	return compareValues("result", expectedValue, actualValue)
+}
+
+func compareValues(path string, expected, actual interface{}) []Difference {
+	expectedKind, actualKind := kind(expected), kind(actual)
+	if expectedKind == "null" || actualKind == "null" {
+		return nil
+	}
+	if expectedKind != actualKind {
+		return []Difference{{Path: path, Expected: expectedKind, Actual: actualKind}}
+	}
+
+	var differences []Difference
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual := actual.(map[string]interface{})
+		keys := make([]string, 0, len(expected)+len(actual))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		for key := range actual {
+			if _, ok := expected[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			expectedField, inExpected := expected[key]
+			actualField, inActual := actual[key]
+			switch {
+			case !inActual:
+				differences = append(differences, Difference{Path: path + "." + key, Expected: kind(expectedField), Actual: "missing"})
+			case !inExpected:
+				differences = append(differences, Difference{Path: path + "." + key, Expected: "missing", Actual: kind(actualField)})
+			default:
+				differences = append(differences, compareValues(path+"."+key, expectedField, actualField)...)
+			}
+		}
+	case []interface{}:
+		actual := actual.([]interface{})
+		if len(expected) > 0 && len(actual) > 0 {
+			differences = compareValues(path+"[0]", expected[0], actual[0])
+		}
+	}
+	return differences
+}
+
+// kind returns the JSON type of a value decoded by encoding/json.
+func kind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return fmt.Sprintf("%T", value)
+}
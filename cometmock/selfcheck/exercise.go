@@ -0,0 +1,180 @@
+// Package selfcheck compares the responses of the RPC of CometMock with the responses of CometBFT,
+// to find the routes of CometBFT that CometMock does not serve faithfully, see the selfcheck command.
+// The responses of CometBFT were recorded with the record command in this package.
+package selfcheck
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Response is the response of the RPC to a request: the result, or the error if the request failed.
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// SkippedRoutes are the routes of the RPC of CometBFT that are not exercised, with the reason why.
+var SkippedRoutes = map[string]string{
+	"subscribe":            "only served over websockets",
+	"unsubscribe":          "only served over websockets",
+	"unsubscribe_all":      "only served over websockets",
+	"broadcast_evidence":   "needs evidence of misbehaviour",
+	"dial_seeds":           "unsafe route",
+	"dial_peers":           "unsafe route",
+	"unsafe_flush_mempool": "unsafe route",
+}
+
+// the transactions are written to the kvstore example app, which stores key=value transactions
+var (
+	commitTx = []byte("selfcheck-commit=1")
+	syncTx   = []byte("selfcheck-sync=1")
+	asyncTx  = []byte("selfcheck-async=1")
+	checkTx  = []byte("selfcheck-check=1")
+)
+
+// Exercise sends a request to each route of the RPC of CometBFT that is not skipped to the RPC at the given address,
+// e.g. tcp://127.0.0.1:26657, and returns the responses by route.
+// The RPC needs to serve a chain of the kvstore example app of CometBFT that produces blocks when transactions
+// are broadcast. A transaction is committed first, so that the requests for blocks and transactions
+// ask for the block and the transaction of that commit.
+func Exercise(rpcAddress string) (map[string]Response, error) {
+	url := "http://" + strings.TrimPrefix(rpcAddress, "tcp://")
+	client := &http.Client{Timeout: 30 * time.Second}
+	responses := make(map[string]Response)
+
+	call := func(route string, params map[string]interface{}) (Response, error) {
+		response, err := callRoute(client, url, route, params)
+		if err != nil {
+			return Response{}, fmt.Errorf("error calling %v: %w", route, err)
+		}
+		responses[route] = response
+		return response, nil
+	}
+
+	committed, err := call("broadcast_tx_commit", map[string]interface{}{"tx": commitTx})
+	if err != nil {
+		return nil, err
+	}
+	var commitResult struct {
+		Hash   string `json:"hash"`
+		Height string `json:"height"`
+	}
+	if committed.Error != "" {
+		return nil, fmt.Errorf("error committing a transaction: %v", committed.Error)
+	}
+	if err := json.Unmarshal(committed.Result, &commitResult); err != nil {
+		return nil, fmt.Errorf("error reading the result of broadcast_tx_commit: %w", err)
+	}
+	txHash, err := hex.DecodeString(commitResult.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the hash of the committed transaction: %w", err)
+	}
+	height := commitResult.Height
+
+	block, err := call("block", map[string]interface{}{"height": height})
+	if err != nil {
+		return nil, err
+	}
+	var blockResult struct {
+		BlockID struct {
+			Hash string `json:"hash"`
+		} `json:"block_id"`
+	}
+	if block.Error != "" {
+		return nil, fmt.Errorf("error getting the block of the committed transaction: %v", block.Error)
+	}
+	if err := json.Unmarshal(block.Result, &blockResult); err != nil {
+		return nil, fmt.Errorf("error reading the result of block: %w", err)
+	}
+	blockHash, err := hex.DecodeString(blockResult.BlockID.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the hash of the block: %w", err)
+	}
+
+	for _, request := range []struct {
+		route  string
+		params map[string]interface{}
+	}{
+		{"health", nil},
+		{"status", nil},
+		{"net_info", nil},
+		{"blockchain", map[string]interface{}{"minHeight": "1", "maxHeight": height}},
+		{"genesis", nil},
+		{"genesis_chunked", map[string]interface{}{"chunk": "0"}},
+		{"block_by_hash", map[string]interface{}{"hash": blockHash}},
+		{"block_results", map[string]interface{}{"height": height}},
+		{"commit", map[string]interface{}{"height": height}},
+		{"header", map[string]interface{}{"height": height}},
+		// unlike block_by_hash, header_by_hash takes the hash as hex
+		{"header_by_hash", map[string]interface{}{"hash": blockResult.BlockID.Hash}},
+		{"check_tx", map[string]interface{}{"tx": checkTx}},
+		{"tx", map[string]interface{}{"hash": txHash}},
+		{"tx_search", map[string]interface{}{"query": "tx.height>0", "per_page": "1"}},
+		{"block_search", map[string]interface{}{"query": "block.height>0", "per_page": "1"}},
+		{"validators", map[string]interface{}{"height": height}},
+		{"dump_consensus_state", nil},
+		{"consensus_state", nil},
+		{"consensus_params", map[string]interface{}{"height": height}},
+		{"broadcast_tx_sync", map[string]interface{}{"tx": syncTx}},
+		{"broadcast_tx_async", map[string]interface{}{"tx": asyncTx}},
+		{"unconfirmed_txs", nil},
+		{"num_unconfirmed_txs", nil},
+		// the kvstore app returns the value of the key given as data
+		{"abci_query", map[string]interface{}{"path": "", "data": hex.EncodeToString([]byte("selfcheck-commit"))}},
+		{"abci_info", nil},
+	} {
+		if _, err := call(request.route, request.params); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// callRoute sends a JSON-RPC request for the route to the RPC at the given url.
+// Byte slices in the params are encoded in base64, like by the JSON encoding of CometBFT.
+func callRoute(client *http.Client, url string, route string, params map[string]interface{}) (Response, error) {
+	encodedParams := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if bz, ok := value.([]byte); ok {
+			value = base64.StdEncoding.EncodeToString(bz)
+		}
+		encodedParams[name] = value
+	}
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  route,
+		"params":  encodedParams,
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpResponse, err := client.Post(url, "application/json", bytes.NewReader(request))
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResponse.Body.Close()
+
+	var rpcResponse struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&rpcResponse); err != nil {
+		return Response{}, fmt.Errorf("error reading the response: %w", err)
+	}
+	if rpcResponse.Error != nil {
+		return Response{Error: strings.TrimSpace(rpcResponse.Error.Message + " " + rpcResponse.Error.Data)}, nil
+	}
+	return Response{Result: rpcResponse.Result}, nil
+}
@@ -0,0 +1,49 @@
+// Command record records the golden responses of CometBFT for the selfcheck command of CometMock.
+// It runs a CometBFT node with the kvstore example app, exercises its RPC like selfcheck exercises
+// the RPC of CometMock, and writes the responses to the golden directory of the selfcheck package.
+// Run it from the root of the repository after upgrading CometBFT:
+//
+//	go run ./cometmock/selfcheck/record
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	rpctest "github.com/cometbft/cometbft/rpc/test"
+	"github.com/informalsystems/CometMock/cometmock/selfcheck"
+)
+
+const goldenDir = "cometmock/selfcheck/golden"
+
+func main() {
+	node := rpctest.StartTendermint(kvstore.NewInMemoryApplication(), rpctest.SuppressStdout)
+	defer rpctest.StopTendermint(node)
+
+	responses, err := selfcheck.Exercise(rpctest.GetConfig().RPC.ListenAddress)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exercising the RPC of CometBFT: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating %v: %v\n", goldenDir, err)
+		os.Exit(1)
+	}
+	for route, response := range responses {
+		content, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding the response of %v: %v\n", route, err)
+			os.Exit(1)
+		}
+		file := filepath.Join(goldenDir, route+".json")
+		if err := os.WriteFile(file, append(content, '\n'), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %v: %v\n", file, err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Recorded %d responses in %v\n", len(responses), goldenDir)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	comet_abciclient "github.com/cometbft/cometbft/abci/client"
+	"github.com/cometbft/cometbft/abci/example/kvstore"
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+	"github.com/informalsystems/CometMock/cometmock/genvalidators"
+	"github.com/informalsystems/CometMock/cometmock/rpc_server"
+	"github.com/informalsystems/CometMock/cometmock/selfcheck"
+	"github.com/informalsystems/CometMock/cometmock/storage"
+	"github.com/urfave/cli/v2"
+)
+
+// selfcheckCommand checks which routes of the RPC of CometBFT this build of CometMock serves faithfully.
+func selfcheckCommand(logger cometlog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "selfcheck",
+		Usage: "Check which routes of the RPC of CometBFT this build of CometMock serves like CometBFT",
+		Description: `Starts a scratch chain with the kvstore example app of CometBFT in the same process,
+sends a request to each route of the RPC of CometBFT, and compares the shape of each response,
+i.e. the fields and their JSON types, with the response of CometBFT, which was recorded on a chain of the same app.
+Prints the result for each route: ok, drift with the fields that differ, error, not implemented, or skipped
+for routes that cannot be exercised this way, e.g. routes that are only served over websockets.
+Exits with code 1 if any route that CometMock implements drifted or failed.`,
+		Action: func(c *cli.Context) error {
+			// the scratch chain only logs errors, so that they do not drown the results
+			logger := cometlog.NewFilter(logger, cometlog.AllowError())
+
+			rpcAddress, implemented, err := startScratchChain(logger)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error starting the scratch chain: %v", err), 1)
+			}
+
+			responses, err := selfcheck.Exercise(rpcAddress)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error exercising the RPC: %v", err), 1)
+			}
+			golden, err := selfcheck.GoldenResponses()
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Error reading the golden responses: %v", err), 1)
+			}
+
+			failed := 0
+			counts := make(map[selfcheck.Status]int)
+			for _, result := range selfcheck.Check(golden, responses, implemented) {
+				counts[result.Status]++
+				line := fmt.Sprintf("%-16v %v", result.Status, result.Route)
+				if result.Reason != "" {
+					line += ": " + result.Reason
+				}
+				fmt.Println(line)
+				for _, difference := range result.Differences {
+					fmt.Printf("%-16v   %v\n", "", difference)
+				}
+				if result.Status == selfcheck.StatusDrift || result.Status == selfcheck.StatusError {
+					failed++
+				}
+			}
+			fmt.Printf("\n%d ok, %d drift, %d error, %d not implemented, %d skipped\n",
+				counts[selfcheck.StatusOK], counts[selfcheck.StatusDrift], counts[selfcheck.StatusError],
+				counts[selfcheck.StatusNotImplemented], counts[selfcheck.StatusSkipped])
+
+			if failed > 0 {
+				return cli.Exit(fmt.Sprintf("%d routes are not served like CometBFT serves them", failed), 1)
+			}
+			return nil
+		},
+	}
+}
+
+// startScratchChain starts a chain with one validator and the kvstore example app, which runs in the same process,
+// and serves its RPC on a free local port. Transactions are included as soon as they are broadcast.
+// It returns the address of the RPC and the routes it serves.
+func startScratchChain(logger cometlog.Logger) (string, map[string]bool, error) {
+	privVals, genesisValidators, err := genvalidators.Generate(1, []int64{10})
+	if err != nil {
+		return "", nil, err
+	}
+	genesisDoc := &types.GenesisDoc{
+		ChainID:       "selfcheck",
+		GenesisTime:   time.Now(),
+		InitialHeight: 1,
+		Validators:    genesisValidators,
+	}
+	if err := genesisDoc.ValidateAndComplete(); err != nil {
+		return "", nil, err
+	}
+	curState, err := state.MakeGenesisState(genesisDoc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	connectClient := func(appAddress string) (comet_abciclient.Client, error) {
+		client := comet_abciclient.NewLocalClient(nil, kvstore.NewInMemoryApplication())
+		client.SetLogger(logger)
+		return client, client.Start()
+	}
+	clientMap, clientOrder, err := ConnectApps([]string{"kvstore"}, privVals, connectClient, logger)
+	if err != nil {
+		return "", nil, err
+	}
+
+	determinismChecks, err := abci_client.ParseDeterminismChecks("")
+	if err != nil {
+		return "", nil, err
+	}
+	client := abci_client.NewAbciClient(
+		clientMap,
+		logger,
+		curState,
+		&types.Block{},
+		&types.ExtendedCommit{},
+		&storage.MapStorage{},
+		abci_client.NewFixedBlockTimeHandler(time.Second),
+		determinismChecks,
+	)
+	client.ClientOrder = clientOrder
+	client.AutoIncludeTx = true
+
+	if err := client.SendInitChain(curState, genesisDoc); err != nil {
+		return "", nil, fmt.Errorf("error from InitChain: %w", err)
+	}
+	// a block before the block of the transaction, so that the latter has a last commit
+	if err := client.RunBlock(); err != nil {
+		return "", nil, err
+	}
+
+	// find a free port for the RPC
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	listenAddress := listener.Addr().String()
+	listener.Close()
+
+	env := rpc_server.NewEnvironment(client)
+	go rpc_server.StartRPCServerWithDefaultConfig(env, "tcp://"+listenAddress, logger)
+
+	// wait for the RPC to serve requests
+	for i := 0; ; i++ {
+		response, err := http.Get("http://" + listenAddress + "/health")
+		if err == nil {
+			response.Body.Close()
+			break
+		}
+		if i == 50 {
+			return "", nil, fmt.Errorf("the RPC did not start: %w", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	implemented := make(map[string]bool)
+	for route := range env.Routes() {
+		implemented[route] = true
+	}
+	return "tcp://" + listenAddress, implemented, nil
+}
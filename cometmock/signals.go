@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	cometlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/informalsystems/CometMock/cometmock/abci_client"
+)
+
+// reloadAppsOnSIGHUP reloads the apps from the app addresses file whenever CometMock receives SIGHUP.
+func reloadAppsOnSIGHUP(client *abci_client.AbciClient, logger cometlog.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		logger.Info("Received SIGHUP, reloading apps", "file", client.AppAddressesFile)
+		if err := client.ReloadAppsFromFile(); err != nil {
+			logger.Error("Error reloading apps", "err", err)
+		}
+	}
+}
+
+// runBlockOnSIGUSR1 produces a single block whenever CometMock receives SIGUSR1.
+func runBlockOnSIGUSR1(client *abci_client.AbciClient, logger cometlog.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	for range signals {
+		logger.Info("Received SIGUSR1, producing a block")
+		if err := client.RunBlock(); err != nil {
+			logger.Error("Error producing a block", "err", err)
+		}
+	}
+}
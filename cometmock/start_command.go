@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfg "github.com/cometbft/cometbft/config"
+	"github.com/spf13/viper"
+	"github.com/urfave/cli/v2"
+)
+
+// startCommand runs CometMock like `cometbft start`, so that scripts that start CometBFT nodes
+// can start CometMock instead without changing their invocation.
+// The flags of `cometbft start` and the configs in the home folders are translated into the arguments of cometmock,
+// and flags of cometmock are passed on as they are.
+func startCommand() *cli.Command {
+	argumentString := "[--home=<value>] [--proxy_app=<value>] [--rpc.laddr=<value>] [--abci=<value>] [--priv_validator_laddr=<value>] [cometmock flags]"
+
+	return &cli.Command{
+		Name:  "start",
+		Usage: "Run CometMock with the flags and home folder layout of `cometbft start`",
+		Description: `Takes the flags of 'cometbft start' that CometMock needs, and reads the config/config.toml
+of each home folder for those that are not given, like CometBFT:
+  --home                   the home folder of the node, default $CMTHOME or ~/.cometbft.
+                           Can be given more than once, or as a comma-separated list, to mock several nodes.
+  --proxy_app              the address of the app of each node, as a comma-separated list in the order of the homes.
+  --rpc.laddr              the address on which CometMock serves the RPC, default from the config of the first home.
+  --abci                   the connection mode, socket or grpc.
+  --priv_validator_laddr   the addresses of the remote signers, as a comma-separated list in the order of the homes.
+The genesis is read from the genesis_file in the config of the first home.
+Other flags of 'cometbft start', e.g. --p2p.laddr or --log_level, are ignored,
+and flags of cometmock, e.g. --block-time, can be given in addition.`,
+		ArgsUsage: argumentString,
+		// the flags are translated in startArgs, since most of the flags of cometbft are ignored
+		SkipFlagParsing: true,
+		Action: func(c *cli.Context) error {
+			for _, arg := range c.Args().Slice() {
+				if arg == "-h" || arg == "--help" {
+					return cli.ShowSubcommandHelp(c)
+				}
+			}
+
+			args, err := startArgs(c.Args().Slice(), c.App.Flags)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("%v\nUsage: cometmock start %v", err, argumentString), 1)
+			}
+			fmt.Printf("Running cometmock %v\n", strings.Join(args, " "))
+			return c.App.RunContext(c.Context, append([]string{c.App.Name}, args...))
+		},
+	}
+}
+
+// startArgs translates the arguments of `cometbft start` into the arguments of cometmock,
+// where cometMockFlags are the flags of cometmock, which are passed on as they are.
+func startArgs(args []string, cometMockFlags []cli.Flag) ([]string, error) {
+	cometMockFlagsByName := make(map[string]cli.Flag)
+	for _, flag := range cometMockFlags {
+		for _, name := range flag.Names() {
+			cometMockFlagsByName[name] = flag
+		}
+	}
+
+	var homes, proxyApps, privValidatorLaddrs []string
+	var rpcLaddr, abci string
+	var passedOn []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return nil, fmt.Errorf("unexpected argument %v, start takes only flags", arg)
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		cometMockFlag, isCometMockFlag := cometMockFlagsByName[name]
+		// boolean flags only have a value if it is given with =
+		_, isBoolFlag := cometMockFlag.(*cli.BoolFlag)
+		takesValue := !isCometMockFlag || !isBoolFlag
+		if !hasValue && takesValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			value = args[i]
+			hasValue = true
+		}
+
+		switch {
+		case name == "home":
+			homes = append(homes, splitList(value)...)
+		case name == "proxy_app":
+			proxyApps = append(proxyApps, splitList(value)...)
+		case name == "rpc.laddr":
+			rpcLaddr = value
+		case name == "abci":
+			abci = value
+		case name == "priv_validator_laddr":
+			privValidatorLaddrs = append(privValidatorLaddrs, strings.Split(value, ",")...)
+		case name == "log_level":
+			// the format of the levels is the same as for CometBFT, though the modules differ
+			passedOn = append(passedOn, "--log-level="+value)
+		case isCometMockFlag:
+			passedOn = append(passedOn, "--"+name)
+			if hasValue {
+				passedOn[len(passedOn)-1] += "=" + value
+			}
+		default:
+			fmt.Printf("Ignoring flag %v, which CometMock does not support\n", arg)
+		}
+	}
+
+	if len(homes) == 0 {
+		home := os.Getenv("CMTHOME")
+		if home == "" {
+			userHome, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("no home folder given, and the default cannot be found: %w", err)
+			}
+			home = filepath.Join(userHome, cfg.DefaultTendermintDir)
+		}
+		homes = []string{home}
+	}
+
+	configs := make([]*cfg.Config, len(homes))
+	for i, home := range homes {
+		config, err := readConfig(home)
+		if err != nil {
+			return nil, err
+		}
+		configs[i] = config
+	}
+
+	if len(proxyApps) == 0 {
+		for _, config := range configs {
+			proxyApps = append(proxyApps, config.ProxyApp)
+		}
+	}
+	if len(proxyApps) != len(homes) {
+		return nil, fmt.Errorf("got %d app addresses for %d homes, proxy_app needs one address per home", len(proxyApps), len(homes))
+	}
+	for _, proxyApp := range proxyApps {
+		// CometBFT runs the built-in apps itself, but CometMock needs an app to connect to
+		if !strings.Contains(proxyApp, "://") {
+			return nil, fmt.Errorf("the app %v is not an address, CometMock does not support built-in apps", proxyApp)
+		}
+	}
+
+	if rpcLaddr == "" {
+		rpcLaddr = configs[0].RPC.ListenAddress
+	}
+	if abci == "" {
+		abci = configs[0].ABCI
+	}
+	if abci != "socket" && abci != "grpc" {
+		return nil, fmt.Errorf("invalid abci %v, must be either socket or grpc", abci)
+	}
+
+	if len(privValidatorLaddrs) == 0 {
+		for _, config := range configs {
+			privValidatorLaddrs = append(privValidatorLaddrs, config.PrivValidatorListenAddr)
+		}
+	}
+	if strings.Join(privValidatorLaddrs, "") != "" {
+		if len(privValidatorLaddrs) != len(homes) {
+			return nil, fmt.Errorf("got %d remote signer addresses for %d homes, priv_validator_laddr needs one address per home", len(privValidatorLaddrs), len(homes))
+		}
+		passedOn = append(passedOn, "--priv-validator-laddrs="+strings.Join(privValidatorLaddrs, ","))
+	}
+
+	return append(passedOn,
+		strings.Join(proxyApps, ","),
+		configs[0].GenesisFile(),
+		rpcLaddr,
+		strings.Join(homes, ","),
+		abci,
+	), nil
+}
+
+// readConfig reads the config of the node with the given home folder from config/config.toml,
+// like CometBFT. If there is no config file, the default config is used.
+func readConfig(home string) (*cfg.Config, error) {
+	config := cfg.DefaultConfig()
+
+	configFile := filepath.Join(home, cfg.DefaultConfigDir, cfg.DefaultConfigFileName)
+	if _, err := os.Stat(configFile); err == nil {
+		v := viper.New()
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading the config of %v: %w", home, err)
+		}
+		if err := v.Unmarshal(config); err != nil {
+			return nil, fmt.Errorf("error reading the config of %v: %w", home, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading the config of %v: %w", home, err)
+	}
+
+	config.SetRoot(home)
+	return config, nil
+}
+
+// splitList splits a comma-separated list, leaving out empty entries.
+func splitList(s string) []string {
+	var entries []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/protoio"
+	cmtstate "github.com/cometbft/cometbft/proto/tendermint/state"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	cometstate "github.com/cometbft/cometbft/state"
+	"github.com/cometbft/cometbft/types"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// overflowEntry is what is stored for a height: the block, the extended commit, the state and the responses.
+type overflowEntry struct {
+	block          *types.Block
+	extendedCommit *types.ExtendedCommit
+	state          *cometstate.State
+	responses      *abcitypes.ResponseFinalizeBlock
+}
+
+// overflowFile returns the file in the overflow folder that holds the entries of the height.
+func (m *MapStorage) overflowFile(height int64) string {
+	return filepath.Join(m.OverflowDir, fmt.Sprintf("height_%d.pb", height))
+}
+
+// entrySize returns the size in bytes of the protobuf encoding of the entries of a height,
+// which is used to estimate how much memory they take.
+func entrySize(block *types.Block, extendedCommit *types.ExtendedCommit, state *cometstate.State, responses *abcitypes.ResponseFinalizeBlock) int64 {
+	var size int
+	if block != nil {
+		size += block.Size()
+	}
+	if extendedCommit != nil {
+		size += extendedCommit.ToProto().Size()
+	}
+	if state != nil {
+		if stateProto, err := state.ToProto(); err == nil {
+			size += stateProto.Size()
+		}
+	}
+	if responses != nil {
+		size += responses.Size()
+	}
+	return int64(size)
+}
+
+// evict removes the entries of the lowest heights from memory until at most MaxHeights heights are left,
+// writing them to the overflow folder if it is set, and dropping them otherwise.
+// Should only be used while holding the lock of the stateUpdateMutex.
+func (m *MapStorage) evict() error {
+	if m.MaxHeights <= 0 {
+		return nil
+	}
+
+	for len(m.blocks) > m.MaxHeights {
+		lowest := int64(-1)
+		for height := range m.blocks {
+			if lowest < 0 || height < lowest {
+				lowest = height
+			}
+		}
+
+		if m.OverflowDir != "" {
+			if err := m.writeOverflow(lowest); err != nil {
+				return fmt.Errorf("error writing height %v to the overflow folder: %w", lowest, err)
+			}
+			if m.spilled == nil {
+				m.spilled = make(map[int64]bool)
+			}
+			m.spilled[lowest] = true
+		} else {
+			if lowest >= m.prunedBelow {
+				m.prunedBelow = lowest + 1
+			}
+			delete(m.heightsByHash, string(m.blocks[lowest].Hash()))
+		}
+
+		delete(m.blocks, lowest)
+		delete(m.extendedCommits, lowest)
+		delete(m.states, lowest)
+		delete(m.responses, lowest)
+		m.memoryBytes -= m.entrySizes[lowest]
+		delete(m.entrySizes, lowest)
+	}
+	return nil
+}
+
+// writeOverflow writes the entries of the height to its file in the overflow folder,
+// as length-delimited protobuf messages. A missing extended commit is written as an empty one.
+// Should only be used while holding the lock of the stateUpdateMutex.
+func (m *MapStorage) writeOverflow(height int64) error {
+	blockProto, err := m.blocks[height].ToProto()
+	if err != nil {
+		return err
+	}
+	extendedCommitProto := &cmtproto.ExtendedCommit{}
+	if extendedCommit := m.extendedCommits[height]; extendedCommit != nil {
+		extendedCommitProto = extendedCommit.ToProto()
+	}
+	stateProto, err := m.states[height].ToProto()
+	if err != nil {
+		return err
+	}
+	responses := m.responses[height]
+	if responses == nil {
+		responses = &abcitypes.ResponseFinalizeBlock{}
+	}
+
+	var buf bytes.Buffer
+	writer := protoio.NewDelimitedWriter(&buf)
+	for _, msg := range []proto.Message{blockProto, extendedCommitProto, stateProto, responses} {
+		if _, err := writer.WriteMsg(msg); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(m.OverflowDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.overflowFile(height), buf.Bytes(), 0o644)
+}
+
+// readOverflow reads the entries of the height from the overflow folder, if they were written there.
+// It returns nil if they were not.
+// Should only be used while holding a lock of the stateUpdateMutex.
+func (m *MapStorage) readOverflow(height int64) (*overflowEntry, error) {
+	if !m.spilled[height] {
+		return nil, nil
+	}
+	file, err := os.Open(m.overflowFile(height))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blockProto := &cmtproto.Block{}
+	extendedCommitProto := &cmtproto.ExtendedCommit{}
+	stateProto := &cmtstate.State{}
+	responses := &abcitypes.ResponseFinalizeBlock{}
+	reader := protoio.NewDelimitedReader(file, maxOverflowMessageSize)
+	for _, msg := range []proto.Message{blockProto, extendedCommitProto, stateProto, responses} {
+		if _, err := reader.ReadMsg(msg); err != nil {
+			return nil, fmt.Errorf("error reading height %v from the overflow folder: %w", height, err)
+		}
+	}
+
+	entry := &overflowEntry{responses: responses}
+	if entry.block, err = types.BlockFromProto(blockProto); err != nil {
+		return nil, err
+	}
+	if extendedCommitProto.Height != 0 {
+		if entry.extendedCommit, err = types.ExtendedCommitFromProto(extendedCommitProto); err != nil {
+			return nil, err
+		}
+	}
+	if entry.state, err = cometstate.FromProto(stateProto); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// removeOverflow removes the files of the given heights from the overflow folder.
+// Should only be used while holding the lock of the stateUpdateMutex.
+func (m *MapStorage) removeOverflow(heights []int64) {
+	for _, height := range heights {
+		os.Remove(m.overflowFile(height))
+		delete(m.spilled, height)
+	}
+}
+
+// maxOverflowMessageSize is the maximal size of a message in the overflow folder, which is the maximal size of blocks.
+const maxOverflowMessageSize = types.MaxBlockSizeBytes
@@ -1,12 +1,14 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
 	"sync"
 
 	abcitypes "github.com/cometbft/cometbft/abci/types"
 	cometstate "github.com/cometbft/cometbft/state"
 	"github.com/cometbft/cometbft/types"
+	"github.com/informalsystems/CometMock/cometmock/failpoints"
 )
 
 // Storage is an interface for storing blocks, commits and states by height.
@@ -15,9 +17,16 @@ type Storage interface {
 	// GetBlock returns the block at a given height.
 	GetBlock(height int64) (*types.Block, error)
 
+	// GetBlockByHash returns the block with the given hash.
+	GetBlockByHash(hash []byte) (*types.Block, error)
+
 	// GetCommit returns the commit at a given height.
 	GetCommit(height int64) (*types.Commit, error)
 
+	// GetExtendedCommit returns the commit at a given height, including the vote extensions.
+	// If vote extensions were not enabled at the height, the extensions are empty.
+	GetExtendedCommit(height int64) (*types.ExtendedCommit, error)
+
 	// GetState returns the state at a given height. This is the state after
 	// applying the block at that height.
 	GetState(height int64) (*cometstate.State, error)
@@ -32,6 +41,7 @@ type Storage interface {
 	UnlockAfterStateUpdate()
 
 	// UpdateStores updates the storage with the given block, commit, state and responses.
+	// The commit for the height is derived from the extended commit.
 	// It is assumed that the block, commit, state and responses are all from the same height.
 	// If they are not, the storage will be in an inconsistent state.
 	// If the storage is already updated with the given height, the storage will overwrite the existing data.
@@ -41,22 +51,75 @@ type Storage interface {
 	UpdateStores(
 		height int64,
 		block *types.Block,
-		commit *types.Commit,
+		extendedCommit *types.ExtendedCommit,
 		state *cometstate.State,
 		responses *abcitypes.ResponseFinalizeBlock,
 	) error
+
+	// Info returns information about the storage backend, used for diagnostics.
+	Info() Info
+
+	// Reset removes all blocks, commits, states and responses, e.g. when the chain is reset.
+	Reset()
+
+	// DeleteAfter removes the blocks, commits, states and responses of all heights after the given height,
+	// e.g. when the chain is rewound to that height.
+	DeleteAfter(height int64)
+
+	// PruneBelow removes the blocks, commits, states and responses of all heights below the given height,
+	// and reports them as dropped, see Info.PrunedBelow, e.g. when the history of a pruned node was imported.
+	PruneBelow(height int64)
+}
+
+// Info describes a storage backend and the data it holds.
+type Info struct {
+	// a short name of the backend, e.g. "memory"
+	Backend string `json:"backend"`
+	// the number of heights for which blocks are stored
+	NumBlocks int `json:"num_blocks"`
+	// the number of heights whose entries are held in memory, and written to disk, see MapStorage.MaxHeights
+	HeightsInMemory int `json:"heights_in_memory"`
+	HeightsOnDisk   int `json:"heights_on_disk"`
+	// the estimated size in bytes of the entries held in memory
+	MemoryBytes int64 `json:"memory_bytes"`
+	// the maximal number of heights held in memory, or 0 if it is not limited
+	MaxHeights int `json:"max_heights"`
+	// the heights below this were dropped from the storage, or 0 if none were dropped
+	PrunedBelow int64 `json:"pruned_below"`
 }
 
 // MapStorage is a simple in-memory implementation of Storage.
+// The number of heights held in memory can be limited with MaxHeights, so that long-running chains
+// do not grow the memory without bound: the entries of older heights are then written to OverflowDir,
+// from where they are read when they are requested, or dropped if OverflowDir is empty.
 type MapStorage struct {
 	// a mutex that gets locked while the state is being updated,
 	// so that a) updates do not interleave and b) reads do not happen while
 	// the state is being updated, i.e. two stores might give bogus data.
 	stateUpdateMutex sync.RWMutex
 	blocks           map[int64]*types.Block
-	commits          map[int64]*types.Commit
+	extendedCommits  map[int64]*types.ExtendedCommit
 	states           map[int64]*cometstate.State
 	responses        map[int64]*abcitypes.ResponseFinalizeBlock
+	// the heights of the stored blocks by their hash, including those of the blocks in OverflowDir
+	heightsByHash map[string]int64
+
+	// the failpoints that make UpdateStores fail. If this is nil, it does not fail
+	Failpoints *failpoints.Registry
+
+	// If this is positive, at most this many heights are held in memory, and the entries of the lowest heights
+	// are evicted when more are stored.
+	MaxHeights int
+	// the folder that evicted entries are written to, see MaxHeights. If this is empty, they are dropped
+	OverflowDir string
+
+	// the heights whose entries were written to OverflowDir
+	spilled map[int64]bool
+	// the heights below this were dropped, see Info
+	prunedBelow int64
+	// the estimated sizes in bytes of the entries held in memory, by height, and their sum
+	entrySizes  map[int64]int64
+	memoryBytes int64
 }
 
 // ensure MapStorage implements Storage
@@ -66,7 +129,11 @@ func (m *MapStorage) insertBlock(height int64, block *types.Block) error {
 	if m.blocks == nil {
 		m.blocks = make(map[int64]*types.Block)
 	}
+	if m.heightsByHash == nil {
+		m.heightsByHash = make(map[string]int64)
+	}
 	m.blocks[height] = block
+	m.heightsByHash[string(block.Hash())] = height
 	return nil
 }
 
@@ -79,27 +146,65 @@ func (m *MapStorage) GetBlock(height int64) (*types.Block, error) {
 	if block, ok := m.blocks[height]; ok {
 		return block, nil
 	}
+	if entry, err := m.readOverflow(height); err != nil {
+		return nil, err
+	} else if entry != nil {
+		return entry.block, nil
+	}
 	return nil, fmt.Errorf("block for height %v not found", height)
 }
 
-func (m *MapStorage) insertCommit(height int64, commit *types.Commit) error {
-	if m.commits == nil {
-		m.commits = make(map[int64]*types.Commit)
+func (m *MapStorage) GetBlockByHash(hash []byte) (*types.Block, error) {
+	m.stateUpdateMutex.RLock()
+	height, ok := m.heightsByHash[string(hash)]
+	m.stateUpdateMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("block with hash %X not found", hash)
 	}
 
-	m.commits[height] = commit
+	block, err := m.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	// the block at the height may have been replaced since, e.g. after rewinding
+	if !bytes.Equal(block.Hash(), hash) {
+		return nil, fmt.Errorf("block with hash %X not found", hash)
+	}
+	return block, nil
+}
+
+func (m *MapStorage) insertExtendedCommit(height int64, extendedCommit *types.ExtendedCommit) error {
+	if m.extendedCommits == nil {
+		m.extendedCommits = make(map[int64]*types.ExtendedCommit)
+	}
+
+	m.extendedCommits[height] = extendedCommit
 	return nil
 }
 
 func (m *MapStorage) GetCommit(height int64) (*types.Commit, error) {
+	extendedCommit, err := m.GetExtendedCommit(height)
+	if err != nil {
+		return nil, err
+	}
+	return extendedCommit.ToCommit(), nil
+}
+
+func (m *MapStorage) GetExtendedCommit(height int64) (*types.ExtendedCommit, error) {
 	m.stateUpdateMutex.RLock()
 	defer m.stateUpdateMutex.RUnlock()
-	if m.commits == nil {
-		m.commits = make(map[int64]*types.Commit)
+	if m.extendedCommits == nil {
+		m.extendedCommits = make(map[int64]*types.ExtendedCommit)
 	}
 
-	if commit, ok := m.commits[height]; ok {
-		return commit, nil
+	// commits can be missing for blocks that were replayed, see AbciClient.ReplayBlock
+	if extendedCommit, ok := m.extendedCommits[height]; ok && extendedCommit != nil {
+		return extendedCommit, nil
+	}
+	if entry, err := m.readOverflow(height); err != nil {
+		return nil, err
+	} else if entry != nil && entry.extendedCommit != nil {
+		return entry.extendedCommit, nil
 	}
 	return nil, fmt.Errorf("commit for height %v not found", height)
 }
@@ -123,6 +228,11 @@ func (m *MapStorage) GetState(height int64) (*cometstate.State, error) {
 	if state, ok := m.states[height]; ok {
 		return state, nil
 	}
+	if entry, err := m.readOverflow(height); err != nil {
+		return nil, err
+	} else if entry != nil {
+		return entry.state, nil
+	}
 	return nil, fmt.Errorf("state for height %v not found", height)
 }
 
@@ -148,7 +258,12 @@ func (m *MapStorage) GetResponses(height int64) (*abcitypes.ResponseFinalizeBloc
 	if responses, ok := m.responses[height]; ok {
 		return responses, nil
 	}
-	return nil, fmt.Errorf("responses for height %v not found", height)
+	if entry, err := m.readOverflow(height); err != nil {
+		return nil, err
+	} else if entry != nil {
+		return entry.responses, nil
+	}
+	return nil, cometstate.ErrNoABCIResponsesForHeight{Height: height}
 }
 
 func (m *MapStorage) LockBeforeStateUpdate() {
@@ -159,10 +274,138 @@ func (m *MapStorage) UnlockAfterStateUpdate() {
 	m.stateUpdateMutex.Unlock()
 }
 
-func (m *MapStorage) UpdateStores(height int64, block *types.Block, commit *types.Commit, state *cometstate.State, responses *abcitypes.ResponseFinalizeBlock) error {
+func (m *MapStorage) UpdateStores(height int64, block *types.Block, extendedCommit *types.ExtendedCommit, state *cometstate.State, responses *abcitypes.ResponseFinalizeBlock) error {
+	if err := m.Failpoints.Error(failpoints.StorageUpdateStoresError); err != nil {
+		return err
+	}
 	m.insertBlock(height, block)
-	m.insertCommit(height, commit)
+	m.insertExtendedCommit(height, extendedCommit)
 	m.insertState(height, state)
 	m.insertResponses(height, responses)
-	return nil
+
+	if m.entrySizes == nil {
+		m.entrySizes = make(map[int64]int64)
+	}
+	size := entrySize(block, extendedCommit, state, responses)
+	m.memoryBytes += size - m.entrySizes[height]
+	m.entrySizes[height] = size
+	// the height is in memory again if it was overwritten, e.g. after rewinding
+	if m.spilled[height] {
+		m.removeOverflow([]int64{height})
+	}
+	return m.evict()
+}
+
+func (m *MapStorage) Reset() {
+	m.stateUpdateMutex.Lock()
+	defer m.stateUpdateMutex.Unlock()
+
+	m.blocks = nil
+	m.extendedCommits = nil
+	m.states = nil
+	m.responses = nil
+	m.heightsByHash = nil
+
+	spilled := make([]int64, 0, len(m.spilled))
+	for height := range m.spilled {
+		spilled = append(spilled, height)
+	}
+	m.removeOverflow(spilled)
+	m.prunedBelow = 0
+	m.entrySizes = nil
+	m.memoryBytes = 0
+}
+
+func (m *MapStorage) DeleteAfter(height int64) {
+	m.stateUpdateMutex.Lock()
+	defer m.stateUpdateMutex.Unlock()
+
+	for h := range m.blocks {
+		if h > height {
+			delete(m.blocks, h)
+		}
+	}
+	for h := range m.extendedCommits {
+		if h > height {
+			delete(m.extendedCommits, h)
+		}
+	}
+	for h := range m.states {
+		if h > height {
+			delete(m.states, h)
+		}
+	}
+	for h := range m.responses {
+		if h > height {
+			delete(m.responses, h)
+		}
+	}
+	for h, size := range m.entrySizes {
+		if h > height {
+			m.memoryBytes -= size
+			delete(m.entrySizes, h)
+		}
+	}
+	m.deleteHashes(func(h int64) bool { return h > height })
+
+	spilled := make([]int64, 0)
+	for h := range m.spilled {
+		if h > height {
+			spilled = append(spilled, h)
+		}
+	}
+	m.removeOverflow(spilled)
+}
+
+func (m *MapStorage) PruneBelow(height int64) {
+	m.stateUpdateMutex.Lock()
+	defer m.stateUpdateMutex.Unlock()
+
+	for h := range m.blocks {
+		if h < height {
+			delete(m.blocks, h)
+			delete(m.extendedCommits, h)
+			delete(m.states, h)
+			delete(m.responses, h)
+			m.memoryBytes -= m.entrySizes[h]
+			delete(m.entrySizes, h)
+		}
+	}
+
+	spilled := make([]int64, 0)
+	for h := range m.spilled {
+		if h < height {
+			spilled = append(spilled, h)
+		}
+	}
+	m.removeOverflow(spilled)
+	m.deleteHashes(func(h int64) bool { return h < height })
+	if height > m.prunedBelow {
+		m.prunedBelow = height
+	}
+}
+
+// deleteHashes removes the hashes of the blocks at the heights for which remove returns true from heightsByHash.
+// Should only be used while holding the lock of the stateUpdateMutex.
+func (m *MapStorage) deleteHashes(remove func(height int64) bool) {
+	for hash, h := range m.heightsByHash {
+		if remove(h) {
+			delete(m.heightsByHash, hash)
+		}
+	}
+}
+
+func (m *MapStorage) Info() Info {
+	m.stateUpdateMutex.RLock()
+	defer m.stateUpdateMutex.RUnlock()
+
+	return Info{
+		Backend:         "memory",
+		NumBlocks:       len(m.blocks) + len(m.spilled),
+		HeightsInMemory: len(m.blocks),
+		HeightsOnDisk:   len(m.spilled),
+		MemoryBytes:     m.memoryBytes,
+		MaxHeights:      m.MaxHeights,
+		PrunedBelow:     m.prunedBelow,
+	}
 }
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/crypto/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// the prefixes with which Amino encodes public keys, which are part of bech32 public keys
+var (
+	aminoPrefixEd25519   = []byte{0x16, 0x24, 0xDE, 0x64, 0x20}
+	aminoPrefixSecp256k1 = []byte{0xEB, 0x5A, 0xE9, 0x87, 0x21}
+)
+
+// ValidatorAddress returns the address of a validator in the format in which CometMock identifies validators,
+// i.e. as uppercase hex, given the validator in any of the formats in which users commonly have it at hand:
+//   - the hex address, in any case
+//   - a bech32 consensus address, e.g. cosmosvalcons1...
+//   - the base64 encoded consensus public key, e.g. from the validators endpoint or priv_validator_key.json
+//   - a bech32 consensus public key, e.g. cosmosvalconspub1..., as shown by older versions of the Cosmos SDK
+//
+// Other identifiers are returned unchanged, e.g. the ids of observers, so that they fail
+// wherever they are looked up like any other unknown address.
+func ValidatorAddress(identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+
+	if bz, err := hex.DecodeString(identifier); err == nil && len(bz) == crypto.AddressSize {
+		return strings.ToUpper(identifier)
+	}
+
+	if _, bz, err := bech32.DecodeAndConvert(identifier); err == nil {
+		if len(bz) == crypto.AddressSize {
+			return crypto.Address(bz).String()
+		}
+		if address, ok := pubKeyAddress(bz); ok {
+			return address
+		}
+	}
+
+	if bz, err := base64.StdEncoding.DecodeString(identifier); err == nil {
+		if address, ok := pubKeyAddress(bz); ok {
+			return address
+		}
+	}
+
+	return identifier
+}
+
+// pubKeyAddress returns the address of the public key, given as raw bytes or as Amino encoded bytes.
+func pubKeyAddress(bz []byte) (string, bool) {
+	switch {
+	case len(bz) == ed25519.PubKeySize:
+		return ed25519.PubKey(bz).Address().String(), true
+	case len(bz) == secp256k1.PubKeySize:
+		return secp256k1.PubKey(bz).Address().String(), true
+	case len(bz) == len(aminoPrefixEd25519)+ed25519.PubKeySize && bytes.HasPrefix(bz, aminoPrefixEd25519):
+		return ed25519.PubKey(bz[len(aminoPrefixEd25519):]).Address().String(), true
+	case len(bz) == len(aminoPrefixSecp256k1)+secp256k1.PubKeySize && bytes.HasPrefix(bz, aminoPrefixSecp256k1):
+		return secp256k1.PubKey(bz[len(aminoPrefixSecp256k1):]).Address().String(), true
+	}
+	return "", false
+}
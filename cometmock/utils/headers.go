@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/cometbft/cometbft/types"
+)
+
+type headerContextKey struct{}
+
+// ContextWithHeader returns a context that carries the header of the block a request is about,
+// for ABCI clients that need more of the block than the request contains,
+// e.g. clients of legacy apps that expect the header in BeginBlock.
+func ContextWithHeader(ctx context.Context, header *types.Header) context.Context {
+	return context.WithValue(ctx, headerContextKey{}, header)
+}
+
+// HeaderFromContext returns the header carried by the context, or nil if there is none.
+func HeaderFromContext(ctx context.Context) *types.Header {
+	header, _ := ctx.Value(headerContextKey{}).(*types.Header)
+	return header
+}
@@ -0,0 +1,205 @@
+// Package validatorkeys reads the keys of the validators that CometMock signs for from several sources,
+// e.g. from node homes, from priv_validator_key.json files, or from seeds in flags and environment variables,
+// so that test setups that generate keys in memory do not need to write node homes to disk, see --validator-keys.
+package validatorkeys
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/cometbft/cometbft/privval"
+)
+
+// The prefixes of the sources of keys, see Parse.
+const (
+	// home:<node home> reads the key from the node home, whose signing state is persisted, like for node homes given as argument
+	SourceHome = "home:"
+	// file:<path> reads the key from a priv_validator_key.json file
+	SourceFile = "file:"
+	// seed:<seed> is a raw ed25519 seed of 32 bytes, or an ed25519 private key of 64 bytes, hex or base64 encoded
+	SourceSeed = "seed:"
+	// env:<name> reads a seed, a priv_validator_key.json document or a bundle from an environment variable
+	SourceEnv = "env:"
+	// bundle:<path> reads many keys from a JSON file, see ParseBundle
+	SourceBundle = "bundle:"
+)
+
+// Key is the key of a validator, with the node home it was read from, if any.
+type Key struct {
+	PrivKey crypto.PrivKey
+	// the node home with the key, for keys from SourceHome, where the signing state of the validator is persisted
+	Home string
+}
+
+// HomeSources returns the sources of the keys of the given node homes.
+func HomeSources(homes []string) string {
+	sources := make([]string, len(homes))
+	for i, home := range homes {
+		sources[i] = SourceHome + home
+	}
+	return strings.Join(sources, ",")
+}
+
+// Parse reads the keys from the comma-separated list of sources, in order, where each source is one of
+// SourceHome, SourceFile, SourceSeed, SourceEnv or SourceBundle followed by its value.
+// Bundles give several keys, all other sources one. getenv looks up environment variables, e.g. os.Getenv,
+// and homeKeyFile returns the priv_validator_key.json file of a node home.
+func Parse(sources string, getenv func(string) string, homeKeyFile func(home string) (string, error)) ([]Key, error) {
+	var keys []Key
+	for _, source := range strings.Split(sources, ",") {
+		var sourceKeys []Key
+		var err error
+		switch {
+		case strings.HasPrefix(source, SourceHome):
+			home := strings.TrimPrefix(source, SourceHome)
+			var keyFile string
+			keyFile, err = homeKeyFile(home)
+			if err == nil {
+				var privKey crypto.PrivKey
+				privKey, err = ReadKeyFile(keyFile)
+				sourceKeys = []Key{{PrivKey: privKey, Home: home}}
+			}
+		case strings.HasPrefix(source, SourceFile):
+			var privKey crypto.PrivKey
+			privKey, err = ReadKeyFile(strings.TrimPrefix(source, SourceFile))
+			sourceKeys = []Key{{PrivKey: privKey}}
+		case strings.HasPrefix(source, SourceSeed):
+			var privKey crypto.PrivKey
+			privKey, err = DecodeSeed(strings.TrimPrefix(source, SourceSeed))
+			sourceKeys = []Key{{PrivKey: privKey}}
+		case strings.HasPrefix(source, SourceEnv):
+			name := strings.TrimPrefix(source, SourceEnv)
+			value := strings.TrimSpace(getenv(name))
+			if value == "" {
+				err = fmt.Errorf("environment variable %v is not set", name)
+			} else {
+				sourceKeys, err = decode([]byte(value))
+			}
+		case strings.HasPrefix(source, SourceBundle):
+			var bundle []byte
+			bundle, err = os.ReadFile(strings.TrimPrefix(source, SourceBundle))
+			if err == nil {
+				sourceKeys, err = ParseBundle(bundle)
+			}
+		default:
+			err = fmt.Errorf("unknown source, must start with one of %v, %v, %v, %v or %v",
+				SourceHome, SourceFile, SourceSeed, SourceEnv, SourceBundle)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading the keys of %v: %w", describe(source), err)
+		}
+		keys = append(keys, sourceKeys...)
+	}
+	return keys, nil
+}
+
+// ParseBundle reads the keys of a bundle, which is a JSON array whose entries are either
+// priv_validator_key.json documents or seeds as strings, see SourceSeed.
+func ParseBundle(bundle []byte) ([]Key, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(bundle, &entries); err != nil {
+		return nil, fmt.Errorf("a bundle needs to be a JSON array: %w", err)
+	}
+
+	keys := make([]Key, len(entries))
+	for i, entry := range entries {
+		var privKey crypto.PrivKey
+		var err error
+		var seed string
+		if json.Unmarshal(entry, &seed) == nil {
+			privKey, err = DecodeSeed(seed)
+		} else {
+			privKey, err = decodeKeyDocument(entry)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading entry %d of the bundle: %w", i, err)
+		}
+		keys[i] = Key{PrivKey: privKey}
+	}
+	return keys, nil
+}
+
+// ReadKeyFile reads the private key from a priv_validator_key.json file.
+func ReadKeyFile(path string) (crypto.PrivKey, error) {
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKeyDocument(document)
+}
+
+// DecodeSeed decodes a hex or base64 encoded ed25519 seed of 32 bytes, or ed25519 private key of 64 bytes.
+func DecodeSeed(seed string) (crypto.PrivKey, error) {
+	seed = strings.TrimSpace(seed)
+	bz, err := hex.DecodeString(seed)
+	if err != nil {
+		bz, err = base64.StdEncoding.DecodeString(seed)
+		if err != nil {
+			return nil, fmt.Errorf("a seed needs to be hex or base64 encoded")
+		}
+	}
+
+	switch len(bz) {
+	case stded25519.SeedSize:
+		return ed25519.PrivKey(stded25519.NewKeyFromSeed(bz)), nil
+	case stded25519.PrivateKeySize:
+		return ed25519.PrivKey(bz), nil
+	default:
+		return nil, fmt.Errorf("a seed needs to have %d bytes, or a private key %d bytes, but got %d bytes",
+			stded25519.SeedSize, stded25519.PrivateKeySize, len(bz))
+	}
+}
+
+// decode reads the keys from the value of an environment variable, which is either a priv_validator_key.json document,
+// a bundle, or a seed.
+func decode(value []byte) ([]Key, error) {
+	switch value[0] {
+	case '{':
+		privKey, err := decodeKeyDocument(value)
+		if err != nil {
+			return nil, err
+		}
+		return []Key{{PrivKey: privKey}}, nil
+	case '[':
+		return ParseBundle(value)
+	default:
+		privKey, err := DecodeSeed(string(value))
+		if err != nil {
+			return nil, err
+		}
+		return []Key{{PrivKey: privKey}}, nil
+	}
+}
+
+// decodeKeyDocument reads the private key from a priv_validator_key.json document.
+func decodeKeyDocument(document []byte) (crypto.PrivKey, error) {
+	var key privval.FilePVKey
+	if err := cmtjson.Unmarshal(document, &key); err != nil {
+		return nil, fmt.Errorf("error reading the priv_validator_key.json document: %w", err)
+	}
+	if key.PrivKey == nil {
+		return nil, fmt.Errorf("the priv_validator_key.json document has no priv_key")
+	}
+	if key.Address != nil && !bytes.Equal(key.PrivKey.PubKey().Address(), key.Address) {
+		return nil, fmt.Errorf("the address %v of the priv_validator_key.json document does not match its priv_key, whose address is %v",
+			key.Address, key.PrivKey.PubKey().Address())
+	}
+	return key.PrivKey, nil
+}
+
+// describe returns the source without its value if the value is secret, so that it can be part of errors.
+func describe(source string) string {
+	if strings.HasPrefix(source, SourceSeed) {
+		return SourceSeed + "..."
+	}
+	return source
+}
@@ -0,0 +1,93 @@
+// Package version holds the version of CometMock, the commit it was built from,
+// and the versions of CometBFT and ABCI that it mocks.
+package version
+
+import (
+	"regexp"
+	"runtime"
+	"runtime/debug"
+
+	cmtversion "github.com/cometbft/cometbft/version"
+)
+
+// Version is the version of CometMock.
+// Release builds can set it with -ldflags "-X github.com/informalsystems/CometMock/cometmock/version.Version=...".
+var Version = "v0.38.x"
+
+// GitCommit is the git commit CometMock was built from. It is set with -ldflags like Version, see the Makefile.
+// If it is empty, the commit is taken from the version control information that go build embeds, if any.
+var GitCommit = ""
+
+// Info describes a build of CometMock.
+type Info struct {
+	// the version of CometMock
+	Version string `json:"version"`
+	// the git commit CometMock was built from, with the suffix -dirty if there were uncommitted changes,
+	// or empty if it is not known
+	GitCommit string `json:"git_commit"`
+	// the version of CometBFT that CometMock mocks
+	CometBFTVersion string `json:"cometbft_version"`
+	// the version of ABCI that CometMock speaks to apps
+	ABCIVersion string `json:"abci_version"`
+	// the versions of the block and p2p protocols of CometBFT
+	BlockProtocol uint64 `json:"block_protocol"`
+	P2PProtocol   uint64 `json:"p2p_protocol"`
+	// the version of Go CometMock was built with
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the information about this build of CometMock.
+func Get() Info {
+	return Info{
+		Version:         Version,
+		GitCommit:       Commit(),
+		CometBFTVersion: cmtversion.TMCoreSemVer,
+		ABCIVersion:     cmtversion.ABCISemVer,
+		BlockProtocol:   cmtversion.BlockProtocol,
+		P2PProtocol:     cmtversion.P2PProtocol,
+		GoVersion:       runtime.Version(),
+	}
+}
+
+// Commit returns the git commit CometMock was built from, see GitCommit.
+func Commit() string {
+	if GitCommit != "" {
+		return GitCommit
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision string
+	var modified bool
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// characters that are not allowed in the build metadata of semantic versions
+var invalidBuildMetadata = regexp.MustCompile(`[^0-9A-Za-z.-]`)
+
+// NodeVersion returns the version that the status endpoint reports for the node: the given version of CometBFT,
+// with the version and the short commit of CometMock as semver build metadata, e.g. 0.38.0+cometmock.v0.38.x.1a2b3c4.
+// Build metadata does not change the precedence of versions, so clients that check the version of CometBFT still work.
+func NodeVersion(cometBFTVersion string) string {
+	metadata := "cometmock." + Version
+	if commit := Commit(); commit != "" {
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		metadata += "." + commit
+	}
+	return cometBFTVersion + "+" + invalidBuildMetadata.ReplaceAllString(metadata, "-")
+}
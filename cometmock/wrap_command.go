@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+)
+
+// wrapCommand runs the binary of an app in place of the binary itself, so that images of apps for testing frameworks
+// like interchaintest, which run the binary of the app for all commands, can run CometMock in place of CometBFT.
+// All commands are passed on to the app, except start, which starts the app without CometBFT and CometMock
+// over the home folder of the node, like the start subcommand.
+func wrapCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "wrap",
+		Usage:     "Run the binary of an app, starting CometMock in place of CometBFT when the app is started",
+		ArgsUsage: "[--app-start-flags=<value>] <app-binary> [app arguments]",
+		Description: `Runs the binary of the app with the given arguments, and exits with its exit code,
+so that the binary of the app in an image can be replaced by a script that runs 'cometmock wrap <app-binary> "$@"'.
+When the app is started with 'start', e.g. 'simd start --home /var/cosmos-chain/simd', the app is started
+without CometBFT, listening on the proxy_app address with the abci connection mode from the config/config.toml
+of the home folder, and CometMock is started over the same home folder, like 'cometmock start --home <home>'.
+The flags of cometmock and the flags of 'cometbft start' that the start subcommand takes are passed to CometMock,
+--home is passed to both, and all other flags are passed to the app.
+Only one home folder is supported, so the chain needs to have a single validator.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "app-start-flags",
+				Usage: "The comma-separated flags that start the app without CometBFT, e.g. --with-comet=false for Cosmos SDK v0.50 apps.",
+				Value: "--with-tendermint=false",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return cli.Exit("Not enough arguments.\nUsage: cometmock wrap "+c.Command.ArgsUsage, 1)
+			}
+			appBinary := c.Args().First()
+			args := c.Args().Tail()
+
+			if len(args) == 0 || args[0] != "start" {
+				return runApp(appBinary, args)
+			}
+
+			appArgs, cometMockArgs, err := wrappedStartArgs(args[1:], c.App.Flags)
+			if err != nil {
+				return cli.Exit(err.Error(), 1)
+			}
+			appArgs = append(append([]string{"start"}, appArgs...), splitList(c.String("app-start-flags"))...)
+
+			app := exec.Command(appBinary, appArgs...)
+			app.Stdin, app.Stdout, app.Stderr = os.Stdin, os.Stdout, os.Stderr
+			fmt.Printf("Running %v %v\n", appBinary, strings.Join(appArgs, " "))
+			if err := app.Start(); err != nil {
+				return cli.Exit(fmt.Sprintf("Error starting the app: %v", err), 1)
+			}
+
+			// the process ends with the app, which is stopped when the container is stopped
+			go forwardSignals(app.Process)
+			go func() {
+				err := app.Wait()
+				fmt.Printf("The app exited: %v\n", app.ProcessState)
+				os.Exit(exitCode(err))
+			}()
+
+			fmt.Printf("Running cometmock %v\n", strings.Join(cometMockArgs, " "))
+			err = c.App.RunContext(c.Context, append([]string{c.App.Name}, cometMockArgs...))
+			_ = app.Process.Kill()
+			return err
+		},
+	}
+}
+
+// wrappedStartArgs splits the arguments of the start command of a wrapped app into the arguments of the app
+// and the arguments of cometmock, where cometMockFlags are the flags of cometmock.
+// The app gets the address and the connection mode that CometMock connects to it with.
+func wrappedStartArgs(args []string, cometMockFlags []cli.Flag) ([]string, []string, error) {
+	cometMockFlagsByName := make(map[string]cli.Flag)
+	for _, flag := range cometMockFlags {
+		for _, name := range flag.Names() {
+			cometMockFlagsByName[name] = flag
+		}
+	}
+
+	var appArgs, startCommandArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		cometMockFlag, isCometMockFlag := cometMockFlagsByName[name]
+		isStartFlag := name == "home" || name == "proxy_app" || name == "rpc.laddr" || name == "abci" || name == "priv_validator_laddr"
+		if !strings.HasPrefix(arg, "-") || (!isCometMockFlag && !isStartFlag) {
+			appArgs = append(appArgs, arg)
+			continue
+		}
+
+		flagArgs := []string{arg}
+		// boolean flags only have a value if it is given with =
+		_, isBoolFlag := cometMockFlag.(*cli.BoolFlag)
+		if !hasValue && !isBoolFlag && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			flagArgs = append(flagArgs, args[i])
+		}
+		startCommandArgs = append(startCommandArgs, flagArgs...)
+		if name == "home" {
+			appArgs = append(appArgs, flagArgs...)
+		}
+	}
+
+	cometMockArgs, err := startArgs(startCommandArgs, cometMockFlags)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the positional arguments of cometmock are the app addresses, the genesis, the listen address,
+	// the home folders and the connection mode
+	positional := cometMockArgs[len(cometMockArgs)-5:]
+	if homes := splitList(positional[3]); len(homes) != 1 {
+		return nil, nil, fmt.Errorf("got %d homes, but a wrapped app has exactly one home", len(homes))
+	}
+	appArgs = append(appArgs, "--address="+positional[0], "--transport="+positional[4])
+	return appArgs, cometMockArgs, nil
+}
+
+// runApp runs the binary of the app with the given arguments, and exits with its exit code.
+func runApp(appBinary string, args []string) error {
+	app := exec.Command(appBinary, args...)
+	app.Stdin, app.Stdout, app.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := app.Start(); err != nil {
+		return cli.Exit(fmt.Sprintf("Error running the app: %v", err), 1)
+	}
+	go forwardSignals(app.Process)
+	if code := exitCode(app.Wait()); code != 0 {
+		return cli.Exit("", code)
+	}
+	return nil
+}
+
+// forwardSignals forwards the signals that stop a process to the given process.
+func forwardSignals(process *os.Process) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	for sig := range signals {
+		_ = process.Signal(sig)
+	}
+}
+
+// exitCode returns the exit code of a process from the error of waiting for it.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if code := exitErr.ExitCode(); code >= 0 {
+			return code
+		}
+		return 1
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -334,3 +334,25 @@ func TestSystemStartingTime(t *testing.T) {
 
 	require.True(t, diff <= delta, "expectedTime: %v, blockTime: %v", expectedTime, blockTime)
 }
+
+// TestRunBlock checks that run_block produces a single block
+// with the given timestamp.
+func TestRunBlock(t *testing.T) {
+	err := StartChain(t, "--block-production-interval=-1")
+	if err != nil {
+		t.Fatalf("Error starting chain: %v", err)
+	}
+
+	height, blockTime, err := GetHeightAndTime()
+	require.NoError(t, err)
+
+	expectedTime := blockTime.Add(time.Hour).UTC()
+	err = RunBlock(fmt.Sprintf(`{"time": "%v", "round": "1"}`, expectedTime.Format(time.RFC3339Nano)))
+	require.NoError(t, err)
+
+	height2, blockTime2, err := GetHeightAndTime()
+	require.NoError(t, err)
+
+	require.Equal(t, height+1, height2)
+	require.True(t, expectedTime.Equal(blockTime2), "expected time %v, got %v", expectedTime, blockTime2)
+}
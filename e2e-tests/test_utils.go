@@ -164,3 +164,18 @@ func AdvanceBlocks(numBlocks int) error {
 	_, err := runCommandWithOutput(cmd)
 	return err
 }
+
+// RunBlock calls the run_block endpoint with the given json params, e.g. {"time": "2023-01-01T00:00:00Z"}.
+func RunBlock(params string) error {
+	stringCmd := fmt.Sprintf("curl -H 'Content-Type: application/json' -H 'Accept:application/json' --data '{\"jsonrpc\":\"2.0\",\"method\":\"run_block\",\"params\":%v,\"id\":1}' 127.0.0.1:22331", params)
+
+	cmd := exec.Command("bash", "-c", stringCmd)
+	out, err := runCommandWithOutput(cmd)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(out, "\"error\"") {
+		return fmt.Errorf("run_block returned an error: %v", out)
+	}
+	return nil
+}